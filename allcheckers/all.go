@@ -36,4 +36,7 @@ var FSMCheckers = checkers.NewParallelChecker(
 	fsmchecker.ValidMaxRevision,
 	fsmchecker.ValidResourcesSet,
 	fsmchecker.ValidPrecondition,
+	fsmchecker.SanePrecondition,
+	fsmchecker.NeverAllocatable,
+	fsmchecker.FeasibleResources,
 )
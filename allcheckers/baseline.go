@@ -0,0 +1,125 @@
+package allcheckers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+)
+
+// BaselineVersion is the version of the baseline file format.
+const BaselineVersion = 1
+
+// Baseline is the known problems. Problems in the baseline are not reported, so that only new problems fail.
+type Baseline struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry is a known problem. The same problems have the same entries as many as the problems.
+type BaselineEntry struct {
+	ProblemID checkers.ProblemID `json:"problem_id"`
+
+	// Fingerprint is the hash of the problem ID and the locations. It does not depend on the order of the locations.
+	Fingerprint string `json:"fingerprint"`
+
+	// Locations is the locations for humans. It is not used for matching.
+	Locations string `json:"locations"`
+}
+
+func compareBaselineEntry(a, b BaselineEntry) int {
+	if c := strings.Compare(string(a.ProblemID), string(b.ProblemID)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Locations, b.Locations); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Fingerprint, b.Fingerprint)
+}
+
+// NewBaseline returns the baseline of the problems. Entries are sorted to make diffs of baseline files small.
+func NewBaseline(ps []checkers.Problem) *Baseline {
+	entries := make([]BaselineEntry, 0, len(ps))
+	for _, problem := range ps {
+		entries = append(entries, NewBaselineEntry(problem))
+	}
+	slices.SortFunc(entries, compareBaselineEntry)
+	return &Baseline{Version: BaselineVersion, Entries: entries}
+}
+
+// NewBaselineEntry returns the entry of the problem.
+func NewBaselineEntry(problem checkers.Problem) BaselineEntry {
+	sb := &strings.Builder{}
+	locs := make([]string, 0, len(problem.Locations))
+	for _, loc := range problem.Locations {
+		sb.Reset()
+		loc.Write(sb)
+		locs = append(locs, sb.String())
+	}
+	slices.Sort(locs)
+	locations := strings.Join(locs, ", ")
+
+	h := sha256.New()
+	io.WriteString(h, string(problem.ProblemID))
+	h.Write([]byte{0})
+	io.WriteString(h, locations)
+
+	return BaselineEntry{
+		ProblemID:   problem.ProblemID,
+		Fingerprint: hex.EncodeToString(h.Sum(nil)),
+		Locations:   locations,
+	}
+}
+
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("allcheckers.ReadBaseline: %w", err)
+	}
+	if b.Version != BaselineVersion {
+		return nil, fmt.Errorf("allcheckers.ReadBaseline: unsupported version: %d", b.Version)
+	}
+	return &b, nil
+}
+
+func WriteBaseline(w io.Writer, b *Baseline) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(b); err != nil {
+		return fmt.Errorf("allcheckers.WriteBaseline: %w", err)
+	}
+	return nil
+}
+
+// Diff returns the problems not in the baseline and the entries that match no problems. Each entry matches only one
+// problem.
+func (b *Baseline) Diff(ps []checkers.Problem) ([]checkers.Problem, []BaselineEntry) {
+	remaining := make(map[string]int, len(b.Entries))
+	for _, entry := range b.Entries {
+		remaining[entry.Fingerprint]++
+	}
+
+	newProblems := make([]checkers.Problem, 0)
+	for _, problem := range ps {
+		fingerprint := NewBaselineEntry(problem).Fingerprint
+		if remaining[fingerprint] > 0 {
+			remaining[fingerprint]--
+			continue
+		}
+		newProblems = append(newProblems, problem)
+	}
+
+	fixed := make([]BaselineEntry, 0)
+	for _, entry := range b.Entries {
+		if remaining[entry.Fingerprint] > 0 {
+			remaining[entry.Fingerprint]--
+			fixed = append(fixed, entry)
+		}
+	}
+	return newProblems, fixed
+}
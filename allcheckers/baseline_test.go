@@ -0,0 +1,100 @@
+package allcheckers
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewBaselineEntry(t *testing.T) {
+	a := checkers.NewProblem("weak-conn", checkers.SeverityWarning, pfdcommon.NewLocations(
+		pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "P1", "P2"),
+		pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"),
+	)...)
+	b := checkers.NewProblem("weak-conn", checkers.SeverityError, pfdcommon.NewLocations(
+		pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"),
+		pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "P1", "P2"),
+	)...)
+
+	entry := NewBaselineEntry(a)
+	if entry.Locations != "PFD[D1], PFD[P1, P2]" {
+		t.Errorf("locations = %q, want %q", entry.Locations, "PFD[D1], PFD[P1, P2]")
+	}
+	if entry != NewBaselineEntry(b) {
+		t.Errorf("entries of the same problem in different orders differ: %v, %v", entry, NewBaselineEntry(b))
+	}
+}
+
+func TestBaselineDiff(t *testing.T) {
+	noDesc := func(id pfd.NodeID) checkers.Problem {
+		return checkers.NewProblem("no-desc", checkers.SeverityWarning, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, id))...)
+	}
+
+	testCases := map[string]struct {
+		Baseline      []checkers.Problem
+		Problems      []checkers.Problem
+		ExpectedNew   []checkers.Problem
+		ExpectedFixed []BaselineEntry
+	}{
+		"empty baseline": {
+			Baseline:      []checkers.Problem{},
+			Problems:      []checkers.Problem{noDesc("D1")},
+			ExpectedNew:   []checkers.Problem{noDesc("D1")},
+			ExpectedFixed: []BaselineEntry{},
+		},
+		"known": {
+			Baseline:      []checkers.Problem{noDesc("D1")},
+			Problems:      []checkers.Problem{noDesc("D1")},
+			ExpectedNew:   []checkers.Problem{},
+			ExpectedFixed: []BaselineEntry{},
+		},
+		"fixed": {
+			Baseline:      []checkers.Problem{noDesc("D1"), noDesc("D2")},
+			Problems:      []checkers.Problem{noDesc("D2"), noDesc("D3")},
+			ExpectedNew:   []checkers.Problem{noDesc("D3")},
+			ExpectedFixed: []BaselineEntry{NewBaselineEntry(noDesc("D1"))},
+		},
+		"duplicated": {
+			Baseline:      []checkers.Problem{noDesc("D1")},
+			Problems:      []checkers.Problem{noDesc("D1"), noDesc("D1")},
+			ExpectedNew:   []checkers.Problem{noDesc("D1")},
+			ExpectedFixed: []BaselineEntry{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actualNew, actualFixed := NewBaseline(testCase.Baseline).Diff(testCase.Problems)
+			if !reflect.DeepEqual(actualNew, testCase.ExpectedNew) {
+				t.Error(cmp.Diff(testCase.ExpectedNew, actualNew))
+			}
+			if !reflect.DeepEqual(actualFixed, testCase.ExpectedFixed) {
+				t.Error(cmp.Diff(testCase.ExpectedFixed, actualFixed))
+			}
+		})
+	}
+}
+
+func TestReadBaseline(t *testing.T) {
+	expected := NewBaseline([]checkers.Problem{
+		checkers.NewProblem("no-desc", checkers.SeverityWarning, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"))...),
+	})
+
+	buf := &bytes.Buffer{}
+	if err := WriteBaseline(buf, expected); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := ReadBaseline(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error(cmp.Diff(expected, actual))
+	}
+}
@@ -0,0 +1,331 @@
+package allcheckers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdmetrics"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/Kuniwak/pfd-tools/userrules"
+	"golang.org/x/sync/errgroup"
+)
+
+// Config is the configuration of the rules. It is the "lint" section of run configs.
+type Config struct {
+	// Rules maps problem IDs to the configurations. Problems of the other IDs are reported as is.
+	Rules map[checkers.ProblemID]RuleConfig `json:"rules"`
+
+	// UserRules are the project-specific rules. Rules and suppressions can refer to the IDs of them.
+	UserRules []userrules.Rule `json:"user_rules,omitempty"`
+
+	// Metrics is the thresholds of the structural metrics. Metrics out of the thresholds are reported as warnings. It
+	// is nil if the metrics are not checked.
+	Metrics *pfdmetrics.Thresholds `json:"metrics,omitempty"`
+}
+
+// MetricThresholds returns the thresholds of the structural metrics. The config may be nil.
+func (c *Config) MetricThresholds() *pfdmetrics.Thresholds {
+	if c == nil {
+		return nil
+	}
+	return c.Metrics
+}
+
+// CompileUserRules validates the user-defined rules. The config may be nil. IDs of the rules should differ from the
+// built-in ones.
+func (c *Config) CompileUserRules() ([]*userrules.CompiledRule, error) {
+	if c == nil {
+		return nil, nil
+	}
+	for _, r := range c.UserRules {
+		if IsKnownProblemID(r.ID) {
+			return nil, fmt.Errorf("allcheckers.Config.CompileUserRules: conflicted with the built-in problem ID: %q", r.ID)
+		}
+	}
+	rules, err := userrules.Compile(c.UserRules)
+	if err != nil {
+		return nil, fmt.Errorf("allcheckers.Config.CompileUserRules: %w", err)
+	}
+	return rules, nil
+}
+
+// isKnownProblemID returns true if the problem ID is reported by any checkers including the user-defined rules.
+func (c *Config) isKnownProblemID(id checkers.ProblemID) bool {
+	if IsKnownProblemID(id) {
+		return true
+	}
+	if c == nil {
+		return false
+	}
+	return slices.ContainsFunc(c.UserRules, func(r userrules.Rule) bool { return r.ID == id })
+}
+
+// RuleConfig is the configuration of the problems of a problem ID.
+type RuleConfig struct {
+	// Disabled suppresses all the problems.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Severity overrides the severity of the problems if not empty. Available values are ERROR, WARNING and
+	// STYLE_PROBLEM.
+	Severity string `json:"severity,omitempty"`
+
+	// Scope limits the problems to the ones related to the composite processes if not empty. Nodes in the composite
+	// processes and deliverables input to or output from them are related.
+	Scope []pfd.NodeID `json:"scope,omitempty"`
+}
+
+// SuppressionDirective is the directive to suppress problems. Write it on comment layers of draw.io files like
+// "pfdlint:ignore no-desc D7".
+const SuppressionDirective = "pfdlint:ignore"
+
+// Suppression suppresses the problems of the problem ID related to any of the IDs. Suppressions without IDs suppress
+// all the problems of the problem ID.
+type Suppression struct {
+	ProblemID checkers.ProblemID
+	IDs       []string
+}
+
+// ParseSuppressions parses the lines starting with SuppressionDirective. The IDs are separated by spaces or commas.
+// The other lines are ignored.
+func ParseSuppressions(text string) ([]Suppression, error) {
+	res := make([]Suppression, 0)
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return unicode.IsSpace(r) || r == ','
+		})
+		if len(fields) == 0 || fields[0] != SuppressionDirective {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("allcheckers.ParseSuppressions: missing problem ID: %q", line)
+		}
+		res = append(res, Suppression{ProblemID: checkers.ProblemID(fields[1]), IDs: fields[2:]})
+	}
+	return res, nil
+}
+
+// DiagramSuppressions returns the suppressions on the comment layers of the diagrams.
+func DiagramSuppressions(diagrams []pfddrawio.Diagram) ([]Suppression, error) {
+	res := make([]Suppression, 0)
+	for _, comment := range pfddrawio.Comments(diagrams) {
+		suppressions, err := ParseSuppressions(comment.Text)
+		if err != nil {
+			return nil, fmt.Errorf("allcheckers.DiagramSuppressions: diagram %q, cell %q: %w", comment.Location.DiagramID, comment.Location.CellID, err)
+		}
+		res = append(res, suppressions...)
+	}
+	return res, nil
+}
+
+// Filter applies the configuration and the suppressions to problems.
+type Filter struct {
+	rules        map[checkers.ProblemID]rule
+	suppressions []Suppression
+}
+
+type rule struct {
+	Disabled    bool
+	HasSeverity bool
+	Severity    checkers.Severity
+
+	// Scope is the IDs in the scope. It is nil if the rule is not scoped.
+	Scope *sets.Set[string]
+}
+
+// NewFilter returns the filter. The config may be nil. The PFD is used to resolve scopes.
+func NewFilter(config *Config, suppressions []Suppression, p *pfd.PFD) (*Filter, error) {
+	rules := make(map[checkers.ProblemID]rule)
+	if config != nil {
+		for id, rc := range config.Rules {
+			if !config.isKnownProblemID(id) {
+				return nil, fmt.Errorf("allcheckers.NewFilter: unknown problem ID: %q", id)
+			}
+
+			var r rule
+			r.Disabled = rc.Disabled
+
+			if rc.Severity != "" {
+				severity, err := checkers.ParseSeverity(rc.Severity)
+				if err != nil {
+					return nil, fmt.Errorf("allcheckers.NewFilter: %q: %w", id, err)
+				}
+				r.HasSeverity = true
+				r.Severity = severity
+			}
+
+			if len(rc.Scope) > 0 {
+				scope, err := scopeIDs(p, rc.Scope)
+				if err != nil {
+					return nil, fmt.Errorf("allcheckers.NewFilter: %q: %w", id, err)
+				}
+				r.Scope = scope
+			}
+
+			rules[id] = r
+		}
+	}
+
+	for _, s := range suppressions {
+		if !config.isKnownProblemID(s.ProblemID) {
+			return nil, fmt.Errorf("allcheckers.NewFilter: unknown problem ID: %q", s.ProblemID)
+		}
+	}
+
+	return &Filter{rules: rules, suppressions: suppressions}, nil
+}
+
+// Apply returns the problem with the configured severity. It returns false if the problem should not be reported.
+func (f *Filter) Apply(problem checkers.Problem) (checkers.Problem, bool) {
+	ids := problemIDs(problem)
+
+	for _, s := range f.suppressions {
+		if s.ProblemID != problem.ProblemID {
+			continue
+		}
+		if len(s.IDs) == 0 || slices.ContainsFunc(s.IDs, func(id string) bool { return ids.Contains(strings.Compare, id) }) {
+			return checkers.Problem{}, false
+		}
+	}
+
+	r, ok := f.rules[problem.ProblemID]
+	if !ok {
+		return problem, true
+	}
+
+	if r.Disabled {
+		return checkers.Problem{}, false
+	}
+
+	if r.Scope != nil && ids.IsDisjointWith(strings.Compare, r.Scope) {
+		return checkers.Problem{}, false
+	}
+
+	if r.HasSeverity {
+		problem.Severity = r.Severity
+	}
+	return problem, true
+}
+
+// NewFilteredLintFunc returns the lint function that reports only the problems passing the filter.
+func NewFilteredLintFunc(lintFunc LintFunc, filter *Filter) LintFunc {
+	return func(
+		up *pfd.PFD,
+		apTable *pfd.AtomicProcessTable,
+		adTable *pfd.AtomicDeliverableTable,
+		cpTable *pfd.CompositeProcessTable,
+		cdTable *pfd.CompositeDeliverableTable,
+		rTable *fsmtable.ResourceTable,
+		mt *fsmtable.MilestoneTable,
+		gt *fsmtable.GroupTable,
+		ch chan<- checkers.Problem,
+	) error {
+		var eg errgroup.Group
+		unfiltered := make(chan checkers.Problem)
+
+		eg.Go(func() error {
+			if err := lintFunc(up, apTable, adTable, cpTable, cdTable, rTable, mt, gt, unfiltered); err != nil {
+				return fmt.Errorf("allcheckers.NewFilteredLintFunc: %w", err)
+			}
+			return nil
+		})
+
+		eg.Go(func() error {
+			defer close(ch)
+			for problem := range unfiltered {
+				if problem, ok := filter.Apply(problem); ok {
+					ch <- problem
+				}
+			}
+			return nil
+		})
+
+		if err := eg.Wait(); err != nil {
+			return fmt.Errorf("allcheckers.NewFilteredLintFunc: %w", err)
+		}
+		return nil
+	}
+}
+
+// scopeIDs returns the IDs of the composite processes, the nodes in them, and the deliverables input to or output
+// from the nodes.
+func scopeIDs(p *pfd.PFD, compIDs []pfd.NodeID) (*sets.Set[string], error) {
+	ids := sets.New(strings.Compare)
+
+	var visit func(id pfd.NodeID)
+	visit = func(id pfd.NodeID) {
+		if ids.Contains(strings.Compare, string(id)) {
+			return
+		}
+		ids.Add(strings.Compare, string(id))
+
+		for _, d := range p.InputsIncludingFeedback(id).Iter() {
+			ids.Add(strings.Compare, string(d))
+		}
+		for _, d := range p.OutputsIncludingFeedback(id).Iter() {
+			ids.Add(strings.Compare, string(d))
+		}
+
+		children, ok := p.ProcessComposition[id]
+		if !ok {
+			return
+		}
+		for _, child := range children.Iter() {
+			visit(child)
+		}
+	}
+
+	for _, compID := range compIDs {
+		if _, ok := p.ProcessComposition[compID]; !ok {
+			return nil, fmt.Errorf("allcheckers.scopeIDs: unknown composite process: %q", compID)
+		}
+		visit(compID)
+	}
+	return ids, nil
+}
+
+// problemIDs returns the IDs in the locations of the problem.
+func problemIDs(problem checkers.Problem) *sets.Set[string] {
+	ids := sets.New(strings.Compare)
+	for _, loc := range problem.Locations {
+		_, locIDs, ok := locationIDs(loc)
+		if !ok {
+			continue
+		}
+		for _, id := range locIDs {
+			ids.Add(strings.Compare, id)
+		}
+	}
+	return ids
+}
+
+// locationIDs returns the location type and the related IDs of the location. It returns false if the location is
+// neither of pfdcommon nor fsmcommon.
+func locationIDs(loc checkers.Location) (fsmcommon.LocationType, []string, bool) {
+	switch loc := loc.(type) {
+	case pfdcommon.Location:
+		ids := make([]string, 0, len(loc.RelatedIDs))
+		for _, id := range loc.RelatedIDs {
+			ids = append(ids, string(id))
+		}
+		return fsmLocationType(loc.Type), ids, true
+	case fsmcommon.Location:
+		ids := make([]string, 0, len(loc.RelatedIDs))
+		sb := &strings.Builder{}
+		for _, id := range loc.RelatedIDs {
+			sb.Reset()
+			id.Write(sb)
+			ids = append(ids, sb.String())
+		}
+		return loc.Type, ids, true
+	default:
+		return "", nil, false
+	}
+}
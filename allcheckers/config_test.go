@@ -0,0 +1,247 @@
+package allcheckers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/locale"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/Kuniwak/pfd-tools/userrules"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSuppressions(t *testing.T) {
+	testCases := map[string]struct {
+		Text     string
+		Expected []Suppression
+	}{
+		"empty": {
+			Text:     "",
+			Expected: []Suppression{},
+		},
+		"not directive": {
+			Text:     "TODO: fix D7",
+			Expected: []Suppression{},
+		},
+		"without IDs": {
+			Text:     "pfdlint:ignore no-desc",
+			Expected: []Suppression{{ProblemID: "no-desc", IDs: []string{}}},
+		},
+		"with IDs": {
+			Text:     "pfdlint:ignore no-desc D7, D8",
+			Expected: []Suppression{{ProblemID: "no-desc", IDs: []string{"D7", "D8"}}},
+		},
+		"multiple lines": {
+			Text: "Known problems\n pfdlint:ignore no-desc D7\npfdlint:ignore single-src D2",
+			Expected: []Suppression{
+				{ProblemID: "no-desc", IDs: []string{"D7"}},
+				{ProblemID: "single-src", IDs: []string{"D2"}},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseSuppressions(testCase.Text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
+
+func TestParseSuppressionsError(t *testing.T) {
+	testCases := map[string]string{
+		"missing problem ID": "pfdlint:ignore",
+	}
+
+	for name, text := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseSuppressions(text); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	// D1 -> P1 (P2) -> D2 -> P3 -> D3
+	p := &pfd.PFD{
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D2", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D3", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "P1", Type: pfd.NodeTypeCompositeProcess},
+			&pfd.Node{ID: "P2", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "P3", Type: pfd.NodeTypeAtomicProcess},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D1", Target: "P1"},
+			&pfd.Edge{Source: "P1", Target: "D2"},
+			&pfd.Edge{Source: "D1", Target: "P2"},
+			&pfd.Edge{Source: "P2", Target: "D2"},
+			&pfd.Edge{Source: "D2", Target: "P3"},
+			&pfd.Edge{Source: "P3", Target: "D3"},
+		),
+		ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+			"P1": sets.New(pfd.NodeID.Compare, "P2"),
+		},
+		DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{},
+	}
+
+	noDesc := func(id pfd.NodeID) checkers.Problem {
+		return checkers.NewProblem("no-desc", checkers.SeverityWarning, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, id))...)
+	}
+	missingAP := checkers.NewProblem("missing-ap-table", checkers.SeverityError, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P2")))...)
+
+	testCases := map[string]struct {
+		Config       *Config
+		Suppressions []Suppression
+		Problem      checkers.Problem
+		Expected     checkers.Problem
+		ExpectedOK   bool
+	}{
+		"no config": {
+			Problem:    noDesc("D1"),
+			Expected:   noDesc("D1"),
+			ExpectedOK: true,
+		},
+		"disabled": {
+			Config:     &Config{Rules: map[checkers.ProblemID]RuleConfig{"no-desc": {Disabled: true}}},
+			Problem:    noDesc("D1"),
+			ExpectedOK: false,
+		},
+		"other rule disabled": {
+			Config:     &Config{Rules: map[checkers.ProblemID]RuleConfig{"single-src": {Disabled: true}}},
+			Problem:    noDesc("D1"),
+			Expected:   noDesc("D1"),
+			ExpectedOK: true,
+		},
+		"severity": {
+			Config:     &Config{Rules: map[checkers.ProblemID]RuleConfig{"no-desc": {Severity: "error"}}},
+			Problem:    noDesc("D1"),
+			Expected:   checkers.NewProblem("no-desc", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"))...),
+			ExpectedOK: true,
+		},
+		"in scope": {
+			Config:     &Config{Rules: map[checkers.ProblemID]RuleConfig{"no-desc": {Scope: []pfd.NodeID{"P1"}}}},
+			Problem:    noDesc("D2"),
+			Expected:   noDesc("D2"),
+			ExpectedOK: true,
+		},
+		"in scope of table": {
+			Config:     &Config{Rules: map[checkers.ProblemID]RuleConfig{"missing-ap-table": {Scope: []pfd.NodeID{"P1"}}}},
+			Problem:    missingAP,
+			Expected:   missingAP,
+			ExpectedOK: true,
+		},
+		"out of scope": {
+			Config:     &Config{Rules: map[checkers.ProblemID]RuleConfig{"no-desc": {Scope: []pfd.NodeID{"P1"}}}},
+			Problem:    noDesc("D3"),
+			ExpectedOK: false,
+		},
+		"suppressed": {
+			Suppressions: []Suppression{{ProblemID: "no-desc", IDs: []string{"D7", "D1"}}},
+			Problem:      noDesc("D1"),
+			ExpectedOK:   false,
+		},
+		"suppressed without IDs": {
+			Suppressions: []Suppression{{ProblemID: "no-desc"}},
+			Problem:      noDesc("D1"),
+			ExpectedOK:   false,
+		},
+		"suppression of other IDs": {
+			Suppressions: []Suppression{{ProblemID: "no-desc", IDs: []string{"D7"}}},
+			Problem:      noDesc("D1"),
+			Expected:     noDesc("D1"),
+			ExpectedOK:   true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			filter, err := NewFilter(testCase.Config, testCase.Suppressions, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, ok := filter.Apply(testCase.Problem)
+			if ok != testCase.ExpectedOK {
+				t.Fatalf("ok = %v, want %v", ok, testCase.ExpectedOK)
+			}
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
+
+func TestNewFilterError(t *testing.T) {
+	p := &pfd.PFD{
+		Nodes:              sets.New((*pfd.Node).Compare),
+		Edges:              sets.New((*pfd.Edge).Compare),
+		ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{},
+	}
+
+	testCases := map[string]struct {
+		Config       *Config
+		Suppressions []Suppression
+	}{
+		"unknown problem ID": {
+			Config: &Config{Rules: map[checkers.ProblemID]RuleConfig{"no-such-problem": {Disabled: true}}},
+		},
+		"unknown severity": {
+			Config: &Config{Rules: map[checkers.ProblemID]RuleConfig{"no-desc": {Severity: "fatal"}}},
+		},
+		"unknown composite process": {
+			Config: &Config{Rules: map[checkers.ProblemID]RuleConfig{"no-desc": {Scope: []pfd.NodeID{"P9"}}}},
+		},
+		"unknown problem ID of suppression": {
+			Config:       nil,
+			Suppressions: []Suppression{{ProblemID: "no-such-problem", IDs: []string{"D1"}}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewFilter(testCase.Config, testCase.Suppressions, p); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
+
+func TestNewFilterUserRules(t *testing.T) {
+	p := &pfd.PFD{
+		Nodes:              sets.New((*pfd.Node).Compare),
+		Edges:              sets.New((*pfd.Edge).Compare),
+		ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{},
+	}
+	config := &Config{
+		Rules:     map[checkers.ProblemID]RuleConfig{"d-id": {Severity: "ERROR"}},
+		UserRules: []userrules.Rule{{ID: "d-id", For: userrules.KindAtomicDeliverable, Assert: `id =~ "^D[0-9]+$"`, Messages: map[locale.Locale]string{locale.LocaleEn: "Bad ID."}}},
+	}
+
+	if _, err := NewFilter(config, []Suppression{{ProblemID: "d-id"}}, p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompileUserRulesError(t *testing.T) {
+	config := &Config{
+		UserRules: []userrules.Rule{{ID: "no-desc", For: userrules.KindAtomicDeliverable, Assert: `description != ""`, Messages: map[locale.Locale]string{locale.LocaleEn: "No description."}}},
+	}
+
+	if _, err := config.CompileUserRules(); err == nil {
+		t.Error("want error, got nil")
+	}
+}
@@ -3,8 +3,9 @@ package allcheckers
 type Format string
 
 const (
-	FormatTSV  Format = "pfdtsv"
-	FormatJSON Format = "json"
+	FormatTSV   Format = "pfdtsv"
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
 )
 
 func (f Format) String() string {
@@ -9,6 +9,8 @@ import (
 	"github.com/Kuniwak/pfd-tools/pfd"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdmetrics"
+	"github.com/Kuniwak/pfd-tools/userrules"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
@@ -27,6 +29,67 @@ type LintFunc func(
 ) error
 
 func NewLintFunc(logger *slog.Logger) LintFunc {
+	return newLintFunc(PFDCheckers, FSMCheckers, logger)
+}
+
+// NewLintFuncWithUserRules returns the lint function that also checks the user-defined rules.
+func NewLintFuncWithUserRules(rules []*userrules.CompiledRule, logger *slog.Logger) LintFunc {
+	return NewLintFuncWithConfig(rules, nil, logger)
+}
+
+// NewLintFuncWithConfig returns the lint function that also checks the user-defined rules and the thresholds of the
+// structural metrics. The thresholds may be nil.
+func NewLintFuncWithConfig(rules []*userrules.CompiledRule, thresholds *pfdmetrics.Thresholds, logger *slog.Logger) LintFunc {
+	return newLintFunc(
+		checkers.NewParallelChecker[pfdcommon.Target](PFDCheckers, userrules.NewPFDChecker(rules, logger)),
+		checkers.NewParallelChecker[*fsmcommon.Target](FSMCheckers, userrules.NewFSMChecker(rules, logger), pfdmetrics.NewChecker(thresholds)),
+		logger,
+	)
+}
+
+// NewLintFuncWithProblems returns the lint function that also reports the problems found before linting, such as the
+// structural defects of draw.io diagrams.
+func NewLintFuncWithProblems(lintFunc LintFunc, ps []checkers.Problem) LintFunc {
+	return func(
+		up *pfd.PFD,
+		apTable *pfd.AtomicProcessTable,
+		adTable *pfd.AtomicDeliverableTable,
+		cpTable *pfd.CompositeProcessTable,
+		cdTable *pfd.CompositeDeliverableTable,
+		rTable *fsmtable.ResourceTable,
+		mt *fsmtable.MilestoneTable,
+		gt *fsmtable.GroupTable,
+		ch chan<- checkers.Problem,
+	) error {
+		var eg errgroup.Group
+		linted := make(chan checkers.Problem)
+
+		eg.Go(func() error {
+			if err := lintFunc(up, apTable, adTable, cpTable, cdTable, rTable, mt, gt, linted); err != nil {
+				return fmt.Errorf("allcheckers.NewLintFuncWithProblems: %w", err)
+			}
+			return nil
+		})
+
+		eg.Go(func() error {
+			defer close(ch)
+			for _, problem := range ps {
+				ch <- problem
+			}
+			for problem := range linted {
+				ch <- problem
+			}
+			return nil
+		})
+
+		if err := eg.Wait(); err != nil {
+			return fmt.Errorf("allcheckers.NewLintFuncWithProblems: %w", err)
+		}
+		return nil
+	}
+}
+
+func newLintFunc(pfdChecker checkers.Checker[pfdcommon.Target], fsmChecker checkers.Checker[*fsmcommon.Target], logger *slog.Logger) LintFunc {
 	return func(
 		up *pfd.PFD,
 		apTable *pfd.AtomicProcessTable,
@@ -54,7 +117,7 @@ func NewLintFunc(logger *slog.Logger) LintFunc {
 			}()
 
 			m := pfdcommon.NewMemoized(up, logger)
-			if err := PFDCheckers.Check(pfdcommon.NewTarget(up, apTable, adTable, cpTable, cdTable, m), ch); err != nil {
+			if err := pfdChecker.Check(pfdcommon.NewTarget(up, apTable, adTable, cpTable, cdTable, m), ch); err != nil {
 				return fmt.Errorf("allcheckers.NewLintFunc: %w", err)
 			}
 
@@ -80,7 +143,7 @@ func NewLintFunc(logger *slog.Logger) LintFunc {
 			if err != nil {
 				return fmt.Errorf("allcheckers.NewLintFunc: %w", err)
 			}
-			if err := FSMCheckers.Check(fsmcommon.NewTarget(p, apTable, adTable, rTable, mt, gt, m, logger), ch); err != nil {
+			if err := fsmChecker.Check(fsmcommon.NewTarget(p, apTable, adTable, rTable, mt, gt, m, logger), ch); err != nil {
 				return fmt.Errorf("allcheckers.NewLintFunc: %w", err)
 			}
 
@@ -106,13 +169,31 @@ func Lint(
 	gt *fsmtable.GroupTable,
 	logger *slog.Logger,
 ) ([]checkers.Problem, error) {
-	lintFunc := NewLintFunc(logger)
+	ps, err := LintByFunc(NewLintFunc(logger), p, apTable, adTable, cpTable, cdTable, rTable, mt, gt)
+	if err != nil {
+		return nil, fmt.Errorf("allcheckers.Lint: %w", err)
+	}
+	return ps, nil
+}
+
+// LintByFunc returns the problems reported by the lint function.
+func LintByFunc(
+	lintFunc LintFunc,
+	p *pfd.PFD,
+	apTable *pfd.AtomicProcessTable,
+	adTable *pfd.AtomicDeliverableTable,
+	cpTable *pfd.CompositeProcessTable,
+	cdTable *pfd.CompositeDeliverableTable,
+	rTable *fsmtable.ResourceTable,
+	mt *fsmtable.MilestoneTable,
+	gt *fsmtable.GroupTable,
+) ([]checkers.Problem, error) {
 	ch := make(chan checkers.Problem)
 
 	var eg errgroup.Group
 	eg.Go(func() error {
 		if err := lintFunc(p, apTable, adTable, cpTable, cdTable, rTable, mt, gt, ch); err != nil {
-			return fmt.Errorf("allcheckers.Lint: %w", err)
+			return fmt.Errorf("allcheckers.LintByFunc: %w", err)
 		}
 		return nil
 	})
@@ -126,7 +207,7 @@ func Lint(
 	})
 
 	if err := eg.Wait(); err != nil {
-		return nil, fmt.Errorf("allcheckers.Lint: %w", err)
+		return nil, fmt.Errorf("allcheckers.LintByFunc: %w", err)
 	}
 
 	return ps, nil
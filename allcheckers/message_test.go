@@ -0,0 +1,119 @@
+package allcheckers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/locale"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCatalogs(t *testing.T) {
+	en, ok := Catalogs[locale.LocaleEn]
+	if !ok {
+		t.Fatal("missing English catalog")
+	}
+	for _, id := range ProblemIDs {
+		entry, ok := en.Problems[id]
+		if !ok || entry.Message == "" || entry.Explanation == "" || entry.Fix == "" {
+			t.Errorf("incomplete English message: %q", id)
+		}
+	}
+
+	for l, catalog := range Catalogs {
+		for id := range catalog.Problems {
+			if !IsKnownProblemID(id) {
+				t.Errorf("unknown problem ID in %q: %q", l, id)
+			}
+		}
+	}
+}
+
+func TestLookupMessage(t *testing.T) {
+	ja := Catalogs[locale.LocaleJa].Problems["no-desc"]
+	en := Catalogs[locale.LocaleEn].Problems["no-desc"]
+
+	testCases := map[string]struct {
+		ID             checkers.ProblemID
+		Locale         locale.Locale
+		Expected       MessageEntry
+		ExpectedLocale locale.Locale
+		OK             bool
+	}{
+		"Japanese": {
+			ID:             "no-desc",
+			Locale:         locale.LocaleJa,
+			Expected:       ja,
+			ExpectedLocale: locale.LocaleJa,
+			OK:             true,
+		},
+		"region falls back to language": {
+			ID:             "no-desc",
+			Locale:         "ja-JP",
+			Expected:       ja,
+			ExpectedLocale: locale.LocaleJa,
+			OK:             true,
+		},
+		"incomplete entry falls back to English as a whole": {
+			ID:             "no-desc",
+			Locale:         "zh-TW",
+			Expected:       en,
+			ExpectedLocale: locale.LocaleEn,
+			OK:             true,
+		},
+		"unknown locale falls back to English": {
+			ID:             "no-desc",
+			Locale:         "fr",
+			Expected:       en,
+			ExpectedLocale: locale.LocaleEn,
+			OK:             true,
+		},
+		"unknown problem ID": {
+			ID:       "no-such-problem",
+			Locale:   locale.LocaleEn,
+			Expected: MessageEntry{},
+			OK:       false,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, actualLocale, ok := LookupMessage(testCase.ID, testCase.Locale)
+			if ok != testCase.OK {
+				t.Errorf("want %t, got %t", testCase.OK, ok)
+			}
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+			if actualLocale != testCase.ExpectedLocale {
+				t.Errorf("want %q, got %q", testCase.ExpectedLocale, actualLocale)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	testCases := map[string]struct {
+		Locale   locale.Locale
+		Expected string
+	}{
+		"translated": {
+			Locale:   "zh-TW",
+			Expected: Catalogs["zh"].Problems["no-desc"].Message,
+		},
+		"unknown locale falls back to English": {
+			Locale:   "fr",
+			Expected: Catalogs[locale.LocaleEn].Problems["no-desc"].Message,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := Message("no-desc", testCase.Locale)
+			if actual != testCase.Expected {
+				t.Errorf("want %q, got %q", testCase.Expected, actual)
+			}
+		})
+	}
+}
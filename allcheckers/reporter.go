@@ -2,6 +2,7 @@ package allcheckers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"slices"
@@ -50,7 +51,7 @@ func WriteTSVRow(w io.Writer, problem checkers.Problem, l locale.Locale) {
 	w.Write(tab)
 	io.WriteString(w, string(problem.ProblemID))
 	w.Write(tab)
-	io.WriteString(w, Message(problem.ProblemID, l))
+	io.WriteString(w, ProblemMessage(problem, l))
 	w.Write(tab)
 	for i, loc := range problem.Locations {
 		if i > 0 {
@@ -78,17 +79,51 @@ func NewTSV(w io.Writer, l locale.Locale) Func {
 	}
 }
 
-func NewJSON(w io.Writer, l locale.Locale) Func {
+// JSONProblem is the problem in JSON.
+type JSONProblem struct {
+	Locations []JSONLocation     `json:"locations"`
+	Message   string             `json:"message"`
+	ProblemID checkers.ProblemID `json:"problem_id"`
+	Severity  string             `json:"severity"`
+}
+
+// NewJSON returns the reporter that writes a JSON object per line for each problem. Locations have the spans in the
+// sources, and the sources may be nil.
+func NewJSON(w io.Writer, l locale.Locale, sources *Sources) Func {
 	return func(ch <-chan checkers.Problem) (int, error) {
+		e := json.NewEncoder(w)
 		count := 0
 		for problem := range ch {
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"locations":  problem.Locations,
-				"problem_id": problem.ProblemID,
-				"severity":   problem.Severity.String(),
-				"message":    Message(problem.ProblemID, l),
-			})
+			locations := make([]JSONLocation, 0, len(problem.Locations))
+			for _, loc := range problem.Locations {
+				locations = append(locations, NewJSONLocation(loc, sources))
+			}
+			if err := e.Encode(JSONProblem{
+				Locations: locations,
+				Message:   ProblemMessage(problem, l),
+				ProblemID: problem.ProblemID,
+				Severity:  problem.Severity.String(),
+			}); err != nil {
+				return 0, fmt.Errorf("allcheckers.NewJSON: %w", err)
+			}
+			count++
 		}
 		return count, nil
 	}
 }
+
+// ReadJSON reads the problems written by the reporter of NewJSON.
+func ReadJSON(r io.Reader) ([]JSONProblem, error) {
+	d := json.NewDecoder(r)
+	res := make([]JSONProblem, 0)
+	for {
+		var problem JSONProblem
+		if err := d.Decode(&problem); err != nil {
+			if errors.Is(err, io.EOF) {
+				return res, nil
+			}
+			return nil, fmt.Errorf("allcheckers.ReadJSON: %w", err)
+		}
+		res = append(res, problem)
+	}
+}
@@ -0,0 +1,41 @@
+package allcheckers
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/locale"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadJSON(t *testing.T) {
+	ch := make(chan checkers.Problem, 1)
+	ch <- checkers.NewProblem("no-desc", checkers.SeverityWarning, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"))...)
+	close(ch)
+
+	buf := &bytes.Buffer{}
+	if _, err := NewJSON(buf, locale.LocaleEn, nil)(ch); err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := ReadJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []JSONProblem{
+		{
+			Locations: []JSONLocation{{Text: "PFD[D1]", Type: fsmcommon.LocationTypePFD, IDs: []string{"D1"}, Spans: []checkers.Span{}}},
+			Message:   ProblemMessage(checkers.NewProblem("no-desc", checkers.SeverityWarning), locale.LocaleEn),
+			ProblemID: "no-desc",
+			Severity:  "WARNING",
+		},
+	}
+	if !reflect.DeepEqual(ps, expected) {
+		t.Error(cmp.Diff(expected, ps))
+	}
+}
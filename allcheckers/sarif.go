@@ -0,0 +1,267 @@
+package allcheckers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/locale"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
+	"github.com/Kuniwak/pfd-tools/version"
+)
+
+const (
+	SARIFVersion   = "2.1.0"
+	SARIFSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIFToolName  = "pfdlint"
+	SARIFToolURI   = "https://github.com/Kuniwak/pfd-tools"
+)
+
+// SARIFArtifacts is the files that locations of problems point to.
+type SARIFArtifacts struct {
+	// URIs maps location types to the URIs of the files. Locations of types without URIs have no physical locations.
+	URIs map[fsmcommon.LocationType]string
+
+	// SourceMap maps nodes in PFD locations to draw.io cells. It is nil if the PFD is not a draw.io file.
+	SourceMap *pfddrawio.SourceMap
+}
+
+// NewSARIF returns the reporter that writes a SARIF 2.1.0 log. Problem IDs are rules, and draw.io cells are logical
+// locations. The artifacts may be nil.
+func NewSARIF(w io.Writer, l locale.Locale, artifacts *SARIFArtifacts) Func {
+	if artifacts == nil {
+		artifacts = &SARIFArtifacts{}
+	}
+	return func(ch <-chan checkers.Problem) (int, error) {
+		ps := make([]checkers.Problem, 0)
+		for problem := range ch {
+			ps = append(ps, problem)
+		}
+
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		if err := e.Encode(NewSARIFLog(ps, l, artifacts)); err != nil {
+			return 0, fmt.Errorf("allcheckers.NewSARIF: %w", err)
+		}
+		return len(ps), nil
+	}
+}
+
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     SARIFMessage           `json:"shortDescription"`
+	DefaultConfiguration SARIFRuleConfiguration `json:"defaultConfiguration"`
+}
+
+type SARIFRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind"`
+}
+
+// SARIFLevel returns the SARIF level of the severity.
+func SARIFLevel(s checkers.Severity) string {
+	switch s {
+	case checkers.SeverityError:
+		return "error"
+	case checkers.SeverityWarning:
+		return "warning"
+	case checkers.SeverityStyleProblem:
+		return "note"
+	default:
+		panic(fmt.Sprintf("allcheckers.SARIFLevel: unknown severity: %d", s))
+	}
+}
+
+// NewSARIFLog returns the SARIF log of the problems. Rules are sorted by the problem IDs.
+func NewSARIFLog(ps []checkers.Problem, l locale.Locale, artifacts *SARIFArtifacts) *SARIFLog {
+	firsts := make(map[checkers.ProblemID]checkers.Problem)
+	ids := make([]checkers.ProblemID, 0)
+	for _, problem := range ps {
+		if _, ok := firsts[problem.ProblemID]; ok {
+			continue
+		}
+		firsts[problem.ProblemID] = problem
+		ids = append(ids, problem.ProblemID)
+	}
+	slices.Sort(ids)
+
+	rules := make([]SARIFRule, 0, len(ids))
+	ruleIndices := make(map[checkers.ProblemID]int, len(ids))
+	for i, id := range ids {
+		rules = append(rules, SARIFRule{
+			ID:                   string(id),
+			ShortDescription:     SARIFMessage{Text: ProblemMessage(firsts[id], l)},
+			DefaultConfiguration: SARIFRuleConfiguration{Level: SARIFLevel(firsts[id].Severity)},
+		})
+		ruleIndices[id] = i
+	}
+
+	sb := &strings.Builder{}
+	results := make([]SARIFResult, 0, len(ps))
+	for _, problem := range ps {
+		locations := make([]SARIFLocation, 0, len(problem.Locations))
+		for _, loc := range problem.Locations {
+			locations = append(locations, newSARIFLocation(loc, artifacts))
+		}
+
+		// NOTE: Locations such as PFD[D1, D2] tell IDs even if the viewer shows no logical locations.
+		sb.Reset()
+		sb.WriteString(ProblemMessage(problem, l))
+		for i, loc := range problem.Locations {
+			if i == 0 {
+				sb.WriteString(" ")
+			} else {
+				sb.WriteString(", ")
+			}
+			loc.Write(sb)
+		}
+
+		results = append(results, SARIFResult{
+			RuleID:    string(problem.ProblemID),
+			RuleIndex: ruleIndices[problem.ProblemID],
+			Level:     SARIFLevel(problem.Severity),
+			Message:   SARIFMessage{Text: sb.String()},
+			Locations: locations,
+		})
+	}
+
+	return &SARIFLog{
+		Schema:  SARIFSchemaURI,
+		Version: SARIFVersion,
+		Runs: []SARIFRun{
+			{
+				Tool: SARIFTool{
+					Driver: SARIFDriver{
+						Name:           SARIFToolName,
+						Version:        version.Version,
+						InformationURI: SARIFToolURI,
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+func newSARIFLocation(loc checkers.Location, artifacts *SARIFArtifacts) SARIFLocation {
+	if cell, ok := loc.(pfddrawio.DrawIOLocation); ok {
+		return newSARIFDrawIOLocation(cell, artifacts)
+	}
+
+	t, ids, ok := locationIDs(loc)
+	if !ok {
+		return SARIFLocation{}
+	}
+
+	var res SARIFLocation
+	if uri, ok := artifacts.URIs[t]; ok && uri != "" {
+		res.PhysicalLocation = &SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: uri}}
+	}
+
+	for _, id := range ids {
+		if t == fsmcommon.LocationTypePFD && artifacts.SourceMap != nil {
+			if cells, ok := artifacts.SourceMap.NodeIDMap[pfd.NodeID(id)]; ok && cells.Len() > 0 {
+				for _, cell := range cells.Iter() {
+					res.LogicalLocations = append(res.LogicalLocations, SARIFLogicalLocation{
+						Name:               string(cell.CellID),
+						FullyQualifiedName: fmt.Sprintf("%s/%s", cell.DiagramID, cell.CellID),
+						Kind:               "element",
+					})
+				}
+				continue
+			}
+		}
+		res.LogicalLocations = append(res.LogicalLocations, SARIFLogicalLocation{Name: id, Kind: "element"})
+	}
+
+	return res
+}
+
+// newSARIFDrawIOLocation returns the location of the draw.io cell in the PFD file.
+func newSARIFDrawIOLocation(cell pfddrawio.DrawIOLocation, artifacts *SARIFArtifacts) SARIFLocation {
+	var res SARIFLocation
+	if uri, ok := artifacts.URIs[fsmcommon.LocationTypePFD]; ok && uri != "" {
+		res.PhysicalLocation = &SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: uri}}
+	}
+	res.LogicalLocations = []SARIFLogicalLocation{{
+		Name:               string(cell.CellID),
+		FullyQualifiedName: fmt.Sprintf("%s/%s", cell.DiagramID, cell.CellID),
+		Kind:               "element",
+	}}
+	return res
+}
+
+// fsmLocationType returns the location type of fsmcommon for the location type of pfdcommon.
+func fsmLocationType(t pfdcommon.LocationType) fsmcommon.LocationType {
+	switch t {
+	case pfdcommon.LocationTypePFD:
+		return fsmcommon.LocationTypePFD
+	case pfdcommon.LocationTypeAtomicProcessTable:
+		return fsmcommon.LocationTypeAtomicProcessTable
+	case pfdcommon.LocationTypeDeliverableTable:
+		return fsmcommon.LocationTypeAtomicDeliverableTable
+	case pfdcommon.LocationTypeCompositeProcessTable:
+		return fsmcommon.LocationTypeCompositeProcessTable
+	default:
+		panic(fmt.Sprintf("allcheckers.fsmLocationType: unknown location type: %q", t))
+	}
+}
@@ -0,0 +1,166 @@
+package allcheckers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/chans"
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/locale"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/Kuniwak/pfd-tools/version"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewSARIF(t *testing.T) {
+	ps := []checkers.Problem{
+		checkers.NewProblem("no-desc", checkers.SeverityWarning, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"))...),
+		checkers.NewProblem("extra-d-table", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeDeliverableTable, "D9"))...),
+		checkers.NewProblem("missing-r-table", checkers.SeverityWarning, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeResourceTable, fsmcommon.NewResourceID("R1")))...),
+	}
+	artifacts := &SARIFArtifacts{
+		URIs: map[fsmcommon.LocationType]string{
+			fsmcommon.LocationTypePFD:                    "pfd.drawio",
+			fsmcommon.LocationTypeAtomicDeliverableTable: "deliv.tsv",
+		},
+		SourceMap: &pfddrawio.SourceMap{
+			NodeIDMap: map[pfd.NodeID]*sets.Set[pfddrawio.DrawIOLocation]{
+				"D1": sets.New(pfddrawio.DrawIOLocation.Compare, pfddrawio.DrawIOLocation{DiagramID: "d", CellID: "2"}, pfddrawio.DrawIOLocation{DiagramID: "d", CellID: "5"}),
+			},
+		},
+	}
+
+	sb := &strings.Builder{}
+	count, err := NewSARIF(sb, locale.LocaleEn, artifacts)(chans.From(ps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(ps) {
+		t.Errorf("count = %d, want %d", count, len(ps))
+	}
+
+	expected := `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "pfdlint",
+          "version": "` + version.Version + `",
+          "informationUri": "https://github.com/Kuniwak/pfd-tools",
+          "rules": [
+            {
+              "id": "extra-d-table",
+              "shortDescription": {
+                "text": "` + Message("extra-d-table", locale.LocaleEn) + `"
+              },
+              "defaultConfiguration": {
+                "level": "error"
+              }
+            },
+            {
+              "id": "missing-r-table",
+              "shortDescription": {
+                "text": "` + Message("missing-r-table", locale.LocaleEn) + `"
+              },
+              "defaultConfiguration": {
+                "level": "warning"
+              }
+            },
+            {
+              "id": "no-desc",
+              "shortDescription": {
+                "text": "Please add a concise description."
+              },
+              "defaultConfiguration": {
+                "level": "warning"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "no-desc",
+          "ruleIndex": 2,
+          "level": "warning",
+          "message": {
+            "text": "Please add a concise description. PFD[D1]"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "pfd.drawio"
+                }
+              },
+              "logicalLocations": [
+                {
+                  "name": "2",
+                  "fullyQualifiedName": "d/2",
+                  "kind": "element"
+                },
+                {
+                  "name": "5",
+                  "fullyQualifiedName": "d/5",
+                  "kind": "element"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "extra-d-table",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "` + Message("extra-d-table", locale.LocaleEn) + ` DELIVERABLE_TABLE[D9]"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "deliv.tsv"
+                }
+              },
+              "logicalLocations": [
+                {
+                  "name": "D9",
+                  "kind": "element"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "missing-r-table",
+          "ruleIndex": 1,
+          "level": "warning",
+          "message": {
+            "text": "` + Message("missing-r-table", locale.LocaleEn) + ` RESOURCE_TABLE[R1]"
+          },
+          "locations": [
+            {
+              "logicalLocations": [
+                {
+                  "name": "R1",
+                  "kind": "element"
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+	if sb.String() != expected {
+		t.Error(cmp.Diff(expected, sb.String()))
+	}
+}
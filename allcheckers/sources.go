@@ -0,0 +1,206 @@
+package allcheckers
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
+)
+
+// LocationTypeDrawIO is the location type of draw.io cells. Locations of the type have no IDs.
+const LocationTypeDrawIO fsmcommon.LocationType = "DRAWIO"
+
+// Sources is the input files that locations point to. It resolves locations into spans.
+type Sources struct {
+	// Paths maps location types to the paths of the files. Locations of types without paths have spans without paths.
+	Paths map[fsmcommon.LocationType]string
+
+	// Pages maps draw.io diagram IDs to the page names.
+	Pages map[pfddrawio.DiagramID]string
+
+	// SourceMap maps nodes in PFD locations to draw.io cells. It is nil if the PFD is not a draw.io file.
+	SourceMap *pfddrawio.SourceMap
+
+	// Rows maps location types and IDs to the row numbers of the table files. The header is the row 1.
+	Rows map[fsmcommon.LocationType]map[string]int
+}
+
+// NewSources returns the sources of the files. Missing tables are nil, and PFDs other than draw.io files have no
+// diagrams and no source map.
+func NewSources(
+	paths map[fsmcommon.LocationType]string,
+	diagrams []pfddrawio.Diagram,
+	srcMap *pfddrawio.SourceMap,
+	atomicProcessTable *pfd.AtomicProcessTable,
+	atomicDeliverableTable *pfd.AtomicDeliverableTable,
+	compositeProcessTable *pfd.CompositeProcessTable,
+	compositeDeliverableTable *pfd.CompositeDeliverableTable,
+	resourceTable *fsmtable.ResourceTable,
+	milestoneTable *fsmtable.MilestoneTable,
+	groupTable *fsmtable.GroupTable,
+) *Sources {
+	pages := make(map[pfddrawio.DiagramID]string, len(diagrams))
+	for _, diagram := range diagrams {
+		pages[diagram.ID] = diagram.Name
+	}
+
+	rows := make(map[fsmcommon.LocationType]map[string]int)
+	if atomicProcessTable != nil {
+		rows[fsmcommon.LocationTypeAtomicProcessTable] = rowNumbers(atomicProcessTable.Rows, func(row *pfd.AtomicProcessRow) string { return string(row.ID) })
+	}
+	if atomicDeliverableTable != nil {
+		rows[fsmcommon.LocationTypeAtomicDeliverableTable] = rowNumbers(atomicDeliverableTable.Rows, func(row *pfd.AtomicDeliverableRow) string { return string(row.ID) })
+	}
+	if compositeProcessTable != nil {
+		rows[fsmcommon.LocationTypeCompositeProcessTable] = rowNumbers(compositeProcessTable.Rows, func(row *pfd.CompositeProcessRow) string { return string(row.ID) })
+	}
+	if compositeDeliverableTable != nil {
+		rows[fsmcommon.LocationTypeCompositeDeliverableTable] = rowNumbers(compositeDeliverableTable.Rows, func(row *pfd.CompositeDeliverableRow) string { return string(row.ID) })
+	}
+	if resourceTable != nil {
+		rows[fsmcommon.LocationTypeResourceTable] = rowNumbers(resourceTable.Rows, func(row *fsmtable.ResourceTableRow) string { return string(row.ID) })
+	}
+	if milestoneTable != nil {
+		rows[fsmcommon.LocationTypeMilestoneTable] = rowNumbers(milestoneTable.Rows, func(row *fsmtable.MilestoneTableRow) string { return string(row.MilestoneID) })
+	}
+	if groupTable != nil {
+		rows[fsmcommon.LocationTypeGroupTable] = rowNumbers(groupTable.Rows, func(row *fsmtable.GroupTableRow) string { return string(row.ID) })
+	}
+
+	return &Sources{Paths: paths, Pages: pages, SourceMap: srcMap, Rows: rows}
+}
+
+// rowNumbers returns the row numbers of the IDs. The first row wins if the IDs are duplicated.
+func rowNumbers[T any](rows []T, idFunc func(T) string) map[string]int {
+	m := make(map[string]int, len(rows))
+	for i, row := range rows {
+		id := idFunc(row)
+		if _, ok := m[id]; ok {
+			continue
+		}
+		// NOTE: The header is the row 1.
+		m[id] = i + 2
+	}
+	return m
+}
+
+// Spans returns the spans of the location. PFD locations have the draw.io cells of the IDs, and table locations have
+// the rows of the IDs. Locations with columns have only the cell of the first ID.
+func (s *Sources) Spans(loc checkers.Location) []checkers.Span {
+	if cell, ok := loc.(pfddrawio.DrawIOLocation); ok {
+		return []checkers.Span{s.drawIOSpan(cell)}
+	}
+
+	t, ids, ok := locationIDs(loc)
+	if !ok {
+		return []checkers.Span{}
+	}
+
+	// NOTE: Locations such as PFD[D1, D1] of self loops have the same IDs.
+	seen := make(map[string]bool, len(ids))
+	ids = slices.DeleteFunc(slices.Clone(ids), func(id string) bool {
+		if seen[id] {
+			return true
+		}
+		seen[id] = true
+		return false
+	})
+
+	if t == fsmcommon.LocationTypePFD {
+		spans := make([]checkers.Span, 0, len(ids))
+		if s.SourceMap == nil {
+			return spans
+		}
+		for _, id := range ids {
+			cells, ok := s.SourceMap.NodeIDMap[pfd.NodeID(id)]
+			if !ok {
+				continue
+			}
+			for _, cell := range cells.Iter() {
+				spans = append(spans, s.drawIOSpan(cell))
+			}
+		}
+		return spans
+	}
+
+	var column string
+	var r *checkers.TextRange
+	if loc, ok := loc.(fsmcommon.Location); ok {
+		column = loc.Column
+		r = loc.Range
+	}
+	if column != "" && len(ids) > 0 {
+		ids = ids[:1]
+	}
+
+	spans := make([]checkers.Span, 0, len(ids))
+	for _, id := range ids {
+		row, ok := s.Rows[t][id]
+		if !ok {
+			continue
+		}
+		spans = append(spans, s.tableSpan(t, row, column, r))
+	}
+	if len(spans) == 0 {
+		// NOTE: Rows of missing IDs are unknown, but the file is still known.
+		spans = append(spans, s.tableSpan(t, 0, column, r))
+	}
+	return spans
+}
+
+func (s *Sources) drawIOSpan(cell pfddrawio.DrawIOLocation) checkers.Span {
+	return checkers.Span{
+		Kind:   checkers.SpanKindDrawIO,
+		Path:   s.Paths[fsmcommon.LocationTypePFD],
+		Page:   s.Pages[cell.DiagramID],
+		PageID: string(cell.DiagramID),
+		CellID: string(cell.CellID),
+	}
+}
+
+func (s *Sources) tableSpan(t fsmcommon.LocationType, row int, column string, r *checkers.TextRange) checkers.Span {
+	kind := checkers.SpanKindTable
+	if r != nil {
+		kind = checkers.SpanKindText
+	}
+	return checkers.Span{
+		Kind:   kind,
+		Path:   s.Paths[t],
+		Row:    row,
+		Column: column,
+		Range:  r,
+	}
+}
+
+// JSONLocation is the structured location of problems in JSON.
+type JSONLocation struct {
+	// Text is the location in the TSV format such as PFD[P1, D1].
+	Text string `json:"text"`
+
+	Type  fsmcommon.LocationType `json:"type"`
+	IDs   []string               `json:"ids"`
+	Spans []checkers.Span        `json:"spans"`
+}
+
+// NewJSONLocation returns the structured location. The sources may be nil, and then the location has no spans.
+func NewJSONLocation(loc checkers.Location, sources *Sources) JSONLocation {
+	sb := &strings.Builder{}
+	loc.Write(sb)
+	res := JSONLocation{Text: sb.String(), IDs: []string{}, Spans: []checkers.Span{}}
+
+	if _, ok := loc.(pfddrawio.DrawIOLocation); ok {
+		res.Type = LocationTypeDrawIO
+	} else if t, ids, ok := locationIDs(loc); ok {
+		res.Type = t
+		res.IDs = ids
+	}
+
+	if sources != nil {
+		res.Spans = sources.Spans(loc)
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package allcheckers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSources_Spans(t *testing.T) {
+	sources := NewSources(
+		map[fsmcommon.LocationType]string{
+			fsmcommon.LocationTypePFD:                "pfd.drawio",
+			fsmcommon.LocationTypeAtomicProcessTable: "atomic_proc.tsv",
+		},
+		[]pfddrawio.Diagram{{ID: "d", Name: "Page-1"}},
+		&pfddrawio.SourceMap{
+			NodeIDMap: map[pfd.NodeID]*sets.Set[pfddrawio.DrawIOLocation]{
+				"D1": sets.New(pfddrawio.DrawIOLocation.Compare, pfddrawio.DrawIOLocation{DiagramID: "d", CellID: "2"}, pfddrawio.DrawIOLocation{DiagramID: "d", CellID: "5"}),
+			},
+		},
+		&pfd.AtomicProcessTable{
+			ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+			Rows: []*pfd.AtomicProcessRow{
+				{ID: "P1", Description: "Process 1", ExtraCells: []string{``}},
+				{ID: "P2", Description: "Process 2", ExtraCells: []string{`\exec(P9)`}},
+			},
+		},
+		nil, nil, nil, nil, nil, nil,
+	)
+
+	testCases := map[string]struct {
+		Location checkers.Location
+		Want     []checkers.Span
+	}{
+		"draw.io cell": {
+			Location: pfddrawio.DrawIOLocation{DiagramID: "d", CellID: "8"},
+			Want: []checkers.Span{
+				{Kind: checkers.SpanKindDrawIO, Path: "pfd.drawio", Page: "Page-1", PageID: "d", CellID: "8"},
+			},
+		},
+		"PFD": {
+			Location: pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1", "D1"),
+			Want: []checkers.Span{
+				{Kind: checkers.SpanKindDrawIO, Path: "pfd.drawio", Page: "Page-1", PageID: "d", CellID: "2"},
+				{Kind: checkers.SpanKindDrawIO, Path: "pfd.drawio", Page: "Page-1", PageID: "d", CellID: "5"},
+			},
+		},
+		"rows": {
+			Location: fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P2"), fsmcommon.NewAtomicProcessID("P1")),
+			Want: []checkers.Span{
+				{Kind: checkers.SpanKindTable, Path: "atomic_proc.tsv", Row: 3},
+				{Kind: checkers.SpanKindTable, Path: "atomic_proc.tsv", Row: 2},
+			},
+		},
+		"missing row": {
+			Location: pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, "P9"),
+			Want: []checkers.Span{
+				{Kind: checkers.SpanKindTable, Path: "atomic_proc.tsv"},
+			},
+		},
+		"text": {
+			Location: fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P2"), fsmcommon.NewAtomicProcessID("P9")).
+				WithColumn(fsmtable.PreconditionColumnHeaderEn).
+				WithRange(0, 9),
+			Want: []checkers.Span{
+				{Kind: checkers.SpanKindText, Path: "atomic_proc.tsv", Row: 3, Column: fsmtable.PreconditionColumnHeaderEn, Range: &checkers.TextRange{Start: 0, End: 9}},
+			},
+		},
+		"table without path": {
+			Location: fsmcommon.NewLocation(fsmcommon.LocationTypeResourceTable, fsmcommon.NewResourceID("R1")),
+			Want: []checkers.Span{
+				{Kind: checkers.SpanKindTable},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := sources.Spans(testCase.Location)
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Error(cmp.Diff(testCase.Want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package checkers
+
+import (
+	"io"
+)
+
+// Fix is a machine-applicable fix of a problem. The problem is resolved when all the edits are applied.
+type Fix struct {
+	Edits []Edit
+}
+
+func NewFix(edits ...Edit) Fix {
+	return Fix{Edits: edits}
+}
+
+// Edit is an edit of the inputs of checkers. The concrete types are defined by the packages that define the inputs.
+type Edit interface {
+	Write(w io.Writer) error
+}
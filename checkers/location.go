@@ -22,3 +22,42 @@ func CompareLocation(a, b Location, sb *strings.Builder) int {
 	bs := sb.String()
 	return strings.Compare(as, bs)
 }
+
+// SpanKind is the kind of the part of the input files that a span points to.
+type SpanKind string
+
+const (
+	// SpanKindDrawIO is a cell on a page of a draw.io file.
+	SpanKindDrawIO SpanKind = "DRAWIO"
+
+	// SpanKindTable is a row or a cell of a table file.
+	SpanKindTable SpanKind = "TABLE"
+
+	// SpanKindText is a part of the text in a cell of a table file, such as a reference in a start condition.
+	SpanKindText SpanKind = "TEXT"
+)
+
+// Span is a structured part of the input files that a location points to. Fields that the kind does not have are zero.
+type Span struct {
+	Kind SpanKind `json:"kind"`
+	Path string   `json:"path,omitempty"`
+
+	// Page, PageID and CellID are the page name, the page ID and the cell ID of draw.io files.
+	Page   string `json:"page,omitempty"`
+	PageID string `json:"page_id,omitempty"`
+	CellID string `json:"cell_id,omitempty"`
+
+	// Row is the row number of table files, where the header is the row 1. It is 0 if the row is unknown, for example
+	// because the row is missing. Column is the header of the column, and it is empty for whole rows.
+	Row    int    `json:"row,omitempty"`
+	Column string `json:"column,omitempty"`
+
+	// Range is the range of the text in the cell.
+	Range *TextRange `json:"range,omitempty"`
+}
+
+// TextRange is a range of a text in characters. Start is inclusive and End is exclusive.
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
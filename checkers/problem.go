@@ -3,6 +3,8 @@ package checkers
 import (
 	"slices"
 	"strings"
+
+	"github.com/Kuniwak/pfd-tools/locale"
 )
 
 type ProblemID string
@@ -11,12 +13,31 @@ type Problem struct {
 	Locations []Location
 	ProblemID ProblemID
 	Severity  Severity
+
+	// Fixes are the alternative fixes of the problem. Problems that cannot be fixed automatically have no fixes.
+	Fixes []Fix
+
+	// Messages maps locales to the messages of the problem. Problems of the built-in checkers have no messages, and
+	// reporters use the messages of the problem IDs instead.
+	Messages map[locale.Locale]string
 }
 
 func NewProblem(problemID ProblemID, severity Severity, locations ...Location) Problem {
 	return Problem{Locations: locations, ProblemID: problemID, Severity: severity}
 }
 
+// WithFixes returns the problem with the fixes.
+func (p Problem) WithFixes(fixes ...Fix) Problem {
+	p.Fixes = fixes
+	return p
+}
+
+// WithMessages returns the problem with the messages.
+func (p Problem) WithMessages(messages map[locale.Locale]string) Problem {
+	p.Messages = messages
+	return p
+}
+
 func CompareProblem(a, b Problem, sb *strings.Builder) int {
 	var i int
 
@@ -2,6 +2,7 @@ package checkers
 
 import (
 	"fmt"
+	"strings"
 )
 
 type Severity int
@@ -28,3 +29,13 @@ const (
 	SeverityStyleProblem
 	SeverityWarning
 )
+
+// ParseSeverity parses the severity written by Severity.String. It is case-insensitive.
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range []Severity{SeverityError, SeverityStyleProblem, SeverityWarning} {
+		if strings.EqualFold(s, severity.String()) {
+			return severity, nil
+		}
+	}
+	return 0, fmt.Errorf("checkers.ParseSeverity: unknown severity: %q", s)
+}
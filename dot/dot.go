@@ -19,9 +19,24 @@ type Digraph struct {
 	NodeAttributes  *sets.Set[*pairs.Pair[AttributeName, AttributeValue]]
 	EdgeAttributes  *sets.Set[*pairs.Pair[AttributeName, AttributeValue]]
 	Nodes           []*Node
+	Subgraphs       []*Subgraph
 	Edges           []*Edge
 }
 
+// Subgraph is a subgraph in a digraph. Graphviz draws subgraphs named with the "cluster" prefix as boxes that enclose
+// their nodes.
+type Subgraph struct {
+	Name            string
+	GraphAttributes *sets.Set[*pairs.Pair[AttributeName, AttributeValue]]
+	Nodes           []*Node
+	Subgraphs       []*Subgraph
+}
+
+// ClusterName returns the name of the subgraph that Graphviz draws as a cluster.
+func ClusterName(id NodeID) string {
+	return "cluster_" + string(id)
+}
+
 type NodeID string
 
 type Node struct {
@@ -36,13 +51,19 @@ func CompareAttribute(a, b *pairs.Pair[AttributeName, AttributeValue]) int {
 type AttributeName string
 
 const (
-	AttributeNameLabel     AttributeName = "label"
-	AttributeNameShape     AttributeName = "shape"
-	AttributeNameColor     AttributeName = "color"
-	AttributeNameFillColor AttributeName = "fillcolor"
-	AttributeNameCharset   AttributeName = "charset"
-	AttributeNameStyle     AttributeName = "style"
-	AttributeNameFontColor AttributeName = "fontcolor"
+	AttributeNameLabel       AttributeName = "label"
+	AttributeNameShape       AttributeName = "shape"
+	AttributeNameColor       AttributeName = "color"
+	AttributeNameFillColor   AttributeName = "fillcolor"
+	AttributeNameCharset     AttributeName = "charset"
+	AttributeNameStyle       AttributeName = "style"
+	AttributeNameFontColor   AttributeName = "fontcolor"
+	AttributeNamePenWidth    AttributeName = "penwidth"
+	AttributeNamePeripheries AttributeName = "peripheries"
+	AttributeNameTooltip     AttributeName = "tooltip"
+	AttributeNameCompound    AttributeName = "compound"
+	AttributeNameLHead       AttributeName = "lhead"
+	AttributeNameLTail       AttributeName = "ltail"
 )
 
 func (a AttributeName) String() string {
@@ -160,18 +181,10 @@ func (d Digraph) Write(w io.Writer) error {
 	io.WriteString(w, "];\n")
 
 	for _, node := range d.Nodes {
-		io.WriteString(w, "  ")
-		io.WriteString(w, string(node.ID))
-		io.WriteString(w, " [")
-		for _, pair := range node.Attributes.Iter() {
-			name := pair.First
-			value := pair.Second
-			io.WriteString(w, name.String())
-			io.WriteString(w, "=")
-			renderAttributeValue(w, value, buf)
-			io.WriteString(w, "; ")
-		}
-		io.WriteString(w, "];\n")
+		writeNode(w, "  ", node, buf)
+	}
+	for _, subgraph := range d.Subgraphs {
+		writeSubgraph(w, "  ", subgraph, buf)
 	}
 	for _, edge := range d.Edges {
 		io.WriteString(w, "  ")
@@ -192,6 +205,52 @@ func (d Digraph) Write(w io.Writer) error {
 	return nil
 }
 
+func writeNode(w io.Writer, indent string, node *Node, buf *bytes.Buffer) {
+	io.WriteString(w, indent)
+	io.WriteString(w, string(node.ID))
+	io.WriteString(w, " [")
+	for _, pair := range node.Attributes.Iter() {
+		name := pair.First
+		value := pair.Second
+		io.WriteString(w, name.String())
+		io.WriteString(w, "=")
+		renderAttributeValue(w, value, buf)
+		io.WriteString(w, "; ")
+	}
+	io.WriteString(w, "];\n")
+}
+
+func writeSubgraph(w io.Writer, indent string, subgraph *Subgraph, buf *bytes.Buffer) {
+	io.WriteString(w, indent)
+	io.WriteString(w, "subgraph ")
+	writeString(w, subgraph.Name, buf)
+	io.WriteString(w, " {\n")
+
+	childIndent := indent + "  "
+	if subgraph.GraphAttributes.Len() > 0 {
+		io.WriteString(w, childIndent)
+		io.WriteString(w, "graph [")
+		for i, pair := range subgraph.GraphAttributes.Iter() {
+			if i > 0 {
+				io.WriteString(w, "; ")
+			}
+			io.WriteString(w, pair.First.String())
+			io.WriteString(w, "=")
+			renderAttributeValue(w, pair.Second, buf)
+		}
+		io.WriteString(w, "];\n")
+	}
+	for _, node := range subgraph.Nodes {
+		writeNode(w, childIndent, node, buf)
+	}
+	for _, child := range subgraph.Subgraphs {
+		writeSubgraph(w, childIndent, child, buf)
+	}
+
+	io.WriteString(w, indent)
+	io.WriteString(w, "}\n")
+}
+
 func writeString(w io.Writer, s string, buf *bytes.Buffer) error {
 	buf.Reset()
 	e := json.NewEncoder(buf)
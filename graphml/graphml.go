@@ -0,0 +1,167 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+const Namespace = "http://graphml.graphdrawing.org/xmlns"
+
+type Document struct {
+	Keys  []*Key
+	Graph *Graph
+}
+
+type KeyID string
+
+type KeyFor string
+
+const (
+	KeyForGraph KeyFor = "graph"
+	KeyForNode  KeyFor = "node"
+	KeyForEdge  KeyFor = "edge"
+)
+
+type KeyType string
+
+const (
+	KeyTypeString  KeyType = "string"
+	KeyTypeBoolean KeyType = "boolean"
+	KeyTypeInt     KeyType = "int"
+	KeyTypeDouble  KeyType = "double"
+)
+
+// Key declares a data key. Name is the attribute name that tools such as yEd and Gephi display.
+type Key struct {
+	ID   KeyID
+	For  KeyFor
+	Name string
+	Type KeyType
+}
+
+type Graph struct {
+	ID       string
+	Directed bool
+	Data     []*Data
+	Nodes    []*Node
+	Edges    []*Edge
+}
+
+type NodeID string
+
+type Node struct {
+	ID   NodeID
+	Data []*Data
+}
+
+type Edge struct {
+	ID     string
+	Source NodeID
+	Target NodeID
+	Data   []*Data
+}
+
+type Data struct {
+	Key   KeyID
+	Value string
+}
+
+type xmlGraphML struct {
+	XMLName xml.Name  `xml:"graphml"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Keys    []xmlKey  `xml:"key"`
+	Graph   *xmlGraph `xml:"graph"`
+}
+
+type xmlKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type xmlGraph struct {
+	ID          string    `xml:"id,attr"`
+	EdgeDefault string    `xml:"edgedefault,attr"`
+	Data        []xmlData `xml:"data"`
+	Nodes       []xmlNode `xml:"node"`
+	Edges       []xmlEdge `xml:"edge"`
+}
+
+type xmlNode struct {
+	ID   string    `xml:"id,attr"`
+	Data []xmlData `xml:"data"`
+}
+
+type xmlEdge struct {
+	ID     string    `xml:"id,attr,omitempty"`
+	Source string    `xml:"source,attr"`
+	Target string    `xml:"target,attr"`
+	Data   []xmlData `xml:"data"`
+}
+
+type xmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func newXMLData(data []*Data) []xmlData {
+	res := make([]xmlData, 0, len(data))
+	for _, d := range data {
+		res = append(res, xmlData{Key: string(d.Key), Value: d.Value})
+	}
+	return res
+}
+
+func (d *Document) Write(w io.Writer) error {
+	doc := xmlGraphML{
+		Xmlns: Namespace,
+		Keys:  make([]xmlKey, 0, len(d.Keys)),
+	}
+	for _, key := range d.Keys {
+		doc.Keys = append(doc.Keys, xmlKey{
+			ID:       string(key.ID),
+			For:      string(key.For),
+			AttrName: key.Name,
+			AttrType: string(key.Type),
+		})
+	}
+
+	edgeDefault := "undirected"
+	if d.Graph.Directed {
+		edgeDefault = "directed"
+	}
+	g := &xmlGraph{
+		ID:          d.Graph.ID,
+		EdgeDefault: edgeDefault,
+		Data:        newXMLData(d.Graph.Data),
+		Nodes:       make([]xmlNode, 0, len(d.Graph.Nodes)),
+		Edges:       make([]xmlEdge, 0, len(d.Graph.Edges)),
+	}
+	for _, node := range d.Graph.Nodes {
+		g.Nodes = append(g.Nodes, xmlNode{ID: string(node.ID), Data: newXMLData(node.Data)})
+	}
+	for _, edge := range d.Graph.Edges {
+		g.Edges = append(g.Edges, xmlEdge{
+			ID:     edge.ID,
+			Source: string(edge.Source),
+			Target: string(edge.Target),
+			Data:   newXMLData(edge.Data),
+		})
+	}
+	doc.Graph = g
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("graphml.Document.Write: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("graphml.Document.Write: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("graphml.Document.Write: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package locale
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 type Locale string
 
@@ -13,12 +17,28 @@ func (l Locale) String() string {
 	return string(l)
 }
 
+// Base returns the language of the locale without regions and scripts, such as zh for zh-TW.
+func (l Locale) Base() Locale {
+	base, _, _ := strings.Cut(string(l), "-")
+	return Locale(base)
+}
+
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// Parse returns the locale of the language tag such as ja, en and zh-TW. Underscores are read as hyphens, and the
+// language is lowercased. Empty strings are English.
 func Parse(s string) (Locale, error) {
-	switch s {
-	case LocaleJa.String():
-		return LocaleJa, nil
-	case LocaleEn.String(), "":
+	if s == "" {
 		return LocaleEn, nil
 	}
-	return LocaleEn, fmt.Errorf("locale.ParseLocale: unknown locale: %q", s)
+	s = strings.ReplaceAll(s, "_", "-")
+	if !localePattern.MatchString(s) {
+		return LocaleEn, fmt.Errorf("locale.ParseLocale: unknown locale: %q", s)
+	}
+	lang, rest, ok := strings.Cut(s, "-")
+	lang = strings.ToLower(lang)
+	if !ok {
+		return Locale(lang), nil
+	}
+	return Locale(lang + "-" + rest), nil
 }
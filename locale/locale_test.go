@@ -0,0 +1,63 @@
+package locale
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	testCases := map[string]struct {
+		Text     string
+		Expected Locale
+		Base     Locale
+		Error    bool
+	}{
+		"empty": {
+			Text:     "",
+			Expected: LocaleEn,
+			Base:     LocaleEn,
+		},
+		"Japanese": {
+			Text:     "ja",
+			Expected: LocaleJa,
+			Base:     LocaleJa,
+		},
+		"new language": {
+			Text:     "ko",
+			Expected: "ko",
+			Base:     "ko",
+		},
+		"region": {
+			Text:     "zh-TW",
+			Expected: "zh-TW",
+			Base:     "zh",
+		},
+		"POSIX style": {
+			Text:     "ZH_tw",
+			Expected: "zh-tw",
+			Base:     "zh",
+		},
+		"malformed": {
+			Text:  "../en",
+			Error: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Parse(testCase.Text)
+			if testCase.Error {
+				if err == nil {
+					t.Errorf("want error, got %q", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != testCase.Expected {
+				t.Errorf("want %q, got %q", testCase.Expected, actual)
+			}
+			if actual.Base() != testCase.Base {
+				t.Errorf("want base %q, got %q", testCase.Base, actual.Base())
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package pfd
+
+import (
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// ExpandCompositeDeliverableEdges returns the edges with edges from the members of composite deliverables.
+// Diagrams connect edges to composite deliverables, so edges to their members are derived from the deliverable composition.
+func ExpandCompositeDeliverableEdges(edges *sets.Set[*Edge], deliverableComposition map[NodeID]*sets.Set[NodeID]) *sets.Set[*Edge] {
+	newEdges := edges.Clone()
+	for _, edge := range edges.Iter() {
+		var ok bool
+		var ns *sets.Set[NodeID]
+
+		ns, ok = deliverableComposition[edge.Source]
+		if !ok {
+			continue
+		}
+		for _, newSource := range ns.Iter() {
+			newEdges.Add((*Edge).Compare, &Edge{Source: newSource, Target: edge.Target, IsFeedback: edge.IsFeedback})
+		}
+
+		ns, ok = deliverableComposition[edge.Target]
+		if !ok {
+			continue
+		}
+		for _, newTarget := range ns.Iter() {
+			newEdges.Add((*Edge).Compare, &Edge{Source: edge.Source, Target: newTarget, IsFeedback: edge.IsFeedback})
+		}
+	}
+	return newEdges
+}
+
+// IsDerivedFromCompositeDeliverable returns true if ExpandCompositeDeliverableEdges derives the edge from an edge of
+// a composite deliverable. Writers for diagrams use this to omit such edges.
+func (p *PFD) IsDerivedFromCompositeDeliverable(e *Edge) bool {
+	for comp, members := range p.DeliverableComposition {
+		if comp == e.Source || !members.Contains(NodeID.Compare, e.Source) {
+			continue
+		}
+		if p.Edges.Contains((*Edge).Compare, &Edge{Source: comp, Target: e.Target, IsFeedback: e.IsFeedback}) {
+			return true
+		}
+	}
+	return false
+}
@@ -22,13 +22,14 @@ var ConsistentGTable = checkers.AtomicChecker[*fsmcommon.Target]{
 		}
 
 		expected := sets.New(fsmmasterschedule.Group.Compare)
+		column := fsmtable.DefaultGroupColumnMatchFunc.Header(t.AtomicProcessTable.ExtraHeaders)
 		for ap, groupIDsText := range t.Memoized.GroupMap {
 			groups, err := fsmtable.ParseGroups(groupIDsText)
 			if err != nil {
 				ch <- checkers.NewProblem(
 					malformedGroupProblemID,
 					checkers.SeverityError,
-					fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)),
+					fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)).WithColumn(column),
 				)
 				continue
 			}
@@ -0,0 +1,129 @@
+package fsmchecker
+
+import (
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// FeasibleResources reports atomic processes that have no allocation elements satisfiable from the resource table,
+// resources that every atomic process needs although some atomic processes may run in parallel, and resources that no
+// atomic process can ever use.
+var FeasibleResources = checkers.AtomicChecker[*fsmcommon.Target]{
+	ID: "feasible-resources",
+	AvailableIfFunc: func(t *fsmcommon.Target) bool {
+		return t.Memoized.HasAllResources && t.Memoized.HasNeededResourceSetsMap
+	},
+	CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
+		const infeasibleResourcesProblemID = "infeasible-resources"
+		const serializedResourceProblemID = "serialized-resource"
+		const unusableResourceProblemID = "unusable-resource"
+
+		available := t.Memoized.AllResources
+		column := fsmtable.DefaultNeededResourceSetsColumnSelectFunc.Header(t.AtomicProcessTable.ExtraHeaders)
+
+		// NOTE: Atomic processes without parsable and non-empty resource sets are reported by valid-resources-set.
+		feasibleMap := make(map[pfd.AtomicProcessID][]fsm.AllocationElement)
+		referenced := sets.New(fsm.ResourceID.Compare)
+		for _, ap := range t.PFD.AtomicProcesses.Iter() {
+			text, ok := t.Memoized.NeededResourceSetsMap[ap]
+			if !ok {
+				continue
+			}
+			elems, err := fsmtable.ParseNeededResourceSetEntry(text)
+			if err != nil || elems.Len() == 0 {
+				continue
+			}
+
+			feasible := make([]fsm.AllocationElement, 0, elems.Len())
+			unavailable := sets.New(fsm.ResourceID.Compare)
+			for _, elem := range elems.Iter() {
+				referenced.Union(fsm.ResourceID.Compare, elem.Resources)
+				if elem.Resources.Len() > 0 && elem.Resources.IsSubsetOf(fsm.ResourceID.Compare, available) {
+					feasible = append(feasible, elem)
+					continue
+				}
+				for _, r := range elem.Resources.Iter() {
+					if !available.Contains(fsm.ResourceID.Compare, r) {
+						unavailable.Add(fsm.ResourceID.Compare, r)
+					}
+				}
+			}
+
+			if len(feasible) == 0 {
+				ids := []fsmcommon.ID{fsmcommon.NewAtomicProcessID(ap)}
+				for _, r := range unavailable.Iter() {
+					ids = append(ids, fsmcommon.NewResourceID(r))
+				}
+				ch <- checkers.NewProblem(infeasibleResourcesProblemID, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, ids...).WithColumn(column))
+				continue
+			}
+			feasibleMap[ap] = feasible
+		}
+
+		if hasParallelAtomicProcesses(t, feasibleMap) {
+			for _, r := range available.Iter() {
+				if isNeededByAll(r, feasibleMap) {
+					ch <- checkers.NewProblem(serializedResourceProblemID, checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypeResourceTable, fsmcommon.NewResourceID(r)))
+				}
+			}
+		}
+
+		a := newAllocatabilityAnalysis(t)
+		a.Run()
+		usable := sets.New(fsm.ResourceID.Compare)
+		for ap, feasible := range feasibleMap {
+			if !a.startable.Contains(pfd.AtomicProcessID.Compare, ap) {
+				continue
+			}
+			for _, elem := range feasible {
+				usable.Union(fsm.ResourceID.Compare, elem.Resources)
+			}
+		}
+		for _, r := range available.Iter() {
+			if !referenced.Contains(fsm.ResourceID.Compare, r) {
+				// NOTE: Reported by consistent-r-table.
+				continue
+			}
+			if !usable.Contains(fsm.ResourceID.Compare, r) {
+				ch <- checkers.NewProblem(unusableResourceProblemID, checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypeResourceTable, fsmcommon.NewResourceID(r)))
+			}
+		}
+		return nil
+	},
+}
+
+// hasParallelAtomicProcesses returns true if the atomic processes in the map include a pair that is not ordered by the
+// PFD except feedback.
+func hasParallelAtomicProcesses(t *fsmcommon.Target, feasibleMap map[pfd.AtomicProcessID][]fsm.AllocationElement) bool {
+	for ap1 := range feasibleMap {
+		reachable := sets.NewWithCapacity[pfd.AtomicProcessID](t.PFD.AtomicProcesses.Len())
+		t.PFD.CollectReachableAtomicProcessesExceptFeedback(ap1, reachable, t.Logger)
+		for ap2 := range feasibleMap {
+			if ap1 == ap2 || reachable.Contains(pfd.AtomicProcessID.Compare, ap2) {
+				continue
+			}
+			reachable2 := sets.NewWithCapacity[pfd.AtomicProcessID](t.PFD.AtomicProcesses.Len())
+			t.PFD.CollectReachableAtomicProcessesExceptFeedback(ap2, reachable2, t.Logger)
+			if !reachable2.Contains(pfd.AtomicProcessID.Compare, ap1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isNeededByAll returns true if every feasible allocation element of every atomic process needs the resource.
+func isNeededByAll(r fsm.ResourceID, feasibleMap map[pfd.AtomicProcessID][]fsm.AllocationElement) bool {
+	for _, feasible := range feasibleMap {
+		for _, elem := range feasible {
+			if !elem.Resources.Contains(fsm.ResourceID.Compare, r) {
+				return false
+			}
+		}
+	}
+	return true
+}
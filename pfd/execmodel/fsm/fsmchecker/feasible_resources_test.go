@@ -0,0 +1,143 @@
+package fsmchecker
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/chans"
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFeasibleResources(t *testing.T) {
+	r1r2 := &fsmtable.ResourceTable{
+		ExtraHeaders: []string{},
+		Rows: []*fsmtable.ResourceTableRow{
+			{ID: "R1", Description: "Resource 1", ExtraCells: []string{}},
+			{ID: "R2", Description: "Resource 2", ExtraCells: []string{}},
+		},
+	}
+
+	testCases := map[string]struct {
+		PFD                *pfd.PFD
+		AtomicProcessTable *pfd.AtomicProcessTable
+		ResourceTable      *fsmtable.ResourceTable
+		Want               []checkers.Problem
+	}{
+		"ok (sequential processes on a single resource)": {
+			PFD: pfd.PresetSequential,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.NeededResourceSetsColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{"R1:1"}},
+					{ID: "P2", Description: "P2", ExtraCells: []string{"R1:1"}},
+				},
+			},
+			ResourceTable: r1r2,
+			Want:          []checkers.Problem{},
+		},
+		"ok (parallel processes with alternatives)": {
+			PFD: pfd.PresetTripleBranch,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.NeededResourceSetsColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{"R1:1"}},
+					{ID: "P2", Description: "P2", ExtraCells: []string{"R1:1; R2:1"}},
+					{ID: "P3", Description: "P3", ExtraCells: []string{"R1:1"}},
+				},
+			},
+			ResourceTable: r1r2,
+			Want:          []checkers.Problem{},
+		},
+		"ng (infeasible)": {
+			PFD: pfd.PresetSequential,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.NeededResourceSetsColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{"R3:1; R1,R4:1"}},
+					{ID: "P2", Description: "P2", ExtraCells: []string{"R1:1; R2:1"}},
+				},
+			},
+			ResourceTable: r1r2,
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"infeasible-resources",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P1"),
+						fsmcommon.NewResourceID("R3"),
+						fsmcommon.NewResourceID("R4"),
+					).WithColumn(fsmtable.NeededResourceSetsColumnHeaderEn),
+				),
+			},
+		},
+		"ng (serialized)": {
+			PFD: pfd.PresetTripleBranch,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.NeededResourceSetsColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{"R1:1"}},
+					{ID: "P2", Description: "P2", ExtraCells: []string{"R1,R2:2; R1:1"}},
+					{ID: "P3", Description: "P3", ExtraCells: []string{"R1:1"}},
+				},
+			},
+			ResourceTable: r1r2,
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"serialized-resource",
+					checkers.SeverityWarning,
+					fsmcommon.NewLocation(fsmcommon.LocationTypeResourceTable, fsmcommon.NewResourceID("R1")),
+				),
+			},
+		},
+		"ng (unusable)": {
+			PFD: pfd.PresetSequential,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.NeededResourceSetsColumnHeaderEn, fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{"R1:1", ""}},
+					{ID: "P2", Description: "P2", ExtraCells: []string{"R1:1; R2:1", `!\true`}},
+				},
+			},
+			ResourceTable: r1r2,
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"unusable-resource",
+					checkers.SeverityWarning,
+					fsmcommon.NewLocation(fsmcommon.LocationTypeResourceTable, fsmcommon.NewResourceID("R2")),
+				),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p, err := pfd.NewSafePFDByUnsafePFD(testCase.PFD)
+			if err != nil {
+				t.Fatalf("pfd.NewSafePFDByUnsafePFD: %v", err)
+			}
+			m, err := fsmcommon.NewMemoized(testCase.AtomicProcessTable, nil, testCase.ResourceTable, nil)
+			if err != nil {
+				t.Fatalf("fsmcommon.NewMemoized: %v", err)
+			}
+			ch := make(chan checkers.Problem)
+			go func() {
+				defer close(ch)
+				tgt := fsmcommon.NewTarget(p, testCase.AtomicProcessTable, nil, testCase.ResourceTable, nil, nil, m, slog.New(slogtest.NewTestHandler(t)))
+				if err := FeasibleResources.Check(tgt, ch); err != nil {
+					t.Errorf("FeasibleResources.Check: %v", err)
+				}
+			}()
+			got := chans.Slice(ch)
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Error(cmp.Diff(testCase.Want, got))
+			}
+		})
+	}
+}
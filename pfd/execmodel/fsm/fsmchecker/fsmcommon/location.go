@@ -150,6 +150,13 @@ func NewLocations(ls ...Location) []checkers.Location {
 type Location struct {
 	RelatedIDs []ID
 	Type       LocationType
+
+	// Column is the header of the column if the location is a cell of the row of the first related ID. It is empty if
+	// the location is whole rows.
+	Column string
+
+	// Range is the range of the text in the cell. It is nil if the location is the whole cell.
+	Range *checkers.TextRange
 }
 
 var _ checkers.Location = Location{}
@@ -161,6 +168,18 @@ func NewLocation(t LocationType, relatedIDs ...ID) Location {
 	}
 }
 
+// WithColumn returns the location of the cell in the column.
+func (l Location) WithColumn(column string) Location {
+	l.Column = column
+	return l
+}
+
+// WithRange returns the location of the range of the text in the cell.
+func (l Location) WithRange(start, end int) Location {
+	l.Range = &checkers.TextRange{Start: start, End: end}
+	return l
+}
+
 var (
 	openSqBracket  = []byte{'['}
 	closeSqBracket = []byte{']'}
@@ -0,0 +1,256 @@
+package fsmchecker
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// NeverAllocatable reports atomic processes that can never be AllocatabilityOKStartable. It over-approximates the
+// atomic processes that may start by a fixpoint iteration, so that reported atomic processes never start in any plan.
+var NeverAllocatable = checkers.AtomicChecker[*fsmcommon.Target]{
+	ID: "never-allocatable",
+	AvailableIfFunc: func(t *fsmcommon.Target) bool {
+		return true
+	},
+	CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
+		const insufficientInputsProblemID = "never-allocatable-inputs"
+		const preconditionProblemID = "never-allocatable-precondition"
+
+		a := newAllocatabilityAnalysis(t)
+		a.Run()
+
+		for _, ap := range t.PFD.AtomicProcesses.Iter() {
+			if a.startable.Contains(pfd.AtomicProcessID.Compare, ap) {
+				continue
+			}
+
+			ids := []fsmcommon.ID{fsmcommon.NewAtomicProcessID(ap)}
+			for _, d := range t.PFD.InputDeliverablesExceptFeedback(ap).Iter() {
+				if !a.MayBeGenerated(d) {
+					ids = append(ids, fsmcommon.NewAtomicDeliverableID(d))
+				}
+			}
+			if len(ids) > 1 {
+				ch <- checkers.NewProblem(insufficientInputsProblemID, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypePFD, ids...))
+				continue
+			}
+
+			a.collectBlockers(a.precondition(ap), &ids)
+			ch <- checkers.NewProblem(preconditionProblemID, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, ids...))
+		}
+		return nil
+	},
+}
+
+type allocatabilityAnalysis struct {
+	pfd           *pfd.ValidPFD
+	preconditions map[pfd.AtomicProcessID]*fsm.Precondition
+	maxRevisions  map[pfd.AtomicDeliverableID]int
+	logger        *slog.Logger
+
+	// startable is the atomic processes that may become AllocatabilityOKStartable.
+	startable *sets.Set[pfd.AtomicProcessID]
+
+	// rerunnable is the atomic processes that may complete twice or more.
+	rerunnable *sets.Set[pfd.AtomicProcessID]
+}
+
+// newAllocatabilityAnalysis returns the analysis. Malformed preconditions and max revisions are regarded as the most
+// permissive ones, because they are reported by the other checkers.
+func newAllocatabilityAnalysis(t *fsmcommon.Target) *allocatabilityAnalysis {
+	preconditions := make(map[pfd.AtomicProcessID]*fsm.Precondition)
+	if t.Memoized.HasPreconditionMap {
+		for ap, text := range t.Memoized.PreconditionMap {
+			p, err := fsmtable.ParsePrecondition(text, ap)
+			if err != nil {
+				continue
+			}
+			preconditions[ap] = p
+		}
+	}
+
+	maxRevisions := make(map[pfd.AtomicDeliverableID]int)
+	if t.Memoized.HasMaxRevisionMap {
+		for _, d := range t.PFD.FeedbackSourceDeliverables().Iter() {
+			maxRevision, err := fsmtable.ValidateMaxRevision(t.Memoized.MaxRevisionMap[d], true)
+			if err != nil {
+				continue
+			}
+			maxRevisions[d] = maxRevision
+		}
+	}
+
+	return &allocatabilityAnalysis{
+		pfd:           t.PFD,
+		preconditions: preconditions,
+		maxRevisions:  maxRevisions,
+		logger:        t.Logger,
+		startable:     sets.New(pfd.AtomicProcessID.Compare),
+		rerunnable:    sets.New(pfd.AtomicProcessID.Compare),
+	}
+}
+
+// Run computes the least fixpoint of startable and rerunnable.
+func (a *allocatabilityAnalysis) Run() {
+	changed := true
+	for changed {
+		changed = false
+		for _, ap := range a.pfd.AtomicProcesses.Iter() {
+			if !a.startable.Contains(pfd.AtomicProcessID.Compare, ap) && a.mayStart(ap) {
+				a.startable.Add(pfd.AtomicProcessID.Compare, ap)
+				changed = true
+			}
+			if a.startable.Contains(pfd.AtomicProcessID.Compare, ap) && !a.rerunnable.Contains(pfd.AtomicProcessID.Compare, ap) && a.mayRerun(ap) {
+				a.rerunnable.Add(pfd.AtomicProcessID.Compare, ap)
+				changed = true
+			}
+		}
+	}
+}
+
+// MayBeGenerated returns false if the deliverable is never generated.
+func (a *allocatabilityAnalysis) MayBeGenerated(d pfd.AtomicDeliverableID) bool {
+	if a.pfd.InitialDeliverables().Contains(pfd.AtomicDeliverableID.Compare, d) {
+		return true
+	}
+	src, ok := a.pfd.SourceAtomicProcess(d)
+	return ok && a.startable.Contains(pfd.AtomicProcessID.Compare, src)
+}
+
+// mayComplete returns false if the revision of the feedback source deliverable never reaches the max revision.
+func (a *allocatabilityAnalysis) mayComplete(d pfd.AtomicDeliverableID) bool {
+	if !a.pfd.FeedbackSourceDeliverables().Contains(pfd.AtomicDeliverableID.Compare, d) {
+		// NOTE: Should be reported by ValidPrecondition, so regard it as satisfiable.
+		return true
+	}
+	if !a.MayBeGenerated(d) {
+		return false
+	}
+	maxRevision, ok := a.maxRevisions[d]
+	if !ok || maxRevision <= 1 {
+		return true
+	}
+	src, ok := a.pfd.SourceAtomicProcess(d)
+	return !ok || a.rerunnable.Contains(pfd.AtomicProcessID.Compare, src)
+}
+
+func (a *allocatabilityAnalysis) mayStart(ap pfd.AtomicProcessID) bool {
+	for _, d := range a.pfd.InputDeliverablesExceptFeedback(ap).Iter() {
+		if !a.MayBeGenerated(d) {
+			return false
+		}
+	}
+	mayBeTrue, _ := a.mayHold(a.precondition(ap))
+	return mayBeTrue
+}
+
+// mayRerun returns true if inputs of the atomic process may be updated after the first execution.
+func (a *allocatabilityAnalysis) mayRerun(ap pfd.AtomicProcessID) bool {
+	for _, d := range a.pfd.InputDeliverablesExceptFeedback(ap).Iter() {
+		src, ok := a.pfd.SourceAtomicProcess(d)
+		if ok && a.rerunnable.Contains(pfd.AtomicProcessID.Compare, src) {
+			return true
+		}
+	}
+	for _, d := range a.pfd.InputDeliverablesOnlyFeedback(ap).Iter() {
+		if a.pfd.InitialDeliverables().Contains(pfd.AtomicDeliverableID.Compare, d) {
+			return true
+		}
+		// NOTE: Feedback deliverables reaching the max revision do not update the destinations.
+		maxRevision, ok := a.maxRevisions[d]
+		if a.MayBeGenerated(d) && (!ok || maxRevision >= 2) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *allocatabilityAnalysis) precondition(ap pfd.AtomicProcessID) *fsm.Precondition {
+	p, ok := a.preconditions[ap]
+	if !ok {
+		return fsm.NewTruePrecondition()
+	}
+	return p.Compile(a.pfd, a.logger)
+}
+
+// mayHold returns whether the precondition may be true and whether it may be false in some states.
+func (a *allocatabilityAnalysis) mayHold(p *fsm.Precondition) (bool, bool) {
+	switch p.Type {
+	case fsm.PreconditionTypeTrue:
+		return true, false
+
+	case fsm.PreconditionTypeExecutable:
+		if !a.pfd.AtomicProcesses.Contains(pfd.AtomicProcessID.Compare, p.Executable) {
+			// NOTE: Should be reported by ValidPrecondition, so regard it as satisfiable.
+			return true, true
+		}
+		return a.startable.Contains(pfd.AtomicProcessID.Compare, p.Executable), true
+
+	case fsm.PreconditionTypeFeedbackSourceCompleted:
+		return a.mayComplete(p.FeedbackSource), true
+
+	case fsm.PreconditionTypeNot:
+		mayBeTrue, mayBeFalse := a.mayHold(p.Not)
+		return mayBeFalse, mayBeTrue
+
+	case fsm.PreconditionTypeOr:
+		mayBeTrue, mayBeFalse := false, true
+		for _, q := range p.Or {
+			t, f := a.mayHold(q)
+			mayBeTrue = mayBeTrue || t
+			mayBeFalse = mayBeFalse && f
+		}
+		return mayBeTrue, mayBeFalse
+
+	case fsm.PreconditionTypeAnd:
+		mayBeTrue, mayBeFalse := true, false
+		for _, q := range p.And {
+			t, f := a.mayHold(q)
+			mayBeTrue = mayBeTrue && t
+			mayBeFalse = mayBeFalse || f
+		}
+		return mayBeTrue, mayBeFalse
+
+	case fsm.PreconditionTypeAllBackwardReachableFeedbackSourcesCompleted:
+		return a.mayHold(p.Compile(a.pfd, a.logger))
+
+	default:
+		panic(fmt.Sprintf("fsmchecker.allocatabilityAnalysis.mayHold: invalid type: %q", p.Type))
+	}
+}
+
+// collectBlockers collects the atomic processes and the deliverables that make the precondition unsatisfiable.
+// Preconditions under negations are not collected because they are always satisfiable by the analysis.
+func (a *allocatabilityAnalysis) collectBlockers(p *fsm.Precondition, ids *[]fsmcommon.ID) {
+	switch p.Type {
+	case fsm.PreconditionTypeExecutable:
+		if mayBeTrue, _ := a.mayHold(p); !mayBeTrue {
+			*ids = append(*ids, fsmcommon.NewAtomicProcessID(p.Executable))
+		}
+	case fsm.PreconditionTypeFeedbackSourceCompleted:
+		if mayBeTrue, _ := a.mayHold(p); !mayBeTrue {
+			*ids = append(*ids, fsmcommon.NewAtomicDeliverableID(p.FeedbackSource))
+		}
+	case fsm.PreconditionTypeOr:
+		for _, q := range p.Or {
+			a.collectBlockers(q, ids)
+		}
+	case fsm.PreconditionTypeAnd:
+		for _, q := range p.And {
+			a.collectBlockers(q, ids)
+		}
+	case fsm.PreconditionTypeAllBackwardReachableFeedbackSourcesCompleted:
+		a.collectBlockers(p.Compile(a.pfd, a.logger), ids)
+	case fsm.PreconditionTypeTrue, fsm.PreconditionTypeNot:
+		break
+	default:
+		panic(fmt.Sprintf("fsmchecker.allocatabilityAnalysis.collectBlockers: invalid type: %q", p.Type))
+	}
+}
@@ -0,0 +1,147 @@
+package fsmchecker
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/chans"
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNeverAllocatable(t *testing.T) {
+	testCases := map[string]struct {
+		PFD                    *pfd.PFD
+		AtomicProcessTable     *pfd.AtomicProcessTable
+		AtomicDeliverableTable *pfd.AtomicDeliverableTable
+		Want                   []checkers.Problem
+	}{
+		"ok (no preconditions)": {
+			PFD:  pfd.PresetSequential,
+			Want: []checkers.Problem{},
+		},
+		"ok (executable reference)": {
+			PFD: pfd.PresetTripleBranch,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{`\exec(P2)`}},
+					{ID: "P2", Description: "P2", ExtraCells: []string{`!\exec(P3)`}},
+					{ID: "P3", Description: "P3", ExtraCells: []string{``}},
+				},
+			},
+			Want: []checkers.Problem{},
+		},
+		"ok (feedback loop)": {
+			PFD: pfd.PresetSmallestLoop,
+			AtomicDeliverableTable: &pfd.AtomicDeliverableTable{
+				ExtraHeaders: []string{fsmtable.MaxRevisionHeaderEn},
+				Rows: []*pfd.AtomicDeliverableRow{
+					{ID: "D1", Description: "D1", ExtraCells: []string{"-"}},
+					{ID: "D2", Description: "D2", ExtraCells: []string{"3"}},
+				},
+			},
+			Want: []checkers.Problem{},
+		},
+		"ng (executable reference to a downstream process)": {
+			PFD: pfd.PresetSequential,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{`\exec(P2)`}},
+					{ID: "P2", Description: "P2", ExtraCells: []string{``}},
+				},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"never-allocatable-precondition",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P1"),
+						fsmcommon.NewAtomicProcessID("P2"),
+					),
+				),
+				checkers.NewProblem(
+					"never-allocatable-inputs",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypePFD,
+						fsmcommon.NewAtomicProcessID("P2"),
+						fsmcommon.NewAtomicDeliverableID("D2"),
+					),
+				),
+			},
+		},
+		"ng (completion of own output)": {
+			PFD: pfd.PresetSmallestLoop,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{`\complete(D2) || \exec(P1)`}},
+				},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"never-allocatable-precondition",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P1"),
+						fsmcommon.NewAtomicDeliverableID("D2"),
+						fsmcommon.NewAtomicProcessID("P1"),
+					),
+				),
+			},
+		},
+		"ng (unsatisfiable negation)": {
+			PFD: pfd.PresetSmallest,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "P1", ExtraCells: []string{`!\true`}},
+				},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"never-allocatable-precondition",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P1"),
+					),
+				),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p, err := pfd.NewSafePFDByUnsafePFD(testCase.PFD)
+			if err != nil {
+				t.Fatalf("pfd.NewSafePFDByUnsafePFD: %v", err)
+			}
+			m, err := fsmcommon.NewMemoized(testCase.AtomicProcessTable, testCase.AtomicDeliverableTable, nil, nil)
+			if err != nil {
+				t.Fatalf("fsmcommon.NewMemoized: %v", err)
+			}
+			ch := make(chan checkers.Problem)
+			go func() {
+				defer close(ch)
+				tgt := fsmcommon.NewTarget(p, testCase.AtomicProcessTable, testCase.AtomicDeliverableTable, nil, nil, nil, m, slog.New(slogtest.NewTestHandler(t)))
+				if err := NeverAllocatable.Check(tgt, ch); err != nil {
+					t.Errorf("NeverAllocatable.Check: %v", err)
+				}
+			}()
+			got := chans.Slice(ch)
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Error(cmp.Diff(testCase.Want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package fsmchecker
+
+import (
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// SanePrecondition reports start conditions that are well-formed but meaningless: references to unknown nodes,
+// references to the atomic process itself, completions of feedback loops unrelated to the atomic process, and
+// conditions that are unsatisfiable or always true by their boolean structure.
+var SanePrecondition = checkers.AtomicChecker[*fsmcommon.Target]{
+	ID: "sane-precondition",
+	AvailableIfFunc: func(t *fsmcommon.Target) bool {
+		return t.Memoized.HasPreconditionMap
+	},
+	CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
+		const preconditionUnknownIDProblemID = "precondition-unknown-id"
+		const preconditionSelfReferenceProblemID = "precondition-self-reference"
+		const preconditionUnrelatedFeedbackSourceProblemID = "precondition-unrelated-feedback-source"
+		const preconditionUnsatisfiableProblemID = "precondition-unsatisfiable"
+		const preconditionAlwaysTrueProblemID = "precondition-always-true"
+
+		for _, ap := range t.PFD.AtomicProcesses.Iter() {
+			preconditionText, ok := t.Memoized.PreconditionMap[ap]
+			if !ok {
+				continue
+			}
+			precondition, err := fsmtable.ParsePrecondition(preconditionText, ap)
+			if err != nil {
+				// NOTE: Reported by ValidPrecondition.
+				continue
+			}
+
+			aps := sets.NewWithCapacity[pfd.AtomicProcessID](t.PFD.AtomicProcesses.Len())
+			t.PFD.CollectBackwardReachableAtomicProcessesExceptFeedback(ap, aps, t.Logger)
+			aps.Add(pfd.AtomicProcessID.Compare, ap)
+
+			precondition.Traverse(func(p *fsm.Precondition) {
+				switch p.Type {
+				case fsm.PreconditionTypeFeedbackSourceCompleted:
+					loc := preconditionLocation(t, ap, p, fsmcommon.NewAtomicDeliverableID(p.FeedbackSource))
+					if !isPFDNodeID(t.PFD, string(p.FeedbackSource)) {
+						ch <- checkers.NewProblem(preconditionUnknownIDProblemID, checkers.SeverityError, loc)
+						return
+					}
+
+					dsts := t.PFD.FeedbackDestinationAtomicProcesses(p.FeedbackSource)
+					if dsts.Len() == 0 {
+						// NOTE: Reported by ValidPrecondition.
+						return
+					}
+					if dsts.IsDisjointWith(pfd.AtomicProcessID.Compare, aps) {
+						ch <- checkers.NewProblem(preconditionUnrelatedFeedbackSourceProblemID, checkers.SeverityWarning, loc)
+					}
+
+				case fsm.PreconditionTypeExecutable:
+					loc := preconditionLocation(t, ap, p, fsmcommon.NewAtomicProcessID(p.Executable))
+					if !isPFDNodeID(t.PFD, string(p.Executable)) {
+						ch <- checkers.NewProblem(preconditionUnknownIDProblemID, checkers.SeverityError, loc)
+						return
+					}
+					if p.Executable == ap {
+						ch <- checkers.NewProblem(preconditionSelfReferenceProblemID, checkers.SeverityError, loc)
+					}
+				}
+			})
+
+			// NOTE: Empty cells and \true are the way to say that there are no conditions.
+			if precondition.Type == fsm.PreconditionTypeTrue {
+				continue
+			}
+			loc := preconditionLocation(t, ap, nil)
+			if !precondition.Satisfiable() {
+				ch <- checkers.NewProblem(preconditionUnsatisfiableProblemID, checkers.SeverityError, loc)
+			} else if precondition.IsTautology() {
+				ch <- checkers.NewProblem(preconditionAlwaysTrueProblemID, checkers.SeverityWarning, loc)
+			}
+		}
+		return nil
+	},
+}
+
+// isPFDNodeID returns true if the ID is an ID of any node in the PFD.
+func isPFDNodeID(p *pfd.ValidPFD, id string) bool {
+	if p.AtomicProcesses.Contains(pfd.AtomicProcessID.Compare, pfd.AtomicProcessID(id)) {
+		return true
+	}
+	if p.AtomicDeliverables.Contains(pfd.AtomicDeliverableID.Compare, pfd.AtomicDeliverableID(id)) {
+		return true
+	}
+	if _, ok := p.CompositeProcessDescriptionMap[pfd.CompositeProcessID(id)]; ok {
+		return true
+	}
+	_, ok := p.CompositeDeliverableDescriptionMap[pfd.CompositeDeliverableID(id)]
+	return ok
+}
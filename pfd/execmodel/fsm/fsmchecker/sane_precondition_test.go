@@ -0,0 +1,177 @@
+package fsmchecker
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/chans"
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSanePrecondition(t *testing.T) {
+	testCases := map[string]struct {
+		PFD                *pfd.PFD
+		AtomicProcessTable *pfd.AtomicProcessTable
+		Want               []checkers.Problem
+	}{
+		"ok": {
+			PFD: pfd.PresetButterflyLoop,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "Process 1", ExtraCells: []string{``}},
+					{ID: "P2", Description: "Process 2", ExtraCells: []string{`\true`}},
+					{ID: "P3", Description: "Process 3", ExtraCells: []string{`\complete(D4) && !\exec(P1) && !\exec(P2)`}},
+				},
+			},
+			Want: []checkers.Problem{},
+		},
+		"ng (unknown ID)": {
+			PFD: pfd.PresetButterflyLoop,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "Process 1", ExtraCells: []string{``}},
+					{ID: "P2", Description: "Process 2", ExtraCells: []string{``}},
+					{ID: "P3", Description: "Process 3", ExtraCells: []string{`\complete(D9) || \exec(P9)`}},
+				},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"precondition-unknown-id",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P3"),
+						fsmcommon.NewAtomicDeliverableID("D9"),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn).WithRange(0, 13),
+				),
+				checkers.NewProblem(
+					"precondition-unknown-id",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P3"),
+						fsmcommon.NewAtomicProcessID("P9"),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn).WithRange(17, 26),
+				),
+			},
+		},
+		"ng (self reference)": {
+			PFD: pfd.PresetButterflyLoop,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "Process 1", ExtraCells: []string{``}},
+					{ID: "P2", Description: "Process 2", ExtraCells: []string{``}},
+					{ID: "P3", Description: "Process 3", ExtraCells: []string{`!\exec(P3) && !\exec(P1)`}},
+				},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"precondition-self-reference",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P3"),
+						fsmcommon.NewAtomicProcessID("P3"),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn).WithRange(1, 10),
+				),
+			},
+		},
+		"ng (unrelated feedback source)": {
+			PFD: pfd.PresetCrossLoop,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "Process 1", ExtraCells: []string{`\complete(D4)`}},
+					{ID: "P2", Description: "Process 2", ExtraCells: []string{`\complete(D3)`}},
+					{ID: "P3", Description: "Process 3", ExtraCells: []string{``}},
+				},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"precondition-unrelated-feedback-source",
+					checkers.SeverityWarning,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P1"),
+						fsmcommon.NewAtomicDeliverableID("D4"),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn).WithRange(0, 13),
+				),
+			},
+		},
+		"ng (unsatisfiable)": {
+			PFD: pfd.PresetButterflyLoop,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "Process 1", ExtraCells: []string{``}},
+					{ID: "P2", Description: "Process 2", ExtraCells: []string{``}},
+					{ID: "P3", Description: "Process 3", ExtraCells: []string{`\complete(D4) && !\complete(D4)`}},
+				},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"precondition-unsatisfiable",
+					checkers.SeverityError,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P3"),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn),
+				),
+			},
+		},
+		"ng (always true)": {
+			PFD: pfd.PresetButterflyLoop,
+			AtomicProcessTable: &pfd.AtomicProcessTable{
+				ExtraHeaders: []string{fsmtable.PreconditionColumnHeaderEn},
+				Rows: []*pfd.AtomicProcessRow{
+					{ID: "P1", Description: "Process 1", ExtraCells: []string{``}},
+					{ID: "P2", Description: "Process 2", ExtraCells: []string{``}},
+					{ID: "P3", Description: "Process 3", ExtraCells: []string{`\exec(P1) || !\exec(P1)`}},
+				},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem(
+					"precondition-always-true",
+					checkers.SeverityWarning,
+					fsmcommon.NewLocation(
+						fsmcommon.LocationTypeAtomicProcessTable,
+						fsmcommon.NewAtomicProcessID("P3"),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn),
+				),
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p, err := pfd.NewSafePFDByUnsafePFD(testCase.PFD)
+			if err != nil {
+				t.Fatalf("pfd.NewSafePFDByUnsafePFD: %v", err)
+			}
+			m, err := fsmcommon.NewMemoized(testCase.AtomicProcessTable, nil, nil, nil)
+			if err != nil {
+				t.Fatalf("fsmcommon.NewMemoized: %v", err)
+			}
+			ch := make(chan checkers.Problem)
+			go func() {
+				defer close(ch)
+				tgt := fsmcommon.NewTarget(p, testCase.AtomicProcessTable, nil, nil, nil, nil, m, slog.New(slogtest.NewTestHandler(t)))
+				if err := SanePrecondition.Check(tgt, ch); err != nil {
+					t.Errorf("SanePrecondition.Check: %v", err)
+				}
+			}()
+			got := chans.Slice(ch)
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Error(cmp.Diff(testCase.Want, got))
+			}
+		})
+	}
+}
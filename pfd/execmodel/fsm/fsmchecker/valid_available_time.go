@@ -13,6 +13,7 @@ var ValidAvailableTime = checkers.AtomicChecker[*fsmcommon.Target]{
 	},
 	CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
 		const problemID = "valid-available-time"
+		column := fsmtable.DefaultAvailableTimeColumnMatchFunc.Header(t.AtomicDeliverableTable.ExtraHeaders)
 		for _, d := range t.PFD.InitialDeliverables().Iter() {
 			availableTimeText, ok := t.Memoized.AvailableTimeMap[d]
 			if !ok {
@@ -20,7 +21,7 @@ var ValidAvailableTime = checkers.AtomicChecker[*fsmcommon.Target]{
 				continue
 			}
 			if _, err := fsmtable.ValidateAvailableTime(availableTimeText); err != nil {
-				ch <- checkers.NewProblem(problemID, checkers.SeverityError, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID(d)))...)
+				ch <- checkers.NewProblem(problemID, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID(d)).WithColumn(column))
 			}
 		}
 		return nil
@@ -29,7 +29,7 @@ func TestValidAvailableTime(t *testing.T) {
 				},
 			},
 			Expected: []checkers.Problem{
-				checkers.NewProblem("valid-available-time", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID("D1"))),
+				checkers.NewProblem("valid-available-time", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID("D1")).WithColumn(fsmtable.AvailableTimeHeaderEn)),
 			},
 		},
 		"ok": {
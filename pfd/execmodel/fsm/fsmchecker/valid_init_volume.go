@@ -13,9 +13,10 @@ var ValidInitVolume = checkers.AtomicChecker[*fsmcommon.Target]{
 	},
 	CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
 		const problemID = "valid-init-volume"
+		column := fsmtable.DefaultInitialVolumeColumnMatchFunc.Header(t.AtomicProcessTable.ExtraHeaders)
 		for ap, initVolumeText := range t.Memoized.InitialVolumeMap {
 			if _, err := fsmtable.ValidateInitialVolume(initVolumeText); err != nil {
-				ch <- checkers.NewProblem(problemID, checkers.SeverityError, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)))...)
+				ch <- checkers.NewProblem(problemID, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)).WithColumn(column))
 			}
 		}
 		return nil
@@ -27,7 +27,7 @@ func TestValidInitVolume(t *testing.T) {
 				},
 			},
 			Expected: []checkers.Problem{
-				checkers.NewProblem("valid-init-volume", checkers.SeverityError, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P1")))...),
+				checkers.NewProblem("valid-init-volume", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P1")).WithColumn(fsmtable.InitialVolumeColumnHeaderEn)),
 			},
 		},
 		"ok": {
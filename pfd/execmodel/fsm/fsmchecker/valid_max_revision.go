@@ -16,6 +16,7 @@ var ValidMaxRevision = checkers.AtomicChecker[*fsmcommon.Target]{
 	},
 	CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
 		const problemID = "malformed-max-revision"
+		column := fsmtable.DefaultMaxRevisionColumnMatchFunc.Header(t.AtomicDeliverableTable.ExtraHeaders)
 		for _, d := range t.PFD.AtomicDeliverables.Iter() {
 			maxRevisionText, ok := t.Memoized.MaxRevisionMap[d]
 			if !ok {
@@ -24,7 +25,7 @@ var ValidMaxRevision = checkers.AtomicChecker[*fsmcommon.Target]{
 			isFeedbackSource := t.PFD.FeedbackSourceDeliverables().Contains(pfd.AtomicDeliverableID.Compare, d)
 
 			if _, err := fsmtable.ValidateMaxRevision(maxRevisionText, isFeedbackSource); err != nil {
-				ch <- checkers.NewProblem(problemID, checkers.SeverityError, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID(d)))...)
+				ch <- checkers.NewProblem(problemID, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID(d)).WithColumn(column))
 				continue
 			}
 		}
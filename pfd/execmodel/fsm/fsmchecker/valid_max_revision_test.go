@@ -50,7 +50,7 @@ func TestValidMaxRevision(t *testing.T) {
 				},
 			},
 			Expected: []checkers.Problem{
-				checkers.NewProblem("malformed-max-revision", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID("D1"))),
+				checkers.NewProblem("malformed-max-revision", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID("D1")).WithColumn(fsmtable.MaxRevisionHeaderEn)),
 			},
 		},
 		"ng (feedback source, empty)": {
@@ -80,7 +80,7 @@ func TestValidMaxRevision(t *testing.T) {
 				},
 			},
 			Expected: []checkers.Problem{
-				checkers.NewProblem("malformed-max-revision", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID("D2"))),
+				checkers.NewProblem("malformed-max-revision", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID("D2")).WithColumn(fsmtable.MaxRevisionHeaderEn)),
 			},
 		},
 		"ng (feedback source, negative)": {
@@ -110,7 +110,7 @@ func TestValidMaxRevision(t *testing.T) {
 				},
 			},
 			Expected: []checkers.Problem{
-				checkers.NewProblem("malformed-max-revision", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID("D2"))),
+				checkers.NewProblem("malformed-max-revision", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicDeliverableTable, fsmcommon.NewAtomicDeliverableID("D2")).WithColumn(fsmtable.MaxRevisionHeaderEn)),
 			},
 		},
 		"ok (feedback source)": {
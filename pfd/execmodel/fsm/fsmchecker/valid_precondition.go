@@ -34,12 +34,7 @@ var ValidPrecondition = checkers.AtomicChecker[*fsmcommon.Target]{
 				ch <- checkers.NewProblem(
 					malformedPreconditionProblemID,
 					checkers.SeverityError,
-					fsmcommon.NewLocations(
-						fsmcommon.NewLocation(
-							fsmcommon.LocationTypeAtomicProcessTable,
-							fsmcommon.NewAtomicProcessID(ap),
-						),
-					)...,
+					preconditionLocation(t, ap, nil),
 				)
 				continue
 			}
@@ -48,17 +43,15 @@ var ValidPrecondition = checkers.AtomicChecker[*fsmcommon.Target]{
 			precondition.Traverse(func(p *fsm.Precondition) {
 				switch p.Type {
 				case fsm.PreconditionTypeFeedbackSourceCompleted:
+					if !isPFDNodeID(t.PFD, string(p.FeedbackSource)) {
+						// NOTE: Reported by SanePrecondition.
+						return
+					}
 					if !t.PFD.AtomicDeliverables.Contains(pfd.AtomicDeliverableID.Compare, p.FeedbackSource) || t.PFD.FeedbackDestinationAtomicProcesses(p.FeedbackSource).Len() == 0 {
 						ch <- checkers.NewProblem(
 							preconditionNotFeedbackSourceProblemID,
 							checkers.SeverityError,
-							fsmcommon.NewLocations(
-								fsmcommon.NewLocation(
-									fsmcommon.LocationTypeAtomicProcessTable,
-									fsmcommon.NewAtomicProcessID(ap),
-									fsmcommon.NewAtomicDeliverableID(p.FeedbackSource),
-								),
-							)...,
+							preconditionLocation(t, ap, p, fsmcommon.NewAtomicDeliverableID(p.FeedbackSource)),
 						)
 						return
 					}
@@ -69,24 +62,20 @@ var ValidPrecondition = checkers.AtomicChecker[*fsmcommon.Target]{
 						ch <- checkers.NewProblem(
 							preconditionReachableFeedbackSourceProblemID,
 							checkers.SeverityError,
-							fsmcommon.NewLocation(
-								fsmcommon.LocationTypeAtomicProcessTable,
-								fsmcommon.NewAtomicProcessID(ap),
-								fsmcommon.NewAtomicDeliverableID(p.FeedbackSource),
-							),
+							preconditionLocation(t, ap, p, fsmcommon.NewAtomicDeliverableID(p.FeedbackSource)),
 						)
 					}
 
 				case fsm.PreconditionTypeExecutable:
+					if !isPFDNodeID(t.PFD, string(p.Executable)) || p.Executable == ap {
+						// NOTE: Reported by SanePrecondition.
+						return
+					}
 					if !t.PFD.AtomicProcesses.Contains(pfd.AtomicProcessID.Compare, p.Executable) {
 						ch <- checkers.NewProblem(
 							preconditionNotAtomicProcessProblemID,
 							checkers.SeverityError,
-							fsmcommon.NewLocation(
-								fsmcommon.LocationTypeAtomicProcessTable,
-								fsmcommon.NewAtomicProcessID(ap),
-								fsmcommon.NewAtomicProcessID(p.Executable),
-							),
+							preconditionLocation(t, ap, p, fsmcommon.NewAtomicProcessID(p.Executable)),
 						)
 						return
 					}
@@ -97,11 +86,7 @@ var ValidPrecondition = checkers.AtomicChecker[*fsmcommon.Target]{
 						ch <- checkers.NewProblem(
 							preconditionReachableExecutableTargetProblemID,
 							checkers.SeverityError,
-							fsmcommon.NewLocation(
-								fsmcommon.LocationTypeAtomicProcessTable,
-								fsmcommon.NewAtomicProcessID(ap),
-								fsmcommon.NewAtomicProcessID(p.Executable),
-							),
+							preconditionLocation(t, ap, p, fsmcommon.NewAtomicProcessID(p.Executable)),
 						)
 					}
 
@@ -139,7 +124,8 @@ func preconditionExecutableReferenceGraphCycles(preconditionMap map[pfd.AtomicPr
 	edges := sets.NewWithCapacity[*pairs.Pair[graph.Node, graph.Node]](0)
 	for ap, precondition := range preconditionMap {
 		precondition.Traverse(func(p *fsm.Precondition) {
-			if p.Type != fsm.PreconditionTypeExecutable {
+			// NOTE: Self references are reported by SanePrecondition.
+			if p.Type != fsm.PreconditionTypeExecutable || p.Executable == ap {
 				return
 			}
 			edges.Add(f1, pairs.New(graph.Node(ap), graph.Node(p.Executable)))
@@ -161,3 +147,17 @@ func preconditionExecutableReferenceGraphCycles(preconditionMap map[pfd.AtomicPr
 	}
 	return res
 }
+
+// preconditionLocation returns the location of the start condition of the atomic process. The location points to the
+// reference in the start condition if the reference is not nil.
+func preconditionLocation(t *fsmcommon.Target, ap pfd.AtomicProcessID, ref *fsm.Precondition, ids ...fsmcommon.ID) fsmcommon.Location {
+	loc := fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, append([]fsmcommon.ID{fsmcommon.NewAtomicProcessID(ap)}, ids...)...).
+		WithColumn(fsmtable.DefaultPreconditionColumnMatchFunc.Header(t.AtomicProcessTable.ExtraHeaders))
+	if ref == nil {
+		return loc
+	}
+	if start, end, ok := fsmtable.FindPreconditionReference(t.Memoized.PreconditionMap[ap], ref); ok {
+		loc = loc.WithRange(start, end)
+	}
+	return loc
+}
@@ -37,7 +37,7 @@ func TestValidPrecondition(t *testing.T) {
 					fsmcommon.NewLocation(
 						fsmcommon.LocationTypeAtomicProcessTable,
 						fsmcommon.NewAtomicProcessID("P1"),
-					),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn),
 				),
 			},
 		},
@@ -81,7 +81,7 @@ func TestValidPrecondition(t *testing.T) {
 						fsmcommon.LocationTypeAtomicProcessTable,
 						fsmcommon.NewAtomicProcessID("P1"),
 						fsmcommon.NewAtomicDeliverableID("D2"),
-					),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn).WithRange(0, 13),
 				),
 			},
 		},
@@ -103,7 +103,7 @@ func TestValidPrecondition(t *testing.T) {
 						fsmcommon.LocationTypeAtomicProcessTable,
 						fsmcommon.NewAtomicProcessID("P1"),
 						fsmcommon.NewAtomicDeliverableID("P2"),
-					),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn).WithRange(0, 13),
 				),
 			},
 		},
@@ -125,7 +125,7 @@ func TestValidPrecondition(t *testing.T) {
 						fsmcommon.LocationTypeAtomicProcessTable,
 						fsmcommon.NewAtomicProcessID("P1"),
 						fsmcommon.NewAtomicDeliverableID("D4"),
-					),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn).WithRange(0, 13),
 				),
 			},
 		},
@@ -147,7 +147,7 @@ func TestValidPrecondition(t *testing.T) {
 						fsmcommon.LocationTypeAtomicProcessTable,
 						fsmcommon.NewAtomicProcessID("P1"),
 						fsmcommon.NewAtomicProcessID("P2"),
-					),
+					).WithColumn(fsmtable.PreconditionColumnHeaderEn).WithRange(1, 10),
 				),
 			},
 		},
@@ -12,24 +12,25 @@ var ValidResourcesSet = checkers.AtomicChecker[*fsmcommon.Target]{
 		return t.Memoized.HasNeededResourceSetsMap
 	},
 	CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
+		column := fsmtable.DefaultNeededResourceSetsColumnSelectFunc.Header(t.AtomicProcessTable.ExtraHeaders)
 		for ap, neededResourceSetsText := range t.Memoized.NeededResourceSetsMap {
 			const problemIDMalformedResourceSetNotation = "malformed-resources-set-notation"
 			neededResourceSets, err := fsmtable.ParseNeededResourceSetEntry(neededResourceSetsText)
 			if err != nil {
-				ch <- checkers.NewProblem(problemIDMalformedResourceSetNotation, checkers.SeverityError, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)))...)
+				ch <- checkers.NewProblem(problemIDMalformedResourceSetNotation, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)).WithColumn(column))
 				return nil
 			}
 
 			const problemIDEmptyResourceSet = "empty-resources-set"
 			if neededResourceSets.Len() == 0 {
-				ch <- checkers.NewProblem(problemIDEmptyResourceSet, checkers.SeverityError, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)))...)
+				ch <- checkers.NewProblem(problemIDEmptyResourceSet, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)).WithColumn(column))
 				continue
 			}
 
 			const problemIDZeroConsumedVolume = "zero-consumed-volume"
 			for _, neededResourceSet := range neededResourceSets.Iter() {
 				if neededResourceSet.ConsumedVolume.IsZero() {
-					ch <- checkers.NewProblem(problemIDZeroConsumedVolume, checkers.SeverityError, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)))...)
+					ch <- checkers.NewProblem(problemIDZeroConsumedVolume, checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID(ap)).WithColumn(column))
 				}
 			}
 		}
@@ -28,7 +28,7 @@ func TestValidResourcesSet(t *testing.T) {
 				},
 			},
 			Expected: []checkers.Problem{
-				checkers.NewProblem("malformed-resources-set-notation", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P1"))),
+				checkers.NewProblem("malformed-resources-set-notation", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P1")).WithColumn(fsmtable.NeededResourceSetsColumnHeaderEn)),
 			},
 		},
 		"ng (initial volume is not zero and empty resource set)": {
@@ -39,7 +39,7 @@ func TestValidResourcesSet(t *testing.T) {
 				},
 			},
 			Expected: []checkers.Problem{
-				checkers.NewProblem("empty-resources-set", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P1"))),
+				checkers.NewProblem("empty-resources-set", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P1")).WithColumn(fsmtable.NeededResourceSetsColumnHeaderEn)),
 			},
 		},
 		"ng (consumed volume is zero)": {
@@ -50,7 +50,7 @@ func TestValidResourcesSet(t *testing.T) {
 				},
 			},
 			Expected: []checkers.Problem{
-				checkers.NewProblem("zero-consumed-volume", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P1"))),
+				checkers.NewProblem("zero-consumed-volume", checkers.SeverityError, fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P1")).WithColumn(fsmtable.NeededResourceSetsColumnHeaderEn)),
 			},
 		},
 		"ok": {
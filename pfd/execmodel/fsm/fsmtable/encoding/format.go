@@ -1,12 +1,15 @@
 package encoding
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmcsv"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmhtml"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmtsv"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmxlsx"
 	"github.com/Kuniwak/pfd-tools/table"
 )
 
@@ -14,8 +17,12 @@ func NewResourceTableParser(format table.Format) (func(r io.Reader) (*fsmtable.R
 	switch format {
 	case table.FormatTSV:
 		return fsmtsv.ParseResourceTable, nil
+	case table.FormatCSV:
+		return fsmcsv.ParseResourceTable, nil
+	case table.FormatXLSX:
+		return fsmxlsx.ParseResourceTable, nil
 	case table.FormatHTML:
-		return nil, fmt.Errorf("pfdencoding.NewResourceTableParser: not supported: %q", format)
+		return nil, fmt.Errorf("pfdencoding.NewResourceTableParser: %q format is not supported", format)
 	default:
 		return nil, fmt.Errorf("pfdencoding.NewResourceTableParser: unknown format: %q", format)
 	}
@@ -25,6 +32,10 @@ func NewResourceTableWriter(format table.Format) (func(w io.Writer, table *fsmta
 	switch format {
 	case table.FormatTSV:
 		return fsmtsv.WriteResourceTable, nil
+	case table.FormatCSV:
+		return fsmcsv.WriteResourceTable, nil
+	case table.FormatXLSX:
+		return fsmxlsx.WriteResourceTable, nil
 	case table.FormatHTML:
 		return fsmhtml.WriteResourceTable, nil
 	default:
@@ -32,12 +43,56 @@ func NewResourceTableWriter(format table.Format) (func(w io.Writer, table *fsmta
 	}
 }
 
+// ParseResourceTable reads the table in the format detected by table.Detect.
+func ParseResourceTable(r io.Reader) (*fsmtable.ResourceTable, error) {
+	format, r, err := table.Detect(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseResourceTable: %w", err)
+	}
+	parse, err := NewResourceTableParser(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseResourceTable: %w", err)
+	}
+	t, err := parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseResourceTable: %w", err)
+	}
+	return t, nil
+}
+
+// RewriteResourceTable returns the table in the format of the original. The other sheets of XLSX workbooks are kept.
+func RewriteResourceTable(original []byte, t *fsmtable.ResourceTable) ([]byte, error) {
+	format, _, err := table.Detect(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteResourceTable: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if format == table.FormatXLSX {
+		if err := fsmxlsx.UpdateResourceTable(buf, bytes.NewReader(original), t); err != nil {
+			return nil, fmt.Errorf("pfdencoding.RewriteResourceTable: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	write, err := NewResourceTableWriter(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteResourceTable: %w", err)
+	}
+	if err := write(buf, t); err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteResourceTable: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 func NewMilestoneTableParser(format table.Format) (func(r io.Reader) (*fsmtable.MilestoneTable, error), error) {
 	switch format {
 	case table.FormatTSV:
 		return fsmtsv.ParseMilestoneTable, nil
+	case table.FormatCSV:
+		return fsmcsv.ParseMilestoneTable, nil
+	case table.FormatXLSX:
+		return fsmxlsx.ParseMilestoneTable, nil
 	case table.FormatHTML:
-		return nil, fmt.Errorf("pfdencoding.NewMilestoneTableParser: not supported: %q", format)
+		return nil, fmt.Errorf("pfdencoding.NewMilestoneTableParser: %q format is not supported", format)
 	default:
 		return nil, fmt.Errorf("pfdencoding.NewMilestoneTableParser: unknown format: %q", format)
 	}
@@ -47,6 +102,10 @@ func NewMilestoneTableWriter(format table.Format) (func(w io.Writer, table *fsmt
 	switch format {
 	case table.FormatTSV:
 		return fsmtsv.WriteMilestoneTable, nil
+	case table.FormatCSV:
+		return fsmcsv.WriteMilestoneTable, nil
+	case table.FormatXLSX:
+		return fsmxlsx.WriteMilestoneTable, nil
 	case table.FormatHTML:
 		return fsmhtml.WriteMilestoneTable, nil
 	default:
@@ -54,12 +113,56 @@ func NewMilestoneTableWriter(format table.Format) (func(w io.Writer, table *fsmt
 	}
 }
 
+// ParseMilestoneTable reads the table in the format detected by table.Detect.
+func ParseMilestoneTable(r io.Reader) (*fsmtable.MilestoneTable, error) {
+	format, r, err := table.Detect(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseMilestoneTable: %w", err)
+	}
+	parse, err := NewMilestoneTableParser(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseMilestoneTable: %w", err)
+	}
+	t, err := parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseMilestoneTable: %w", err)
+	}
+	return t, nil
+}
+
+// RewriteMilestoneTable returns the table in the format of the original. The other sheets of XLSX workbooks are kept.
+func RewriteMilestoneTable(original []byte, t *fsmtable.MilestoneTable) ([]byte, error) {
+	format, _, err := table.Detect(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteMilestoneTable: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if format == table.FormatXLSX {
+		if err := fsmxlsx.UpdateMilestoneTable(buf, bytes.NewReader(original), t); err != nil {
+			return nil, fmt.Errorf("pfdencoding.RewriteMilestoneTable: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	write, err := NewMilestoneTableWriter(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteMilestoneTable: %w", err)
+	}
+	if err := write(buf, t); err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteMilestoneTable: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 func NewGroupTableParser(format table.Format) (func(r io.Reader) (*fsmtable.GroupTable, error), error) {
 	switch format {
 	case table.FormatTSV:
 		return fsmtsv.ParseGroupTable, nil
+	case table.FormatCSV:
+		return fsmcsv.ParseGroupTable, nil
+	case table.FormatXLSX:
+		return fsmxlsx.ParseGroupTable, nil
 	case table.FormatHTML:
-		return nil, fmt.Errorf("pfdencoding.NewGroupTableParser: not supported: %q", format)
+		return nil, fmt.Errorf("pfdencoding.NewGroupTableParser: %q format is not supported", format)
 	default:
 		return nil, fmt.Errorf("pfdencoding.NewGroupTableParser: unknown format: %q", format)
 	}
@@ -69,9 +172,53 @@ func NewGroupTableWriter(format table.Format) (func(w io.Writer, table *fsmtable
 	switch format {
 	case table.FormatTSV:
 		return fsmtsv.WriteGroupTable, nil
+	case table.FormatCSV:
+		return fsmcsv.WriteGroupTable, nil
+	case table.FormatXLSX:
+		return fsmxlsx.WriteGroupTable, nil
 	case table.FormatHTML:
 		return fsmhtml.WriteGroupTable, nil
 	default:
 		return nil, fmt.Errorf("pfdencoding.NewGroupTableWriter: unknown format: %q", format)
 	}
 }
+
+// ParseGroupTable reads the table in the format detected by table.Detect.
+func ParseGroupTable(r io.Reader) (*fsmtable.GroupTable, error) {
+	format, r, err := table.Detect(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseGroupTable: %w", err)
+	}
+	parse, err := NewGroupTableParser(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseGroupTable: %w", err)
+	}
+	t, err := parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseGroupTable: %w", err)
+	}
+	return t, nil
+}
+
+// RewriteGroupTable returns the table in the format of the original. The other sheets of XLSX workbooks are kept.
+func RewriteGroupTable(original []byte, t *fsmtable.GroupTable) ([]byte, error) {
+	format, _, err := table.Detect(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteGroupTable: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if format == table.FormatXLSX {
+		if err := fsmxlsx.UpdateGroupTable(buf, bytes.NewReader(original), t); err != nil {
+			return nil, fmt.Errorf("pfdencoding.RewriteGroupTable: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	write, err := NewGroupTableWriter(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteGroupTable: %w", err)
+	}
+	if err := write(buf, t); err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteGroupTable: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,67 @@
+package fsmcsv
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmrecords"
+	"github.com/Kuniwak/pfd-tools/table/tablecsv"
+)
+
+func WriteResourceTable(w io.Writer, table *fsmtable.ResourceTable) error {
+	if err := tablecsv.WriteTable(w, &tablecsv.Table{Header: table.Header(), Rows: fsmrecords.ResourceTableRows(table)}); err != nil {
+		return fmt.Errorf("fsmcsv.WriteResourceTable: %w", err)
+	}
+	return nil
+}
+
+func ParseResourceTable(r io.Reader) (*fsmtable.ResourceTable, error) {
+	t, err := tablecsv.ParseTable(r)
+	if err != nil {
+		return nil, fmt.Errorf("fsmcsv.ParseResourceTable: %w", err)
+	}
+	table, err := fsmrecords.ResourceTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("fsmcsv.ParseResourceTable: %w", err)
+	}
+	return table, nil
+}
+
+func WriteMilestoneTable(w io.Writer, table *fsmtable.MilestoneTable) error {
+	if err := tablecsv.WriteTable(w, &tablecsv.Table{Header: table.Header(), Rows: fsmrecords.MilestoneTableRows(table)}); err != nil {
+		return fmt.Errorf("fsmcsv.WriteMilestoneTable: %w", err)
+	}
+	return nil
+}
+
+func ParseMilestoneTable(r io.Reader) (*fsmtable.MilestoneTable, error) {
+	t, err := tablecsv.ParseTable(r)
+	if err != nil {
+		return nil, fmt.Errorf("fsmcsv.ParseMilestoneTable: %w", err)
+	}
+	table, err := fsmrecords.MilestoneTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("fsmcsv.ParseMilestoneTable: %w", err)
+	}
+	return table, nil
+}
+
+func WriteGroupTable(w io.Writer, table *fsmtable.GroupTable) error {
+	if err := tablecsv.WriteTable(w, &tablecsv.Table{Header: table.Header(), Rows: fsmrecords.GroupTableRows(table)}); err != nil {
+		return fmt.Errorf("fsmcsv.WriteGroupTable: %w", err)
+	}
+	return nil
+}
+
+func ParseGroupTable(r io.Reader) (*fsmtable.GroupTable, error) {
+	t, err := tablecsv.ParseTable(r)
+	if err != nil {
+		return nil, fmt.Errorf("fsmcsv.ParseGroupTable: %w", err)
+	}
+	table, err := fsmrecords.GroupTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("fsmcsv.ParseGroupTable: %w", err)
+	}
+	return table, nil
+}
@@ -0,0 +1,81 @@
+// Package fsmrecords converts the tables of the execution model from and to the headers and the rows of cells that
+// are common to the table formats.
+package fsmrecords
+
+import (
+	"fmt"
+
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmmasterschedule"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+)
+
+func ResourceTable(header []string, rows [][]string) (*fsmtable.ResourceTable, error) {
+	if err := validateColumns(header, rows, 2); err != nil {
+		return nil, fmt.Errorf("fsmrecords.ResourceTable: %w", err)
+	}
+	rows2 := make([]*fsmtable.ResourceTableRow, 0, len(rows))
+	for _, row := range rows {
+		rows2 = append(rows2, &fsmtable.ResourceTableRow{ID: fsm.ResourceID(row[0]), Description: row[1], ExtraCells: row[2:]})
+	}
+	return &fsmtable.ResourceTable{ExtraHeaders: header[2:], Rows: rows2}, nil
+}
+
+func ResourceTableRows(table *fsmtable.ResourceTable) [][]string {
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, append([]string{string(row.ID), row.Description}, row.ExtraCells...))
+	}
+	return rows
+}
+
+func MilestoneTable(header []string, rows [][]string) (*fsmtable.MilestoneTable, error) {
+	if err := validateColumns(header, rows, 4); err != nil {
+		return nil, fmt.Errorf("fsmrecords.MilestoneTable: %w", err)
+	}
+	rows2 := make([]*fsmtable.MilestoneTableRow, 0, len(rows))
+	for _, row := range rows {
+		rows2 = append(rows2, &fsmtable.MilestoneTableRow{MilestoneID: fsmmasterschedule.Milestone(row[0]), Description: row[1], GroupIDs: row[2], Successors: row[3], ExtraCells: row[4:]})
+	}
+	return &fsmtable.MilestoneTable{ExtraHeaders: header[4:], Rows: rows2}, nil
+}
+
+func MilestoneTableRows(table *fsmtable.MilestoneTable) [][]string {
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, row.Row())
+	}
+	return rows
+}
+
+func GroupTable(header []string, rows [][]string) (*fsmtable.GroupTable, error) {
+	if err := validateColumns(header, rows, 2); err != nil {
+		return nil, fmt.Errorf("fsmrecords.GroupTable: %w", err)
+	}
+	rows2 := make([]*fsmtable.GroupTableRow, 0, len(rows))
+	for _, row := range rows {
+		rows2 = append(rows2, &fsmtable.GroupTableRow{ID: fsmmasterschedule.Group(row[0]), Description: row[1], ExtraCells: row[2:]})
+	}
+	return &fsmtable.GroupTable{ExtraHeaders: header[2:], Rows: rows2}, nil
+}
+
+func GroupTableRows(table *fsmtable.GroupTable) [][]string {
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, row.Row())
+	}
+	return rows
+}
+
+// validateColumns returns an error if the header or the rows are shorter than the columns that the table needs.
+func validateColumns(header []string, rows [][]string, n int) error {
+	if len(header) < n {
+		return fmt.Errorf("fsmrecords.validateColumns: want at least %d columns, got %d", n, len(header))
+	}
+	for i, row := range rows {
+		if len(row) < n {
+			return fmt.Errorf("fsmrecords.validateColumns: row %d: want at least %d columns, got %d", i+1, n, len(row))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package fsmxlsx
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmrecords"
+	"github.com/Kuniwak/pfd-tools/table/tablexlsx"
+)
+
+// WriteResourceTable writes a workbook that has only the sheet named fsmtable.TableTypeResource.
+func WriteResourceTable(w io.Writer, table *fsmtable.ResourceTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: fsmrecords.ResourceTableRows(table)}
+	if err := tablexlsx.WriteTable(w, string(fsmtable.TableTypeResource), t); err != nil {
+		return fmt.Errorf("fsmxlsx.WriteResourceTable: %w", err)
+	}
+	return nil
+}
+
+// UpdateResourceTable writes the original workbook with the sheet of the table replaced.
+func UpdateResourceTable(w io.Writer, original io.Reader, table *fsmtable.ResourceTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: fsmrecords.ResourceTableRows(table)}
+	if err := tablexlsx.UpdateTable(w, original, string(fsmtable.TableTypeResource), t); err != nil {
+		return fmt.Errorf("fsmxlsx.UpdateResourceTable: %w", err)
+	}
+	return nil
+}
+
+// ParseResourceTable reads the sheet named fsmtable.TableTypeResource, or the only sheet of the workbook.
+func ParseResourceTable(r io.Reader) (*fsmtable.ResourceTable, error) {
+	t, err := tablexlsx.ParseTable(r, string(fsmtable.TableTypeResource))
+	if err != nil {
+		return nil, fmt.Errorf("fsmxlsx.ParseResourceTable: %w", err)
+	}
+	table, err := fsmrecords.ResourceTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("fsmxlsx.ParseResourceTable: %w", err)
+	}
+	return table, nil
+}
+
+// WriteMilestoneTable writes a workbook that has only the sheet named fsmtable.TableTypeMilestone.
+func WriteMilestoneTable(w io.Writer, table *fsmtable.MilestoneTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: fsmrecords.MilestoneTableRows(table)}
+	if err := tablexlsx.WriteTable(w, string(fsmtable.TableTypeMilestone), t); err != nil {
+		return fmt.Errorf("fsmxlsx.WriteMilestoneTable: %w", err)
+	}
+	return nil
+}
+
+// UpdateMilestoneTable writes the original workbook with the sheet of the table replaced.
+func UpdateMilestoneTable(w io.Writer, original io.Reader, table *fsmtable.MilestoneTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: fsmrecords.MilestoneTableRows(table)}
+	if err := tablexlsx.UpdateTable(w, original, string(fsmtable.TableTypeMilestone), t); err != nil {
+		return fmt.Errorf("fsmxlsx.UpdateMilestoneTable: %w", err)
+	}
+	return nil
+}
+
+// ParseMilestoneTable reads the sheet named fsmtable.TableTypeMilestone, or the only sheet of the workbook.
+func ParseMilestoneTable(r io.Reader) (*fsmtable.MilestoneTable, error) {
+	t, err := tablexlsx.ParseTable(r, string(fsmtable.TableTypeMilestone))
+	if err != nil {
+		return nil, fmt.Errorf("fsmxlsx.ParseMilestoneTable: %w", err)
+	}
+	table, err := fsmrecords.MilestoneTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("fsmxlsx.ParseMilestoneTable: %w", err)
+	}
+	return table, nil
+}
+
+// WriteGroupTable writes a workbook that has only the sheet named fsmtable.TableTypeGroup.
+func WriteGroupTable(w io.Writer, table *fsmtable.GroupTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: fsmrecords.GroupTableRows(table)}
+	if err := tablexlsx.WriteTable(w, string(fsmtable.TableTypeGroup), t); err != nil {
+		return fmt.Errorf("fsmxlsx.WriteGroupTable: %w", err)
+	}
+	return nil
+}
+
+// UpdateGroupTable writes the original workbook with the sheet of the table replaced.
+func UpdateGroupTable(w io.Writer, original io.Reader, table *fsmtable.GroupTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: fsmrecords.GroupTableRows(table)}
+	if err := tablexlsx.UpdateTable(w, original, string(fsmtable.TableTypeGroup), t); err != nil {
+		return fmt.Errorf("fsmxlsx.UpdateGroupTable: %w", err)
+	}
+	return nil
+}
+
+// ParseGroupTable reads the sheet named fsmtable.TableTypeGroup, or the only sheet of the workbook.
+func ParseGroupTable(r io.Reader) (*fsmtable.GroupTable, error) {
+	t, err := tablexlsx.ParseTable(r, string(fsmtable.TableTypeGroup))
+	if err != nil {
+		return nil, fmt.Errorf("fsmxlsx.ParseGroupTable: %w", err)
+	}
+	table, err := fsmrecords.GroupTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("fsmxlsx.ParseGroupTable: %w", err)
+	}
+	return table, nil
+}
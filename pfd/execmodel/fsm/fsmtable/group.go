@@ -1,12 +1,41 @@
 package fsmtable
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/Kuniwak/pfd-tools/pfd"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmmasterschedule"
 	"github.com/Kuniwak/pfd-tools/sets"
 )
 
+// GroupsByTable returns the groups that the atomic processes in the atomic process table belong to.
+func GroupsByTable(t *pfd.AtomicProcessTable, groupColumnSelectFunc pfd.ColumnSelectFunc) (*sets.Set[fsmmasterschedule.Group], error) {
+	m, err := RawGroupsMap(t, groupColumnSelectFunc)
+	if err != nil {
+		return nil, fmt.Errorf("fsmtable.GroupsByTable: %w", err)
+	}
+	groupsMap, err := ValidateGroupsMap(m)
+	if err != nil {
+		return nil, fmt.Errorf("fsmtable.GroupsByTable: %w", err)
+	}
+
+	groups := sets.New(fsmmasterschedule.Group.Compare)
+	for _, groups2 := range groupsMap {
+		groups.Union(fsmmasterschedule.Group.Compare, groups2)
+	}
+	return groups, nil
+}
+
+func NewGroupTable(groups *sets.Set[fsmmasterschedule.Group]) *GroupTable {
+	rows := make([]*GroupTableRow, 0, groups.Len())
+	for _, group := range groups.Iter() {
+		rows = append(rows, &GroupTableRow{ID: group, Description: "", ExtraCells: make([]string, 0)})
+	}
+	return &GroupTable{ExtraHeaders: []string{}, Rows: rows}
+}
+
 type GroupTable struct {
 	ExtraHeaders []string         `json:"extra_headers"`
 	Rows         []*GroupTableRow `json:"rows"`
@@ -16,6 +45,34 @@ func (t *GroupTable) Header() []string {
 	return append([]string{"ID", "Description"}, t.ExtraHeaders...)
 }
 
+// Refresh adds the missing groups. Descriptions are kept. Orphaned groups that no atomic processes belong to are kept
+// and returned to be reported.
+func (t *GroupTable) Refresh(groups *sets.Set[fsmmasterschedule.Group]) *sets.Set[fsmmasterschedule.Group] {
+	orphans := sets.New(fsmmasterschedule.Group.Compare)
+	actual := sets.NewWithCapacity[fsmmasterschedule.Group](len(t.Rows))
+	for _, row := range t.Rows {
+		actual.Add(fsmmasterschedule.Group.Compare, row.ID)
+		if !groups.Contains(fsmmasterschedule.Group.Compare, row.ID) {
+			orphans.Add(fsmmasterschedule.Group.Compare, row.ID)
+		}
+	}
+
+	missings := groups.Clone()
+	missings.Difference(fsmmasterschedule.Group.Compare, actual)
+
+	rows := slices.Clone(t.Rows)
+	for _, missing := range missings.Iter() {
+		rows = append(rows, &GroupTableRow{ID: missing, Description: "", ExtraCells: make([]string, len(t.ExtraHeaders))})
+	}
+
+	slices.SortFunc(rows, func(a, b *GroupTableRow) int {
+		return a.ID.Compare(b.ID)
+	})
+
+	t.Rows = rows
+	return orphans
+}
+
 func (t *GroupTable) Groups() *sets.Set[fsmmasterschedule.Group] {
 	groups := sets.New(fsmmasterschedule.Group.Compare)
 	for _, row := range t.Rows {
@@ -81,6 +81,57 @@ func RawMilestoneEdgesMap(t *MilestoneTable) (map[fsmmasterschedule.Milestone]st
 	return m, nil
 }
 
+// MilestoneGroupsMapByTable returns the groups of each milestone in the atomic process table. The groups of a
+// milestone are the groups of the atomic processes of the milestone. Atomic processes without milestones are ignored,
+// and the group column is optional.
+func MilestoneGroupsMapByTable(t *pfd.AtomicProcessTable, milestoneColumnSelectFunc pfd.ColumnSelectFunc, groupColumnSelectFunc pfd.ColumnSelectFunc) (map[fsmmasterschedule.Milestone]*sets.Set[fsmmasterschedule.Group], error) {
+	milestoneIdx := milestoneColumnSelectFunc(t.ExtraHeaders)
+	if milestoneIdx < 0 {
+		return nil, fmt.Errorf("fsmtable.MilestoneGroupsMapByTable: missing milestone column")
+	}
+	groupIdx := groupColumnSelectFunc(t.ExtraHeaders)
+
+	m := make(map[fsmmasterschedule.Milestone]*sets.Set[fsmmasterschedule.Group])
+	for _, row := range t.Rows {
+		milestoneText := strings.TrimSpace(row.ExtraCells[milestoneIdx])
+		if milestoneText == "" {
+			continue
+		}
+		milestone, err := ParseMilestone(milestoneText)
+		if err != nil {
+			return nil, fmt.Errorf("fsmtable.MilestoneGroupsMapByTable: %w", err)
+		}
+
+		groups, ok := m[milestone]
+		if !ok {
+			groups = sets.New(fsmmasterschedule.Group.Compare)
+			m[milestone] = groups
+		}
+		if groupIdx < 0 {
+			continue
+		}
+		groups2, err := ParseGroups(row.ExtraCells[groupIdx])
+		if err != nil {
+			return nil, fmt.Errorf("fsmtable.MilestoneGroupsMapByTable: %s: %w", row.ID, err)
+		}
+		groups.Union(fsmmasterschedule.Group.Compare, groups2)
+	}
+	return m, nil
+}
+
+// NewMilestoneTable returns the milestone table that has the milestones of the map. Successors are empty because the
+// atomic process table has no order of milestones.
+func NewMilestoneTable(m map[fsmmasterschedule.Milestone]*sets.Set[fsmmasterschedule.Group]) *MilestoneTable {
+	rows := make([]*MilestoneTableRow, 0, len(m))
+	for milestone, groups := range m {
+		rows = append(rows, &MilestoneTableRow{MilestoneID: milestone, GroupIDs: formatGroups(groups), ExtraCells: make([]string, 0)})
+	}
+	slices.SortFunc(rows, func(a, b *MilestoneTableRow) int {
+		return a.MilestoneID.Compare(b.MilestoneID)
+	})
+	return &MilestoneTable{ExtraHeaders: []string{}, Rows: rows}
+}
+
 type MilestoneTable struct {
 	ExtraHeaders []string             `json:"extra_headers"`
 	Rows         []*MilestoneTableRow `json:"rows"`
@@ -90,6 +141,45 @@ func (t *MilestoneTable) Header() []string {
 	return append([]string{"ID", "Description", "Groups", "Successors"}, t.ExtraHeaders...)
 }
 
+// Refresh adds the missing milestones of the map and updates the groups. Descriptions and successors are kept.
+// Orphaned milestones that no atomic processes refer to are kept because their successors may be written by hand, and
+// they are returned to be reported.
+func (t *MilestoneTable) Refresh(m map[fsmmasterschedule.Milestone]*sets.Set[fsmmasterschedule.Group]) *sets.Set[fsmmasterschedule.Milestone] {
+	orphans := sets.New(fsmmasterschedule.Milestone.Compare)
+	actual := sets.NewWithCapacity[fsmmasterschedule.Milestone](len(t.Rows))
+	rows := make([]*MilestoneTableRow, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		actual.Add(fsmmasterschedule.Milestone.Compare, row.MilestoneID)
+		groups, ok := m[row.MilestoneID]
+		if !ok {
+			orphans.Add(fsmmasterschedule.Milestone.Compare, row.MilestoneID)
+			rows = append(rows, row)
+			continue
+		}
+		rows = append(rows, &MilestoneTableRow{
+			MilestoneID: row.MilestoneID,
+			GroupIDs:    formatGroups(groups),
+			Description: row.Description,
+			Successors:  row.Successors,
+			ExtraCells:  row.ExtraCells,
+		})
+	}
+
+	for milestone, groups := range m {
+		if actual.Contains(fsmmasterschedule.Milestone.Compare, milestone) {
+			continue
+		}
+		rows = append(rows, &MilestoneTableRow{MilestoneID: milestone, GroupIDs: formatGroups(groups), ExtraCells: make([]string, len(t.ExtraHeaders))})
+	}
+
+	slices.SortFunc(rows, func(a, b *MilestoneTableRow) int {
+		return a.MilestoneID.Compare(b.MilestoneID)
+	})
+
+	t.Rows = rows
+	return orphans
+}
+
 func (t *MilestoneTable) Milestones() *sets.Set[fsmmasterschedule.Milestone] {
 	s := sets.New(fsmmasterschedule.Milestone.Compare)
 	for _, row := range t.Rows {
@@ -202,6 +292,14 @@ func ParseGroups(s string) (*sets.Set[fsmmasterschedule.Group], error) {
 	return gs2, nil
 }
 
+func formatGroups(groups *sets.Set[fsmmasterschedule.Group]) string {
+	ss := make([]string, 0, groups.Len())
+	for _, group := range groups.Iter() {
+		ss = append(ss, string(group))
+	}
+	return strings.Join(ss, ",")
+}
+
 func ParseMilestone(s string) (fsmmasterschedule.Milestone, error) {
 	if s == "" {
 		return "", fmt.Errorf("fsmtable.ParseMilestone: empty milestone")
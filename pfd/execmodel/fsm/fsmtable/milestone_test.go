@@ -0,0 +1,91 @@
+package fsmtable
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmmasterschedule"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMilestoneGroupsMapByTable(t *testing.T) {
+	apTable := &pfd.AtomicProcessTable{
+		ExtraHeaders: []string{MilestoneColumnHeaderEn, GroupColumnHeaderEn},
+		Rows: []*pfd.AtomicProcessRow{
+			{ID: "P1", ExtraCells: []string{"M1", "G1"}},
+			{ID: "P2", ExtraCells: []string{"M1", "G2"}},
+			{ID: "P3", ExtraCells: []string{"M2", ""}},
+			{ID: "P4", ExtraCells: []string{"", "G3"}},
+		},
+	}
+
+	got, err := MilestoneGroupsMapByTable(apTable, DefaultMilestoneColumnMatchFunc, DefaultGroupColumnMatchFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[fsmmasterschedule.Milestone]*sets.Set[fsmmasterschedule.Group]{
+		"M1": sets.New(fsmmasterschedule.Group.Compare, "G1", "G2"),
+		"M2": sets.New(fsmmasterschedule.Group.Compare),
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Error(cmp.Diff(expected, got))
+	}
+}
+
+func TestMilestoneTableRefresh(t *testing.T) {
+	milestoneTable := &MilestoneTable{
+		ExtraHeaders: []string{"Note"},
+		Rows: []*MilestoneTableRow{
+			{MilestoneID: "M1", Description: "Design", GroupIDs: "G1", Successors: "M2", ExtraCells: []string{"note"}},
+			{MilestoneID: "M9", Description: "Removed", GroupIDs: "G1", Successors: "", ExtraCells: []string{""}},
+		},
+	}
+
+	orphans := milestoneTable.Refresh(map[fsmmasterschedule.Milestone]*sets.Set[fsmmasterschedule.Group]{
+		"M1": sets.New(fsmmasterschedule.Group.Compare, "G1", "G2"),
+		"M2": sets.New(fsmmasterschedule.Group.Compare, "G2"),
+	})
+
+	expected := []*MilestoneTableRow{
+		{MilestoneID: "M1", Description: "Design", GroupIDs: "G1,G2", Successors: "M2", ExtraCells: []string{"note"}},
+		{MilestoneID: "M2", Description: "", GroupIDs: "G2", Successors: "", ExtraCells: []string{""}},
+		{MilestoneID: "M9", Description: "Removed", GroupIDs: "G1", Successors: "", ExtraCells: []string{""}},
+	}
+	if !reflect.DeepEqual(milestoneTable.Rows, expected) {
+		t.Error(cmp.Diff(expected, milestoneTable.Rows))
+	}
+
+	expectedOrphans := sets.New(fsmmasterschedule.Milestone.Compare, "M9")
+	if !reflect.DeepEqual(orphans, expectedOrphans) {
+		t.Error(cmp.Diff(expectedOrphans, orphans))
+	}
+}
+
+func TestGroupTableRefresh(t *testing.T) {
+	groupTable := &GroupTable{
+		ExtraHeaders: []string{},
+		Rows: []*GroupTableRow{
+			{ID: "G1", Description: "Backend", ExtraCells: []string{}},
+			{ID: "G9", Description: "Removed", ExtraCells: []string{}},
+		},
+	}
+
+	orphans := groupTable.Refresh(sets.New(fsmmasterschedule.Group.Compare, "G1", "G2"))
+
+	expected := []*GroupTableRow{
+		{ID: "G1", Description: "Backend", ExtraCells: []string{}},
+		{ID: "G2", Description: "", ExtraCells: []string{}},
+		{ID: "G9", Description: "Removed", ExtraCells: []string{}},
+	}
+	if !reflect.DeepEqual(groupTable.Rows, expected) {
+		t.Error(cmp.Diff(expected, groupTable.Rows))
+	}
+
+	expectedOrphans := sets.New(fsmmasterschedule.Group.Compare, "G9")
+	if !reflect.DeepEqual(orphans, expectedOrphans) {
+		t.Error(cmp.Diff(expectedOrphans, orphans))
+	}
+}
@@ -101,6 +101,35 @@ func ParsePrecondition(s string, ap pfd.AtomicProcessID) (*fsm.Precondition, err
 	return p, nil
 }
 
+// FindPreconditionReference returns the range of the first \exec(...) or \complete(...) in the text that is the same
+// as the reference. The range is in characters, and it does not include the trailing spaces.
+func FindPreconditionReference(s string, ref *fsm.Precondition) (int, int, bool) {
+	rs := []rune(s)
+	for start := range rs {
+		var p *fsm.Precondition
+		var end int
+		switch ref.Type {
+		case fsm.PreconditionTypeFeedbackSourceCompleted:
+			p, end = parseComplete(rs, start, "")
+			if p == nil || p.Type != ref.Type || p.FeedbackSource != ref.FeedbackSource {
+				continue
+			}
+		case fsm.PreconditionTypeExecutable:
+			p, end = parseExecutable(rs, start)
+			if p == nil || p.Executable != ref.Executable {
+				continue
+			}
+		default:
+			panic(fmt.Sprintf("fsmtable.FindPreconditionReference: not a reference: %q", ref.Type))
+		}
+		for end > start && Whitespaces.Contains(cmp.Compare, rs[end-1]) {
+			end--
+		}
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
 var (
 	OrKeyword               = []rune{'|', '|'}
 	AndKeyword              = []rune{'&', '&'}
@@ -225,3 +225,55 @@ func TestParseNodeID(t *testing.T) {
 		})
 	}
 }
+
+func TestFindPreconditionReference(t *testing.T) {
+	testCases := map[string]struct {
+		Input     string
+		Reference *fsm.Precondition
+		WantStart int
+		WantEnd   int
+		WantOK    bool
+	}{
+		"executable": {
+			Input:     `\complete(D1) && \exec(P1)`,
+			Reference: fsm.NewExecutablePrecondition("P1"),
+			WantStart: 17,
+			WantEnd:   26,
+			WantOK:    true,
+		},
+		"executable with spaces": {
+			Input:     `!\exec( P1 )  || \exec(P2)`,
+			Reference: fsm.NewExecutablePrecondition("P1"),
+			WantStart: 1,
+			WantEnd:   12,
+			WantOK:    true,
+		},
+		"complete": {
+			Input:     `\exec(D1) || \complete(D1)`,
+			Reference: fsm.NewFeedbackSourceCompletedPrecondition("D1"),
+			WantStart: 13,
+			WantEnd:   26,
+			WantOK:    true,
+		},
+		"multibyte": {
+			Input:     `（） \exec(P1)`,
+			Reference: fsm.NewExecutablePrecondition("P1"),
+			WantStart: 3,
+			WantEnd:   12,
+			WantOK:    true,
+		},
+		"missing": {
+			Input:     `\exec(P10)`,
+			Reference: fsm.NewExecutablePrecondition("P1"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			start, end, ok := FindPreconditionReference(testCase.Input, testCase.Reference)
+			if ok != testCase.WantOK || start != testCase.WantStart || end != testCase.WantEnd {
+				t.Errorf("want (%d, %d, %t), got (%d, %d, %t)", testCase.WantStart, testCase.WantEnd, testCase.WantOK, start, end, ok)
+			}
+		})
+	}
+}
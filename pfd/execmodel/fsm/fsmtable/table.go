@@ -5,4 +5,5 @@ type TableType string
 const (
 	TableTypeResource  TableType = "RESOURCE"
 	TableTypeMilestone TableType = "MILESTONE"
+	TableTypeGroup     TableType = "GROUP"
 )
@@ -0,0 +1,148 @@
+package fsmviz
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/graphml"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+const (
+	KeyLabel          graphml.KeyID = "label"
+	KeyTime           graphml.KeyID = "time"
+	KeyCompleted      graphml.KeyID = "completed"
+	KeyDeadlock       graphml.KeyID = "deadlock"
+	KeyBestPlan       graphml.KeyID = "best_plan"
+	KeyRevision       graphml.KeyID = "revision"
+	KeyRemainedVolume graphml.KeyID = "remained_volume"
+	KeyNumOfComplete  graphml.KeyID = "num_of_complete"
+	KeyEdgeLabel      graphml.KeyID = "edge_label"
+	KeyAllocation     graphml.KeyID = "allocation"
+	KeyConsumedVolume graphml.KeyID = "consumed_volume"
+)
+
+// GraphMLKeys are the data keys of state transition graphs. Maps such as revisions and allocations are encoded as
+// JSON objects.
+var GraphMLKeys = []*graphml.Key{
+	{ID: KeyLabel, For: graphml.KeyForNode, Name: "label", Type: graphml.KeyTypeString},
+	{ID: KeyTime, For: graphml.KeyForNode, Name: "time", Type: graphml.KeyTypeDouble},
+	{ID: KeyCompleted, For: graphml.KeyForNode, Name: "completed", Type: graphml.KeyTypeBoolean},
+	{ID: KeyDeadlock, For: graphml.KeyForNode, Name: "deadlock", Type: graphml.KeyTypeBoolean},
+	{ID: KeyBestPlan, For: graphml.KeyForNode, Name: "best plan", Type: graphml.KeyTypeBoolean},
+	{ID: KeyRevision, For: graphml.KeyForNode, Name: "revision", Type: graphml.KeyTypeString},
+	{ID: KeyRemainedVolume, For: graphml.KeyForNode, Name: "remained volume", Type: graphml.KeyTypeString},
+	{ID: KeyNumOfComplete, For: graphml.KeyForNode, Name: "num of complete", Type: graphml.KeyTypeString},
+	{ID: KeyEdgeLabel, For: graphml.KeyForEdge, Name: "label", Type: graphml.KeyTypeString},
+	{ID: KeyAllocation, For: graphml.KeyForEdge, Name: "allocation", Type: graphml.KeyTypeString},
+	{ID: KeyConsumedVolume, For: graphml.KeyForEdge, Name: "consumed volume", Type: graphml.KeyTypeDouble},
+}
+
+// GraphML returns the state transition graph as GraphML. States on the plans are marked as best plan.
+func GraphML(isCompleted func(fsm.State) bool, g fsm.StateTransitionGraph, plans *sets.Set[*fsm.Plan], logger *slog.Logger) (*graphml.Document, error) {
+	res := &graphml.Graph{
+		ID:       "G",
+		Directed: true,
+		Nodes:    make([]*graphml.Node, 0, len(g.Nodes)),
+		Edges:    make([]*graphml.Edge, 0),
+	}
+
+	logger.Debug("GraphML: starting...")
+
+	completedState := CompletedStates(isCompleted, g)
+	bestPlanPaths := BestPlanStates(g, plans)
+
+	for _, p := range sortedKeyValue(g.Nodes, fsm.StateID.Compare) {
+		node, err := GraphMLNode(p.First, p.Second, completedState, bestPlanPaths, g.Edges)
+		if err != nil {
+			return nil, fmt.Errorf("fsmviz.GraphML: %w", err)
+		}
+		res.Nodes = append(res.Nodes, node)
+	}
+
+	logger.Debug("GraphML: nodes completed")
+
+	sb := &strings.Builder{}
+	for _, p1 := range sortedKeyValue(g.Edges, fsm.StateID.Compare) {
+		for _, p2 := range sortedKeyValue(p1.Second, fsm.StateID.Compare) {
+			for _, allocation := range p2.Second.Iter() {
+				edge, err := GraphMLEdge(len(res.Edges), fsm.StateTransitionGraphEdge{
+					Source:     p1.First,
+					Target:     p2.First,
+					Allocation: allocation,
+				}, sb)
+				if err != nil {
+					return nil, fmt.Errorf("fsmviz.GraphML: %w", err)
+				}
+				res.Edges = append(res.Edges, edge)
+			}
+		}
+	}
+
+	logger.Debug("GraphML: edges completed")
+
+	return &graphml.Document{Keys: GraphMLKeys, Graph: res}, nil
+}
+
+func GraphMLNode(stateID fsm.StateID, state fsm.State, completedState *sets.Set[fsm.StateID], bestPlanPaths *sets.Set[fsm.State], edgeMap map[fsm.StateID]map[fsm.StateID]*sets.Set[fsm.Allocation]) (*graphml.Node, error) {
+	isCompleted := completedState.Contains(fsm.StateID.Compare, stateID)
+	var isUnexpectedDeadlock bool
+	if m, ok := edgeMap[stateID]; !ok || len(m) == 0 {
+		isUnexpectedDeadlock = !isCompleted
+	}
+
+	revision, err := json.Marshal(state.RevisionMap)
+	if err != nil {
+		return nil, fmt.Errorf("fsmviz.GraphMLNode: %w", err)
+	}
+	remainedVolume, err := json.Marshal(state.RemainedVolumeMap)
+	if err != nil {
+		return nil, fmt.Errorf("fsmviz.GraphMLNode: %w", err)
+	}
+	numOfComplete, err := json.Marshal(state.NumOfCompleteMap)
+	if err != nil {
+		return nil, fmt.Errorf("fsmviz.GraphMLNode: %w", err)
+	}
+
+	return &graphml.Node{
+		ID: graphml.NodeID(DotNodeID(stateID)),
+		Data: []*graphml.Data{
+			{Key: KeyLabel, Value: string(DotNodeID(stateID))},
+			{Key: KeyTime, Value: strconv.FormatFloat(float64(state.Time), 'f', -1, 64)},
+			{Key: KeyCompleted, Value: strconv.FormatBool(isCompleted)},
+			{Key: KeyDeadlock, Value: strconv.FormatBool(isUnexpectedDeadlock)},
+			{Key: KeyBestPlan, Value: strconv.FormatBool(bestPlanPaths.Contains(fsm.State.Compare, state))},
+			{Key: KeyRevision, Value: string(revision)},
+			{Key: KeyRemainedVolume, Value: string(remainedVolume)},
+			{Key: KeyNumOfComplete, Value: string(numOfComplete)},
+		},
+	}, nil
+}
+
+func GraphMLEdge(index int, edge fsm.StateTransitionGraphEdge, sb *strings.Builder) (*graphml.Edge, error) {
+	allocation, err := json.Marshal(edge.Allocation)
+	if err != nil {
+		return nil, fmt.Errorf("fsmviz.GraphMLEdge: %w", err)
+	}
+
+	sb.Reset()
+	if err := edge.Allocation.Write(sb); err != nil {
+		return nil, fmt.Errorf("fsmviz.GraphMLEdge: %w", err)
+	}
+	label := strings.TrimSuffix(sb.String(), "\n")
+
+	return &graphml.Edge{
+		ID:     fmt.Sprintf("e%d", index),
+		Source: graphml.NodeID(DotNodeID(edge.Source)),
+		Target: graphml.NodeID(DotNodeID(edge.Target)),
+		Data: []*graphml.Data{
+			{Key: KeyEdgeLabel, Value: label},
+			{Key: KeyAllocation, Value: string(allocation)},
+			{Key: KeyConsumedVolume, Value: strconv.FormatFloat(float64(edge.Allocation.TotalConsumedVolume()), 'f', -1, 64)},
+		},
+	}, nil
+}
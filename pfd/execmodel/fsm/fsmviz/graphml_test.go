@@ -0,0 +1,129 @@
+package fsmviz
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGraphML(t *testing.T) {
+	// 0 -(P1)-> 1
+	// |
+	// +-(P2)-> 2
+	states := map[fsm.StateID]fsm.State{
+		0: {
+			Time:              0,
+			RemainedVolumeMap: map[pfd.AtomicProcessID]fsm.Volume{"P1": 1, "P2": 1},
+			RevisionMap:       map[pfd.AtomicDeliverableID]int{"D1": 1, "D2": 0},
+			NumOfCompleteMap:  map[pfd.AtomicProcessID]int{"P1": 0, "P2": 0},
+		},
+		1: {
+			Time:              1,
+			RemainedVolumeMap: map[pfd.AtomicProcessID]fsm.Volume{"P1": 0, "P2": 1},
+			RevisionMap:       map[pfd.AtomicDeliverableID]int{"D1": 1, "D2": 1},
+			NumOfCompleteMap:  map[pfd.AtomicProcessID]int{"P1": 1, "P2": 0},
+		},
+		2: {
+			Time:              1.5,
+			RemainedVolumeMap: map[pfd.AtomicProcessID]fsm.Volume{"P1": 1, "P2": 0},
+			RevisionMap:       map[pfd.AtomicDeliverableID]int{"D1": 1, "D2": 0},
+			NumOfCompleteMap:  map[pfd.AtomicProcessID]int{"P1": 0, "P2": 1},
+		},
+	}
+	p1 := fsm.Allocation{"P1": {Resources: sets.New(fsm.ResourceID.Compare, "R1"), ConsumedVolume: 1}}
+	p2 := fsm.Allocation{"P2": {Resources: sets.New(fsm.ResourceID.Compare, "R1", "R2"), ConsumedVolume: 2}}
+	g := fsm.StateTransitionGraph{
+		InitialState: 0,
+		Nodes:        states,
+		Edges: map[fsm.StateID]map[fsm.StateID]*sets.Set[fsm.Allocation]{
+			0: {
+				1: sets.New(fsm.Allocation.Compare, p1),
+				2: sets.New(fsm.Allocation.Compare, p2),
+			},
+		},
+	}
+	plans := sets.New((*fsm.Plan).Compare, &fsm.Plan{
+		InitialState: states[0],
+		Transitions:  []*fsm.Trans{{Allocation: p1, NextState: states[1]}},
+	})
+	isCompleted := func(s fsm.State) bool {
+		return s.RevisionMap["D2"] > 0
+	}
+
+	doc, err := GraphML(isCompleted, g, plans, slog.New(slogtest.NewTestHandler(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sb := &strings.Builder{}
+	if err := doc.Write(sb); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="label" for="node" attr.name="label" attr.type="string"></key>
+  <key id="time" for="node" attr.name="time" attr.type="double"></key>
+  <key id="completed" for="node" attr.name="completed" attr.type="boolean"></key>
+  <key id="deadlock" for="node" attr.name="deadlock" attr.type="boolean"></key>
+  <key id="best_plan" for="node" attr.name="best plan" attr.type="boolean"></key>
+  <key id="revision" for="node" attr.name="revision" attr.type="string"></key>
+  <key id="remained_volume" for="node" attr.name="remained volume" attr.type="string"></key>
+  <key id="num_of_complete" for="node" attr.name="num of complete" attr.type="string"></key>
+  <key id="edge_label" for="edge" attr.name="label" attr.type="string"></key>
+  <key id="allocation" for="edge" attr.name="allocation" attr.type="string"></key>
+  <key id="consumed_volume" for="edge" attr.name="consumed volume" attr.type="double"></key>
+  <graph id="G" edgedefault="directed">
+    <node id="S0">
+      <data key="label">S0</data>
+      <data key="time">0</data>
+      <data key="completed">false</data>
+      <data key="deadlock">false</data>
+      <data key="best_plan">true</data>
+      <data key="revision">{&#34;D1&#34;:1,&#34;D2&#34;:0}</data>
+      <data key="remained_volume">{&#34;P1&#34;:1,&#34;P2&#34;:1}</data>
+      <data key="num_of_complete">{&#34;P1&#34;:0,&#34;P2&#34;:0}</data>
+    </node>
+    <node id="S1">
+      <data key="label">S1</data>
+      <data key="time">1</data>
+      <data key="completed">true</data>
+      <data key="deadlock">false</data>
+      <data key="best_plan">true</data>
+      <data key="revision">{&#34;D1&#34;:1,&#34;D2&#34;:1}</data>
+      <data key="remained_volume">{&#34;P1&#34;:0,&#34;P2&#34;:1}</data>
+      <data key="num_of_complete">{&#34;P1&#34;:1,&#34;P2&#34;:0}</data>
+    </node>
+    <node id="S2">
+      <data key="label">S2</data>
+      <data key="time">1.5</data>
+      <data key="completed">false</data>
+      <data key="deadlock">true</data>
+      <data key="best_plan">false</data>
+      <data key="revision">{&#34;D1&#34;:1,&#34;D2&#34;:0}</data>
+      <data key="remained_volume">{&#34;P1&#34;:1,&#34;P2&#34;:0}</data>
+      <data key="num_of_complete">{&#34;P1&#34;:0,&#34;P2&#34;:1}</data>
+    </node>
+    <edge id="e0" source="S0" target="S1">
+      <data key="edge_label">P1 -&gt; R1, 1;</data>
+      <data key="allocation">{&#34;P1&#34;:{&#34;resources&#34;:[&#34;R1&#34;],&#34;consumed_volume&#34;:1}}</data>
+      <data key="consumed_volume">1</data>
+    </edge>
+    <edge id="e1" source="S0" target="S2">
+      <data key="edge_label">P2 -&gt; R1, R2, 2;</data>
+      <data key="allocation">{&#34;P2&#34;:{&#34;resources&#34;:[&#34;R1&#34;,&#34;R2&#34;],&#34;consumed_volume&#34;:2}}</data>
+      <data key="consumed_volume">2</data>
+    </edge>
+  </graph>
+</graphml>
+`
+	if sb.String() != expected {
+		t.Error(cmp.Diff(expected, sb.String()))
+	}
+}
@@ -3,13 +3,11 @@ package fsmviz
 import (
 	"fmt"
 	"log/slog"
-	"math"
 	"slices"
 	"strings"
 
 	"github.com/Kuniwak/pfd-tools/dot"
 	"github.com/Kuniwak/pfd-tools/pairs"
-	"github.com/Kuniwak/pfd-tools/pfd/execmodel"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
 	"github.com/Kuniwak/pfd-tools/sets"
 )
@@ -44,24 +42,11 @@ func Dot(isCompleted func(fsm.State) bool, g fsm.StateTransitionGraph, plans *se
 
 	sb := &strings.Builder{}
 
-	completedState := sets.New(fsm.StateID.Compare)
-	minTime := execmodel.Time(math.MaxFloat64)
-	for nodeID, state := range g.Nodes {
-		if !isCompleted(state) {
-			continue
-		}
-		completedState.Add(fsm.StateID.Compare, nodeID)
-		minTime = min(minTime, state.Time)
-	}
+	completedState := CompletedStates(isCompleted, g)
 
 	logger.Debug("Dot: completed state completed")
 
-	bestPlanPaths := sets.New(fsm.State.Compare, g.Nodes[g.InitialState])
-	for _, plan := range plans.Iter() {
-		for _, tr := range plan.Transitions {
-			bestPlanPaths.Add(fsm.State.Compare, tr.NextState)
-		}
-	}
+	bestPlanPaths := BestPlanStates(g, plans)
 
 	logger.Debug("Dot: best plan paths completed")
 
@@ -96,6 +81,29 @@ func Dot(isCompleted func(fsm.State) bool, g fsm.StateTransitionGraph, plans *se
 	return res, nil
 }
 
+// CompletedStates returns the states that complete all processes.
+func CompletedStates(isCompleted func(fsm.State) bool, g fsm.StateTransitionGraph) *sets.Set[fsm.StateID] {
+	completedState := sets.New(fsm.StateID.Compare)
+	for nodeID, state := range g.Nodes {
+		if !isCompleted(state) {
+			continue
+		}
+		completedState.Add(fsm.StateID.Compare, nodeID)
+	}
+	return completedState
+}
+
+// BestPlanStates returns the states on the plans including the initial state.
+func BestPlanStates(g fsm.StateTransitionGraph, plans *sets.Set[*fsm.Plan]) *sets.Set[fsm.State] {
+	bestPlanPaths := sets.New(fsm.State.Compare, g.Nodes[g.InitialState])
+	for _, plan := range plans.Iter() {
+		for _, tr := range plan.Transitions {
+			bestPlanPaths.Add(fsm.State.Compare, tr.NextState)
+		}
+	}
+	return bestPlanPaths
+}
+
 func InitialState(g fsm.StateTransitionGraph) (fsm.StateID, fsm.State) {
 	for nodeID, state := range g.Nodes {
 		if state.Time == 0 {
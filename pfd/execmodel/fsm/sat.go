@@ -0,0 +1,114 @@
+package fsm
+
+import "fmt"
+
+// preconditionAtom is a boolean variable of the boolean structure of preconditions.
+type preconditionAtom struct {
+	Type PreconditionType
+	ID   string
+}
+
+// Satisfiable returns true if the precondition is true under some truth assignment. Every \exec(...),
+// \complete(...) and \complete(*) is regarded as an independent boolean variable, so the result depends only on the
+// boolean structure of the precondition.
+func (p *Precondition) Satisfiable() bool {
+	return p.satisfiable(make(map[preconditionAtom]bool), p.atoms())
+}
+
+// IsTautology returns true if the precondition is true under every truth assignment. See Satisfiable for the
+// definition of truth assignments.
+func (p *Precondition) IsTautology() bool {
+	return !NewNotPrecondition(p).Satisfiable()
+}
+
+// satisfiable decides the satisfiability by splitting on the unassigned atoms. Partial assignments that already
+// determine the value are not extended.
+func (p *Precondition) satisfiable(assignment map[preconditionAtom]bool, unassigned []preconditionAtom) bool {
+	if v, ok := p.evalPartial(assignment); ok {
+		return v
+	}
+	if len(unassigned) == 0 {
+		panic(fmt.Sprintf("fsm.Precondition.satisfiable: undetermined under the total assignment: %v", assignment))
+	}
+
+	atom := unassigned[0]
+	defer delete(assignment, atom)
+	for _, b := range []bool{true, false} {
+		assignment[atom] = b
+		if p.satisfiable(assignment, unassigned[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// evalPartial returns the value of the precondition under the partial assignment and whether the value is determined.
+func (p *Precondition) evalPartial(assignment map[preconditionAtom]bool) (bool, bool) {
+	switch p.Type {
+	case PreconditionTypeTrue:
+		return true, true
+
+	case PreconditionTypeFeedbackSourceCompleted, PreconditionTypeExecutable, PreconditionTypeAllBackwardReachableFeedbackSourcesCompleted:
+		v, ok := assignment[p.atom()]
+		return v, ok
+
+	case PreconditionTypeNot:
+		v, ok := p.Not.evalPartial(assignment)
+		return !v, ok
+
+	case PreconditionTypeOr:
+		determined := true
+		for _, q := range p.Or {
+			v, ok := q.evalPartial(assignment)
+			if ok && v {
+				return true, true
+			}
+			determined = determined && ok
+		}
+		return false, determined
+
+	case PreconditionTypeAnd:
+		determined := true
+		for _, q := range p.And {
+			v, ok := q.evalPartial(assignment)
+			if ok && !v {
+				return false, true
+			}
+			determined = determined && ok
+		}
+		return true, determined
+
+	default:
+		panic(fmt.Sprintf("fsm.Precondition.evalPartial: invalid type: %q", p.Type))
+	}
+}
+
+// atoms returns the distinct atoms in the order of appearance.
+func (p *Precondition) atoms() []preconditionAtom {
+	atoms := make([]preconditionAtom, 0)
+	seen := make(map[preconditionAtom]bool)
+	p.Traverse(func(q *Precondition) {
+		switch q.Type {
+		case PreconditionTypeFeedbackSourceCompleted, PreconditionTypeExecutable, PreconditionTypeAllBackwardReachableFeedbackSourcesCompleted:
+			atom := q.atom()
+			if !seen[atom] {
+				seen[atom] = true
+				atoms = append(atoms, atom)
+			}
+		}
+	})
+	return atoms
+}
+
+func (p *Precondition) atom() preconditionAtom {
+	switch p.Type {
+	case PreconditionTypeFeedbackSourceCompleted:
+		return preconditionAtom{Type: p.Type, ID: string(p.FeedbackSource)}
+	case PreconditionTypeExecutable:
+		return preconditionAtom{Type: p.Type, ID: string(p.Executable)}
+	case PreconditionTypeAllBackwardReachableFeedbackSourcesCompleted:
+		return preconditionAtom{Type: p.Type, ID: string(p.AllBackwardReachableFeedbackSourcesCompletedTarget)}
+	default:
+		panic(fmt.Sprintf("fsm.Precondition.atom: not an atom: %q", p.Type))
+	}
+}
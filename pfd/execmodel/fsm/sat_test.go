@@ -0,0 +1,81 @@
+package fsm
+
+import "testing"
+
+func TestPrecondition_Satisfiable(t *testing.T) {
+	testCases := map[string]struct {
+		Input           *Precondition
+		WantSatisfiable bool
+		WantTautology   bool
+	}{
+		"true": {
+			Input:           NewTruePrecondition(),
+			WantSatisfiable: true,
+			WantTautology:   true,
+		},
+		"not true": {
+			Input:           NewNotPrecondition(NewTruePrecondition()),
+			WantSatisfiable: false,
+			WantTautology:   false,
+		},
+		"atom": {
+			Input:           NewFeedbackSourceCompletedPrecondition("D2"),
+			WantSatisfiable: true,
+			WantTautology:   false,
+		},
+		"contradiction": {
+			Input: NewAndPrecondition(
+				NewFeedbackSourceCompletedPrecondition("D2"),
+				NewNotPrecondition(NewFeedbackSourceCompletedPrecondition("D2")),
+			),
+			WantSatisfiable: false,
+			WantTautology:   false,
+		},
+		"excluded middle": {
+			Input: NewOrPrecondition(
+				NewNotPrecondition(NewExecutablePrecondition("P1")),
+				NewExecutablePrecondition("P1"),
+			),
+			WantSatisfiable: true,
+			WantTautology:   true,
+		},
+		"different atoms with the same ID": {
+			Input: NewAndPrecondition(
+				NewExecutablePrecondition("P1"),
+				NewNotPrecondition(NewAllBackwardReachableFeedbackSourcesCompleted("P1")),
+			),
+			WantSatisfiable: true,
+			WantTautology:   false,
+		},
+		"nested contradiction": {
+			// (a || b) && !a && !b
+			Input: NewAndPrecondition(
+				NewOrPrecondition(NewExecutablePrecondition("P1"), NewFeedbackSourceCompletedPrecondition("D2")),
+				NewNotPrecondition(NewExecutablePrecondition("P1")),
+				NewNotPrecondition(NewFeedbackSourceCompletedPrecondition("D2")),
+			),
+			WantSatisfiable: false,
+			WantTautology:   false,
+		},
+		"tautology by implication": {
+			// (a && b) -> a
+			Input: NewOrPrecondition(
+				NewNotPrecondition(NewAndPrecondition(NewExecutablePrecondition("P1"), NewFeedbackSourceCompletedPrecondition("D2"))),
+				NewExecutablePrecondition("P1"),
+			),
+			WantSatisfiable: true,
+			WantTautology:   true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := testCase.Input.Satisfiable(); got != testCase.WantSatisfiable {
+				t.Errorf("Satisfiable: want %t, got %t", testCase.WantSatisfiable, got)
+			}
+			if got := testCase.Input.IsTautology(); got != testCase.WantTautology {
+				t.Errorf("IsTautology: want %t, got %t", testCase.WantTautology, got)
+			}
+		})
+	}
+}
@@ -33,11 +33,13 @@ var ConsistentAPTable = checkers.AtomicChecker[pfdcommon.Target]{
 
 		const missingProblemID = "missing-ap-table"
 		for _, ap := range missing.Iter() {
-			ch <- checkers.NewProblem(missingProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, pfd.NodeID(ap)))...)
+			fix := checkers.NewFix(pfdcommon.EditAddRow{Table: pfd.TableTypeAtomicProcess, ID: pfd.NodeID(ap), Description: t.Memoized.NodeMap[pfd.NodeID(ap)].Description})
+			ch <- checkers.NewProblem(missingProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, pfd.NodeID(ap)))...).WithFixes(fix)
 		}
 		const extraProblemID = "extra-ap-table"
 		for _, ap := range extra.Iter() {
-			ch <- checkers.NewProblem(extraProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, pfd.NodeID(ap)))...)
+			fix := checkers.NewFix(pfdcommon.EditRemoveRow{Table: pfd.TableTypeAtomicProcess, ID: pfd.NodeID(ap)})
+			ch <- checkers.NewProblem(extraProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, pfd.NodeID(ap)))...).WithFixes(fix)
 		}
 		return nil
 	},
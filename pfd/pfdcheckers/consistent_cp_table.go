@@ -35,12 +35,14 @@ var ConsistentCPTable = checkers.AtomicChecker[pfdcommon.Target]{
 
 		const missingProblemID = "missing-cp-table"
 		for _, cp := range missing.Iter() {
-			ch <- checkers.NewProblem(missingProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeCompositeProcessTable, pfd.NodeID(cp)))...)
+			fix := checkers.NewFix(pfdcommon.EditAddRow{Table: pfd.TableTypeCompositeProcess, ID: pfd.NodeID(cp), Description: t.Memoized.NodeMap[pfd.NodeID(cp)].Description})
+			ch <- checkers.NewProblem(missingProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeCompositeProcessTable, pfd.NodeID(cp)))...).WithFixes(fix)
 		}
 
 		const extraProblemID = "extra-cp-table"
 		for _, cp := range extra.Iter() {
-			ch <- checkers.NewProblem(extraProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeCompositeProcessTable, pfd.NodeID(cp)))...)
+			fix := checkers.NewFix(pfdcommon.EditRemoveRow{Table: pfd.TableTypeCompositeProcess, ID: pfd.NodeID(cp)})
+			ch <- checkers.NewProblem(extraProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeCompositeProcessTable, pfd.NodeID(cp)))...).WithFixes(fix)
 		}
 
 		return nil
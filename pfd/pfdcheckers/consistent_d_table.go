@@ -34,12 +34,14 @@ var ConsistentDTable = checkers.AtomicChecker[pfdcommon.Target]{
 
 		const missingProblemID = "missing-d-table"
 		for _, d := range missing.Iter() {
-			ch <- checkers.NewProblem(missingProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeDeliverableTable, pfd.NodeID(d)))...)
+			fix := checkers.NewFix(pfdcommon.EditAddRow{Table: pfd.TableTypeAtomicDeliverable, ID: pfd.NodeID(d), Description: t.Memoized.NodeMap[pfd.NodeID(d)].Description})
+			ch <- checkers.NewProblem(missingProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeDeliverableTable, pfd.NodeID(d)))...).WithFixes(fix)
 		}
 
 		const extraProblemID = "extra-d-table"
 		for _, d := range extra.Iter() {
-			ch <- checkers.NewProblem(extraProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeDeliverableTable, pfd.NodeID(d)))...)
+			fix := checkers.NewFix(pfdcommon.EditRemoveRow{Table: pfd.TableTypeAtomicDeliverable, ID: pfd.NodeID(d)})
+			ch <- checkers.NewProblem(extraProblemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeDeliverableTable, pfd.NodeID(d)))...).WithFixes(fix)
 		}
 
 		return nil
@@ -17,7 +17,9 @@ var ConsistentDesc = checkers.AtomicChecker[pfdcommon.Target]{
 		for _, node := range t.PFD.Nodes.Iter() {
 			if _, ok := descMap[node.ID]; ok {
 				if descMap[node.ID] != node.Description {
-					ch <- checkers.NewProblem(problemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, node.ID))...)
+					// NOTE: The first description is canonical because nodes are sorted.
+					fix := checkers.NewFix(pfdcommon.EditSetDescription{ID: node.ID, Description: descMap[node.ID]})
+					ch <- checkers.NewProblem(problemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, node.ID))...).WithFixes(fix)
 				}
 			} else {
 				descMap[node.ID] = node.Description
@@ -42,7 +42,7 @@ func TestConsistentDesc(t *testing.T) {
 					&pfd.Edge{Source: "P1", Target: "D3"},
 				),
 			},
-			Expected: []checkers.Problem{checkers.NewProblem("consistent-desc", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"))...)},
+			Expected: []checkers.Problem{checkers.NewProblem("consistent-desc", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"))...).WithFixes(checkers.NewFix(pfdcommon.EditSetDescription{ID: "D1", Description: "D1"}))},
 		},
 		"ok": {
 			PFD: &pfd.PFD{
@@ -29,7 +29,8 @@ var NoP2DFB = checkers.AtomicChecker[pfdcommon.Target]{
 			if src.Type.IsDeliverable() && target.Type.IsProcess() {
 				continue
 			}
-			ch <- checkers.NewProblem(problemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, edge.Source, edge.Target))...)
+			fix := checkers.NewFix(pfdcommon.EditSetFeedback{Source: edge.Source, Target: edge.Target, IsFeedback: false})
+			ch <- checkers.NewProblem(problemID, checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, edge.Source, edge.Target))...).WithFixes(fix)
 		}
 		return nil
 	},
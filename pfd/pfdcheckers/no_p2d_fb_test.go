@@ -38,7 +38,7 @@ func TestNoP2DFB(t *testing.T) {
 					&pfd.Edge{Source: "P1", Target: "D1", IsFeedback: true},
 				),
 			},
-			Expected: []checkers.Problem{checkers.NewProblem("no-p2d-fb", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "P1", "D1"))...)},
+			Expected: []checkers.Problem{checkers.NewProblem("no-p2d-fb", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "P1", "D1"))...).WithFixes(checkers.NewFix(pfdcommon.EditSetFeedback{Source: "P1", Target: "D1", IsFeedback: false}))},
 		},
 		"ok": {
 			PFD: &pfd.PFD{
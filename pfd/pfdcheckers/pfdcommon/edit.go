@@ -0,0 +1,75 @@
+package pfdcommon
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+)
+
+// EditSetDescription sets the description of all the vertices of the node in the PFD.
+type EditSetDescription struct {
+	ID          pfd.NodeID
+	Description string
+}
+
+var _ checkers.Edit = EditSetDescription{}
+
+func (e EditSetDescription) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "set the description of %s to %q", e.ID, e.Description); err != nil {
+		return fmt.Errorf("pfdcommon.EditSetDescription.Write: %w", err)
+	}
+	return nil
+}
+
+// EditSetFeedback sets whether the edges from the source to the target in the PFD are feedback edges.
+type EditSetFeedback struct {
+	Source     pfd.NodeID
+	Target     pfd.NodeID
+	IsFeedback bool
+}
+
+var _ checkers.Edit = EditSetFeedback{}
+
+func (e EditSetFeedback) Write(w io.Writer) error {
+	kind := "a non-feedback edge"
+	if e.IsFeedback {
+		kind = "a feedback edge"
+	}
+	if _, err := fmt.Fprintf(w, "make %s -> %s %s", e.Source, e.Target, kind); err != nil {
+		return fmt.Errorf("pfdcommon.EditSetFeedback.Write: %w", err)
+	}
+	return nil
+}
+
+// EditAddRow adds the row of the node to the table. Extra cells of the row are empty.
+type EditAddRow struct {
+	Table       pfd.TableType
+	ID          pfd.NodeID
+	Description string
+}
+
+var _ checkers.Edit = EditAddRow{}
+
+func (e EditAddRow) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "add %s to the %s table", e.ID, e.Table); err != nil {
+		return fmt.Errorf("pfdcommon.EditAddRow.Write: %w", err)
+	}
+	return nil
+}
+
+// EditRemoveRow removes the rows of the node from the table.
+type EditRemoveRow struct {
+	Table pfd.TableType
+	ID    pfd.NodeID
+}
+
+var _ checkers.Edit = EditRemoveRow{}
+
+func (e EditRemoveRow) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "remove %s from the %s table", e.ID, e.Table); err != nil {
+		return fmt.Errorf("pfdcommon.EditRemoveRow.Write: %w", err)
+	}
+	return nil
+}
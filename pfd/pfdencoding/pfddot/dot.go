@@ -11,14 +11,24 @@ import (
 )
 
 func Write(w io.Writer, p *pfd.PFD) error {
-	d, err := Dot(p)
+	return WriteWithOverlay(w, p, nil)
+}
+
+func WriteWithOverlay(w io.Writer, p *pfd.PFD, overlay *Overlay) error {
+	d, err := DotWithOverlay(p, overlay)
 	if err != nil {
-		return fmt.Errorf("pfddot.Write: %w", err)
+		return fmt.Errorf("pfddot.WriteWithOverlay: %w", err)
 	}
 	return d.Write(w)
 }
 
 func Dot(p *pfd.PFD) (*dot.Digraph, error) {
+	return DotWithOverlay(p, nil)
+}
+
+// DotWithOverlay returns the PFD as a digraph. Composite processes are drawn as clusters that enclose their members,
+// and edges connected to composite processes are clipped at the clusters. The overlay may be nil.
+func DotWithOverlay(p *pfd.PFD, overlay *Overlay) (*dot.Digraph, error) {
 	res := &dot.Digraph{
 		Name: p.Title,
 		GraphAttributes: sets.New(
@@ -37,26 +47,163 @@ func Dot(p *pfd.PFD) (*dot.Digraph, error) {
 		),
 		EdgeAttributes: sets.New(dot.CompareAttribute),
 		Nodes:          make([]*dot.Node, 0, p.Nodes.Len()),
+		Subgraphs:      make([]*dot.Subgraph, 0),
 		Edges:          make([]*dot.Edge, 0, p.Edges.Len()),
 	}
 
+	decorator := newNodeDecorator(overlay)
+	c := newClusters(p, decorator)
+
 	for _, node := range p.Nodes.Iter() {
-		res.Nodes = append(res.Nodes, DotNode(node))
+		if c.placed.Contains(pfd.NodeID.Compare, node.ID) {
+			continue
+		}
+		if cluster, ok := c.clusters[node.ID]; ok {
+			res.Subgraphs = append(res.Subgraphs, cluster)
+			continue
+		}
+		res.Nodes = append(res.Nodes, decorator.Decorate(node))
+	}
+
+	if len(c.representatives) > 0 {
+		res.GraphAttributes.Add(dot.CompareAttribute, &pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+			First:  dot.AttributeNameCompound,
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeBool, Bool: true},
+		})
 	}
 
 	for _, edge := range p.Edges.Iter() {
-		res.Edges = append(res.Edges, DotEdge(edge))
+		res.Edges = append(res.Edges, c.clipEdge(DotEdge(edge)))
 	}
 
 	return res, nil
 }
 
+// clusters is the clusters of composite processes. A composite process without members is drawn as a node, because
+// Graphviz does not draw empty clusters and edges clipped at clusters need a node in them.
+type clusters struct {
+	clusters map[pfd.NodeID]*dot.Subgraph
+
+	// representatives maps composite processes drawn as clusters to nodes in the clusters.
+	representatives map[pfd.NodeID]pfd.NodeID
+
+	// placed is the nodes and the nested clusters drawn in some cluster.
+	placed *sets.Set[pfd.NodeID]
+}
+
+func newClusters(p *pfd.PFD, decorator *nodeDecorator) *clusters {
+	nodeMap := make(map[pfd.NodeID]*pfd.Node, p.Nodes.Len())
+	for _, node := range p.Nodes.Iter() {
+		nodeMap[node.ID] = node
+	}
+
+	c := &clusters{
+		clusters:        make(map[pfd.NodeID]*dot.Subgraph),
+		representatives: make(map[pfd.NodeID]pfd.NodeID),
+		placed:          sets.New(pfd.NodeID.Compare),
+	}
+
+	children := sets.New(pfd.NodeID.Compare)
+	for _, members := range p.ProcessComposition {
+		for _, member := range members.Iter() {
+			children.Add(pfd.NodeID.Compare, member)
+		}
+	}
+
+	visited := sets.New(pfd.NodeID.Compare)
+	var build func(node *pfd.Node) (*dot.Subgraph, pfd.NodeID, bool)
+	build = func(node *pfd.Node) (*dot.Subgraph, pfd.NodeID, bool) {
+		cluster := &dot.Subgraph{
+			Name: dot.ClusterName(dot.NodeID(node.ID)),
+			GraphAttributes: sets.New(
+				dot.CompareAttribute,
+				&pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+					First:  dot.AttributeNameLabel,
+					Second: dot.AttributeValue{Type: dot.AttributeValueTypeString, String: label(node)},
+				},
+			),
+		}
+
+		var representative pfd.NodeID
+		var found bool
+		for _, id := range p.ProcessComposition[node.ID].Iter() {
+			// NOTE: Guard against cyclic process compositions and processes in multiple composite processes.
+			if visited.Contains(pfd.NodeID.Compare, id) {
+				continue
+			}
+			member, ok := nodeMap[id]
+			if !ok {
+				continue
+			}
+			visited.Add(pfd.NodeID.Compare, id)
+
+			if member.Type == pfd.NodeTypeCompositeProcess {
+				if sub, subRepresentative, ok := build(member); ok {
+					cluster.Subgraphs = append(cluster.Subgraphs, sub)
+					c.placed.Add(pfd.NodeID.Compare, id)
+					if !found {
+						representative, found = subRepresentative, true
+					}
+					continue
+				}
+			}
+
+			cluster.Nodes = append(cluster.Nodes, decorator.Decorate(member))
+			c.placed.Add(pfd.NodeID.Compare, id)
+			if !found {
+				representative, found = id, true
+			}
+		}
+		if !found {
+			return nil, "", false
+		}
+
+		c.clusters[node.ID] = cluster
+		c.representatives[node.ID] = representative
+		return cluster, representative, true
+	}
+
+	for _, node := range p.Nodes.Iter() {
+		if node.Type != pfd.NodeTypeCompositeProcess || children.Contains(pfd.NodeID.Compare, node.ID) {
+			continue
+		}
+		visited.Add(pfd.NodeID.Compare, node.ID)
+		build(node)
+	}
+
+	return c
+}
+
+// clipEdge replaces the composite process ends of the edge with nodes in their clusters and clips the edge at the
+// clusters.
+func (c *clusters) clipEdge(edge *dot.Edge) *dot.Edge {
+	if representative, ok := c.representatives[pfd.NodeID(edge.Source)]; ok {
+		edge.Attributes.Add(dot.CompareAttribute, &pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+			First:  dot.AttributeNameLTail,
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeString, String: dot.ClusterName(edge.Source)},
+		})
+		edge.Source = dot.NodeID(representative)
+	}
+	if representative, ok := c.representatives[pfd.NodeID(edge.Target)]; ok {
+		edge.Attributes.Add(dot.CompareAttribute, &pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+			First:  dot.AttributeNameLHead,
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeString, String: dot.ClusterName(edge.Target)},
+		})
+		edge.Target = dot.NodeID(representative)
+	}
+	return edge
+}
+
+func label(node *pfd.Node) string {
+	return fmt.Sprintf("%s: %s", node.ID, node.Description)
+}
+
 func DotNode(node *pfd.Node) *dot.Node {
 	attrs := sets.New(
 		dot.CompareAttribute,
 		&pairs.Pair[dot.AttributeName, dot.AttributeValue]{
 			First:  dot.AttributeNameLabel,
-			Second: dot.AttributeValue{Type: dot.AttributeValueTypeString, String: fmt.Sprintf("%s: %s", node.ID, node.Description)},
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeString, String: label(node)},
 		},
 	)
 
@@ -0,0 +1,129 @@
+package pfddot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteWithOverlay(t *testing.T) {
+	p := &pfd.PFD{
+		Title: "Composite",
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Description: "Spec", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D2", Description: "Code", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D3", Description: "Binary", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "P2", Description: "Build", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "P3", Description: "Develop", Type: pfd.NodeTypeCompositeProcess},
+			&pfd.Node{ID: "P4", Description: "Release", Type: pfd.NodeTypeCompositeProcess},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D1", Target: "P1"},
+			&pfd.Edge{Source: "P1", Target: "D2"},
+			&pfd.Edge{Source: "D2", Target: "P2"},
+			&pfd.Edge{Source: "P2", Target: "D3"},
+			&pfd.Edge{Source: "D1", Target: "P4"},
+			&pfd.Edge{Source: "P4", Target: "D3"},
+		),
+		ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+			"P3": sets.New(pfd.NodeID.Compare, "P1"),
+			"P4": sets.New(pfd.NodeID.Compare, "P2", "P3"),
+		},
+		DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{},
+	}
+
+	testCases := map[string]struct {
+		Overlay  *Overlay
+		Expected string
+	}{
+		"no overlay": {
+			Overlay: nil,
+			Expected: `digraph "Composite" {
+  graph [charset="UTF-8"; compound=true];
+  node [style="filled"];
+  edge [];
+  D1 [label="D1: Spec"; shape="box"; ];
+  D2 [label="D2: Code"; shape="box"; ];
+  D3 [label="D3: Binary"; shape="box"; ];
+  subgraph "cluster_P4" {
+    graph [label="P4: Release"];
+    P2 [label="P2: Build"; shape="ellipse"; ];
+    subgraph "cluster_P3" {
+      graph [label="P3: Develop"];
+      P1 [label="P1: Implement"; shape="ellipse"; ];
+    }
+  }
+  D1 -> P1 [style="solid"; ];
+  D1 -> P2 [lhead="cluster_P4"; style="solid"; ];
+  D2 -> P2 [style="solid"; ];
+  P1 -> D2 [style="solid"; ];
+  P2 -> D3 [style="solid"; ];
+  P2 -> D3 [ltail="cluster_P4"; style="solid"; ];
+}
+`,
+		},
+		"overlay": {
+			Overlay: &Overlay{
+				CriticalPath: fsm.CriticalPathInfo{
+					"P1": &fsm.CriticalPathInfoItem{MaximumElasticity: 0},
+					"P2": &fsm.CriticalPathInfoItem{MaximumElasticity: 1},
+				},
+				Problems: []checkers.Problem{
+					checkers.NewProblem("no-desc", checkers.SeverityWarning, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"))...),
+					checkers.NewProblem("single-src", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1", "D2"))...),
+					checkers.NewProblem("never-allocatable", checkers.SeverityWarning, fsmcommon.NewLocations(fsmcommon.NewLocation(fsmcommon.LocationTypeAtomicProcessTable, fsmcommon.NewAtomicProcessID("P2"), fsmcommon.NewResourceID("R1")))...),
+				},
+				StartTimes: map[pfd.AtomicProcessID]execmodel.Time{
+					"P1": 0,
+					"P2": 2.5,
+				},
+			},
+			Expected: `digraph "Composite" {
+  graph [charset="UTF-8"; compound=true];
+  node [style="filled"];
+  edge [];
+  D1 [color="red"; fillcolor="lightgrey"; label="D1: Spec"; shape="box"; tooltip="WARNING: no-desc\lERROR: single-src"; ];
+  D2 [color="red"; fillcolor="lightgrey"; label="D2: Code"; shape="box"; tooltip="ERROR: single-src"; ];
+  D3 [label="D3: Binary"; shape="box"; ];
+  subgraph "cluster_P4" {
+    graph [label="P4: Release"];
+    P2 [color="orange"; fillcolor="0.600 0.700 1.000"; label="P2: Build"; shape="ellipse"; tooltip="start: 2.5\lWARNING: never-allocatable"; ];
+    subgraph "cluster_P3" {
+      graph [label="P3: Develop"];
+      P1 [fillcolor="0.600 0.100 1.000"; label="P1: Implement"; penwidth=2; peripheries=2; shape="ellipse"; tooltip="critical path\lstart: 0"; ];
+    }
+  }
+  D1 -> P1 [style="solid"; ];
+  D1 -> P2 [lhead="cluster_P4"; style="solid"; ];
+  D2 -> P2 [style="solid"; ];
+  P1 -> D2 [style="solid"; ];
+  P2 -> D3 [style="solid"; ];
+  P2 -> D3 [ltail="cluster_P4"; style="solid"; ];
+}
+`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			if err := WriteWithOverlay(sb, p, tc.Overlay); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tc.Expected {
+				t.Error(cmp.Diff(tc.Expected, sb.String()))
+			}
+		})
+	}
+}
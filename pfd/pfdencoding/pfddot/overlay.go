@@ -0,0 +1,203 @@
+package pfddot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/dot"
+	"github.com/Kuniwak/pfd-tools/pairs"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmreporter"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+)
+
+// Overlay is analysis results drawn over PFDs. Nil fields are not drawn.
+type Overlay struct {
+	// CriticalPath draws atomic processes without maximum elasticity with double borders.
+	CriticalPath fsm.CriticalPathInfo
+
+	// Problems colors the borders of nodes in the locations of the problems by the most severe one.
+	Problems []checkers.Problem
+
+	// StartTimes shades atomic processes by their planned start times. Later processes are darker.
+	StartTimes map[pfd.AtomicProcessID]execmodel.Time
+}
+
+// NewStartTimes returns the earliest start times of atomic processes in the timeline table.
+func NewStartTimes(tt fsmreporter.TimelineTable) map[pfd.AtomicProcessID]execmodel.Time {
+	res := make(map[pfd.AtomicProcessID]execmodel.Time, len(tt))
+	for _, row := range tt {
+		if t, ok := res[row.AtomicProcess]; ok && t <= row.StartTime {
+			continue
+		}
+		res[row.AtomicProcess] = row.StartTime
+	}
+	return res
+}
+
+// NodeIDsOfLocation returns the IDs of nodes in the location. Locations that do not point to nodes such as resources
+// have no IDs.
+func NodeIDsOfLocation(l checkers.Location) []pfd.NodeID {
+	switch l := l.(type) {
+	case pfdcommon.Location:
+		return l.RelatedIDs
+	case fsmcommon.Location:
+		res := make([]pfd.NodeID, 0, len(l.RelatedIDs))
+		for _, id := range l.RelatedIDs {
+			switch id.Type {
+			case fsmcommon.IDTypeAtomicProcess:
+				res = append(res, pfd.NodeID(id.AtomicProcessID))
+			case fsmcommon.IDTypeAtomicDeliverable:
+				res = append(res, pfd.NodeID(id.AtomicDeliverableID))
+			case fsmcommon.IDTypeCompositeProcess:
+				res = append(res, pfd.NodeID(id.CompositeProcessID))
+			case fsmcommon.IDTypeCompositeDeliverable:
+				res = append(res, pfd.NodeID(id.CompositeDeliverableID))
+			}
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// SeverityColor returns the color of borders of nodes that have problems of the severity.
+func SeverityColor(s checkers.Severity) string {
+	switch s {
+	case checkers.SeverityError:
+		return "red"
+	case checkers.SeverityStyleProblem:
+		return "blue"
+	case checkers.SeverityWarning:
+		return "orange"
+	default:
+		panic(fmt.Sprintf("pfddot.SeverityColor: unknown severity: %d", s))
+	}
+}
+
+// defaultFillColor is the fill color of Graphviz. Filled nodes with the color attribute need it explicitly because
+// Graphviz fills them with the color attribute otherwise.
+const defaultFillColor = "lightgrey"
+
+type nodeDecorator struct {
+	critical     map[pfd.NodeID]bool
+	severities   map[pfd.NodeID]checkers.Severity
+	problemIDs   map[pfd.NodeID][]string
+	startTimes   map[pfd.NodeID]execmodel.Time
+	minStartTime execmodel.Time
+	maxStartTime execmodel.Time
+}
+
+func newNodeDecorator(overlay *Overlay) *nodeDecorator {
+	d := &nodeDecorator{
+		critical:   make(map[pfd.NodeID]bool),
+		severities: make(map[pfd.NodeID]checkers.Severity),
+		problemIDs: make(map[pfd.NodeID][]string),
+		startTimes: make(map[pfd.NodeID]execmodel.Time),
+	}
+	if overlay == nil {
+		return d
+	}
+
+	for ap, item := range overlay.CriticalPath {
+		if item.MaximumElasticity == 0 {
+			d.critical[pfd.NodeID(ap)] = true
+		}
+	}
+
+	for _, problem := range overlay.Problems {
+		for _, location := range problem.Locations {
+			for _, id := range NodeIDsOfLocation(location) {
+				if s, ok := d.severities[id]; !ok || checkers.CompareSeverity(problem.Severity, s) < 0 {
+					d.severities[id] = problem.Severity
+				}
+				line := fmt.Sprintf("%s: %s", problem.Severity, problem.ProblemID)
+				if !slices.Contains(d.problemIDs[id], line) {
+					d.problemIDs[id] = append(d.problemIDs[id], line)
+				}
+			}
+		}
+	}
+
+	first := true
+	for ap, t := range overlay.StartTimes {
+		d.startTimes[pfd.NodeID(ap)] = t
+		if first || t < d.minStartTime {
+			d.minStartTime = t
+		}
+		if first || t > d.maxStartTime {
+			d.maxStartTime = t
+		}
+		first = false
+	}
+
+	return d
+}
+
+// Decorate returns the node with the attributes for the overlay.
+func (d *nodeDecorator) Decorate(node *pfd.Node) *dot.Node {
+	res := DotNode(node)
+	tooltip := make([]string, 0)
+
+	if d.critical[node.ID] {
+		res.Attributes.Add(dot.CompareAttribute, &pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+			First:  dot.AttributeNamePeripheries,
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeInt, Int: 2},
+		})
+		res.Attributes.Add(dot.CompareAttribute, &pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+			First:  dot.AttributeNamePenWidth,
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeFloat, Float: 2},
+		})
+		tooltip = append(tooltip, "critical path")
+	}
+
+	fillColor := ""
+	if t, ok := d.startTimes[node.ID]; ok {
+		fillColor = d.startTimeColor(t)
+		tooltip = append(tooltip, fmt.Sprintf("start: %s", formatTime(t)))
+	}
+
+	if s, ok := d.severities[node.ID]; ok {
+		res.Attributes.Add(dot.CompareAttribute, &pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+			First:  dot.AttributeNameColor,
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeString, String: SeverityColor(s)},
+		})
+		if fillColor == "" {
+			fillColor = defaultFillColor
+		}
+		tooltip = append(tooltip, d.problemIDs[node.ID]...)
+	}
+
+	if fillColor != "" {
+		res.Attributes.Add(dot.CompareAttribute, &pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+			First:  dot.AttributeNameFillColor,
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeString, String: fillColor},
+		})
+	}
+	if len(tooltip) > 0 {
+		res.Attributes.Add(dot.CompareAttribute, &pairs.Pair[dot.AttributeName, dot.AttributeValue]{
+			First:  dot.AttributeNameTooltip,
+			Second: dot.AttributeValue{Type: dot.AttributeValueTypeString, String: strings.Join(tooltip, "\n")},
+		})
+	}
+
+	return res
+}
+
+// startTimeColor returns a HSV color of blue. The saturation grows from the earliest start time to the latest one.
+func (d *nodeDecorator) startTimeColor(t execmodel.Time) string {
+	var ratio float64
+	if d.maxStartTime > d.minStartTime {
+		ratio = float64(t-d.minStartTime) / float64(d.maxStartTime-d.minStartTime)
+	}
+	return fmt.Sprintf("0.600 %.3f 1.000", 0.1+0.6*ratio)
+}
+
+func formatTime(t execmodel.Time) string {
+	return fmt.Sprintf("%g", float64(t))
+}
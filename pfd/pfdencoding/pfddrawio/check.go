@@ -0,0 +1,105 @@
+package pfddrawio
+
+import (
+	"slices"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+)
+
+// CheckDiagrams returns the structural defects of the diagrams. NormalizeDiagrams skips the cells with the defects, so
+// the PFD parsed from the diagrams silently lacks them. Cells on comment layers are not checked.
+func CheckDiagrams(diagrams []Diagram) []checkers.Problem {
+	const danglingEdgeProblemID = "drawio-dangling-edge"
+	const unparsableLabelProblemID = "drawio-unparsable-label"
+	const unknownShapeProblemID = "drawio-unknown-shape"
+	const overlappingDuplicateProblemID = "drawio-overlapping-duplicate"
+
+	problems := make([]checkers.Problem, 0)
+	for _, diagram := range diagrams {
+		layerMap := NewLayerMap(diagram.Cells)
+
+		cellMap := make(map[CellID]Cell, len(diagram.Cells))
+		for _, cell := range diagram.Cells {
+			cellMap[cell.ID] = cell
+		}
+
+		nodeIDMap := make(map[CellID]pfd.NodeID)
+		cellsByNodeID := make(map[pfd.NodeID][]Cell)
+		for _, cell := range diagram.Cells {
+			if !cell.IsVertex || layerMap.IsCommentLayer(cell.Parent) {
+				continue
+			}
+			loc := DrawIOLocation{DiagramID: diagram.ID, CellID: cell.ID}
+
+			if !cell.Style.IsRectangle() && !cell.Style.IsEllipse() {
+				if isDecoration(cell, cellMap) {
+					continue
+				}
+				problems = append(problems, checkers.NewProblem(unknownShapeProblemID, checkers.SeverityWarning, loc))
+				continue
+			}
+
+			id, _, err := ParseVertexValue(cell.Value)
+			if err != nil {
+				problems = append(problems, checkers.NewProblem(unparsableLabelProblemID, checkers.SeverityError, loc))
+				continue
+			}
+			nodeIDMap[cell.ID] = id
+			cellsByNodeID[id] = append(cellsByNodeID[id], cell)
+		}
+
+		for _, cell := range diagram.Cells {
+			if !cell.IsEdge || layerMap.IsCommentLayer(cell.Parent) {
+				continue
+			}
+			_, hasSource := nodeIDMap[cell.Source]
+			_, hasTarget := nodeIDMap[cell.Target]
+			if !hasSource || !hasTarget {
+				loc := DrawIOLocation{DiagramID: diagram.ID, CellID: cell.ID}
+				problems = append(problems, checkers.NewProblem(danglingEdgeProblemID, checkers.SeverityError, loc))
+			}
+		}
+
+		ids := make([]pfd.NodeID, 0, len(cellsByNodeID))
+		for id := range cellsByNodeID {
+			ids = append(ids, id)
+		}
+		slices.SortFunc(ids, pfd.NodeID.Compare)
+
+		// NOTE: Duplicated vertices apart from each other are common for layouts, but overlapping ones are usually
+		// copy-and-paste mistakes.
+		for _, id := range ids {
+			cells := cellsByNodeID[id]
+			for i, a := range cells {
+				for _, b := range cells[i+1:] {
+					if a.Parent != b.Parent || a.Geometry == nil || b.Geometry == nil || !a.Geometry.Overlaps(*b.Geometry) {
+						continue
+					}
+					problems = append(problems, checkers.NewProblem(
+						overlappingDuplicateProblemID,
+						checkers.SeverityWarning,
+						DrawIOLocation{DiagramID: diagram.ID, CellID: a.ID},
+						DrawIOLocation{DiagramID: diagram.ID, CellID: b.ID},
+					))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// isDecoration returns true if the vertex is not intended to be a node, such as texts, groups and edge labels.
+func isDecoration(cell Cell, cellMap map[CellID]Cell) bool {
+	if cell.Style.IsText() {
+		return true
+	}
+	if _, ok := cell.Style["group"]; ok {
+		return true
+	}
+	if _, ok := cell.Style["edgeLabel"]; ok {
+		return true
+	}
+	parent, ok := cellMap[cell.Parent]
+	return ok && parent.IsEdge
+}
@@ -0,0 +1,95 @@
+package pfddrawio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckDiagrams(t *testing.T) {
+	rect := StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}
+	ellipse := StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1"}
+	edge := StyleMap{"edgeStyle": "none", "html": "1"}
+
+	testCases := map[string]struct {
+		Cells    []Cell
+		Expected []checkers.Problem
+	}{
+		"ok": {
+			Cells: []Cell{
+				NewRoot("0"),
+				NewLayer("1", ""),
+				NewVertex("2", "1", "D1: Spec", rect).WithGeometry(Geometry{X: 0, Y: 0, Width: 120, Height: 80}),
+				NewVertex("3", "1", "P1: Implement", ellipse).WithGeometry(Geometry{X: 160, Y: 0, Width: 120, Height: 80}),
+				NewEdge("4", "1", "2", "3", edge),
+				NewVertex("5", "1", "D1: Spec", rect).WithGeometry(Geometry{X: 320, Y: 0, Width: 120, Height: 80}),
+				NewVertex("6", "1", "Title", StyleMap{"text": "", "html": "1"}),
+				NewVertex("7", "4", "label", StyleMap{"edgeLabel": "", "html": "1"}),
+				NewLayer("8", "Comment"),
+				NewVertex("9", "8", "", StyleMap{"rounded": "1"}),
+				NewEdge("10", "8", "", "9", edge),
+			},
+			Expected: []checkers.Problem{},
+		},
+		"dangling edges": {
+			Cells: []Cell{
+				NewRoot("0"),
+				NewLayer("1", ""),
+				NewVertex("2", "1", "D1: Spec", rect),
+				NewEdge("3", "1", "2", "", edge),
+				NewEdge("4", "1", "missing", "2", edge),
+			},
+			Expected: []checkers.Problem{
+				checkers.NewProblem("drawio-dangling-edge", checkers.SeverityError, DrawIOLocation{DiagramID: "d", CellID: "3"}),
+				checkers.NewProblem("drawio-dangling-edge", checkers.SeverityError, DrawIOLocation{DiagramID: "d", CellID: "4"}),
+			},
+		},
+		"unparsable label": {
+			Cells: []Cell{
+				NewRoot("0"),
+				NewLayer("1", ""),
+				NewVertex("2", "1", " : Spec", rect),
+			},
+			Expected: []checkers.Problem{
+				checkers.NewProblem("drawio-unparsable-label", checkers.SeverityError, DrawIOLocation{DiagramID: "d", CellID: "2"}),
+			},
+		},
+		"unknown shape": {
+			Cells: []Cell{
+				NewRoot("0"),
+				NewLayer("1", ""),
+				NewVertex("2", "1", "D1: Spec", StyleMap{"rounded": "1", "whiteSpace": "wrap", "html": "1"}),
+				NewVertex("3", "1", "P1: Implement", ellipse),
+				NewEdge("4", "1", "2", "3", edge),
+			},
+			Expected: []checkers.Problem{
+				checkers.NewProblem("drawio-unknown-shape", checkers.SeverityWarning, DrawIOLocation{DiagramID: "d", CellID: "2"}),
+				checkers.NewProblem("drawio-dangling-edge", checkers.SeverityError, DrawIOLocation{DiagramID: "d", CellID: "4"}),
+			},
+		},
+		"overlapping duplicates": {
+			Cells: []Cell{
+				NewRoot("0"),
+				NewLayer("1", ""),
+				NewVertex("2", "1", "D1: Spec", rect).WithGeometry(Geometry{X: 0, Y: 0, Width: 120, Height: 80}),
+				NewVertex("3", "1", "D1: Spec", rect).WithGeometry(Geometry{X: 10, Y: 10, Width: 120, Height: 80}),
+				NewVertex("4", "1", "D1: Spec", rect).WithGeometry(Geometry{X: 120, Y: 0, Width: 120, Height: 80}),
+			},
+			Expected: []checkers.Problem{
+				checkers.NewProblem("drawio-overlapping-duplicate", checkers.SeverityWarning, DrawIOLocation{DiagramID: "d", CellID: "2"}, DrawIOLocation{DiagramID: "d", CellID: "3"}),
+				checkers.NewProblem("drawio-overlapping-duplicate", checkers.SeverityWarning, DrawIOLocation{DiagramID: "d", CellID: "3"}, DrawIOLocation{DiagramID: "d", CellID: "4"}),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := CheckDiagrams([]Diagram{{ID: "d", Name: "P0", Cells: testCase.Cells}})
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package pfddrawio
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// DecompressDiagram decodes the content of diagram elements compressed by draw.io. The content is the base64-encoded
+// raw deflate of the URL-encoded mxGraphModel XML.
+func DecompressDiagram(s string) ([]byte, error) {
+	deflated, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("pfddrawio.DecompressDiagram: %w", err)
+	}
+
+	fr := flate.NewReader(bytes.NewReader(deflated))
+	defer fr.Close()
+	encoded, err := io.ReadAll(fr)
+	if err != nil {
+		return nil, fmt.Errorf("pfddrawio.DecompressDiagram: %w", err)
+	}
+
+	decoded, err := url.PathUnescape(string(encoded))
+	if err != nil {
+		// NOTE: Very old draw.io compressed diagrams without URL-encoding.
+		return encoded, nil
+	}
+	return []byte(decoded), nil
+}
@@ -1,9 +1,12 @@
 package pfddrawio
 
 import (
+	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/Kuniwak/pfd-tools/checkers"
 	"github.com/Kuniwak/pfd-tools/pfd"
 	"github.com/Kuniwak/pfd-tools/sets"
 )
@@ -29,6 +32,16 @@ func (a DrawIOLocation) Compare(b DrawIOLocation) int {
 	return strings.Compare(string(a.CellID), string(b.CellID))
 }
 
+var _ checkers.Location = DrawIOLocation{}
+
+// Write writes the location like DRAWIO[diagramID, cellID].
+func (a DrawIOLocation) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "DRAWIO[%s, %s]", a.DiagramID, a.CellID); err != nil {
+		return fmt.Errorf("pfddrawio.DrawIOLocation.Write: %w", err)
+	}
+	return nil
+}
+
 type SourceMap struct {
 	NodeIDMap map[pfd.NodeID]*sets.Set[DrawIOLocation]                `json:"nodeIDMap"`
 	EdgeIDMap map[pfd.NodeID]map[pfd.NodeID]*sets.Set[DrawIOLocation] `json:"edgeIDMap"`
@@ -47,6 +60,22 @@ type Cell struct {
 	IsVertex bool     `json:"isVertex,omitempty"`
 	IsLayer  bool     `json:"isLayer,omitempty"`
 	IsRoot   bool     `json:"isRoot,omitempty"`
+
+	// Geometry is the bounds of the vertex relative to the parent. It is nil if the cell is not a vertex or the vertex
+	// has no geometry.
+	Geometry *Geometry `json:"geometry,omitempty"`
+}
+
+type Geometry struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Overlaps returns true if the intersection of the bounds has a positive area.
+func (g Geometry) Overlaps(other Geometry) bool {
+	return g.X < other.X+other.Width && other.X < g.X+g.Width && g.Y < other.Y+other.Height && other.Y < g.Y+g.Height
 }
 
 func NewRoot(id CellID) Cell {
@@ -71,6 +100,12 @@ func NewVertex(id CellID, parent CellID, value string, style StyleMap) Cell {
 	}
 }
 
+// WithGeometry returns the cell with the geometry.
+func (c Cell) WithGeometry(g Geometry) Cell {
+	c.Geometry = &g
+	return c
+}
+
 func NewEdge(id CellID, parent CellID, source CellID, target CellID, style StyleMap) Cell {
 	return Cell{
 		ID:     id,
@@ -13,23 +13,23 @@ var exampleFile = []Diagram{
 			NewRoot("0"),
 			NewLayer("1", "PFD"),
 			NewEdge("4", "1", "2", "3", StyleMap{"edgeStyle": "none", "html": "1"}),
-			NewVertex("2", "1", "D4: Specification", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}),
+			NewVertex("2", "1", "D4: Specification", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 40, Y: 360, Width: 120, Height: 80}),
 			NewEdge("6", "1", "3", "5", StyleMap{"edgeStyle": "none", "html": "1"}),
-			NewVertex("3", "1", "P1: Implement", StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1", "strokeColor": "default", "strokeWidth": "2"}),
+			NewVertex("3", "1", "P1: Implement", StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1", "strokeColor": "default", "strokeWidth": "2"}).WithGeometry(Geometry{X: 200, Y: 360, Width: 120, Height: 80}),
 			NewEdge("9", "1", "5", "8", StyleMap{"edgeStyle": "none", "html": "1"}),
-			NewVertex("5", "1", "D1: Implementation", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}),
+			NewVertex("5", "1", "D1: Implementation", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 360, Y: 360, Width: 120, Height: 80}),
 			NewEdge("11", "1", "8", "10", StyleMap{"edgeStyle": "none", "html": "1"}),
-			NewVertex("8", "1", "P2: Review", StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1"}),
+			NewVertex("8", "1", "P2: Review", StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 520, Y: 360, Width: 120, Height: 80}),
 			NewEdge("12", "1", "10", "3", StyleMap{"edgeStyle": "orthogonalEdgeStyle", "html": "1", "entryX": "0.5", "entryY": "0", "entryDx": "0", "entryDy": "0", "dashed": "1"}),
-			NewVertex("10", "1", "D2: Review\ncomments", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}),
+			NewVertex("10", "1", "D2: Review\ncomments", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 680, Y: 360, Width: 120, Height: 80}),
 			NewEdge("17", "1", "13", "16", StyleMap{"edgeStyle": "none", "html": "1"}),
-			NewVertex("13", "1", "P3: Verify", StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1"}),
+			NewVertex("13", "1", "P3: Verify", StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 520, Y: 480, Width: 120, Height: 80}),
 			NewEdge("14", "1", "5", "13", StyleMap{"edgeStyle": "orthogonalEdgeStyle", "html": "1"}),
 			NewEdge("15", "1", "2", "13", StyleMap{"edgeStyle": "orthogonalEdgeStyle", "html": "1"}),
 			NewEdge("18", "1", "16", "3", StyleMap{"edgeStyle": "orthogonalEdgeStyle", "html": "1", "jumpStyle": "gap", "dashed": "1"}),
-			NewVertex("16", "1", "D3: Verification result", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}),
+			NewVertex("16", "1", "D3: Verification result", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 680, Y: 480, Width: 120, Height: 80}),
 			NewLayer("20", "Comment"),
-			NewVertex("21", "20", "Comments", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}),
+			NewVertex("21", "20", "Comments", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 40, Y: 190, Width: 120, Height: 80}),
 		},
 	},
 	{
@@ -39,10 +39,10 @@ var exampleFile = []Diagram{
 			NewRoot("0"),
 			NewLayer("1", ""),
 			NewEdge("h_2N5Sa6hUkllRCv-iia-3", "1", "hO8RJ9AdBYUCDEpOiIOb-1", "h_2N5Sa6hUkllRCv-iia-1", StyleMap{"edgeStyle": "none", "html": "1"}),
-			NewVertex("hO8RJ9AdBYUCDEpOiIOb-1", "1", "D4: Specification", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}),
-			NewVertex("hO8RJ9AdBYUCDEpOiIOb-2", "1", "D1: Implementation", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}),
+			NewVertex("hO8RJ9AdBYUCDEpOiIOb-1", "1", "D4: Specification", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 40, Y: 360, Width: 120, Height: 80}),
+			NewVertex("hO8RJ9AdBYUCDEpOiIOb-2", "1", "D1: Implementation", StyleMap{"rounded": "0", "whiteSpace": "wrap", "html": "1"}).WithGeometry(Geometry{X: 360, Y: 360, Width: 120, Height: 80}),
 			NewEdge("h_2N5Sa6hUkllRCv-iia-2", "1", "h_2N5Sa6hUkllRCv-iia-1", "hO8RJ9AdBYUCDEpOiIOb-2", StyleMap{"edgeStyle": "none", "html": "1"}),
-			NewVertex("h_2N5Sa6hUkllRCv-iia-1", "1", "P4: Implement", StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1", "strokeColor": "default", "strokeWidth": "1"}),
+			NewVertex("h_2N5Sa6hUkllRCv-iia-1", "1", "P4: Implement", StyleMap{"ellipse": "", "whiteSpace": "wrap", "html": "1", "strokeColor": "default", "strokeWidth": "1"}).WithGeometry(Geometry{X: 200, Y: 360, Width: 120, Height: 80}),
 		},
 	},
 }
@@ -0,0 +1,255 @@
+package pfddrawio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strings"
+)
+
+// PNGSignature is the first 8 bytes of PNG files.
+const PNGSignature = "\x89PNG\r\n\x1a\n"
+
+// mxFileKeyword is the keyword of PNG text chunks that draw.io embeds the mxfile into.
+const mxFileKeyword = "mxfile"
+
+// ReadMxFile returns the mxfile XML in draw.io files, editable PNG files or editable SVG files exported by draw.io.
+func ReadMxFile(r io.Reader) ([]byte, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfddrawio.ReadMxFile: %w", err)
+	}
+
+	if bytes.HasPrefix(bs, []byte(PNGSignature)) {
+		mxFile, err := ExtractMxFileFromPNG(bytes.NewReader(bs))
+		if err != nil {
+			return nil, fmt.Errorf("pfddrawio.ReadMxFile: %w", err)
+		}
+		return mxFile, nil
+	}
+
+	if IsSVG(bs) {
+		mxFile, err := ExtractMxFileFromSVG(bytes.NewReader(bs))
+		if err != nil {
+			return nil, fmt.Errorf("pfddrawio.ReadMxFile: %w", err)
+		}
+		return mxFile, nil
+	}
+
+	return bs, nil
+}
+
+// IsSVG returns true if the root element of the XML is svg.
+func IsSVG(bs []byte) bool {
+	dec := xml.NewDecoder(bytes.NewReader(bs))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local == "svg"
+		}
+	}
+}
+
+// ExtractMxFileFromPNG returns the mxfile XML in a tEXt, zTXt or iTXt chunk of PNG files exported by draw.io.
+func ExtractMxFileFromPNG(r io.Reader) ([]byte, error) {
+	signature := make([]byte, len(PNGSignature))
+	if _, err := io.ReadFull(r, signature); err != nil {
+		return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromPNG: %w", err)
+	}
+	if string(signature) != PNGSignature {
+		return nil, errors.New("pfddrawio.ExtractMxFileFromPNG: not a PNG file")
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("pfddrawio.ExtractMxFileFromPNG: missing mxfile chunk")
+			}
+			return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromPNG: %w", err)
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		chunkType := string(header[4:])
+		if length > math.MaxInt32 {
+			return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromPNG: %s chunk: too long: %d", chunkType, length)
+		}
+
+		// NOTE: The data is followed by 4 bytes of CRC.
+		size := int64(length) + 4
+		switch chunkType {
+		case "tEXt", "zTXt", "iTXt":
+		case "IEND":
+			return nil, errors.New("pfddrawio.ExtractMxFileFromPNG: missing mxfile chunk")
+		default:
+			// NOTE: Other chunks such as image data are skipped without reading them into memory.
+			if _, err := io.CopyN(io.Discard, r, size); err != nil {
+				return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromPNG: %s chunk: %w", chunkType, unexpectedEOF(err))
+			}
+			continue
+		}
+
+		// NOTE: Lengths are not trusted for allocating buffers because broken files can have any lengths.
+		data, err := io.ReadAll(io.LimitReader(r, size))
+		if err != nil {
+			return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromPNG: %s chunk: %w", chunkType, err)
+		}
+		if int64(len(data)) < size {
+			return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromPNG: %s chunk: %w", chunkType, io.ErrUnexpectedEOF)
+		}
+		data = data[:length]
+
+		var text []byte
+		var ok bool
+		switch chunkType {
+		case "tEXt":
+			text, ok = parseTEXt(data)
+		case "zTXt":
+			text, ok, err = parseZTXt(data)
+		case "iTXt":
+			text, ok, err = parseITXt(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromPNG: %s chunk: %w", chunkType, err)
+		}
+		if !ok {
+			continue
+		}
+
+		mxFile, err := decodeEmbeddedMxFile(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromPNG: %w", err)
+		}
+		return mxFile, nil
+	}
+}
+
+// unexpectedEOF returns io.ErrUnexpectedEOF for io.EOF, because chunks ending early are broken.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func parseTEXt(data []byte) ([]byte, bool) {
+	keyword, text, ok := bytes.Cut(data, []byte{0})
+	if !ok || string(keyword) != mxFileKeyword {
+		return nil, false
+	}
+	return text, true
+}
+
+func parseZTXt(data []byte) ([]byte, bool, error) {
+	keyword, rest, ok := bytes.Cut(data, []byte{0})
+	if !ok || string(keyword) != mxFileKeyword {
+		return nil, false, nil
+	}
+	if len(rest) < 1 {
+		return nil, false, errors.New("missing compression method")
+	}
+	text, err := inflateZlib(rest[1:])
+	if err != nil {
+		return nil, false, err
+	}
+	return text, true, nil
+}
+
+func parseITXt(data []byte) ([]byte, bool, error) {
+	keyword, rest, ok := bytes.Cut(data, []byte{0})
+	if !ok || string(keyword) != mxFileKeyword {
+		return nil, false, nil
+	}
+	if len(rest) < 2 {
+		return nil, false, errors.New("missing compression flag")
+	}
+	compressed := rest[0] != 0
+	// NOTE: Skip the compression method, the language tag and the translated keyword.
+	_, rest, ok = bytes.Cut(rest[2:], []byte{0})
+	if !ok {
+		return nil, false, errors.New("missing language tag")
+	}
+	_, text, ok := bytes.Cut(rest, []byte{0})
+	if !ok {
+		return nil, false, errors.New("missing translated keyword")
+	}
+	if !compressed {
+		return text, true, nil
+	}
+	text, err := inflateZlib(text)
+	if err != nil {
+		return nil, false, err
+	}
+	return text, true, nil
+}
+
+func inflateZlib(bs []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// ExtractMxFileFromSVG returns the mxfile XML in the content attribute of SVG files exported by draw.io.
+func ExtractMxFileFromSVG(r io.Reader) ([]byte, error) {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromSVG: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromSVG: unexpected root element: %q", start.Name.Local)
+		}
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "content" && attr.Name.Space == "" {
+				mxFile, err := decodeEmbeddedMxFile(attr.Value)
+				if err != nil {
+					return nil, fmt.Errorf("pfddrawio.ExtractMxFileFromSVG: %w", err)
+				}
+				return mxFile, nil
+			}
+		}
+		return nil, errors.New("pfddrawio.ExtractMxFileFromSVG: missing content attribute")
+	}
+}
+
+// decodeEmbeddedMxFile decodes the mxfile XML that is as is, URL-encoded or base64-encoded.
+func decodeEmbeddedMxFile(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "<") {
+		return []byte(s), nil
+	}
+
+	if strings.HasPrefix(s, "%3C") || strings.HasPrefix(s, "%3c") {
+		unescaped, err := url.PathUnescape(s)
+		if err != nil {
+			return nil, fmt.Errorf("pfddrawio.decodeEmbeddedMxFile: %w", err)
+		}
+		return []byte(unescaped), nil
+	}
+
+	bs, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("pfddrawio.decodeEmbeddedMxFile: %w", err)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(bs), []byte("<")) {
+		return nil, errors.New("pfddrawio.decodeEmbeddedMxFile: not a mxfile")
+	}
+	return bs, nil
+}
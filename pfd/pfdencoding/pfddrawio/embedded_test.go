@@ -0,0 +1,24 @@
+package pfddrawio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExtractMxFileFromPNGBroken(t *testing.T) {
+	testCases := map[string][]byte{
+		"too long chunk":       []byte(PNGSignature + "\xff\xff\xff\xf0tEXtmxfile\x00"),
+		"truncated text":       []byte(PNGSignature + "\x7f\xff\xff\xfftEXtmxfile\x00"),
+		"truncated image":      []byte(PNGSignature + "\x7f\xff\xff\xffIDAT\x00\x00"),
+		"missing mxfile":       []byte(PNGSignature + "\x00\x00\x00\x00IEND\xae\x42\x60\x82"),
+		"missing chunk header": []byte(PNGSignature + "\x00\x00"),
+	}
+
+	for name, input := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ExtractMxFileFromPNG(bytes.NewReader(input)); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
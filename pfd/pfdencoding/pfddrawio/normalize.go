@@ -30,6 +30,30 @@ func (l LayerMap) IsCommentLayer(id CellID) bool {
 	return strings.HasPrefix(strings.ToLower(value), "comment")
 }
 
+// Comment is a text on a comment layer. Comment layers are not a part of PFDs, so tools can use them for annotations.
+type Comment struct {
+	Location DrawIOLocation
+	Text     string
+}
+
+// Comments returns the texts of the vertices on the comment layers.
+func Comments(diagrams []Diagram) []Comment {
+	comments := make([]Comment, 0)
+	for _, diagram := range diagrams {
+		layerMap := NewLayerMap(diagram.Cells)
+		for _, cell := range diagram.Cells {
+			if !cell.IsVertex || !layerMap.IsCommentLayer(cell.Parent) {
+				continue
+			}
+			comments = append(comments, Comment{
+				Location: DrawIOLocation{DiagramID: diagram.ID, CellID: cell.ID},
+				Text:     cell.Value,
+			})
+		}
+	}
+	return comments
+}
+
 func NormalizeDiagrams(title string, diagrams []Diagram, logger *slog.Logger) (*pfd.PFD, *SourceMap, error) {
 	p := &pfd.PFD{
 		Title:                  title,
@@ -148,3 +148,30 @@ func TestNormalize(t *testing.T) {
 		})
 	}
 }
+
+func TestComments(t *testing.T) {
+	testCases := map[string]struct {
+		File     []Diagram
+		Expected []Comment
+	}{
+		"example": {
+			File: exampleFile,
+			Expected: []Comment{
+				{Location: DrawIOLocation{DiagramID: "PrNXtJMoFKdakpcB9KSm", CellID: "21"}, Text: "Comments"},
+			},
+		},
+		"empty": {
+			File:     []Diagram{},
+			Expected: []Comment{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := Comments(testCase.File)
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
@@ -1,14 +1,15 @@
 package pfddrawio
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"strconv"
 	"strings"
 
 	"github.com/Kuniwak/pfd-tools/pfd"
-	"github.com/Kuniwak/pfd-tools/sets"
 	"github.com/Kuniwak/pfd-tools/xmldom"
 	"golang.org/x/net/html"
 )
@@ -21,29 +22,7 @@ func Parse(title string, r io.Reader, cdt *pfd.CompositeDeliverableTable, logger
 
 	p.DeliverableComposition = cdt.NodeIDMap(logger)
 
-	newEdges := p.Edges.Clone()
-	for _, edge := range p.Edges.Iter() {
-		var ok bool
-		var ns *sets.Set[pfd.NodeID]
-
-		ns, ok = p.DeliverableComposition[edge.Source]
-		if !ok {
-			continue
-		}
-		for _, newSource := range ns.Iter() {
-			newEdges.Add((*pfd.Edge).Compare, &pfd.Edge{Source: newSource, Target: edge.Target, IsFeedback: edge.IsFeedback})
-		}
-
-		ns, ok = p.DeliverableComposition[edge.Target]
-		if !ok {
-			continue
-		}
-		for _, newTarget := range ns.Iter() {
-			newEdges.Add((*pfd.Edge).Compare, &pfd.Edge{Source: edge.Source, Target: newTarget, IsFeedback: edge.IsFeedback})
-		}
-	}
-
-	p.Edges = newEdges
+	p.Edges = pfd.ExpandCompositeDeliverableEdges(p.Edges, p.DeliverableComposition)
 	return p, srcMap, nil
 }
 
@@ -60,8 +39,14 @@ func ParseExceptCompositeDeliverables(title string, r io.Reader, logger *slog.Lo
 	return p, srcMap, nil
 }
 
+// ParseDiagrams parses draw.io files, editable PNG files or editable SVG files exported by draw.io.
 func ParseDiagrams(r io.Reader, logger *slog.Logger) ([]Diagram, error) {
-	nodes, err := xmldom.ParseXML(r)
+	mxFile, err := ReadMxFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfddrawio.ParseDiagrams: %w", err)
+	}
+
+	nodes, err := xmldom.ParseXML(bytes.NewReader(mxFile))
 	if err != nil {
 		return nil, fmt.Errorf("pfddrawio.ParseDiagrams: %w", err)
 	}
@@ -126,6 +111,13 @@ func ParseDiagram(node *xmldom.Node, sb *strings.Builder, logger *slog.Logger) (
 		return Diagram{}, false
 	}
 
+	if !hasMxGraphModel(node) {
+		if err := InflateDiagram(node); err != nil {
+			logger.Warn("pfddrawio.ParseDiagram: failed to decompress diagram", "error", err.Error(), "id", id)
+			return Diagram{}, false
+		}
+	}
+
 	for _, child := range node.Children {
 		if child.Start.Name.Local == "mxGraphModel" {
 			cells := ParseMxGraphModel(child, sb, logger)
@@ -140,6 +132,47 @@ func ParseDiagram(node *xmldom.Node, sb *strings.Builder, logger *slog.Logger) (
 	return Diagram{}, false
 }
 
+func hasMxGraphModel(node *xmldom.Node) bool {
+	for _, child := range node.Children {
+		if child.Kind == xmldom.ElementNode && child.Start.Name.Local == "mxGraphModel" {
+			return true
+		}
+	}
+	return false
+}
+
+// InflateDiagram replaces the compressed content of the diagram element with the decompressed mxGraphModel element.
+// It does nothing if the diagram element has no text content. Rewriting the tree keeps Renumber working on
+// compressed diagrams.
+func InflateDiagram(node *xmldom.Node) error {
+	if node.Start.Name.Local != "diagram" {
+		panic("pfddrawio.InflateDiagram: invalid diagram element")
+	}
+
+	sb := &strings.Builder{}
+	for _, child := range node.Children {
+		if child.Kind == xmldom.TextNode {
+			sb.Write(child.Data)
+		}
+	}
+	text := strings.TrimSpace(sb.String())
+	if text == "" {
+		return nil
+	}
+
+	decompressed, err := DecompressDiagram(text)
+	if err != nil {
+		return fmt.Errorf("pfddrawio.InflateDiagram: %w", err)
+	}
+
+	children, err := xmldom.ParseXML(bytes.NewReader(decompressed))
+	if err != nil {
+		return fmt.Errorf("pfddrawio.InflateDiagram: %w", err)
+	}
+	node.Children = children
+	return nil
+}
+
 func ParseMxGraphModel(node *xmldom.Node, sb *strings.Builder, logger *slog.Logger) []Cell {
 	if node.Start.Name.Local != "mxGraphModel" {
 		panic("pfddrawio.ParseMxGraphModel: invalid mxGraphModel element")
@@ -213,33 +246,27 @@ func ParseMxCell(node *xmldom.Node, sb *strings.Builder, logger *slog.Logger) (C
 	vertex, ok := node.GetAttr("vertex", "")
 	isVertex := ok && vertex != ""
 	if isVertex {
-		valueHTML, ok := node.GetAttr("value", "")
-		if !ok {
-			logger.Warn("pfddrawio.ParseMxCell: missing value attribute", "id", id)
-			return Cell{}, false
-		}
+		// NOTE: Keep vertices without values, so that the checkers can report them.
+		valueHTML, _ := node.GetAttr("value", "")
 		sb.Reset()
 		if err := ParseValueHTML(ValueHTML(valueHTML), sb); err != nil {
 			logger.Warn("pfddrawio.ParseMxCell: failed to parse value", "error", err.Error(), "id", id)
 			return Cell{}, false
 		}
 		value := sb.String()
-		return NewVertex(id, parent, value, styleMap), true
+		cell := NewVertex(id, parent, value, styleMap)
+		if geometry, ok := ParseGeometry(node); ok {
+			cell = cell.WithGeometry(geometry)
+		}
+		return cell, true
 	}
 
 	edge, ok := node.GetAttr("edge", "")
 	isEdge := ok && edge != ""
 	if isEdge {
-		sourceText, ok := node.GetAttr("source", "")
-		if !ok {
-			logger.Warn("pfddrawio.ParseMxCell: missing source attribute", "id", id)
-			return Cell{}, false
-		}
-		targetText, ok := node.GetAttr("target", "")
-		if !ok {
-			logger.Warn("pfddrawio.ParseMxCell: missing target attribute", "id", id)
-			return Cell{}, false
-		}
+		// NOTE: Keep dangling edges, so that the checkers can report them. NormalizeDiagrams skips them.
+		sourceText, _ := node.GetAttr("source", "")
+		targetText, _ := node.GetAttr("target", "")
 
 		source := CellID(sourceText)
 		target := CellID(targetText)
@@ -250,6 +277,38 @@ func ParseMxCell(node *xmldom.Node, sb *strings.Builder, logger *slog.Logger) (C
 	return Cell{}, false
 }
 
+// ParseGeometry returns the geometry of the cell element. Missing or malformed attributes are regarded as 0 as draw.io
+// does.
+func ParseGeometry(node *xmldom.Node) (Geometry, bool) {
+	for _, child := range node.Children {
+		if child.Kind != xmldom.ElementNode || child.Start.Name.Local != "mxGeometry" {
+			continue
+		}
+		if as, ok := child.GetAttr("as", ""); !ok || as != "geometry" {
+			continue
+		}
+		return Geometry{
+			X:      parseGeometryAttr(child, "x"),
+			Y:      parseGeometryAttr(child, "y"),
+			Width:  parseGeometryAttr(child, "width"),
+			Height: parseGeometryAttr(child, "height"),
+		}, true
+	}
+	return Geometry{}, false
+}
+
+func parseGeometryAttr(node *xmldom.Node, name string) float64 {
+	text, ok := node.GetAttr(name, "")
+	if !ok {
+		return 0
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
 func ParseStyle(style string) (StyleMap, error) {
 	styleMap := make(StyleMap)
 	for _, style := range strings.Split(style, ";") {
@@ -283,15 +342,21 @@ func ParseValueHTML(value ValueHTML, sb *strings.Builder) error {
 
 func ParseVertexValue(s string) (pfd.NodeID, string, error) {
 	parts := strings.SplitN(s, ":", 2)
+	var id pfd.NodeID
+	var desc string
 	switch len(parts) {
 	case 1:
 		// NOTE: If there are unnumbered IDs, use the description as the ID.
-		return pfd.NodeID(strings.TrimSpace(parts[0])), "", nil
+		id = pfd.NodeID(strings.TrimSpace(parts[0]))
 	case 2:
-		return pfd.NodeID(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]), nil
+		id, desc = pfd.NodeID(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
 	default:
 		return "", "", fmt.Errorf("pfddrawio.ParseVertexValue: invalid value: %s", s)
 	}
+	if id == "" {
+		return "", "", fmt.Errorf("pfddrawio.ParseVertexValue: missing ID: %q", s)
+	}
+	return id, desc, nil
 }
 
 func TextContent(node *html.Node, sb *strings.Builder) {
@@ -26,6 +26,18 @@ func TestParse(t *testing.T) {
 			FilePath: "testdata/example_with_xml_decl.drawio",
 			Expected: exampleFile,
 		},
+		"compressed": {
+			FilePath: "testdata/example_compressed.drawio",
+			Expected: exampleFile,
+		},
+		"editable PNG": {
+			FilePath: "testdata/example.png",
+			Expected: exampleFile,
+		},
+		"editable SVG": {
+			FilePath: "testdata/example.svg",
+			Expected: exampleFile,
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -1,6 +1,7 @@
 package pfddrawio
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -11,8 +12,15 @@ import (
 	"github.com/Kuniwak/pfd-tools/xmldom"
 )
 
+// Renumber renumbers the PFD elements. Editable PNG and SVG files are written back as draw.io files, and compressed
+// diagrams are written back decompressed.
 func Renumber(r io.Reader, logger *slog.Logger) ([]*xmldom.Node, error) {
-	nodes, err := xmldom.ParseXML(r)
+	mxFile, err := ReadMxFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfddrawio.Renumber: %w", err)
+	}
+
+	nodes, err := xmldom.ParseXML(bytes.NewReader(mxFile))
 	if err != nil {
 		return nil, fmt.Errorf("pfddrawio.Renumber: %w", err)
 	}
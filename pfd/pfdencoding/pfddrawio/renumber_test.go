@@ -19,6 +19,10 @@ func TestRenumber(t *testing.T) {
 			FilePath:     "testdata/sequential_without_id.drawio",
 			ExpectedPath: "testdata/sequential_with_id.drawio",
 		},
+		{
+			FilePath:     "testdata/sequential_without_id_compressed.drawio",
+			ExpectedPath: "testdata/sequential_with_id_inflated.drawio",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.FilePath, func(t *testing.T) {
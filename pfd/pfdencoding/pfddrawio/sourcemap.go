@@ -0,0 +1,111 @@
+package pfddrawio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdjson"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// SourceMapSchemaVersion is the version of the JSON schema written by WriteWithSourceMap.
+const SourceMapSchemaVersion = 1
+
+// DocumentWithSourceMap is the JSON representation of a PFD and the draw.io cells that the PFD is parsed from.
+// Editor tools use it to jump from nodes and edges to the cells.
+type DocumentWithSourceMap struct {
+	Version   int                `json:"version"`
+	PFD       *pfdjson.Document  `json:"pfd"`
+	SourceMap *SourceMapDocument `json:"source_map"`
+}
+
+// SourceMapDocument is the JSON representation of a SourceMap. Nodes, edges and locations are sorted.
+// Edges derived from composite deliverables have no cells, so they do not appear.
+type SourceMapDocument struct {
+	Nodes []NodeSource `json:"nodes"`
+	Edges []EdgeSource `json:"edges"`
+}
+
+type NodeSource struct {
+	ID        string           `json:"id"`
+	Locations []LocationSource `json:"locations"`
+}
+
+type EdgeSource struct {
+	Source    string           `json:"source"`
+	Target    string           `json:"target"`
+	Locations []LocationSource `json:"locations"`
+}
+
+type LocationSource struct {
+	DiagramID string `json:"diagram_id"`
+	CellID    string `json:"cell_id"`
+}
+
+// WriteWithSourceMap writes the PFD and the source map as JSON.
+func WriteWithSourceMap(w io.Writer, p *pfd.PFD, srcMap *SourceMap) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(NewDocumentWithSourceMap(p, srcMap)); err != nil {
+		return fmt.Errorf("pfddrawio.WriteWithSourceMap: %w", err)
+	}
+	return nil
+}
+
+func NewDocumentWithSourceMap(p *pfd.PFD, srcMap *SourceMap) *DocumentWithSourceMap {
+	return &DocumentWithSourceMap{
+		Version:   SourceMapSchemaVersion,
+		PFD:       pfdjson.NewDocument(p),
+		SourceMap: NewSourceMapDocument(srcMap),
+	}
+}
+
+func NewSourceMapDocument(srcMap *SourceMap) *SourceMapDocument {
+	nodeIDs := make([]pfd.NodeID, 0, len(srcMap.NodeIDMap))
+	for id := range srcMap.NodeIDMap {
+		nodeIDs = append(nodeIDs, id)
+	}
+	slices.SortFunc(nodeIDs, pfd.NodeID.Compare)
+
+	nodes := make([]NodeSource, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		nodes = append(nodes, NodeSource{ID: string(id), Locations: newLocationSources(srcMap.NodeIDMap[id])})
+	}
+
+	sources := make([]pfd.NodeID, 0, len(srcMap.EdgeIDMap))
+	for source := range srcMap.EdgeIDMap {
+		sources = append(sources, source)
+	}
+	slices.SortFunc(sources, pfd.NodeID.Compare)
+
+	edges := make([]EdgeSource, 0)
+	for _, source := range sources {
+		targetMap := srcMap.EdgeIDMap[source]
+		targets := make([]pfd.NodeID, 0, len(targetMap))
+		for target := range targetMap {
+			targets = append(targets, target)
+		}
+		slices.SortFunc(targets, pfd.NodeID.Compare)
+
+		for _, target := range targets {
+			edges = append(edges, EdgeSource{
+				Source:    string(source),
+				Target:    string(target),
+				Locations: newLocationSources(targetMap[target]),
+			})
+		}
+	}
+
+	return &SourceMapDocument{Nodes: nodes, Edges: edges}
+}
+
+func newLocationSources(locs *sets.Set[DrawIOLocation]) []LocationSource {
+	res := make([]LocationSource, 0, locs.Len())
+	for _, loc := range locs.Iter() {
+		res = append(res, LocationSource{DiagramID: string(loc.DiagramID), CellID: string(loc.CellID)})
+	}
+	return res
+}
@@ -0,0 +1,113 @@
+package pfddrawio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteWithSourceMap(t *testing.T) {
+	p := &pfd.PFD{
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Description: "Spec", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D1", Target: "P1"},
+		),
+		ProcessComposition:     map[pfd.NodeID]*sets.Set[pfd.NodeID]{},
+		DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{},
+	}
+	srcMap := &SourceMap{
+		NodeIDMap: map[pfd.NodeID]*sets.Set[DrawIOLocation]{
+			"P1": sets.New(DrawIOLocation.Compare, DrawIOLocation{DiagramID: "A", CellID: "3"}),
+			"D1": sets.New(
+				DrawIOLocation.Compare,
+				DrawIOLocation{DiagramID: "B", CellID: "2"},
+				DrawIOLocation{DiagramID: "A", CellID: "2"},
+			),
+		},
+		EdgeIDMap: map[pfd.NodeID]map[pfd.NodeID]*sets.Set[DrawIOLocation]{
+			"D1": {
+				"P1": sets.New(DrawIOLocation.Compare, DrawIOLocation{DiagramID: "A", CellID: "4"}),
+			},
+		},
+	}
+
+	expected := `{
+  "version": 1,
+  "pfd": {
+    "version": 1,
+    "nodes": [
+      {
+        "id": "D1",
+        "desc": "Spec",
+        "type": "DELIVERABLE"
+      },
+      {
+        "id": "P1",
+        "desc": "Implement",
+        "type": "PROCESS"
+      }
+    ],
+    "edges": [
+      {
+        "source": "D1",
+        "target": "P1"
+      }
+    ]
+  },
+  "source_map": {
+    "nodes": [
+      {
+        "id": "D1",
+        "locations": [
+          {
+            "diagram_id": "A",
+            "cell_id": "2"
+          },
+          {
+            "diagram_id": "B",
+            "cell_id": "2"
+          }
+        ]
+      },
+      {
+        "id": "P1",
+        "locations": [
+          {
+            "diagram_id": "A",
+            "cell_id": "3"
+          }
+        ]
+      }
+    ],
+    "edges": [
+      {
+        "source": "D1",
+        "target": "P1",
+        "locations": [
+          {
+            "diagram_id": "A",
+            "cell_id": "4"
+          }
+        ]
+      }
+    ]
+  }
+}
+`
+
+	sb := &strings.Builder{}
+	if err := WriteWithSourceMap(sb, p, srcMap); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != expected {
+		t.Error(cmp.Diff(expected, sb.String()))
+	}
+}
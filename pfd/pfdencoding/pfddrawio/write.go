@@ -0,0 +1,358 @@
+package pfddrawio
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+const (
+	layoutMargin       = 40
+	layoutVertexWidth  = 120
+	layoutVertexHeight = 80
+	layoutLayerGap     = 80
+	layoutRowGap       = 40
+
+	// CompositeStrokeWidth is the stroke width of composite processes and composite deliverables.
+	CompositeStrokeWidth = 2
+)
+
+const (
+	deliverableStyle = "rounded=0;whiteSpace=wrap;html=1;"
+	processStyle     = "ellipse;whiteSpace=wrap;html=1;"
+	edgeStyle        = "edgeStyle=orthogonalEdgeStyle;html=1;"
+	feedbackStyle    = "dashed=1;"
+)
+
+type mxFile struct {
+	XMLName  xml.Name    `xml:"mxfile"`
+	Diagrams []mxDiagram `xml:"diagram"`
+}
+
+type mxDiagram struct {
+	ID         string       `xml:"id,attr"`
+	Name       string       `xml:"name,attr"`
+	GraphModel mxGraphModel `xml:"mxGraphModel"`
+}
+
+type mxGraphModel struct {
+	Grid     string   `xml:"grid,attr"`
+	GridSize string   `xml:"gridSize,attr"`
+	Guides   string   `xml:"guides,attr"`
+	Tooltips string   `xml:"tooltips,attr"`
+	Connect  string   `xml:"connect,attr"`
+	Arrows   string   `xml:"arrows,attr"`
+	Fold     string   `xml:"fold,attr"`
+	Page     string   `xml:"page,attr"`
+	Math     string   `xml:"math,attr"`
+	Shadow   string   `xml:"shadow,attr"`
+	Cells    []mxCell `xml:"root>mxCell"`
+}
+
+type mxCell struct {
+	ID       string      `xml:"id,attr"`
+	Value    *string     `xml:"value,attr"`
+	Style    string      `xml:"style,attr,omitempty"`
+	Parent   string      `xml:"parent,attr,omitempty"`
+	Source   string      `xml:"source,attr,omitempty"`
+	Target   string      `xml:"target,attr,omitempty"`
+	Vertex   string      `xml:"vertex,attr,omitempty"`
+	Edge     string      `xml:"edge,attr,omitempty"`
+	Geometry *mxGeometry `xml:"mxGeometry"`
+}
+
+type mxGeometry struct {
+	X        string `xml:"x,attr,omitempty"`
+	Y        string `xml:"y,attr,omitempty"`
+	Width    string `xml:"width,attr,omitempty"`
+	Height   string `xml:"height,attr,omitempty"`
+	Relative string `xml:"relative,attr,omitempty"`
+	As       string `xml:"as,attr"`
+}
+
+// Write writes the PFD as a draw.io file.
+// The context diagram is written to the page named P0, and each composite process in ProcessComposition is written to
+// the page named after its ID. Deliverables are rectangles, processes are ellipses, composite elements have a thicker
+// stroke, and feedback edges are dashed. Vertices are placed by a deterministic layered layout.
+// Deliverable composition is not written because it is given by composite deliverable tables.
+func Write(w io.Writer, p *pfd.PFD) error {
+	file := mxFile{Diagrams: newPages(p)}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "    ")
+	if err := enc.Encode(file); err != nil {
+		return fmt.Errorf("pfddrawio.Write: %w", err)
+	}
+	if err := enc.Flush(); err != nil {
+		return fmt.Errorf("pfddrawio.Write: %w", err)
+	}
+	return nil
+}
+
+// newPages returns the pages of the draw.io file for the PFD. The first page is the context diagram.
+func newPages(p *pfd.PFD) []mxDiagram {
+	nodeMap := make(map[pfd.NodeID]*pfd.Node, p.Nodes.Len())
+	for _, n := range p.Nodes.Iter() {
+		nodeMap[n.ID] = n
+	}
+
+	pageOf := make(map[pfd.NodeID]pfd.NodeID)
+	for comp, children := range p.ProcessComposition {
+		for _, child := range children.Iter() {
+			pageOf[child] = comp
+		}
+	}
+
+	pageIDs := sets.New(pfd.NodeID.Compare, pfd.NodeIDContextDiagram)
+	for comp := range p.ProcessComposition {
+		pageIDs.Add(pfd.NodeID.Compare, comp)
+	}
+
+	vertices := make(map[pfd.NodeID]*sets.Set[pfd.NodeID], pageIDs.Len())
+	edges := make(map[pfd.NodeID]*sets.Set[*pfd.Edge], pageIDs.Len())
+	for _, pageID := range pageIDs.Iter() {
+		vertices[pageID] = sets.New(pfd.NodeID.Compare)
+		edges[pageID] = sets.New((*pfd.Edge).Compare)
+	}
+
+	pageOfProcess := func(id pfd.NodeID) pfd.NodeID {
+		if page, ok := pageOf[id]; ok {
+			return page
+		}
+		return pfd.NodeIDContextDiagram
+	}
+
+	for _, n := range p.Nodes.Iter() {
+		if n.Type.IsProcess() {
+			vertices[pageOfProcess(n.ID)].Add(pfd.NodeID.Compare, n.ID)
+		}
+	}
+
+	hasEdge := sets.New(pfd.NodeID.Compare)
+	for _, e := range p.Edges.Iter() {
+		if p.IsDerivedFromCompositeDeliverable(e) {
+			continue
+		}
+
+		var pageID pfd.NodeID
+		if src, ok := nodeMap[e.Source]; ok && src.Type.IsProcess() {
+			pageID = pageOfProcess(e.Source)
+		} else if dst, ok := nodeMap[e.Target]; ok && dst.Type.IsProcess() {
+			pageID = pageOfProcess(e.Target)
+		} else {
+			pageID = pfd.NodeIDContextDiagram
+		}
+
+		vertices[pageID].Add(pfd.NodeID.Compare, e.Source)
+		vertices[pageID].Add(pfd.NodeID.Compare, e.Target)
+		edges[pageID].Add((*pfd.Edge).Compare, e)
+		hasEdge.Add(pfd.NodeID.Compare, e.Source)
+		hasEdge.Add(pfd.NodeID.Compare, e.Target)
+	}
+
+	for _, n := range p.Nodes.Iter() {
+		if n.Type.IsDeliverable() && !hasEdge.Contains(pfd.NodeID.Compare, n.ID) {
+			vertices[pfd.NodeIDContextDiagram].Add(pfd.NodeID.Compare, n.ID)
+		}
+	}
+
+	pages := make([]mxDiagram, 0, pageIDs.Len())
+	for _, pageID := range pageIDs.Iter() {
+		pages = append(pages, newPage(pageID, vertices[pageID], edges[pageID], nodeMap))
+	}
+	return pages
+}
+
+func newPage(pageID pfd.NodeID, vertices *sets.Set[pfd.NodeID], edges *sets.Set[*pfd.Edge], nodeMap map[pfd.NodeID]*pfd.Node) mxDiagram {
+	const layerID = "1"
+
+	cells := make([]mxCell, 0, 2+vertices.Len()+edges.Len())
+	cells = append(cells, mxCell{ID: "0"}, mxCell{ID: layerID, Parent: "0"})
+
+	cellIDs := make(map[pfd.NodeID]string, vertices.Len())
+	nextID := 2
+	for _, id := range vertices.Iter() {
+		cellIDs[id] = strconv.Itoa(nextID)
+		nextID++
+	}
+
+	positions := Layout(vertices, edges)
+	for _, id := range vertices.Iter() {
+		value := vertexValue(id, nodeMap)
+		pos := positions[id]
+		cells = append(cells, mxCell{
+			ID:     cellIDs[id],
+			Value:  &value,
+			Style:  vertexStyle(id, nodeMap),
+			Parent: layerID,
+			Vertex: "1",
+			Geometry: &mxGeometry{
+				X:      strconv.Itoa(layoutMargin + pos.Layer*(layoutVertexWidth+layoutLayerGap)),
+				Y:      strconv.Itoa(layoutMargin + pos.Row*(layoutVertexHeight+layoutRowGap)),
+				Width:  strconv.Itoa(layoutVertexWidth),
+				Height: strconv.Itoa(layoutVertexHeight),
+				As:     "geometry",
+			},
+		})
+	}
+
+	empty := ""
+	for _, e := range edges.Iter() {
+		style := edgeStyle
+		if e.IsFeedback {
+			style += feedbackStyle
+		}
+		cells = append(cells, mxCell{
+			ID:       strconv.Itoa(nextID),
+			Value:    &empty,
+			Style:    style,
+			Parent:   layerID,
+			Source:   cellIDs[e.Source],
+			Target:   cellIDs[e.Target],
+			Edge:     "1",
+			Geometry: &mxGeometry{Relative: "1", As: "geometry"},
+		})
+		nextID++
+	}
+
+	return mxDiagram{
+		ID:   string(pageID),
+		Name: string(pageID),
+		GraphModel: mxGraphModel{
+			Grid:     "1",
+			GridSize: "10",
+			Guides:   "1",
+			Tooltips: "1",
+			Connect:  "1",
+			Arrows:   "1",
+			Fold:     "1",
+			Page:     "1",
+			Math:     "0",
+			Shadow:   "0",
+			Cells:    cells,
+		},
+	}
+}
+
+func vertexValue(id pfd.NodeID, nodeMap map[pfd.NodeID]*pfd.Node) string {
+	text := string(id)
+	if n, ok := nodeMap[id]; ok && n.Description != "" {
+		text += ": " + n.Description
+	}
+	// NOTE: Values are HTML because styles have html=1.
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+func vertexStyle(id pfd.NodeID, nodeMap map[pfd.NodeID]*pfd.Node) string {
+	n, ok := nodeMap[id]
+	if !ok {
+		return deliverableStyle
+	}
+
+	var style string
+	if n.Type.IsProcess() {
+		style = processStyle
+	} else {
+		style = deliverableStyle
+	}
+	if n.Type == pfd.NodeTypeCompositeProcess || n.Type == pfd.NodeTypeCompositeDeliverable {
+		style += "strokeWidth=" + strconv.Itoa(CompositeStrokeWidth) + ";"
+	}
+	return style
+}
+
+// Position is a position of a vertex in the layered layout.
+type Position struct {
+	Layer int
+	Row   int
+}
+
+// Layout places vertices into layers from left to right. Each vertex is placed in the layer next to the farthest
+// predecessor connected by non-feedback edges. Vertices in a layer are ordered by the average row of their
+// predecessors, and ties are broken by IDs. The result depends only on the given vertices and edges.
+func Layout(vertices *sets.Set[pfd.NodeID], edges *sets.Set[*pfd.Edge]) map[pfd.NodeID]Position {
+	preds := make(map[pfd.NodeID]*sets.Set[pfd.NodeID], vertices.Len())
+	for _, e := range edges.Iter() {
+		if e.IsFeedback || e.Source == e.Target {
+			continue
+		}
+		if s, ok := preds[e.Target]; ok {
+			s.Add(pfd.NodeID.Compare, e.Source)
+		} else {
+			preds[e.Target] = sets.New(pfd.NodeID.Compare, e.Source)
+		}
+	}
+
+	layers := make(map[pfd.NodeID]int, vertices.Len())
+	visiting := sets.New(pfd.NodeID.Compare)
+	var layerOf func(id pfd.NodeID) int
+	layerOf = func(id pfd.NodeID) int {
+		if l, ok := layers[id]; ok {
+			return l
+		}
+		// NOTE: Cycles without feedback edges are broken at the vertex visited again.
+		if visiting.Contains(pfd.NodeID.Compare, id) {
+			return -1
+		}
+		visiting.Add(pfd.NodeID.Compare, id)
+		l := 0
+		if s, ok := preds[id]; ok {
+			for _, pred := range s.Iter() {
+				l = max(l, layerOf(pred)+1)
+			}
+		}
+		visiting.Remove(pfd.NodeID.Compare, id)
+		layers[id] = l
+		return l
+	}
+
+	numOfLayers := 0
+	for _, id := range vertices.Iter() {
+		numOfLayers = max(numOfLayers, layerOf(id)+1)
+	}
+
+	byLayer := make([][]pfd.NodeID, numOfLayers)
+	for _, id := range vertices.Iter() {
+		byLayer[layers[id]] = append(byLayer[layers[id]], id)
+	}
+
+	res := make(map[pfd.NodeID]Position, vertices.Len())
+	for l, ids := range byLayer {
+		barycenters := make(map[pfd.NodeID]float64, len(ids))
+		for _, id := range ids {
+			sum, n := 0, 0
+			if s, ok := preds[id]; ok {
+				for _, pred := range s.Iter() {
+					if pos, ok := res[pred]; ok {
+						sum += pos.Row
+						n++
+					}
+				}
+			}
+			if n > 0 {
+				barycenters[id] = float64(sum) / float64(n)
+			}
+		}
+
+		slices.SortStableFunc(ids, func(a, b pfd.NodeID) int {
+			c := cmp.Compare(barycenters[a], barycenters[b])
+			if c != 0 {
+				return c
+			}
+			return a.Compare(b)
+		})
+
+		for row, id := range ids {
+			res[id] = Position{Layer: l, Row: row}
+		}
+	}
+	return res
+}
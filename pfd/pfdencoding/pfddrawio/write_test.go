@@ -0,0 +1,125 @@
+package pfddrawio
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	testCases := map[string]*pfd.PFD{
+		"composite": {
+			Nodes: sets.New(
+				(*pfd.Node).Compare,
+				&pfd.Node{ID: "D1", Description: "Spec", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D2", Description: "Code", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D3", Description: "Review <draft>", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D4", Description: "Code & Review", Type: pfd.NodeTypeCompositeDeliverable},
+				&pfd.Node{ID: "D5", Description: "Release", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+				&pfd.Node{ID: "P2", Description: "Review", Type: pfd.NodeTypeAtomicProcess},
+				&pfd.Node{ID: "P3", Description: "Develop", Type: pfd.NodeTypeCompositeProcess},
+				&pfd.Node{ID: "P4", Description: "Release", Type: pfd.NodeTypeAtomicProcess},
+			),
+			Edges: sets.New(
+				(*pfd.Edge).Compare,
+				&pfd.Edge{Source: "D1", Target: "P1"},
+				&pfd.Edge{Source: "D1", Target: "P3"},
+				&pfd.Edge{Source: "P1", Target: "D2"},
+				&pfd.Edge{Source: "D2", Target: "P2"},
+				&pfd.Edge{Source: "P2", Target: "D3"},
+				&pfd.Edge{Source: "P3", Target: "D4"},
+				&pfd.Edge{Source: "D3", Target: "P1", IsFeedback: true},
+				&pfd.Edge{Source: "D4", Target: "P4"},
+				&pfd.Edge{Source: "D2", Target: "P4"},
+				&pfd.Edge{Source: "D3", Target: "P4"},
+				&pfd.Edge{Source: "P4", Target: "D5"},
+			),
+			ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+				"P3": sets.New(pfd.NodeID.Compare, "P1", "P2"),
+			},
+			DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+				"D4": sets.New(pfd.NodeID.Compare, "D2", "D3"),
+			},
+		},
+	}
+	for name, p := range pfd.PresetsAll {
+		testCases[name] = p
+	}
+
+	for name, p := range testCases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := Write(buf, p); err != nil {
+				t.Fatal(err)
+			}
+			written := buf.String()
+
+			cdt := &pfd.CompositeDeliverableTable{}
+			for _, comp := range sets.New(pfd.NodeID.Compare, keys(p.DeliverableComposition)...).Iter() {
+				row := &pfd.CompositeDeliverableRow{ID: pfd.CompositeDeliverableID(comp)}
+				for _, d := range p.DeliverableComposition[comp].Iter() {
+					row.Deliverables = append(row.Deliverables, pfd.AtomicDeliverableID(d))
+				}
+				cdt.Rows = append(cdt.Rows, row)
+			}
+
+			logger := slog.New(slogtest.NewTestHandler(t))
+			actual, _, err := Parse(p.Title, bytes.NewReader(buf.Bytes()), cdt, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := pfd.NewPFD(p.Title, p.Nodes, p.Edges, p.ProcessComposition, p.DeliverableComposition)
+			if expected.ProcessComposition == nil {
+				expected.ProcessComposition = map[pfd.NodeID]*sets.Set[pfd.NodeID]{}
+			}
+			if expected.DeliverableComposition == nil {
+				expected.DeliverableComposition = map[pfd.NodeID]*sets.Set[pfd.NodeID]{}
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Error(cmp.Diff(expected, actual))
+			}
+
+			buf.Reset()
+			if err := Write(buf, p); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != written {
+				t.Error("output is not deterministic")
+			}
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	p := pfd.PresetSmallestLoop
+	vertices := sets.New(pfd.NodeID.Compare)
+	for _, n := range p.Nodes.Iter() {
+		vertices.Add(pfd.NodeID.Compare, n.ID)
+	}
+
+	actual := Layout(vertices, p.Edges)
+	expected := map[pfd.NodeID]Position{
+		"D1": {Layer: 0, Row: 0},
+		"P1": {Layer: 1, Row: 0},
+		"D2": {Layer: 2, Row: 0},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error(cmp.Diff(expected, actual))
+	}
+}
+
+func keys[K comparable, V any](m map[K]V) []K {
+	ks := make([]K, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
@@ -2,9 +2,14 @@ package pfdfmt
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
 )
 
 type Format string
@@ -12,16 +17,27 @@ type Format string
 const (
 	FormatDrawio  Format = "drawio"
 	FormatJSON    Format = "json"
+	FormatMermaid Format = "mermaid"
+	FormatText    Format = "text"
 	FormatUnknown Format = "unknown"
 )
 
 const (
-	drawioPrefixWithoutXMLDecl = "<mxfile "
-	drawioPrefixWithXMLDecl    = "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<mxfile "
-	jsonPrefix                 = "{"
+	drawioPrefixWithoutXMLDecl   = "<mxfile "
+	drawioPrefixWithXMLDecl      = "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<mxfile "
+	jsonPrefix                   = "{"
+	mermaidFlowchartPrefix       = "flowchart"
+	mermaidGraphPrefix           = "graph"
+	mermaidFrontMatterPrefix     = "---\n"
+	mermaidFrontMatterCRLFPrefix = "---\r\n"
+	mermaidCommentPrefix         = "%%"
+	markdownMermaidFence         = "```mermaid"
+	textKeyword                  = "pfd"
+	textCommentPrefix            = "//"
 )
 
 var allPrefixes = []string{
+	pfddrawio.PNGSignature,
 	drawioPrefixWithoutXMLDecl,
 	drawioPrefixWithXMLDecl,
 	jsonPrefix,
@@ -36,18 +52,62 @@ func Detect(r io.Reader) (Format, io.Reader, error) {
 	}
 	buf := make([]byte, maxPrefixLen)
 
-	_, err := io.ReadAtLeast(r, buf, maxPrefixLen)
-	if err != nil {
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
 		return FormatUnknown, r, fmt.Errorf("pfdfmt.Detect: %w", err)
 	}
+	// NOTE: Short inputs such as small JSON documents are shorter than the longest prefix.
+	buf = buf[:n]
 
 	r2 := io.MultiReader(bytes.NewReader(buf), r)
 	s := string(buf)
-	if strings.HasPrefix(s, drawioPrefixWithoutXMLDecl) || strings.HasPrefix(s, drawioPrefixWithXMLDecl) {
+	if strings.HasPrefix(s, drawioPrefixWithoutXMLDecl) || strings.HasPrefix(s, drawioPrefixWithXMLDecl) || strings.HasPrefix(s, pfddrawio.PNGSignature) {
 		return FormatDrawio, r2, nil
 	}
 	if strings.HasPrefix(s, jsonPrefix) {
 		return FormatJSON, r2, nil
 	}
-	return FormatUnknown, r2, nil
+	for _, prefix := range []string{mermaidFlowchartPrefix, mermaidGraphPrefix, mermaidFrontMatterPrefix, mermaidFrontMatterCRLFPrefix, mermaidCommentPrefix} {
+		if strings.HasPrefix(s, prefix) {
+			return FormatMermaid, r2, nil
+		}
+	}
+
+	if isText(s) {
+		return FormatText, r2, nil
+	}
+
+	// NOTE: Markdown files such as READMEs can contain Mermaid diagrams anywhere, so read all of them.
+	all, err := io.ReadAll(r2)
+	if err != nil {
+		return FormatUnknown, r2, fmt.Errorf("pfdfmt.Detect: %w", err)
+	}
+	r3 := bytes.NewReader(all)
+	// NOTE: Editable SVG files exported by draw.io have the mxfile in the content attribute of the root element.
+	if pfddrawio.IsSVG(all) {
+		return FormatDrawio, r3, nil
+	}
+	if bytes.Contains(all, []byte(markdownMermaidFence)) {
+		return FormatMermaid, r3, nil
+	}
+	// NOTE: Leading blank lines may be longer than the prefix read above.
+	if isText(string(all)) {
+		return FormatText, r3, nil
+	}
+	return FormatUnknown, r3, nil
+}
+
+// isText returns true if the text starts with the header or a comment of the text notation after whitespaces. The
+// header is the pfd keyword followed by any whitespace including CRLF line breaks.
+func isText(s string) bool {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	if strings.HasPrefix(s, textCommentPrefix) {
+		return true
+	}
+	rest, ok := strings.CutPrefix(s, textKeyword)
+	if !ok {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsSpace(next)
 }
@@ -0,0 +1,90 @@
+package pfdfmt
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := map[string]struct {
+		Input    string
+		Expected Format
+	}{
+		"drawio": {
+			Input:    "<mxfile host=\"app.diagrams.net\"></mxfile>",
+			Expected: FormatDrawio,
+		},
+		"json": {
+			Input:    "{}",
+			Expected: FormatJSON,
+		},
+		"mermaid": {
+			Input:    "flowchart LR\n  P1[P1: Implement]\n",
+			Expected: FormatMermaid,
+		},
+		"mermaid front matter": {
+			Input:    "---\ntitle: Example\n---\nflowchart LR\n",
+			Expected: FormatMermaid,
+		},
+		"mermaid front matter with CRLF": {
+			Input:    "---\r\ntitle: Example\r\n---\r\nflowchart LR\r\n",
+			Expected: FormatMermaid,
+		},
+		"text header": {
+			Input:    "pfd\nD1 -> P1 -> D2\n",
+			Expected: FormatText,
+		},
+		"text header with title": {
+			Input:    "pfd \"Example\"\nD1 -> P1 -> D2\n",
+			Expected: FormatText,
+		},
+		"text header with CRLF": {
+			Input:    "pfd\r\nD1 -> P1 -> D2\r\n",
+			Expected: FormatText,
+		},
+		"text header with title and CRLF": {
+			Input:    "pfd \"Example\"\r\nD1 -> P1 -> D2\r\n",
+			Expected: FormatText,
+		},
+		"text header with tab": {
+			Input:    "pfd\t\"Example\"\n",
+			Expected: FormatText,
+		},
+		"text header after blank lines": {
+			Input:    "\r\n\r\n  pfd\r\nD1 -> P1 -> D2\r\n",
+			Expected: FormatText,
+		},
+		"text header after long blank lines": {
+			Input:    strings.Repeat("\n", 100) + "pfd\nD1 -> P1 -> D2\n",
+			Expected: FormatText,
+		},
+		"text comment after blank lines": {
+			Input:    "\n// Example\npfd\n",
+			Expected: FormatText,
+		},
+		"not text header": {
+			Input:    "pfdx\n",
+			Expected: FormatUnknown,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			f, r, err := Detect(strings.NewReader(testCase.Input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f != testCase.Expected {
+				t.Errorf("want %q, got %q", testCase.Expected, f)
+			}
+			body, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != testCase.Input {
+				t.Errorf("want %q, got %q", testCase.Input, string(body))
+			}
+		})
+	}
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/Kuniwak/pfd-tools/pfd"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdjson"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdmermaid"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdtext"
 )
 
 type ParseOptions struct {
@@ -14,25 +17,69 @@ type ParseOptions struct {
 }
 
 func Parse(title string, r io.Reader, opts *ParseOptions, logger *slog.Logger) (*pfd.PFD, error) {
-	format, r2, err := Detect(r)
+	p, _, err := ParseWithSourceMap(title, r, opts, logger)
 	if err != nil {
 		return nil, err
 	}
+	return p, nil
+}
+
+// ParseWithSourceMap parses the PFD in any format. The source map is nil unless the format is draw.io.
+func ParseWithSourceMap(title string, r io.Reader, opts *ParseOptions, logger *slog.Logger) (*pfd.PFD, *pfddrawio.SourceMap, error) {
+	format, r2, err := Detect(r)
+	if err != nil {
+		return nil, nil, err
+	}
 	logger.Debug("detected format", "format", format)
 
 	switch format {
 	case FormatDrawio:
 		if opts == nil || opts.CompositeDeliverableTable == nil {
-			return nil, fmt.Errorf("pfdfmt.Parse: missing composite deliverable table")
+			return nil, nil, fmt.Errorf("pfdfmt.ParseWithSourceMap: missing composite deliverable table")
+		}
+
+		p, srcMap, err := pfddrawio.Parse(title, r2, opts.CompositeDeliverableTable, logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("pfdfmt.ParseWithSourceMap: %w", err)
+		}
+
+		return p, srcMap, nil
+	case FormatJSON:
+		p, err := pfdjson.Parse(r2)
+		if err != nil {
+			return nil, nil, fmt.Errorf("pfdfmt.ParseWithSourceMap: %w", err)
+		}
+		if title != "" {
+			p.Title = title
+		}
+
+		return p, nil, nil
+	case FormatMermaid:
+		var cdt *pfd.CompositeDeliverableTable
+		if opts != nil {
+			cdt = opts.CompositeDeliverableTable
 		}
 
-		p, _, err := pfddrawio.Parse(title, r2, opts.CompositeDeliverableTable, logger)
+		p, err := pfdmermaid.Parse(r2, cdt, logger)
 		if err != nil {
-			return nil, fmt.Errorf("pfdfmt.Parse: %w", err)
+			return nil, nil, fmt.Errorf("pfdfmt.ParseWithSourceMap: %w", err)
+		}
+		if title != "" {
+			p.Title = title
+		}
+
+		return p, nil, nil
+	case FormatText:
+		p, err := pfdtext.Parse(r2)
+		if err != nil {
+			return nil, nil, fmt.Errorf("pfdfmt.ParseWithSourceMap: %w", err)
+		}
+		if title != "" {
+			p.Title = title
 		}
 
-		return p, nil
+		return p, nil, nil
 	default:
-		return nil, fmt.Errorf("pfdfmt.Parse: unknown pfdfmt")
+		return nil, nil, fmt.Errorf("pfdfmt.ParseWithSourceMap: unknown pfdfmt")
 	}
 }
@@ -0,0 +1,128 @@
+package pfdgraphml
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/graphml"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+const (
+	KeyTitle                  graphml.KeyID = "title"
+	KeyLabel                  graphml.KeyID = "label"
+	KeyType                   graphml.KeyID = "type"
+	KeyDescription            graphml.KeyID = "description"
+	KeyCompositeProcess       graphml.KeyID = "composite_process"
+	KeyCompositeDeliverables  graphml.KeyID = "composite_deliverables"
+	KeyMembers                graphml.KeyID = "members"
+	KeyFeedback               graphml.KeyID = "feedback"
+	KeyDerivedFromComposition graphml.KeyID = "derived_from_composite_deliverable"
+)
+
+// Keys are the data keys of PFDs. Multiple IDs such as members are joined by commas.
+var Keys = []*graphml.Key{
+	{ID: KeyTitle, For: graphml.KeyForGraph, Name: "title", Type: graphml.KeyTypeString},
+	{ID: KeyLabel, For: graphml.KeyForNode, Name: "label", Type: graphml.KeyTypeString},
+	{ID: KeyType, For: graphml.KeyForNode, Name: "type", Type: graphml.KeyTypeString},
+	{ID: KeyDescription, For: graphml.KeyForNode, Name: "description", Type: graphml.KeyTypeString},
+	{ID: KeyCompositeProcess, For: graphml.KeyForNode, Name: "composite process", Type: graphml.KeyTypeString},
+	{ID: KeyCompositeDeliverables, For: graphml.KeyForNode, Name: "composite deliverables", Type: graphml.KeyTypeString},
+	{ID: KeyMembers, For: graphml.KeyForNode, Name: "members", Type: graphml.KeyTypeString},
+	{ID: KeyFeedback, For: graphml.KeyForEdge, Name: "feedback", Type: graphml.KeyTypeBoolean},
+	{ID: KeyDerivedFromComposition, For: graphml.KeyForEdge, Name: "derived from composite deliverable", Type: graphml.KeyTypeBoolean},
+}
+
+func Write(w io.Writer, p *pfd.PFD) error {
+	if err := GraphML(p).Write(w); err != nil {
+		return fmt.Errorf("pfdgraphml.Write: %w", err)
+	}
+	return nil
+}
+
+func GraphML(p *pfd.PFD) *graphml.Document {
+	parents := make(map[pfd.NodeID]*sets.Set[pfd.NodeID])
+	for parent, members := range p.ProcessComposition {
+		for _, member := range members.Iter() {
+			addParent(parents, member, parent)
+		}
+	}
+	composites := make(map[pfd.NodeID]*sets.Set[pfd.NodeID])
+	for composite, members := range p.DeliverableComposition {
+		for _, member := range members.Iter() {
+			addParent(composites, member, composite)
+		}
+	}
+
+	g := &graphml.Graph{
+		ID:       "G",
+		Directed: true,
+		Nodes:    make([]*graphml.Node, 0, p.Nodes.Len()),
+		Edges:    make([]*graphml.Edge, 0, p.Edges.Len()),
+	}
+
+	for _, node := range p.Nodes.Iter() {
+		data := []*graphml.Data{
+			{Key: KeyLabel, Value: label(node)},
+			{Key: KeyType, Value: string(node.Type)},
+			{Key: KeyDescription, Value: node.Description},
+		}
+		if s, ok := parents[node.ID]; ok {
+			data = append(data, &graphml.Data{Key: KeyCompositeProcess, Value: join(s)})
+		}
+		if s, ok := composites[node.ID]; ok {
+			data = append(data, &graphml.Data{Key: KeyCompositeDeliverables, Value: join(s)})
+		}
+		if s, ok := p.ProcessComposition[node.ID]; ok && node.Type.IsProcess() {
+			data = append(data, &graphml.Data{Key: KeyMembers, Value: join(s)})
+		}
+		if s, ok := p.DeliverableComposition[node.ID]; ok && node.Type.IsDeliverable() {
+			data = append(data, &graphml.Data{Key: KeyMembers, Value: join(s)})
+		}
+		g.Nodes = append(g.Nodes, &graphml.Node{ID: graphml.NodeID(node.ID), Data: data})
+	}
+
+	for i, edge := range p.Edges.Iter() {
+		g.Edges = append(g.Edges, &graphml.Edge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: graphml.NodeID(edge.Source),
+			Target: graphml.NodeID(edge.Target),
+			Data: []*graphml.Data{
+				{Key: KeyFeedback, Value: strconv.FormatBool(edge.IsFeedback)},
+				{Key: KeyDerivedFromComposition, Value: strconv.FormatBool(p.IsDerivedFromCompositeDeliverable(edge))},
+			},
+		})
+	}
+
+	if p.Title != "" {
+		g.Data = []*graphml.Data{{Key: KeyTitle, Value: p.Title}}
+	}
+
+	return &graphml.Document{Keys: Keys, Graph: g}
+}
+
+func label(node *pfd.Node) string {
+	if node.Description == "" {
+		return string(node.ID)
+	}
+	return fmt.Sprintf("%s: %s", node.ID, node.Description)
+}
+
+func addParent(m map[pfd.NodeID]*sets.Set[pfd.NodeID], child, parent pfd.NodeID) {
+	if s, ok := m[child]; ok {
+		s.Add(pfd.NodeID.Compare, parent)
+		return
+	}
+	m[child] = sets.New(pfd.NodeID.Compare, parent)
+}
+
+func join(s *sets.Set[pfd.NodeID]) string {
+	ss := make([]string, 0, s.Len())
+	for _, id := range s.Iter() {
+		ss = append(ss, string(id))
+	}
+	return strings.Join(ss, ",")
+}
@@ -0,0 +1,103 @@
+package pfdgraphml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	p := &pfd.PFD{
+		Title: "Composite",
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Description: "Spec & Design", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D2", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D3", Description: "All", Type: pfd.NodeTypeCompositeDeliverable},
+			&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "P2", Description: "Develop", Type: pfd.NodeTypeCompositeProcess},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D3", Target: "P1"},
+			&pfd.Edge{Source: "D1", Target: "P1"},
+			&pfd.Edge{Source: "D2", Target: "P1", IsFeedback: true},
+		),
+		ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+			"P2": sets.New(pfd.NodeID.Compare, "P1"),
+		},
+		DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+			"D3": sets.New(pfd.NodeID.Compare, "D1", "D2"),
+		},
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="title" for="graph" attr.name="title" attr.type="string"></key>
+  <key id="label" for="node" attr.name="label" attr.type="string"></key>
+  <key id="type" for="node" attr.name="type" attr.type="string"></key>
+  <key id="description" for="node" attr.name="description" attr.type="string"></key>
+  <key id="composite_process" for="node" attr.name="composite process" attr.type="string"></key>
+  <key id="composite_deliverables" for="node" attr.name="composite deliverables" attr.type="string"></key>
+  <key id="members" for="node" attr.name="members" attr.type="string"></key>
+  <key id="feedback" for="edge" attr.name="feedback" attr.type="boolean"></key>
+  <key id="derived_from_composite_deliverable" for="edge" attr.name="derived from composite deliverable" attr.type="boolean"></key>
+  <graph id="G" edgedefault="directed">
+    <data key="title">Composite</data>
+    <node id="D1">
+      <data key="label">D1: Spec &amp; Design</data>
+      <data key="type">DELIVERABLE</data>
+      <data key="description">Spec &amp; Design</data>
+      <data key="composite_deliverables">D3</data>
+    </node>
+    <node id="D2">
+      <data key="label">D2</data>
+      <data key="type">DELIVERABLE</data>
+      <data key="description"></data>
+      <data key="composite_deliverables">D3</data>
+    </node>
+    <node id="D3">
+      <data key="label">D3: All</data>
+      <data key="type">COMPOSITE_DELIVERABLE</data>
+      <data key="description">All</data>
+      <data key="members">D1,D2</data>
+    </node>
+    <node id="P1">
+      <data key="label">P1: Implement</data>
+      <data key="type">PROCESS</data>
+      <data key="description">Implement</data>
+      <data key="composite_process">P2</data>
+    </node>
+    <node id="P2">
+      <data key="label">P2: Develop</data>
+      <data key="type">COMPOSITE_PROCESS</data>
+      <data key="description">Develop</data>
+      <data key="members">P1</data>
+    </node>
+    <edge id="e0" source="D1" target="P1">
+      <data key="feedback">false</data>
+      <data key="derived_from_composite_deliverable">true</data>
+    </edge>
+    <edge id="e1" source="D2" target="P1">
+      <data key="feedback">true</data>
+      <data key="derived_from_composite_deliverable">false</data>
+    </edge>
+    <edge id="e2" source="D3" target="P1">
+      <data key="feedback">false</data>
+      <data key="derived_from_composite_deliverable">false</data>
+    </edge>
+  </graph>
+</graphml>
+`
+
+	sb := &strings.Builder{}
+	if err := Write(sb, p); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != expected {
+		t.Error(cmp.Diff(expected, sb.String()))
+	}
+}
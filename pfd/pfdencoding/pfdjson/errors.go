@@ -0,0 +1,49 @@
+package pfdjson
+
+import (
+	"errors"
+	"strings"
+)
+
+// ValidationError is an error found in a JSON document. Path is a JSONPath such as `$.nodes[2].type`.
+type ValidationError struct {
+	Path    string
+	Wrapped error
+}
+
+func NewValidationErrorByMessage(path string, message string) ValidationError {
+	return ValidationError{Path: path, Wrapped: errors.New(message)}
+}
+
+func (e ValidationError) Write(sb *strings.Builder) {
+	sb.WriteString(e.Path)
+	sb.WriteString(": ")
+	sb.WriteString(e.Wrapped.Error())
+}
+
+func (e ValidationError) Error() string {
+	sb := &strings.Builder{}
+	e.Write(sb)
+	return sb.String()
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Wrapped
+}
+
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 0 {
+		panic("pfdjson.ValidationErrors: empty")
+	}
+
+	sb := &strings.Builder{}
+	for i, err := range []ValidationError(e) {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		err.Write(sb)
+	}
+	return sb.String()
+}
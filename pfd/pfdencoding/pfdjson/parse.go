@@ -0,0 +1,175 @@
+package pfdjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// Parse parses a PFD encoded by Write. Returned errors point at JSON paths when the document is malformed.
+func Parse(r io.Reader) (*pfd.PFD, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("pfdjson.Parse: %w", ValidationErrors{
+				NewValidationErrorByMessage(newPathByField(typeErr.Field), fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)),
+			})
+		}
+		return nil, fmt.Errorf("pfdjson.Parse: %w", err)
+	}
+
+	p, err := NewPFDByDocument(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("pfdjson.Parse: %w", err)
+	}
+	return p, nil
+}
+
+// newPathByField converts a field path of encoding/json such as "nodes.0.id" to a JSONPath such as "$.nodes[0].id".
+func newPathByField(field string) string {
+	sb := &strings.Builder{}
+	sb.WriteString("$")
+	if field == "" {
+		return sb.String()
+	}
+	for _, seg := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(seg); err == nil {
+			sb.WriteString("[")
+			sb.WriteString(seg)
+			sb.WriteString("]")
+			continue
+		}
+		sb.WriteString(".")
+		sb.WriteString(seg)
+	}
+	return sb.String()
+}
+
+// NewPFDByDocument validates the document and converts it to a PFD.
+// The returned error is ValidationErrors if the document is invalid.
+func NewPFDByDocument(doc *Document) (*pfd.PFD, error) {
+	if doc.Version == 0 {
+		return nil, ValidationErrors{NewValidationErrorByMessage("$.version", "missing schema version")}
+	}
+	if doc.Version != SchemaVersion {
+		return nil, ValidationErrors{NewValidationErrorByMessage("$.version", fmt.Sprintf("unsupported schema version: %d (supported: %d)", doc.Version, SchemaVersion))}
+	}
+
+	errs := make(ValidationErrors, 0)
+
+	nodeMap := make(map[pfd.NodeID]*pfd.Node, len(doc.Nodes))
+	nodes := sets.NewWithCapacity[*pfd.Node](len(doc.Nodes))
+	for i, n := range doc.Nodes {
+		path := fmt.Sprintf("$.nodes[%d]", i)
+		if n.ID == "" {
+			errs = append(errs, NewValidationErrorByMessage(path+".id", "empty node ID"))
+			continue
+		}
+		nodeType := pfd.NodeType(n.Type)
+		if !nodeType.IsProcess() && !nodeType.IsDeliverable() {
+			errs = append(errs, NewValidationErrorByMessage(path+".type", fmt.Sprintf("unknown node type: %q", n.Type)))
+			continue
+		}
+		id := pfd.NodeID(n.ID)
+		if _, ok := nodeMap[id]; ok {
+			errs = append(errs, NewValidationErrorByMessage(path+".id", fmt.Sprintf("duplicated node ID: %q", n.ID)))
+			continue
+		}
+		node := &pfd.Node{ID: id, Description: n.Description, Type: nodeType}
+		nodeMap[id] = node
+		nodes.Add((*pfd.Node).Compare, node)
+	}
+
+	edges := sets.NewWithCapacity[*pfd.Edge](len(doc.Edges))
+	for i, e := range doc.Edges {
+		path := fmt.Sprintf("$.edges[%d]", i)
+		ok := true
+		if _, found := nodeMap[pfd.NodeID(e.Source)]; !found {
+			errs = append(errs, NewValidationErrorByMessage(path+".source", fmt.Sprintf("no such node: %q", e.Source)))
+			ok = false
+		}
+		if _, found := nodeMap[pfd.NodeID(e.Target)]; !found {
+			errs = append(errs, NewValidationErrorByMessage(path+".target", fmt.Sprintf("no such node: %q", e.Target)))
+			ok = false
+		}
+		if !ok {
+			continue
+		}
+		edges.Add((*pfd.Edge).Compare, &pfd.Edge{Source: pfd.NodeID(e.Source), Target: pfd.NodeID(e.Target), IsFeedback: e.IsFeedback})
+	}
+
+	processComposition, processErrs := newComposition(
+		"$.process_composition",
+		doc.ProcessComposition,
+		nodeMap,
+		pfd.NodeTypeCompositeProcess,
+		pfd.NodeType.IsProcess,
+	)
+	errs = append(errs, processErrs...)
+
+	deliverableComposition, deliverableErrs := newComposition(
+		"$.deliverable_composition",
+		doc.DeliverableComposition,
+		nodeMap,
+		pfd.NodeTypeCompositeDeliverable,
+		pfd.NodeType.IsDeliverable,
+	)
+	errs = append(errs, deliverableErrs...)
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return pfd.NewPFD(doc.Title, nodes, edges, processComposition, deliverableComposition), nil
+}
+
+func newComposition(
+	path string,
+	m map[string][]string,
+	nodeMap map[pfd.NodeID]*pfd.Node,
+	parentType pfd.NodeType,
+	isChildType func(pfd.NodeType) bool,
+) (map[pfd.NodeID]*sets.Set[pfd.NodeID], ValidationErrors) {
+	errs := make(ValidationErrors, 0)
+	res := make(map[pfd.NodeID]*sets.Set[pfd.NodeID], len(m))
+
+	for _, parent := range slices.Sorted(maps.Keys(m)) {
+		parentPath := path + "[" + strconv.Quote(parent) + "]"
+		parentNode, ok := nodeMap[pfd.NodeID(parent)]
+		if !ok {
+			errs = append(errs, NewValidationErrorByMessage(parentPath, fmt.Sprintf("no such node: %q", parent)))
+			continue
+		}
+		if parentNode.Type != parentType {
+			errs = append(errs, NewValidationErrorByMessage(parentPath, fmt.Sprintf("node type must be %s: %s", parentType, parentNode.Type)))
+			continue
+		}
+
+		children := sets.NewWithCapacity[pfd.NodeID](len(m[parent]))
+		for i, child := range m[parent] {
+			childPath := fmt.Sprintf("%s[%d]", parentPath, i)
+			childNode, ok := nodeMap[pfd.NodeID(child)]
+			if !ok {
+				errs = append(errs, NewValidationErrorByMessage(childPath, fmt.Sprintf("no such node: %q", child)))
+				continue
+			}
+			if !isChildType(childNode.Type) {
+				errs = append(errs, NewValidationErrorByMessage(childPath, fmt.Sprintf("unexpected node type: %s", childNode.Type)))
+				continue
+			}
+			children.Add(pfd.NodeID.Compare, childNode.ID)
+		}
+		res[parentNode.ID] = children
+	}
+
+	return res, errs
+}
@@ -0,0 +1,139 @@
+package pfdjson
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRoundTrip(t *testing.T) {
+	testCases := map[string]*pfd.PFD{
+		"composite": {
+			Title: "Composite",
+			Nodes: sets.New(
+				(*pfd.Node).Compare,
+				&pfd.Node{ID: "D1", Description: "Spec", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D2", Description: "Code", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D3", Description: "Review", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D4", Description: "All", Type: pfd.NodeTypeCompositeDeliverable},
+				&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+				&pfd.Node{ID: "P2", Description: "Review", Type: pfd.NodeTypeAtomicProcess},
+				&pfd.Node{ID: "P3", Description: "Develop", Type: pfd.NodeTypeCompositeProcess},
+			),
+			Edges: sets.New(
+				(*pfd.Edge).Compare,
+				&pfd.Edge{Source: "D1", Target: "P1"},
+				&pfd.Edge{Source: "P1", Target: "D2"},
+				&pfd.Edge{Source: "D2", Target: "P2"},
+				&pfd.Edge{Source: "P2", Target: "D3"},
+				&pfd.Edge{Source: "D3", Target: "P1", IsFeedback: true},
+			),
+			ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+				"P3": sets.New(pfd.NodeID.Compare, "P1", "P2"),
+			},
+			DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+				"D4": sets.New(pfd.NodeID.Compare, "D2", "D3"),
+			},
+		},
+	}
+	for name, p := range pfd.PresetsAll {
+		testCases[name] = p
+	}
+
+	for name, p := range testCases {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := Write(buf, p); err != nil {
+				t.Fatal(err)
+			}
+			written := buf.String()
+
+			actual, err := Parse(strings.NewReader(written))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := pfd.NewPFD(p.Title, p.Nodes, p.Edges, p.ProcessComposition, p.DeliverableComposition)
+			if expected.ProcessComposition == nil {
+				expected.ProcessComposition = map[pfd.NodeID]*sets.Set[pfd.NodeID]{}
+			}
+			if expected.DeliverableComposition == nil {
+				expected.DeliverableComposition = map[pfd.NodeID]*sets.Set[pfd.NodeID]{}
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Error(cmp.Diff(expected, actual))
+			}
+
+			buf.Reset()
+			if err := Write(buf, actual); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != written {
+				t.Error(cmp.Diff(written, buf.String()))
+			}
+		})
+	}
+}
+
+func TestParseValidationErrors(t *testing.T) {
+	testCases := map[string]struct {
+		Input         string
+		ExpectedPaths []string
+	}{
+		"missing version": {
+			Input:         `{"nodes": [], "edges": []}`,
+			ExpectedPaths: []string{"$.version"},
+		},
+		"unsupported version": {
+			Input:         `{"version": 2, "nodes": [], "edges": []}`,
+			ExpectedPaths: []string{"$.version"},
+		},
+		"unknown node type": {
+			Input:         `{"version": 1, "nodes": [{"id": "D1", "type": "DELIVERABLE"}, {"id": "P1", "type": "UNKNOWN"}], "edges": []}`,
+			ExpectedPaths: []string{"$.nodes[1].type"},
+		},
+		"duplicated node ID": {
+			Input:         `{"version": 1, "nodes": [{"id": "D1", "type": "DELIVERABLE"}, {"id": "D1", "type": "DELIVERABLE"}], "edges": []}`,
+			ExpectedPaths: []string{"$.nodes[1].id"},
+		},
+		"dangling edge": {
+			Input:         `{"version": 1, "nodes": [{"id": "D1", "type": "DELIVERABLE"}], "edges": [{"source": "D1", "target": "P1"}]}`,
+			ExpectedPaths: []string{"$.edges[0].target"},
+		},
+		"bad composition": {
+			Input:         `{"version": 1, "nodes": [{"id": "P0", "type": "COMPOSITE_PROCESS"}, {"id": "D1", "type": "DELIVERABLE"}], "edges": [], "process_composition": {"P0": ["D1", "P1"]}}`,
+			ExpectedPaths: []string{`$.process_composition["P0"][0]`, `$.process_composition["P0"][1]`},
+		},
+		"wrong JSON type": {
+			Input:         `{"version": 1, "nodes": [{"id": 1, "type": "DELIVERABLE"}], "edges": []}`,
+			ExpectedPaths: []string{"$.nodes[0].id"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(testCase.Input))
+			if err == nil {
+				t.Fatal("want error, got nil")
+			}
+
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("want ValidationErrors, got %T: %v", err, err)
+			}
+			actual := make([]string, 0, len(errs))
+			for _, e := range errs {
+				actual = append(actual, e.Path)
+			}
+			if !reflect.DeepEqual(actual, testCase.ExpectedPaths) {
+				t.Error(cmp.Diff(testCase.ExpectedPaths, actual))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package pfdjson
+
+import (
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// SchemaVersion is the version of the JSON schema written by Write.
+// Parse rejects documents with other versions.
+const SchemaVersion = 1
+
+// Document is the JSON representation of a PFD.
+type Document struct {
+	Version                int                 `json:"version"`
+	Title                  string              `json:"title,omitempty"`
+	Nodes                  []Node              `json:"nodes"`
+	Edges                  []Edge              `json:"edges"`
+	ProcessComposition     map[string][]string `json:"process_composition,omitempty"`
+	DeliverableComposition map[string][]string `json:"deliverable_composition,omitempty"`
+}
+
+type Node struct {
+	ID          string `json:"id"`
+	Description string `json:"desc,omitempty"`
+	Type        string `json:"type"`
+}
+
+type Edge struct {
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	IsFeedback bool   `json:"feedback,omitempty"`
+}
+
+// NewDocument returns the JSON representation of the given PFD.
+func NewDocument(p *pfd.PFD) *Document {
+	nodes := make([]Node, 0, p.Nodes.Len())
+	for _, n := range p.Nodes.Iter() {
+		nodes = append(nodes, Node{ID: string(n.ID), Description: n.Description, Type: string(n.Type)})
+	}
+
+	edges := make([]Edge, 0, p.Edges.Len())
+	for _, e := range p.Edges.Iter() {
+		edges = append(edges, Edge{Source: string(e.Source), Target: string(e.Target), IsFeedback: e.IsFeedback})
+	}
+
+	return &Document{
+		Version:                SchemaVersion,
+		Title:                  p.Title,
+		Nodes:                  nodes,
+		Edges:                  edges,
+		ProcessComposition:     newCompositionDocument(p.ProcessComposition),
+		DeliverableComposition: newCompositionDocument(p.DeliverableComposition),
+	}
+}
+
+func newCompositionDocument(m map[pfd.NodeID]*sets.Set[pfd.NodeID]) map[string][]string {
+	if len(m) == 0 {
+		return nil
+	}
+	res := make(map[string][]string, len(m))
+	for parent, children := range m {
+		ids := make([]string, 0, children.Len())
+		for _, child := range children.Iter() {
+			ids = append(ids, string(child))
+		}
+		res[string(parent)] = ids
+	}
+	return res
+}
@@ -0,0 +1,19 @@
+package pfdjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+)
+
+// Write writes the PFD as JSON. The output is stable: nodes and edges are sorted and Parse restores the same PFD.
+func Write(w io.Writer, p *pfd.PFD) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(NewDocument(p)); err != nil {
+		return fmt.Errorf("pfdjson.Write: %w", err)
+	}
+	return nil
+}
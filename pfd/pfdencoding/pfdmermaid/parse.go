@@ -0,0 +1,457 @@
+package pfdmermaid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// SyntaxError is an error at a position of the Mermaid source. Line and Column are 1-origin.
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+type shape int
+
+const (
+	shapeNone shape = iota
+	shapeBox
+	shapeSubroutine
+	shapeStadium
+	shapeRound
+	shapeSubgraph
+)
+
+type vertex struct {
+	Handle   string
+	Label    string
+	HasLabel bool
+	Shape    shape
+	Parent   string
+}
+
+type rawEdge struct {
+	Source     string
+	Target     string
+	IsFeedback bool
+}
+
+type parser struct {
+	vertices map[string]*vertex
+	handles  []string
+	edges    []rawEdge
+	stack    []string
+}
+
+var headerPattern = regexp.MustCompile(`^(flowchart|graph)(\s+(LR|RL|TB|TD|BT))?$`)
+
+// Parse parses a Mermaid flowchart written by Write. If the input is Markdown, the first mermaid code block is parsed.
+// Labels follow the same convention as draw.io: "ID: description". Nodes without labels use Mermaid node IDs as IDs.
+// Boxes are deliverables, subroutine shapes are composite deliverables, stadium and round shapes are processes, and
+// subgraphs are composite processes. Dotted links are feedback edges.
+// The composite deliverable table is optional. If given, it gives the deliverable composition.
+func Parse(r io.Reader, cdt *pfd.CompositeDeliverableTable, logger *slog.Logger) (*pfd.PFD, error) {
+	lines, offset, err := readLines(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdmermaid.Parse: %w", err)
+	}
+
+	title, i := parseFrontMatter(lines)
+
+	ps := &parser{vertices: make(map[string]*vertex)}
+	hasHeader := false
+	for ; i < len(lines); i++ {
+		line := strings.TrimSuffix(strings.TrimSpace(lines[i]), ";")
+		lineNum := offset + i + 1
+		if line == "" || strings.HasPrefix(line, "%%") {
+			continue
+		}
+		if !hasHeader {
+			if !headerPattern.MatchString(line) {
+				return nil, fmt.Errorf("pfdmermaid.Parse: %w", SyntaxError{Line: lineNum, Column: 1, Message: "expected flowchart header"})
+			}
+			hasHeader = true
+			continue
+		}
+
+		col := strings.Index(lines[i], line) + 1
+		if err := ps.parseStatement(line, lineNum, col); err != nil {
+			return nil, fmt.Errorf("pfdmermaid.Parse: %w", err)
+		}
+	}
+	if !hasHeader {
+		return nil, fmt.Errorf("pfdmermaid.Parse: missing flowchart header")
+	}
+	if len(ps.stack) > 0 {
+		return nil, fmt.Errorf("pfdmermaid.Parse: %w", SyntaxError{Line: offset + len(lines), Column: 1, Message: fmt.Sprintf("missing end of subgraph %q", ps.stack[len(ps.stack)-1])})
+	}
+
+	p := ps.pfd(title)
+
+	if cdt != nil {
+		p.DeliverableComposition = cdt.NodeIDMap(logger)
+		p.Edges = pfd.ExpandCompositeDeliverableEdges(p.Edges, p.DeliverableComposition)
+	}
+	return p, nil
+}
+
+// readLines returns lines of the Mermaid source and the number of lines before the source.
+func readLines(r io.Reader) ([]string, int, error) {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "```mermaid" {
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "```" {
+				return lines[i+1 : j], i + 1, nil
+			}
+		}
+		return lines[i+1:], i + 1, nil
+	}
+	return lines, 0, nil
+}
+
+func parseFrontMatter(lines []string) (string, int) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "", 0
+	}
+	title := ""
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "---" {
+			return title, i + 1
+		}
+		if t, ok := strings.CutPrefix(line, "title:"); ok {
+			title = strings.TrimSpace(t)
+		}
+	}
+	return title, len(lines)
+}
+
+func (ps *parser) parseStatement(line string, lineNum int, col int) error {
+	if line == "end" {
+		if len(ps.stack) == 0 {
+			return SyntaxError{Line: lineNum, Column: col, Message: "unexpected end"}
+		}
+		ps.stack = ps.stack[:len(ps.stack)-1]
+		return nil
+	}
+
+	keyword, _, _ := strings.Cut(line, " ")
+	switch keyword {
+	case "direction", "classDef", "class", "style", "linkStyle", "click":
+		// NOTE: Styles do not affect PFDs.
+		return nil
+	case "subgraph":
+		return ps.parseSubgraph(line, lineNum, col)
+	}
+
+	s := &scanner{src: line, line: lineNum, col: col}
+	src, err := ps.parseNodeRef(s)
+	if err != nil {
+		return err
+	}
+	for {
+		s.skipSpaces()
+		if s.eof() {
+			return nil
+		}
+
+		isFeedback := false
+		switch {
+		case s.consume("-.->"):
+			isFeedback = true
+		case s.consume("-->"):
+		default:
+			return s.errorAt("expected --> or -.->")
+		}
+		if s.peek() == '|' {
+			s.pos++
+			if _, ok := s.readUntil("|"); !ok {
+				return s.errorAt("missing | after link label")
+			}
+		}
+		s.skipSpaces()
+
+		dst, err := ps.parseNodeRef(s)
+		if err != nil {
+			return err
+		}
+		ps.edges = append(ps.edges, rawEdge{Source: src, Target: dst, IsFeedback: isFeedback})
+		src = dst
+	}
+}
+
+func (ps *parser) parseSubgraph(line string, lineNum int, col int) error {
+	s := &scanner{src: line, line: lineNum, col: col}
+	s.consume("subgraph")
+	s.skipSpaces()
+
+	handle := s.readHandle()
+	if handle == "" {
+		return s.errorAt("expected subgraph ID")
+	}
+	v := &vertex{Handle: handle, Shape: shapeSubgraph}
+	s.skipSpaces()
+	if s.consume("[") {
+		label, err := s.readLabel("]")
+		if err != nil {
+			return err
+		}
+		v.Label = label
+		v.HasLabel = true
+	}
+	s.skipSpaces()
+	if !s.eof() {
+		return s.errorAt("unexpected characters after subgraph")
+	}
+
+	if err := ps.declare(v, s); err != nil {
+		return err
+	}
+	ps.stack = append(ps.stack, handle)
+	return nil
+}
+
+func (ps *parser) parseNodeRef(s *scanner) (string, error) {
+	handle := s.readHandle()
+	if handle == "" {
+		return "", s.errorAt("expected node ID")
+	}
+	v := &vertex{Handle: handle}
+
+	var closing string
+	switch {
+	case s.consume("(["):
+		v.Shape, closing = shapeStadium, "])"
+	case s.consume("[["):
+		v.Shape, closing = shapeSubroutine, "]]"
+	case s.consume("(("), s.consume("{"), s.consume(">"):
+		return "", s.errorAt("unsupported node shape")
+	case s.consume("["):
+		v.Shape, closing = shapeBox, "]"
+	case s.consume("("):
+		v.Shape, closing = shapeRound, ")"
+	}
+	if v.Shape != shapeNone {
+		label, err := s.readLabel(closing)
+		if err != nil {
+			return "", err
+		}
+		v.Label = label
+		v.HasLabel = true
+	}
+
+	if err := ps.declare(v, s); err != nil {
+		return "", err
+	}
+	return handle, nil
+}
+
+// declare registers the vertex. A vertex belongs to the subgraph where it appears first, as Mermaid does.
+func (ps *parser) declare(v *vertex, s *scanner) error {
+	if len(ps.stack) > 0 {
+		v.Parent = ps.stack[len(ps.stack)-1]
+	}
+
+	existing, ok := ps.vertices[v.Handle]
+	if !ok {
+		ps.vertices[v.Handle] = v
+		ps.handles = append(ps.handles, v.Handle)
+		return nil
+	}
+
+	if v.Shape == shapeNone {
+		return nil
+	}
+	if existing.Shape != shapeNone && existing.Shape != v.Shape {
+		return s.errorAt(fmt.Sprintf("conflicting shapes for %q", v.Handle))
+	}
+	if existing.HasLabel && v.HasLabel && existing.Label != v.Label {
+		return s.errorAt(fmt.Sprintf("conflicting labels for %q", v.Handle))
+	}
+	existing.Shape = v.Shape
+	existing.Label = v.Label
+	existing.HasLabel = v.HasLabel
+	return nil
+}
+
+func (ps *parser) pfd(title string) *pfd.PFD {
+	nodes := sets.NewWithCapacity[*pfd.Node](len(ps.handles))
+	ids := make(map[string]pfd.NodeID, len(ps.handles))
+	for _, handle := range ps.handles {
+		v := ps.vertices[handle]
+
+		id, desc := pfd.NodeID(handle), ""
+		if v.HasLabel {
+			id, desc = parseLabel(v.Label)
+		}
+		ids[handle] = id
+
+		var t pfd.NodeType
+		switch v.Shape {
+		case shapeNone, shapeBox:
+			// NOTE: Mermaid draws nodes without shapes as boxes.
+			t = pfd.NodeTypeAtomicDeliverable
+		case shapeSubroutine:
+			t = pfd.NodeTypeCompositeDeliverable
+		case shapeStadium, shapeRound:
+			t = pfd.NodeTypeAtomicProcess
+		case shapeSubgraph:
+			t = pfd.NodeTypeCompositeProcess
+		default:
+			panic(fmt.Sprintf("pfdmermaid.parser.pfd: unknown shape: %d", v.Shape))
+		}
+		nodes.Add((*pfd.Node).Compare, &pfd.Node{ID: id, Description: desc, Type: t})
+	}
+
+	processComposition := make(map[pfd.NodeID]*sets.Set[pfd.NodeID])
+	for _, handle := range ps.handles {
+		if ps.vertices[handle].Shape == shapeSubgraph {
+			processComposition[ids[handle]] = sets.New(pfd.NodeID.Compare)
+		}
+	}
+	for _, handle := range ps.handles {
+		v := ps.vertices[handle]
+		if v.Parent == "" {
+			continue
+		}
+		switch v.Shape {
+		case shapeStadium, shapeRound, shapeSubgraph:
+			processComposition[ids[v.Parent]].Add(pfd.NodeID.Compare, ids[handle])
+		}
+	}
+
+	edges := sets.NewWithCapacity[*pfd.Edge](len(ps.edges))
+	for _, e := range ps.edges {
+		edges.Add((*pfd.Edge).Compare, &pfd.Edge{Source: ids[e.Source], Target: ids[e.Target], IsFeedback: e.IsFeedback})
+	}
+
+	return pfd.NewPFD(title, nodes, edges, processComposition, make(map[pfd.NodeID]*sets.Set[pfd.NodeID]))
+}
+
+func parseLabel(label string) (pfd.NodeID, string) {
+	id, desc, ok := strings.Cut(unescapeLabel(label), ":")
+	if !ok {
+		// NOTE: If there are unnumbered IDs, use the description as the ID.
+		return pfd.NodeID(strings.TrimSpace(id)), ""
+	}
+	return pfd.NodeID(strings.TrimSpace(id)), strings.TrimSpace(desc)
+}
+
+var entityPattern = regexp.MustCompile(`#(quot|[0-9]+);`)
+var brPattern = regexp.MustCompile(`<br\s*/?>`)
+
+func unescapeLabel(s string) string {
+	s = brPattern.ReplaceAllString(s, "\n")
+	return entityPattern.ReplaceAllStringFunc(s, func(entity string) string {
+		name := entity[1 : len(entity)-1]
+		if name == "quot" {
+			return "\""
+		}
+		r, err := strconv.Atoi(name)
+		if err != nil {
+			return entity
+		}
+		return string(rune(r))
+	})
+}
+
+type scanner struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+func (s *scanner) eof() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *scanner) peek() byte {
+	if s.eof() {
+		return 0
+	}
+	return s.src[s.pos]
+}
+
+func (s *scanner) consume(prefix string) bool {
+	if strings.HasPrefix(s.src[s.pos:], prefix) {
+		s.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (s *scanner) skipSpaces() {
+	for !s.eof() && (s.src[s.pos] == ' ' || s.src[s.pos] == '\t') {
+		s.pos++
+	}
+}
+
+func (s *scanner) readHandle() string {
+	start := s.pos
+	for _, r := range s.src[s.pos:] {
+		if !isHandleRune(r) {
+			break
+		}
+		s.pos += len(string(r))
+	}
+	return s.src[start:s.pos]
+}
+
+func (s *scanner) readUntil(closing string) (string, bool) {
+	idx := strings.Index(s.src[s.pos:], closing)
+	if idx < 0 {
+		return "", false
+	}
+	res := s.src[s.pos : s.pos+idx]
+	s.pos += idx + len(closing)
+	return res, true
+}
+
+func (s *scanner) readLabel(closing string) (string, error) {
+	if s.peek() == '"' {
+		s.pos++
+		label, ok := s.readUntil("\"")
+		if !ok {
+			return "", s.errorAt("missing closing quote")
+		}
+		if !s.consume(closing) {
+			return "", s.errorAt(fmt.Sprintf("expected %s", closing))
+		}
+		return label, nil
+	}
+
+	label, ok := s.readUntil(closing)
+	if !ok {
+		return "", s.errorAt(fmt.Sprintf("expected %s", closing))
+	}
+	return strings.TrimSpace(label), nil
+}
+
+func (s *scanner) errorAt(message string) SyntaxError {
+	return SyntaxError{Line: s.line, Column: s.col + s.pos, Message: message}
+}
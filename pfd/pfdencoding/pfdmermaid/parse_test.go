@@ -0,0 +1,173 @@
+package pfdmermaid
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	testCases := map[string]*pfd.PFD{
+		"composite": {
+			Title: "Composite",
+			Nodes: sets.New(
+				(*pfd.Node).Compare,
+				&pfd.Node{ID: "D1", Description: "Spec #1 \"draft\"", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D2", Description: "Code", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D3", Description: "Review", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "D4", Description: "All", Type: pfd.NodeTypeCompositeDeliverable},
+				&pfd.Node{ID: "D1.1", Description: "Note", Type: pfd.NodeTypeAtomicDeliverable},
+				&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+				&pfd.Node{ID: "P2", Description: "Review", Type: pfd.NodeTypeAtomicProcess},
+				&pfd.Node{ID: "P3", Description: "Develop", Type: pfd.NodeTypeCompositeProcess},
+				&pfd.Node{ID: "P4", Description: "Release", Type: pfd.NodeTypeAtomicProcess},
+				&pfd.Node{ID: "Unnumbered process", Type: pfd.NodeTypeAtomicProcess},
+			),
+			Edges: sets.New(
+				(*pfd.Edge).Compare,
+				&pfd.Edge{Source: "D1", Target: "P1"},
+				&pfd.Edge{Source: "D1.1", Target: "P1"},
+				&pfd.Edge{Source: "P1", Target: "D2"},
+				&pfd.Edge{Source: "D2", Target: "P2"},
+				&pfd.Edge{Source: "P2", Target: "D3"},
+				&pfd.Edge{Source: "D3", Target: "P1", IsFeedback: true},
+				&pfd.Edge{Source: "D4", Target: "P4"},
+				&pfd.Edge{Source: "D2", Target: "P4"},
+				&pfd.Edge{Source: "D3", Target: "P4"},
+				&pfd.Edge{Source: "D1", Target: "Unnumbered process"},
+			),
+			ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+				"P3": sets.New(pfd.NodeID.Compare, "P1", "P2"),
+			},
+			DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+				"D4": sets.New(pfd.NodeID.Compare, "D2", "D3"),
+			},
+		},
+	}
+	for name, p := range pfd.PresetsAll {
+		testCases[name] = p
+	}
+
+	for name, p := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			if err := Write(sb, p); err != nil {
+				t.Fatal(err)
+			}
+
+			cdt := &pfd.CompositeDeliverableTable{}
+			for comp, members := range p.DeliverableComposition {
+				row := &pfd.CompositeDeliverableRow{ID: pfd.CompositeDeliverableID(comp)}
+				for _, d := range members.Iter() {
+					row.Deliverables = append(row.Deliverables, pfd.AtomicDeliverableID(d))
+				}
+				cdt.Rows = append(cdt.Rows, row)
+			}
+
+			logger := slog.New(slogtest.NewTestHandler(t))
+			actual, err := Parse(strings.NewReader(sb.String()), cdt, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := pfd.NewPFD(p.Title, p.Nodes, p.Edges, p.ProcessComposition, p.DeliverableComposition)
+			if expected.ProcessComposition == nil {
+				expected.ProcessComposition = map[pfd.NodeID]*sets.Set[pfd.NodeID]{}
+			}
+			if expected.DeliverableComposition == nil {
+				expected.DeliverableComposition = map[pfd.NodeID]*sets.Set[pfd.NodeID]{}
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Log(sb.String())
+				t.Error(cmp.Diff(expected, actual))
+			}
+		})
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	input := "# Design\n" +
+		"\n" +
+		"```mermaid\n" +
+		"graph LR;\n" +
+		"  %% hand-written\n" +
+		"  D1[D1: Spec] --> P1(P1: Implement) --> D2\n" +
+		"  D2 -.->|rework| P1\n" +
+		"```\n"
+
+	logger := slog.New(slogtest.NewTestHandler(t))
+	actual, err := Parse(strings.NewReader(input), nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &pfd.PFD{
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Description: "Spec", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D2", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D1", Target: "P1"},
+			&pfd.Edge{Source: "P1", Target: "D2"},
+			&pfd.Edge{Source: "D2", Target: "P1", IsFeedback: true},
+		),
+		ProcessComposition:     map[pfd.NodeID]*sets.Set[pfd.NodeID]{},
+		DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error(cmp.Diff(expected, actual))
+	}
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	testCases := map[string]struct {
+		Input    string
+		Expected SyntaxError
+	}{
+		"missing header": {
+			Input:    "D1 --> P1\n",
+			Expected: SyntaxError{Line: 1, Column: 1, Message: "expected flowchart header"},
+		},
+		"unsupported link": {
+			Input:    "flowchart LR\n    D1 ==> P1\n",
+			Expected: SyntaxError{Line: 2, Column: 8, Message: "expected --> or -.->"},
+		},
+		"unsupported shape": {
+			Input:    "flowchart LR\n  P1{Decide}\n",
+			Expected: SyntaxError{Line: 2, Column: 6, Message: "unsupported node shape"},
+		},
+		"unexpected end": {
+			Input:    "flowchart LR\nend\n",
+			Expected: SyntaxError{Line: 2, Column: 1, Message: "unexpected end"},
+		},
+		"missing end": {
+			Input:    "flowchart LR\nsubgraph P1\n  P2([P2])\n",
+			Expected: SyntaxError{Line: 3, Column: 1, Message: "missing end of subgraph \"P1\""},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := slog.New(slogtest.NewTestHandler(t))
+			_, err := Parse(strings.NewReader(testCase.Input), nil, logger)
+
+			var actual SyntaxError
+			if !errors.As(err, &actual) {
+				t.Fatalf("want SyntaxError, got %v", err)
+			}
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
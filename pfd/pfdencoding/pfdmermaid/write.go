@@ -0,0 +1,197 @@
+package pfdmermaid
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+const indent = "    "
+
+// Write writes the PFD as a Mermaid flowchart.
+// Deliverables are boxes, composite deliverables are subroutine shapes, processes are stadium shapes, and composite
+// processes are subgraphs. Feedback edges are dotted links. Deliverable composition is not written because it is given
+// by composite deliverable tables.
+func Write(w io.Writer, p *pfd.PFD) error {
+	sb := &strings.Builder{}
+	write(sb, p)
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("pfdmermaid.Write: %w", err)
+	}
+	return nil
+}
+
+func write(sb *strings.Builder, p *pfd.PFD) {
+	handles := newHandles(p.Nodes)
+
+	if p.Title != "" {
+		sb.WriteString("---\n")
+		sb.WriteString("title: ")
+		sb.WriteString(p.Title)
+		sb.WriteString("\n---\n")
+	}
+	sb.WriteString("flowchart LR\n")
+
+	for _, n := range p.Nodes.Iter() {
+		if n.Type.IsDeliverable() {
+			writeNode(sb, indent, n, handles)
+		}
+	}
+
+	parentOf := make(map[pfd.NodeID]pfd.NodeID)
+	for comp, children := range p.ProcessComposition {
+		for _, child := range children.Iter() {
+			parentOf[child] = comp
+		}
+	}
+	childrenOf := func(parent pfd.NodeID) []*pfd.Node {
+		res := make([]*pfd.Node, 0)
+		for _, n := range p.Nodes.Iter() {
+			if !n.Type.IsProcess() {
+				continue
+			}
+			parentID, ok := parentOf[n.ID]
+			if !ok {
+				parentID = pfd.NodeIDContextDiagram
+			}
+			if parentID == parent {
+				res = append(res, n)
+			}
+		}
+		return res
+	}
+
+	written := sets.New(pfd.NodeID.Compare)
+	var writeProcesses func(parent pfd.NodeID, prefix string)
+	writeProcesses = func(parent pfd.NodeID, prefix string) {
+		for _, n := range childrenOf(parent) {
+			// NOTE: Guard against cyclic process compositions.
+			if written.Contains(pfd.NodeID.Compare, n.ID) {
+				continue
+			}
+			written.Add(pfd.NodeID.Compare, n.ID)
+			if n.Type != pfd.NodeTypeCompositeProcess {
+				writeNode(sb, prefix, n, handles)
+				continue
+			}
+			sb.WriteString(prefix)
+			sb.WriteString("subgraph ")
+			sb.WriteString(handles[n.ID])
+			sb.WriteString("[\"")
+			sb.WriteString(escapeLabel(label(n)))
+			sb.WriteString("\"]\n")
+			writeProcesses(n.ID, prefix+indent)
+			sb.WriteString(prefix)
+			sb.WriteString("end\n")
+		}
+	}
+	writeProcesses(pfd.NodeIDContextDiagram, indent)
+
+	for _, e := range p.Edges.Iter() {
+		if p.IsDerivedFromCompositeDeliverable(e) {
+			continue
+		}
+		sb.WriteString(indent)
+		sb.WriteString(handleOrFallback(e.Source, handles))
+		if e.IsFeedback {
+			sb.WriteString(" -.-> ")
+		} else {
+			sb.WriteString(" --> ")
+		}
+		sb.WriteString(handleOrFallback(e.Target, handles))
+		sb.WriteString("\n")
+	}
+}
+
+func writeNode(sb *strings.Builder, prefix string, n *pfd.Node, handles map[pfd.NodeID]string) {
+	var opening, closing string
+	switch n.Type {
+	case pfd.NodeTypeAtomicDeliverable:
+		opening, closing = "[", "]"
+	case pfd.NodeTypeCompositeDeliverable:
+		opening, closing = "[[", "]]"
+	case pfd.NodeTypeAtomicProcess:
+		opening, closing = "([", "])"
+	default:
+		panic(fmt.Sprintf("pfdmermaid.writeNode: unexpected node type: %q", n.Type))
+	}
+
+	sb.WriteString(prefix)
+	sb.WriteString(handles[n.ID])
+	sb.WriteString(opening)
+	sb.WriteString("\"")
+	sb.WriteString(escapeLabel(label(n)))
+	sb.WriteString("\"")
+	sb.WriteString(closing)
+	sb.WriteString("\n")
+}
+
+// newHandles returns Mermaid node IDs. Node IDs are used as is if Mermaid accepts them, otherwise n1, n2, ... are used.
+// PFD node IDs are restored from labels, so handles do not need to be the same as node IDs.
+func newHandles(nodes *sets.Set[*pfd.Node]) map[pfd.NodeID]string {
+	handles := make(map[pfd.NodeID]string, nodes.Len())
+	used := make(map[string]bool, nodes.Len())
+	for _, n := range nodes.Iter() {
+		if IsValidHandle(string(n.ID)) {
+			handles[n.ID] = string(n.ID)
+			used[string(n.ID)] = true
+		}
+	}
+
+	next := 1
+	for _, n := range nodes.Iter() {
+		if _, ok := handles[n.ID]; ok {
+			continue
+		}
+		for used["n"+strconv.Itoa(next)] {
+			next++
+		}
+		h := "n" + strconv.Itoa(next)
+		handles[n.ID] = h
+		used[h] = true
+	}
+	return handles
+}
+
+func handleOrFallback(id pfd.NodeID, handles map[pfd.NodeID]string) string {
+	if h, ok := handles[id]; ok {
+		return h
+	}
+	// NOTE: Edges to undefined nodes only appear in invalid PFDs.
+	return string(id)
+}
+
+// IsValidHandle returns true if the string can be used as a Mermaid node ID.
+func IsValidHandle(s string) bool {
+	if s == "" || s == "end" || s == "subgraph" {
+		return false
+	}
+	for _, r := range s {
+		if !isHandleRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isHandleRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func label(n *pfd.Node) string {
+	if n.Description == "" {
+		return string(n.ID)
+	}
+	return string(n.ID) + ": " + n.Description
+}
+
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, "#", "#35;")
+	s = strings.ReplaceAll(s, "\"", "#quot;")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,78 @@
+package pfdmermaid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	testCases := map[string]struct {
+		PFD      *pfd.PFD
+		Expected string
+	}{
+		"smallest loop": {
+			PFD: pfd.PresetSmallestLoop,
+			Expected: `---
+title: SmallestLoop
+---
+flowchart LR
+    D1["D1: D1"]
+    D2["D2: D2"]
+    P1(["P1: P1"])
+    D1 --> P1
+    D2 -.-> P1
+    P1 --> D2
+`,
+		},
+		"composite": {
+			PFD: &pfd.PFD{
+				Nodes: sets.New(
+					(*pfd.Node).Compare,
+					&pfd.Node{ID: "D1", Description: "Spec \"v1\"", Type: pfd.NodeTypeAtomicDeliverable},
+					&pfd.Node{ID: "D2", Description: "Code", Type: pfd.NodeTypeAtomicDeliverable},
+					&pfd.Node{ID: "D3", Description: "All", Type: pfd.NodeTypeCompositeDeliverable},
+					&pfd.Node{ID: "D1.1", Description: "Note", Type: pfd.NodeTypeAtomicDeliverable},
+					&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+					&pfd.Node{ID: "P2", Description: "Develop", Type: pfd.NodeTypeCompositeProcess},
+				),
+				Edges: sets.New(
+					(*pfd.Edge).Compare,
+					&pfd.Edge{Source: "D1", Target: "P1"},
+					&pfd.Edge{Source: "D1.1", Target: "P1"},
+					&pfd.Edge{Source: "P1", Target: "D2"},
+				),
+				ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+					"P2": sets.New(pfd.NodeID.Compare, "P1"),
+				},
+			},
+			Expected: `flowchart LR
+    D1["D1: Spec #quot;v1#quot;"]
+    D2["D2: Code"]
+    D3[["D3: All"]]
+    n1["D1.1: Note"]
+    subgraph P2["P2: Develop"]
+        P1(["P1: Implement"])
+    end
+    D1 --> P1
+    P1 --> D2
+    n1 --> P1
+`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			if err := Write(sb, testCase.PFD); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != testCase.Expected {
+				t.Error(cmp.Diff(testCase.Expected, sb.String()))
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package pfdtext
+
+// Position is a position in the source. Line and Column are 1-origin, and Column counts runes.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// File is the syntax tree of a PFD text.
+type File struct {
+	// Comments are the comments before the header.
+	Comments   []string
+	Title      string
+	HasTitle   bool
+	Statements []Statement
+	// TrailingComments are the comments after the last statement.
+	TrailingComments []string
+}
+
+// Statement is a node declaration or an edge chain.
+type Statement interface {
+	// Pos returns the position of the statement.
+	Pos() Position
+	// Leading returns the comments and whether a blank line precedes the statement.
+	Leading() *Leading
+}
+
+// Leading is the comments before a statement.
+type Leading struct {
+	Comments []string
+	// BlankLine is true if the statement or its comments are preceded by a blank line.
+	BlankLine bool
+}
+
+// Ident is a node ID in the source.
+type Ident struct {
+	Position Position
+	Name     string
+}
+
+type NodeKind string
+
+const (
+	NodeKindDeliverable NodeKind = "deliverable"
+	NodeKindProcess     NodeKind = "process"
+)
+
+// NodeDecl is a declaration such as `deliverable D1 "Spec"`, `composite deliverable D3 "All" = D1, D2` or
+// `composite process P1 "Develop" { ... }`.
+type NodeDecl struct {
+	Position       Position
+	LeadingInfo    Leading
+	Composite      bool
+	Kind           NodeKind
+	ID             Ident
+	Description    string
+	HasDescription bool
+	// Members are the members of a composite deliverable.
+	Members []Ident
+	// Body is the statements in a composite process block.
+	Body []Statement
+	// BodyTrailingComments are the comments after the last statement in a composite process block.
+	BodyTrailingComments []string
+}
+
+func (d *NodeDecl) Pos() Position {
+	return d.Position
+}
+
+func (d *NodeDecl) Leading() *Leading {
+	return &d.LeadingInfo
+}
+
+// EdgeChain is a chain of edges such as `D1 -> P1 -> D2` or `D3 ~> P1`.
+type EdgeChain struct {
+	Position    Position
+	LeadingInfo Leading
+	Nodes       []Ident
+	// IsFeedback[i] is true if the edge from Nodes[i] to Nodes[i+1] is a feedback edge.
+	IsFeedback []bool
+}
+
+func (c *EdgeChain) Pos() Position {
+	return c.Position
+}
+
+func (c *EdgeChain) Leading() *Leading {
+	return &c.LeadingInfo
+}
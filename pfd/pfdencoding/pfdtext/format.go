@@ -0,0 +1,250 @@
+package pfdtext
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+const indent = "    "
+
+// Format writes the syntax tree in the canonical form. Comments, the order of statements and edge chains are kept,
+// and consecutive blank lines are collapsed into one.
+func Format(w io.Writer, f *File) error {
+	sb := &strings.Builder{}
+
+	writeComments(sb, f.Comments, 0)
+	sb.WriteString(keywordPFD)
+	if f.HasTitle {
+		sb.WriteString(" ")
+		sb.WriteString(strconv.Quote(f.Title))
+	}
+	sb.WriteString("\n")
+
+	if len(f.Statements) > 0 {
+		sb.WriteString("\n")
+	}
+	writeStatements(sb, f.Statements, 0)
+
+	if len(f.TrailingComments) > 0 {
+		sb.WriteString("\n")
+		writeComments(sb, f.TrailingComments, 0)
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("pfdtext.Format: %w", err)
+	}
+	return nil
+}
+
+func writeComments(sb *strings.Builder, comments []string, depth int) {
+	for _, comment := range comments {
+		sb.WriteString(strings.Repeat(indent, depth))
+		sb.WriteString("//")
+		sb.WriteString(comment)
+		sb.WriteString("\n")
+	}
+}
+
+func writeStatements(sb *strings.Builder, stmts []Statement, depth int) {
+	for i, stmt := range stmts {
+		l := stmt.Leading()
+		if i > 0 && l.BlankLine {
+			sb.WriteString("\n")
+		}
+		writeComments(sb, l.Comments, depth)
+		sb.WriteString(strings.Repeat(indent, depth))
+
+		switch stmt := stmt.(type) {
+		case *NodeDecl:
+			writeNodeDecl(sb, stmt, depth)
+		case *EdgeChain:
+			writeEdgeChain(sb, stmt)
+		default:
+			panic(fmt.Sprintf("pfdtext.writeStatements: unknown statement: %T", stmt))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func writeNodeDecl(sb *strings.Builder, decl *NodeDecl, depth int) {
+	if decl.Composite {
+		sb.WriteString(keywordComposite)
+		sb.WriteString(" ")
+	}
+	sb.WriteString(string(decl.Kind))
+	sb.WriteString(" ")
+	sb.WriteString(FormatIdent(decl.ID.Name))
+	if decl.HasDescription {
+		sb.WriteString(" ")
+		sb.WriteString(strconv.Quote(decl.Description))
+	}
+
+	if len(decl.Members) > 0 {
+		sb.WriteString(" =")
+		for i, member := range decl.Members {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(" ")
+			sb.WriteString(FormatIdent(member.Name))
+		}
+	}
+
+	if decl.Body != nil {
+		sb.WriteString(" {\n")
+		writeStatements(sb, decl.Body, depth+1)
+		writeComments(sb, decl.BodyTrailingComments, depth+1)
+		sb.WriteString(strings.Repeat(indent, depth))
+		sb.WriteString("}")
+	}
+}
+
+func writeEdgeChain(sb *strings.Builder, chain *EdgeChain) {
+	sb.WriteString(FormatIdent(chain.Nodes[0].Name))
+	for i, isFeedback := range chain.IsFeedback {
+		if isFeedback {
+			sb.WriteString(" ~> ")
+		} else {
+			sb.WriteString(" -> ")
+		}
+		sb.WriteString(FormatIdent(chain.Nodes[i+1].Name))
+	}
+}
+
+// FormatIdent returns the node ID as is if it can be unquoted, otherwise the quoted node ID.
+func FormatIdent(s string) string {
+	if IsIdent(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// Write writes the PFD in the canonical form. Atomic deliverables, composite deliverables, processes and edges are
+// written in this order. Edges derived from composite deliverables are omitted.
+func Write(w io.Writer, p *pfd.PFD) error {
+	if err := Format(w, NewFile(p)); err != nil {
+		return fmt.Errorf("pfdtext.Write: %w", err)
+	}
+	return nil
+}
+
+// NewFile returns the canonical syntax tree of the PFD.
+func NewFile(p *pfd.PFD) *File {
+	f := &File{Title: p.Title, HasTitle: p.Title != ""}
+
+	nodeMap := make(map[pfd.NodeID]*pfd.Node, p.Nodes.Len())
+	for _, node := range p.Nodes.Iter() {
+		nodeMap[node.ID] = node
+	}
+
+	atomicDeliverables := make([]Statement, 0)
+	compositeDeliverables := make([]Statement, 0)
+	for _, node := range p.Nodes.Iter() {
+		switch node.Type {
+		case pfd.NodeTypeAtomicDeliverable:
+			atomicDeliverables = append(atomicDeliverables, newNodeDecl(node))
+		case pfd.NodeTypeCompositeDeliverable:
+			decl := newNodeDecl(node)
+			if members, ok := p.DeliverableComposition[node.ID]; ok {
+				for _, member := range members.Iter() {
+					decl.Members = append(decl.Members, Ident{Name: string(member)})
+				}
+			}
+			compositeDeliverables = append(compositeDeliverables, decl)
+		}
+	}
+
+	children := sets.New(pfd.NodeID.Compare)
+	for _, members := range p.ProcessComposition {
+		for _, member := range members.Iter() {
+			children.Add(pfd.NodeID.Compare, member)
+		}
+	}
+
+	visited := sets.New(pfd.NodeID.Compare)
+	var newProcessDecl func(node *pfd.Node) *NodeDecl
+	newProcessDecl = func(node *pfd.Node) *NodeDecl {
+		visited.Add(pfd.NodeID.Compare, node.ID)
+		decl := newNodeDecl(node)
+
+		members, ok := p.ProcessComposition[node.ID]
+		if !ok {
+			return decl
+		}
+		decl.Body = make([]Statement, 0, members.Len())
+		for _, member := range members.Iter() {
+			if visited.Contains(pfd.NodeID.Compare, member) {
+				continue
+			}
+			child, ok := nodeMap[member]
+			if !ok || !child.Type.IsProcess() {
+				continue
+			}
+			decl.Body = append(decl.Body, newProcessDecl(child))
+		}
+		return decl
+	}
+
+	processes := make([]Statement, 0)
+	for _, node := range p.Nodes.Iter() {
+		if !node.Type.IsProcess() || children.Contains(pfd.NodeID.Compare, node.ID) {
+			continue
+		}
+		processes = append(processes, newProcessDecl(node))
+	}
+	// NOTE: Processes only reachable through a composition cycle are written at the top level.
+	for _, node := range p.Nodes.Iter() {
+		if !node.Type.IsProcess() || visited.Contains(pfd.NodeID.Compare, node.ID) {
+			continue
+		}
+		processes = append(processes, newProcessDecl(node))
+	}
+
+	edges := make([]Statement, 0)
+	for _, edge := range p.Edges.Iter() {
+		if p.IsDerivedFromCompositeDeliverable(edge) {
+			continue
+		}
+		edges = append(edges, &EdgeChain{
+			Nodes:      []Ident{{Name: string(edge.Source)}, {Name: string(edge.Target)}},
+			IsFeedback: []bool{edge.IsFeedback},
+		})
+	}
+
+	for _, group := range [][]Statement{atomicDeliverables, compositeDeliverables, processes, edges} {
+		if len(group) == 0 {
+			continue
+		}
+		group[0].Leading().BlankLine = true
+		f.Statements = append(f.Statements, group...)
+	}
+	return f
+}
+
+func newNodeDecl(node *pfd.Node) *NodeDecl {
+	decl := &NodeDecl{
+		ID:             Ident{Name: string(node.ID)},
+		Description:    node.Description,
+		HasDescription: node.Description != "",
+	}
+	switch node.Type {
+	case pfd.NodeTypeAtomicDeliverable:
+		decl.Kind = NodeKindDeliverable
+	case pfd.NodeTypeCompositeDeliverable:
+		decl.Kind = NodeKindDeliverable
+		decl.Composite = true
+	case pfd.NodeTypeAtomicProcess:
+		decl.Kind = NodeKindProcess
+	case pfd.NodeTypeCompositeProcess:
+		decl.Kind = NodeKindProcess
+		decl.Composite = true
+	default:
+		panic(fmt.Sprintf("pfdtext.newNodeDecl: unknown node type: %q", node.Type))
+	}
+	return decl
+}
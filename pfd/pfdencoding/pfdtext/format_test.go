@@ -0,0 +1,172 @@
+package pfdtext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	testCases := map[string]struct {
+		Input    string
+		Expected string
+	}{
+		"canonical": {
+			Input: `pfd "Example"
+
+deliverable D1 "Spec"
+`,
+			Expected: `pfd "Example"
+
+deliverable D1 "Spec"
+`,
+		},
+		"spacing and blank lines": {
+			Input: `// Header comment
+pfd   "Example"
+deliverable   D1	"Spec"
+
+
+
+// Deliverables
+composite deliverable D3 "All"=D1,D2
+deliverable D2
+composite process P2 "Develop"{
+        // Inner
+  process P1 "Implement"
+
+  process "P 3"
+    // Trailing in block
+}
+D1->P1  ->D2
+D2 ~>P1
+
+
+// Trailing
+`,
+			Expected: `// Header comment
+pfd "Example"
+
+deliverable D1 "Spec"
+
+// Deliverables
+composite deliverable D3 "All" = D1, D2
+deliverable D2
+composite process P2 "Develop" {
+    // Inner
+    process P1 "Implement"
+
+    process "P 3"
+    // Trailing in block
+}
+D1 -> P1 -> D2
+D2 ~> P1
+
+// Trailing
+`,
+		},
+		"quoted IDs": {
+			Input: "pfd\nprocess \"process\"\ndeliverable \"D\\\"1\"\n\"D\\\"1\" -> \"process\"\n",
+			Expected: `pfd
+
+process "process"
+deliverable "D\"1"
+"D\"1" -> "process"
+`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			f, err := ParseFile(strings.NewReader(testCase.Input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sb := &strings.Builder{}
+			if err := Format(sb, f); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != testCase.Expected {
+				t.Error(cmp.Diff(testCase.Expected, sb.String()))
+			}
+
+			f2, err := ParseFile(strings.NewReader(sb.String()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sb2 := &strings.Builder{}
+			if err := Format(sb2, f2); err != nil {
+				t.Fatal(err)
+			}
+			if sb2.String() != sb.String() {
+				t.Errorf("not idempotent:\n%s", cmp.Diff(sb.String(), sb2.String()))
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	p := &pfd.PFD{
+		Title: "Composite",
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Description: "Spec \"v1\"", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D2", Description: "Code", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D3", Description: "Review", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D4", Description: "All", Type: pfd.NodeTypeCompositeDeliverable},
+			&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "P2", Description: "Develop", Type: pfd.NodeTypeCompositeProcess},
+			&pfd.Node{ID: "P3", Description: "Release", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "Unnumbered process", Type: pfd.NodeTypeAtomicProcess},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D1", Target: "P1"},
+			&pfd.Edge{Source: "P1", Target: "D2"},
+			&pfd.Edge{Source: "D3", Target: "P1", IsFeedback: true},
+			&pfd.Edge{Source: "D4", Target: "P3"},
+			&pfd.Edge{Source: "D2", Target: "P3"},
+			&pfd.Edge{Source: "D3", Target: "P3"},
+			&pfd.Edge{Source: "D1", Target: "Unnumbered process"},
+		),
+		ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+			"P2": sets.New(pfd.NodeID.Compare, "P1"),
+		},
+		DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+			"D4": sets.New(pfd.NodeID.Compare, "D2", "D3"),
+		},
+	}
+
+	expected := `pfd "Composite"
+
+deliverable D1 "Spec \"v1\""
+deliverable D2 "Code"
+deliverable D3 "Review"
+
+composite deliverable D4 "All" = D2, D3
+
+composite process P2 "Develop" {
+    process P1 "Implement"
+}
+process P3 "Release"
+process "Unnumbered process"
+
+D1 -> P1
+D1 -> "Unnumbered process"
+D3 ~> P1
+D4 -> P3
+P1 -> D2
+`
+
+	sb := &strings.Builder{}
+	if err := Write(sb, p); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != expected {
+		t.Error(cmp.Diff(expected, sb.String()))
+	}
+}
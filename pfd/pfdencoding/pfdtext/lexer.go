@@ -0,0 +1,175 @@
+package pfdtext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewline
+	tokenComment
+	tokenIdent
+	tokenString
+	tokenArrow
+	tokenFeedbackArrow
+	tokenLBrace
+	tokenRBrace
+	tokenEquals
+	tokenComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of file"
+	case tokenNewline:
+		return "newline"
+	case tokenComment:
+		return "comment"
+	case tokenIdent:
+		return "identifier"
+	case tokenString:
+		return "string"
+	case tokenArrow:
+		return "->"
+	case tokenFeedbackArrow:
+		return "~>"
+	case tokenLBrace:
+		return "{"
+	case tokenRBrace:
+		return "}"
+	case tokenEquals:
+		return "="
+	case tokenComma:
+		return ","
+	default:
+		panic(fmt.Sprintf("pfdtext.tokenKind.String: unknown token kind: %d", k))
+	}
+}
+
+type token struct {
+	Kind tokenKind
+	// Text is the identifier, the unquoted string or the comment without the leading //.
+	Text     string
+	Position Position
+}
+
+// IsIdentRune returns true if the rune can be used in unquoted node IDs.
+func IsIdentRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// IsIdent returns true if the string can be written as an unquoted node ID.
+func IsIdent(s string) bool {
+	if s == "" || isKeyword(s) {
+		return false
+	}
+	for _, r := range s {
+		if !IsIdentRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isKeyword(s string) bool {
+	switch s {
+	case keywordPFD, keywordComposite, keywordDeliverable, keywordProcess:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	keywordPFD         = "pfd"
+	keywordComposite   = "composite"
+	keywordDeliverable = "deliverable"
+	keywordProcess     = "process"
+)
+
+func tokenize(src string) ([]token, error) {
+	tokens := make([]token, 0)
+	rs := []rune(src)
+	line, col := 1, 1
+	i := 0
+
+	advance := func(n int) {
+		i += n
+		col += n
+	}
+
+	for i < len(rs) {
+		r := rs[i]
+		pos := Position{Line: line, Column: col}
+		switch {
+		case r == '\n':
+			tokens = append(tokens, token{Kind: tokenNewline, Position: pos})
+			i++
+			line++
+			col = 1
+		case r == ' ' || r == '\t' || r == '\r':
+			advance(1)
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			j := i
+			for j < len(rs) && rs[j] != '\n' {
+				j++
+			}
+			text := strings.TrimRight(string(rs[i+2:j]), " \t\r")
+			tokens = append(tokens, token{Kind: tokenComment, Text: text, Position: pos})
+			advance(j - i)
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '>':
+			tokens = append(tokens, token{Kind: tokenArrow, Position: pos})
+			advance(2)
+		case r == '~' && i+1 < len(rs) && rs[i+1] == '>':
+			tokens = append(tokens, token{Kind: tokenFeedbackArrow, Position: pos})
+			advance(2)
+		case r == '{':
+			tokens = append(tokens, token{Kind: tokenLBrace, Position: pos})
+			advance(1)
+		case r == '}':
+			tokens = append(tokens, token{Kind: tokenRBrace, Position: pos})
+			advance(1)
+		case r == '=':
+			tokens = append(tokens, token{Kind: tokenEquals, Position: pos})
+			advance(1)
+		case r == ',':
+			tokens = append(tokens, token{Kind: tokenComma, Position: pos})
+			advance(1)
+		case r == '"':
+			j := i + 1
+			for j < len(rs) && rs[j] != '"' && rs[j] != '\n' {
+				if rs[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(rs) || rs[j] != '"' {
+				return nil, Error{Position: pos, Message: "unterminated string"}
+			}
+			text, err := strconv.Unquote(string(rs[i : j+1]))
+			if err != nil {
+				return nil, Error{Position: pos, Message: fmt.Sprintf("invalid string: %s", err.Error())}
+			}
+			tokens = append(tokens, token{Kind: tokenString, Text: text, Position: pos})
+			advance(j + 1 - i)
+		case IsIdentRune(r):
+			j := i
+			for j < len(rs) && IsIdentRune(rs[j]) {
+				j++
+			}
+			tokens = append(tokens, token{Kind: tokenIdent, Text: string(rs[i:j]), Position: pos})
+			advance(j - i)
+		default:
+			return nil, Error{Position: pos, Message: fmt.Sprintf("unexpected character: %q", r)}
+		}
+	}
+
+	tokens = append(tokens, token{Kind: tokenEOF, Position: Position{Line: line, Column: col}})
+	return tokens, nil
+}
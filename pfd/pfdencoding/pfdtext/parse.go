@@ -0,0 +1,435 @@
+package pfdtext
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// Error is an error at a position of the source.
+type Error struct {
+	Position Position
+	Message  string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Position.Line, e.Position.Column, e.Message)
+}
+
+type Errors []Error
+
+func (e Errors) Error() string {
+	if len(e) == 0 {
+		panic("pfdtext.Errors: empty")
+	}
+
+	sb := &strings.Builder{}
+	for i, err := range e {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+// Parse parses a PFD text such as:
+//
+//	pfd "Example"
+//
+//	deliverable D1 "Spec"
+//	deliverable D2 "Code"
+//	composite deliverable D3 "All" = D1, D2
+//
+//	composite process P2 "Develop" {
+//	    process P1 "Implement"
+//	}
+//
+//	D1 -> P1 -> D2
+//	D2 ~> P1
+func Parse(r io.Reader) (*pfd.PFD, error) {
+	f, err := ParseFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdtext.Parse: %w", err)
+	}
+
+	p, err := NewPFDByFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("pfdtext.Parse: %w", err)
+	}
+	return p, nil
+}
+
+// ParseFile parses a PFD text into a syntax tree. The returned error is Error if the text has syntax errors.
+func ParseFile(r io.Reader) (*File, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdtext.ParseFile: %w", err)
+	}
+
+	tokens, err := tokenize(string(bs))
+	if err != nil {
+		return nil, err
+	}
+
+	ps := &parser{tokens: tokens}
+	return ps.parseFile()
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (ps *parser) peek() token {
+	return ps.tokens[ps.pos]
+}
+
+func (ps *parser) next() token {
+	t := ps.tokens[ps.pos]
+	if t.Kind != tokenEOF {
+		ps.pos++
+	}
+	return t
+}
+
+func (ps *parser) isKeyword(keyword string) bool {
+	t := ps.peek()
+	return t.Kind == tokenIdent && t.Text == keyword
+}
+
+func (ps *parser) errorAt(t token, message string) Error {
+	return Error{Position: t.Position, Message: message}
+}
+
+func (ps *parser) unexpected(t token, expected string) Error {
+	return ps.errorAt(t, fmt.Sprintf("expected %s, found %s", expected, t.Kind))
+}
+
+// leading reads newlines and comments before a statement. The newline ending the previous statement is not consumed
+// yet, so two or more newlines mean a blank line.
+func (ps *parser) leading() Leading {
+	l := Leading{}
+	newlines := 0
+	for {
+		t := ps.peek()
+		switch t.Kind {
+		case tokenNewline:
+			newlines++
+			ps.next()
+		case tokenComment:
+			if len(l.Comments) == 0 && newlines >= 2 {
+				l.BlankLine = true
+			}
+			l.Comments = append(l.Comments, t.Text)
+			newlines = 0
+			ps.next()
+		default:
+			if len(l.Comments) == 0 && newlines >= 2 {
+				l.BlankLine = true
+			}
+			return l
+		}
+	}
+}
+
+func (ps *parser) endOfStatement() error {
+	t := ps.peek()
+	switch t.Kind {
+	case tokenNewline, tokenEOF:
+		return nil
+	case tokenComment:
+		return ps.errorAt(t, "comments must be on their own lines")
+	default:
+		return ps.unexpected(t, "newline")
+	}
+}
+
+func (ps *parser) parseFile() (*File, error) {
+	f := &File{}
+
+	l := ps.leading()
+	f.Comments = l.Comments
+
+	if !ps.isKeyword(keywordPFD) {
+		return nil, ps.unexpected(ps.peek(), "pfd header")
+	}
+	ps.next()
+	if ps.peek().Kind == tokenString {
+		f.Title = ps.next().Text
+		f.HasTitle = true
+	}
+	if err := ps.endOfStatement(); err != nil {
+		return nil, err
+	}
+
+	stmts, trailing, err := ps.parseStatements(false)
+	if err != nil {
+		return nil, err
+	}
+	f.Statements = stmts
+	f.TrailingComments = trailing
+	return f, nil
+}
+
+// parseStatements parses statements until the end of file or the end of block.
+func (ps *parser) parseStatements(inBlock bool) ([]Statement, []string, error) {
+	stmts := make([]Statement, 0)
+	for {
+		l := ps.leading()
+		t := ps.peek()
+
+		switch t.Kind {
+		case tokenEOF:
+			if inBlock {
+				return nil, nil, ps.unexpected(t, "}")
+			}
+			return stmts, l.Comments, nil
+		case tokenRBrace:
+			if !inBlock {
+				return nil, nil, ps.errorAt(t, "unexpected }")
+			}
+			return stmts, l.Comments, nil
+		}
+
+		var stmt Statement
+		var err error
+		if ps.isKeyword(keywordComposite) || ps.isKeyword(keywordDeliverable) || ps.isKeyword(keywordProcess) {
+			stmt, err = ps.parseNodeDecl(l)
+		} else {
+			stmt, err = ps.parseEdgeChain(l)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := ps.endOfStatement(); err != nil {
+			return nil, nil, err
+		}
+		stmts = append(stmts, stmt)
+	}
+}
+
+func (ps *parser) parseIdent() (Ident, error) {
+	t := ps.peek()
+	switch t.Kind {
+	case tokenIdent:
+		if isKeyword(t.Text) {
+			return Ident{}, ps.errorAt(t, fmt.Sprintf("keyword %q cannot be used as node ID without quotes", t.Text))
+		}
+	case tokenString:
+		if t.Text == "" {
+			return Ident{}, ps.errorAt(t, "empty node ID")
+		}
+	default:
+		return Ident{}, ps.unexpected(t, "node ID")
+	}
+	ps.next()
+	return Ident{Position: t.Position, Name: t.Text}, nil
+}
+
+func (ps *parser) parseNodeDecl(l Leading) (*NodeDecl, error) {
+	decl := &NodeDecl{Position: ps.peek().Position, LeadingInfo: l}
+
+	if ps.isKeyword(keywordComposite) {
+		ps.next()
+		decl.Composite = true
+	}
+
+	switch {
+	case ps.isKeyword(keywordDeliverable):
+		decl.Kind = NodeKindDeliverable
+	case ps.isKeyword(keywordProcess):
+		decl.Kind = NodeKindProcess
+	default:
+		return nil, ps.unexpected(ps.peek(), "deliverable or process")
+	}
+	ps.next()
+
+	id, err := ps.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	decl.ID = id
+
+	if ps.peek().Kind == tokenString {
+		decl.Description = ps.next().Text
+		decl.HasDescription = true
+	}
+
+	t := ps.peek()
+	switch t.Kind {
+	case tokenEquals:
+		if !decl.Composite || decl.Kind != NodeKindDeliverable {
+			return nil, ps.errorAt(t, "only composite deliverables can have members")
+		}
+		ps.next()
+		for {
+			member, err := ps.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			decl.Members = append(decl.Members, member)
+			if ps.peek().Kind != tokenComma {
+				break
+			}
+			ps.next()
+		}
+	case tokenLBrace:
+		if !decl.Composite || decl.Kind != NodeKindProcess {
+			return nil, ps.errorAt(t, "only composite processes can have blocks")
+		}
+		ps.next()
+		if ps.peek().Kind != tokenNewline {
+			return nil, ps.unexpected(ps.peek(), "newline")
+		}
+		body, trailing, err := ps.parseStatements(true)
+		if err != nil {
+			return nil, err
+		}
+		ps.next()
+		decl.Body = body
+		decl.BodyTrailingComments = trailing
+	}
+
+	return decl, nil
+}
+
+func (ps *parser) parseEdgeChain(l Leading) (*EdgeChain, error) {
+	chain := &EdgeChain{Position: ps.peek().Position, LeadingInfo: l}
+
+	first, err := ps.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	chain.Nodes = append(chain.Nodes, first)
+
+	for {
+		t := ps.peek()
+		switch t.Kind {
+		case tokenArrow:
+			chain.IsFeedback = append(chain.IsFeedback, false)
+		case tokenFeedbackArrow:
+			chain.IsFeedback = append(chain.IsFeedback, true)
+		default:
+			if len(chain.Nodes) == 1 {
+				return nil, ps.unexpected(t, "-> or ~>")
+			}
+			return chain, nil
+		}
+		ps.next()
+
+		node, err := ps.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		chain.Nodes = append(chain.Nodes, node)
+	}
+}
+
+// NewPFDByFile converts the syntax tree to a PFD. The returned error is Errors if the tree has undeclared or duplicated
+// nodes. Edges to composite deliverables are expanded to their members as draw.io diagrams are.
+func NewPFDByFile(f *File) (*pfd.PFD, error) {
+	errs := make(Errors, 0)
+
+	nodes := sets.New((*pfd.Node).Compare)
+	declared := make(map[pfd.NodeID]*NodeDecl)
+	processComposition := make(map[pfd.NodeID]*sets.Set[pfd.NodeID])
+	deliverableComposition := make(map[pfd.NodeID]*sets.Set[pfd.NodeID])
+	members := make([]*NodeDecl, 0)
+	chains := make([]*EdgeChain, 0)
+
+	var walk func(stmts []Statement, parent pfd.NodeID)
+	walk = func(stmts []Statement, parent pfd.NodeID) {
+		for _, stmt := range stmts {
+			switch stmt := stmt.(type) {
+			case *NodeDecl:
+				id := pfd.NodeID(stmt.ID.Name)
+				if prev, ok := declared[id]; ok {
+					errs = append(errs, Error{
+						Position: stmt.ID.Position,
+						Message:  fmt.Sprintf("duplicated node ID %q (previous declaration at %d:%d)", id, prev.ID.Position.Line, prev.ID.Position.Column),
+					})
+					continue
+				}
+				declared[id] = stmt
+
+				var t pfd.NodeType
+				switch {
+				case stmt.Kind == NodeKindDeliverable && stmt.Composite:
+					t = pfd.NodeTypeCompositeDeliverable
+				case stmt.Kind == NodeKindDeliverable:
+					t = pfd.NodeTypeAtomicDeliverable
+				case stmt.Kind == NodeKindProcess && stmt.Composite:
+					t = pfd.NodeTypeCompositeProcess
+				default:
+					t = pfd.NodeTypeAtomicProcess
+				}
+				nodes.Add((*pfd.Node).Compare, &pfd.Node{ID: id, Description: stmt.Description, Type: t})
+
+				if stmt.Kind == NodeKindProcess && parent != pfd.NodeIDContextDiagram {
+					processComposition[parent].Add(pfd.NodeID.Compare, id)
+				}
+				if stmt.Members != nil {
+					members = append(members, stmt)
+				}
+				if stmt.Body != nil {
+					processComposition[id] = sets.New(pfd.NodeID.Compare)
+					walk(stmt.Body, id)
+				}
+			case *EdgeChain:
+				chains = append(chains, stmt)
+			default:
+				panic(fmt.Sprintf("pfdtext.NewPFDByFile: unknown statement: %T", stmt))
+			}
+		}
+	}
+	walk(f.Statements, pfd.NodeIDContextDiagram)
+
+	for _, decl := range members {
+		s := sets.NewWithCapacity[pfd.NodeID](len(decl.Members))
+		for _, member := range decl.Members {
+			memberDecl, ok := declared[pfd.NodeID(member.Name)]
+			if !ok {
+				errs = append(errs, Error{Position: member.Position, Message: fmt.Sprintf("undeclared node %q", member.Name)})
+				continue
+			}
+			if memberDecl.Kind != NodeKindDeliverable {
+				errs = append(errs, Error{Position: member.Position, Message: fmt.Sprintf("%q is not a deliverable", member.Name)})
+				continue
+			}
+			s.Add(pfd.NodeID.Compare, pfd.NodeID(member.Name))
+		}
+		deliverableComposition[pfd.NodeID(decl.ID.Name)] = s
+	}
+
+	edges := sets.New((*pfd.Edge).Compare)
+	for _, chain := range chains {
+		ok := true
+		for _, node := range chain.Nodes {
+			if _, found := declared[pfd.NodeID(node.Name)]; !found {
+				errs = append(errs, Error{Position: node.Position, Message: fmt.Sprintf("undeclared node %q", node.Name)})
+				ok = false
+			}
+		}
+		if !ok {
+			continue
+		}
+		for i, isFeedback := range chain.IsFeedback {
+			edges.Add((*pfd.Edge).Compare, &pfd.Edge{
+				Source:     pfd.NodeID(chain.Nodes[i].Name),
+				Target:     pfd.NodeID(chain.Nodes[i+1].Name),
+				IsFeedback: isFeedback,
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	edges = pfd.ExpandCompositeDeliverableEdges(edges, deliverableComposition)
+	return pfd.NewPFD(f.Title, nodes, edges, processComposition, deliverableComposition), nil
+}
@@ -0,0 +1,176 @@
+package pfdtext
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	input := `// Example
+pfd "Composite"
+
+deliverable D1 "Spec"
+deliverable D2 "Code"
+deliverable D3 "Review"
+composite deliverable D4 "All" = D2, D3
+
+composite process P3 "Develop" {
+    process P1 "Implement"
+    process P2 "Review"
+}
+process P4 "Release"
+process "Unnumbered process"
+
+D1 -> P1 -> D2 -> P2 -> D3
+D3 ~> P1
+D4 -> P4
+D1 -> "Unnumbered process"
+`
+
+	actual, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &pfd.PFD{
+		Title: "Composite",
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Description: "Spec", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D2", Description: "Code", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D3", Description: "Review", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D4", Description: "All", Type: pfd.NodeTypeCompositeDeliverable},
+			&pfd.Node{ID: "P1", Description: "Implement", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "P2", Description: "Review", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "P3", Description: "Develop", Type: pfd.NodeTypeCompositeProcess},
+			&pfd.Node{ID: "P4", Description: "Release", Type: pfd.NodeTypeAtomicProcess},
+			&pfd.Node{ID: "Unnumbered process", Type: pfd.NodeTypeAtomicProcess},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D1", Target: "P1"},
+			&pfd.Edge{Source: "P1", Target: "D2"},
+			&pfd.Edge{Source: "D2", Target: "P2"},
+			&pfd.Edge{Source: "P2", Target: "D3"},
+			&pfd.Edge{Source: "D3", Target: "P1", IsFeedback: true},
+			&pfd.Edge{Source: "D4", Target: "P4"},
+			&pfd.Edge{Source: "D2", Target: "P4"},
+			&pfd.Edge{Source: "D3", Target: "P4"},
+			&pfd.Edge{Source: "D1", Target: "Unnumbered process"},
+		),
+		ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+			"P3": sets.New(pfd.NodeID.Compare, "P1", "P2"),
+		},
+		DeliverableComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+			"D4": sets.New(pfd.NodeID.Compare, "D2", "D3"),
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error(cmp.Diff(expected, actual))
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for name, p := range pfd.PresetsAll {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			if err := Write(sb, p); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := Parse(strings.NewReader(sb.String()))
+			if err != nil {
+				t.Log(sb.String())
+				t.Fatal(err)
+			}
+
+			expected := pfd.NewPFD(p.Title, p.Nodes, p.Edges, p.ProcessComposition, p.DeliverableComposition)
+			if expected.ProcessComposition == nil {
+				expected.ProcessComposition = map[pfd.NodeID]*sets.Set[pfd.NodeID]{}
+			}
+			if expected.DeliverableComposition == nil {
+				expected.DeliverableComposition = map[pfd.NodeID]*sets.Set[pfd.NodeID]{}
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Log(sb.String())
+				t.Error(cmp.Diff(expected, actual))
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	testCases := map[string]struct {
+		Input    string
+		Expected []Error
+	}{
+		"missing header": {
+			Input:    "deliverable D1\n",
+			Expected: []Error{{Position: Position{Line: 1, Column: 1}, Message: "expected pfd header, found identifier"}},
+		},
+		"unterminated string": {
+			Input:    "pfd\ndeliverable D1 \"Spec\n",
+			Expected: []Error{{Position: Position{Line: 2, Column: 16}, Message: "unterminated string"}},
+		},
+		"unexpected character": {
+			Input:    "pfd\nD1 <- P1\n",
+			Expected: []Error{{Position: Position{Line: 2, Column: 4}, Message: "unexpected character: '<'"}},
+		},
+		"missing arrow": {
+			Input:    "pfd\nD1 P1\n",
+			Expected: []Error{{Position: Position{Line: 2, Column: 4}, Message: "expected -> or ~>, found identifier"}},
+		},
+		"missing node after arrow": {
+			Input:    "pfd\nD1 ->\n",
+			Expected: []Error{{Position: Position{Line: 2, Column: 6}, Message: "expected node ID, found newline"}},
+		},
+		"members of process": {
+			Input:    "pfd\ncomposite process P1 = P2\n",
+			Expected: []Error{{Position: Position{Line: 2, Column: 22}, Message: "only composite deliverables can have members"}},
+		},
+		"unclosed block": {
+			Input:    "pfd\ncomposite process P1 {\n    process P2\n",
+			Expected: []Error{{Position: Position{Line: 4, Column: 1}, Message: "expected }, found end of file"}},
+		},
+		"duplicated node": {
+			Input: "pfd\ndeliverable D1\nprocess D1\n",
+			Expected: []Error{
+				{Position: Position{Line: 3, Column: 9}, Message: "duplicated node ID \"D1\" (previous declaration at 2:13)"},
+			},
+		},
+		"undeclared nodes": {
+			Input: "pfd\ndeliverable D1\ncomposite deliverable D2 = D1, D3\nD1 -> P1\n",
+			Expected: []Error{
+				{Position: Position{Line: 3, Column: 32}, Message: "undeclared node \"D3\""},
+				{Position: Position{Line: 4, Column: 7}, Message: "undeclared node \"P1\""},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(testCase.Input))
+
+			var single Error
+			var multiple Errors
+			var actual []Error
+			switch {
+			case errors.As(err, &single):
+				actual = []Error{single}
+			case errors.As(err, &multiple):
+				actual = multiple
+			default:
+				t.Fatalf("want Error or Errors, got %v", err)
+			}
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package pfdfix
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
+	"github.com/Kuniwak/pfd-tools/xmldom"
+)
+
+// IsFixableDrawIO returns true if the file is a draw.io file. Editable PNG and SVG files are not fixable because
+// draw.io cannot open them after rewriting the embedded mxfile without the images.
+func IsFixableDrawIO(bs []byte) bool {
+	format, _, err := pfdfmt.Detect(bytes.NewReader(bs))
+	if err != nil || format != pfdfmt.FormatDrawio {
+		return false
+	}
+	return !bytes.HasPrefix(bs, []byte(pfddrawio.PNGSignature)) && !pfddrawio.IsSVG(bs)
+}
+
+type drawIO struct {
+	nodes  []*xmldom.Node
+	srcMap *pfddrawio.SourceMap
+	cells  map[pfddrawio.DrawIOLocation]*xmldom.Node
+	dirty  bool
+}
+
+func newDrawIO(bs []byte, logger *slog.Logger) (*drawIO, error) {
+	nodes, err := xmldom.ParseXMLPreservingFormat(bytes.NewReader(bs))
+	if err != nil {
+		return nil, fmt.Errorf("pfdfix.newDrawIO: %w", err)
+	}
+
+	// NOTE: Compressed diagrams are decompressed in the tree, so they are written back decompressed like Renumber.
+	diagrams, err := pfddrawio.ParseNodes(nodes, logger)
+	if err != nil {
+		return nil, fmt.Errorf("pfdfix.newDrawIO: %w", err)
+	}
+
+	_, srcMap, err := pfddrawio.NormalizeDiagrams("", diagrams, logger)
+	if err != nil {
+		return nil, fmt.Errorf("pfdfix.newDrawIO: %w", err)
+	}
+
+	cells := make(map[pfddrawio.DrawIOLocation]*xmldom.Node)
+	var diagramID pfddrawio.DiagramID
+	for _, node := range nodes {
+		node.Traverse(func(n *xmldom.Node) {
+			switch n.Start.Name.Local {
+			case "diagram":
+				id, _ := n.GetAttr("id", "")
+				diagramID = pfddrawio.DiagramID(id)
+			case "mxCell":
+				id, ok := n.GetAttr("id", "")
+				if !ok {
+					return
+				}
+				cells[pfddrawio.DrawIOLocation{DiagramID: diagramID, CellID: pfddrawio.CellID(id)}] = n
+			}
+		}, nil)
+	}
+
+	return &drawIO{nodes: nodes, srcMap: srcMap, cells: cells}, nil
+}
+
+func (d *drawIO) prepareSetDescription(id pfd.NodeID, desc string) (func(), bool) {
+	locs, ok := d.srcMap.NodeIDMap[id]
+	if !ok || locs.Len() == 0 {
+		return nil, false
+	}
+
+	cells := make([]*xmldom.Node, 0, locs.Len())
+	for _, loc := range locs.Iter() {
+		cell, ok := d.cells[loc]
+		if !ok {
+			return nil, false
+		}
+		cells = append(cells, cell)
+	}
+
+	text := string(id)
+	if desc != "" {
+		text = fmt.Sprintf("%s: %s", id, desc)
+	}
+
+	return func() {
+		for _, cell := range cells {
+			value := text
+			if style, ok := cell.GetAttr("style", ""); ok {
+				if styleMap, err := pfddrawio.ParseStyle(style); err == nil && styleMap.Get("html") == "1" {
+					// NOTE: Values are HTML if styles have html=1.
+					value = strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+				}
+			}
+			setAttr(cell, "value", value)
+		}
+		d.dirty = true
+	}, true
+}
+
+func (d *drawIO) prepareSetFeedback(source, target pfd.NodeID, isFeedback bool) (func(), bool) {
+	locs, ok := d.srcMap.EdgeIDMap[source][target]
+	if !ok || locs.Len() == 0 {
+		// NOTE: Edges expanded from composite deliverables have no cells.
+		return nil, false
+	}
+
+	cells := make([]*xmldom.Node, 0, locs.Len())
+	for _, loc := range locs.Iter() {
+		cell, ok := d.cells[loc]
+		if !ok {
+			return nil, false
+		}
+		cells = append(cells, cell)
+	}
+
+	return func() {
+		for _, cell := range cells {
+			style, _ := cell.GetAttr("style", "")
+			// NOTE: Edges with dashed=0 are also feedback edges for draw.io parsers, so remove the key entirely.
+			style = removeStyle(style, "dashed")
+			if isFeedback {
+				style = addStyle(style, "dashed", "1")
+			}
+			setAttr(cell, "style", style)
+		}
+		d.dirty = true
+	}, true
+}
+
+func (d *drawIO) bytes() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, node := range d.nodes {
+		if err := node.WritePreservingFormat(buf); err != nil {
+			return nil, fmt.Errorf("pfdfix.drawIO.bytes: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func setAttr(node *xmldom.Node, local string, value string) {
+	for i, attr := range node.Start.Attr {
+		if attr.Name.Local == local && attr.Name.Space == "" {
+			node.Start.Attr[i].Value = value
+			return
+		}
+	}
+	node.Start.Attr = append(node.Start.Attr, xml.Attr{Name: xml.Name{Local: local}, Value: value})
+}
+
+// removeStyle removes the key from the style without reordering the other keys.
+func removeStyle(style string, name string) string {
+	sb := &strings.Builder{}
+	for _, item := range strings.Split(style, ";") {
+		if item == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(item, "=")
+		if key == name {
+			continue
+		}
+		sb.WriteString(item)
+		sb.WriteString(";")
+	}
+	return sb.String()
+}
+
+func addStyle(style string, name string, value string) string {
+	if style != "" && !strings.HasSuffix(style, ";") {
+		style += ";"
+	}
+	return fmt.Sprintf("%s%s=%s;", style, name, value)
+}
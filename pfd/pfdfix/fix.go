@@ -0,0 +1,273 @@
+package pfdfix
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
+)
+
+// Files is the contents of the files that fixes are applied to. Nil contents are not fixed. Fixed tables are written
+// in the same formats as the original ones.
+type Files struct {
+	PFD                    []byte
+	AtomicProcessTable     []byte
+	AtomicDeliverableTable []byte
+	CompositeProcessTable  []byte
+}
+
+// Apply applies the first applicable fix of each problem and returns the fixed contents and the problems that are
+// not fixed. Fixes of PFDs are applicable only to draw.io files, and editable PNG and SVG files are not fixed.
+func Apply(files *Files, problems []checkers.Problem, logger *slog.Logger) (*Files, []checkers.Problem, error) {
+	f, err := newFixer(files, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pfdfix.Apply: %w", err)
+	}
+
+	unfixed := make([]checkers.Problem, 0)
+	for _, problem := range problems {
+		if !f.fix(problem.Fixes) {
+			unfixed = append(unfixed, problem)
+		}
+	}
+
+	res, err := f.files()
+	if err != nil {
+		return nil, nil, fmt.Errorf("pfdfix.Apply: %w", err)
+	}
+	return res, unfixed, nil
+}
+
+type fixer struct {
+	original *Files
+
+	drawIO *drawIO
+
+	atomicProcessTable          *pfd.AtomicProcessTable
+	atomicProcessTableDirty     bool
+	atomicDeliverableTable      *pfd.AtomicDeliverableTable
+	atomicDeliverableTableDirty bool
+	compositeProcessTable       *pfd.CompositeProcessTable
+	compositeProcessTableDirty  bool
+}
+
+func newFixer(files *Files, logger *slog.Logger) (*fixer, error) {
+	f := &fixer{original: files}
+
+	if files.PFD != nil && IsFixableDrawIO(files.PFD) {
+		d, err := newDrawIO(files.PFD, logger)
+		if err != nil {
+			return nil, fmt.Errorf("pfdfix.newFixer: %w", err)
+		}
+		f.drawIO = d
+	}
+
+	if files.AtomicProcessTable != nil {
+		t, err := pfdtableencoding.ParseAtomicProcessTable(bytes.NewReader(files.AtomicProcessTable))
+		if err != nil {
+			return nil, fmt.Errorf("pfdfix.newFixer: %w", err)
+		}
+		f.atomicProcessTable = t
+	}
+
+	if files.AtomicDeliverableTable != nil {
+		t, err := pfdtableencoding.ParseAtomicDeliverableTable(bytes.NewReader(files.AtomicDeliverableTable))
+		if err != nil {
+			return nil, fmt.Errorf("pfdfix.newFixer: %w", err)
+		}
+		f.atomicDeliverableTable = t
+	}
+
+	if files.CompositeProcessTable != nil {
+		t, err := pfdtableencoding.ParseCompositeProcessTable(bytes.NewReader(files.CompositeProcessTable))
+		if err != nil {
+			return nil, fmt.Errorf("pfdfix.newFixer: %w", err)
+		}
+		f.compositeProcessTable = t
+	}
+
+	return f, nil
+}
+
+// fix applies the first fix that all the edits are applicable. It returns false if no fixes are applicable.
+func (f *fixer) fix(fixes []checkers.Fix) bool {
+	for _, fix := range fixes {
+		applies := make([]func(), 0, len(fix.Edits))
+		applicable := true
+		for _, edit := range fix.Edits {
+			apply, ok := f.prepare(edit)
+			if !ok {
+				applicable = false
+				break
+			}
+			applies = append(applies, apply)
+		}
+		if !applicable {
+			continue
+		}
+
+		for _, apply := range applies {
+			apply()
+		}
+		return true
+	}
+	return false
+}
+
+// prepare returns the function that applies the edit. It returns false if the edit is not applicable.
+func (f *fixer) prepare(edit checkers.Edit) (func(), bool) {
+	switch e := edit.(type) {
+	case pfdcommon.EditSetDescription:
+		if f.drawIO == nil {
+			return nil, false
+		}
+		return f.drawIO.prepareSetDescription(e.ID, e.Description)
+
+	case pfdcommon.EditSetFeedback:
+		if f.drawIO == nil {
+			return nil, false
+		}
+		return f.drawIO.prepareSetFeedback(e.Source, e.Target, e.IsFeedback)
+
+	case pfdcommon.EditAddRow:
+		return f.prepareAddRow(e)
+
+	case pfdcommon.EditRemoveRow:
+		return f.prepareRemoveRow(e)
+
+	default:
+		return nil, false
+	}
+}
+
+func (f *fixer) prepareAddRow(e pfdcommon.EditAddRow) (func(), bool) {
+	switch e.Table {
+	case pfd.TableTypeAtomicProcess:
+		t := f.atomicProcessTable
+		if t == nil {
+			return nil, false
+		}
+		return func() {
+			row := &pfd.AtomicProcessRow{ID: pfd.AtomicProcessID(e.ID), Description: e.Description, ExtraCells: make([]string, len(t.ExtraHeaders))}
+			if rows, ok := addRow(t.Rows, row, func(row *pfd.AtomicProcessRow) pfd.NodeID { return pfd.NodeID(row.ID) }); ok {
+				t.Rows = rows
+				f.atomicProcessTableDirty = true
+			}
+		}, true
+
+	case pfd.TableTypeAtomicDeliverable:
+		t := f.atomicDeliverableTable
+		if t == nil {
+			return nil, false
+		}
+		return func() {
+			row := &pfd.AtomicDeliverableRow{ID: pfd.AtomicDeliverableID(e.ID), Description: e.Description, ExtraCells: make([]string, len(t.ExtraHeaders))}
+			if rows, ok := addRow(t.Rows, row, func(row *pfd.AtomicDeliverableRow) pfd.NodeID { return pfd.NodeID(row.ID) }); ok {
+				t.Rows = rows
+				f.atomicDeliverableTableDirty = true
+			}
+		}, true
+
+	case pfd.TableTypeCompositeProcess:
+		t := f.compositeProcessTable
+		if t == nil {
+			return nil, false
+		}
+		return func() {
+			row := &pfd.CompositeProcessRow{ID: pfd.CompositeProcessID(e.ID), Description: e.Description, ExtraCells: make([]string, len(t.ExtraHeaders))}
+			if rows, ok := addRow(t.Rows, row, func(row *pfd.CompositeProcessRow) pfd.NodeID { return pfd.NodeID(row.ID) }); ok {
+				t.Rows = rows
+				f.compositeProcessTableDirty = true
+			}
+		}, true
+
+	default:
+		return nil, false
+	}
+}
+
+func (f *fixer) prepareRemoveRow(e pfdcommon.EditRemoveRow) (func(), bool) {
+	switch e.Table {
+	case pfd.TableTypeAtomicProcess:
+		t := f.atomicProcessTable
+		if t == nil {
+			return nil, false
+		}
+		return func() {
+			if rows, ok := removeRows(t.Rows, e.ID, func(row *pfd.AtomicProcessRow) pfd.NodeID { return pfd.NodeID(row.ID) }); ok {
+				t.Rows = rows
+				f.atomicProcessTableDirty = true
+			}
+		}, true
+
+	case pfd.TableTypeAtomicDeliverable:
+		t := f.atomicDeliverableTable
+		if t == nil {
+			return nil, false
+		}
+		return func() {
+			if rows, ok := removeRows(t.Rows, e.ID, func(row *pfd.AtomicDeliverableRow) pfd.NodeID { return pfd.NodeID(row.ID) }); ok {
+				t.Rows = rows
+				f.atomicDeliverableTableDirty = true
+			}
+		}, true
+
+	case pfd.TableTypeCompositeProcess:
+		t := f.compositeProcessTable
+		if t == nil {
+			return nil, false
+		}
+		return func() {
+			if rows, ok := removeRows(t.Rows, e.ID, func(row *pfd.CompositeProcessRow) pfd.NodeID { return pfd.NodeID(row.ID) }); ok {
+				t.Rows = rows
+				f.compositeProcessTableDirty = true
+			}
+		}, true
+
+	default:
+		return nil, false
+	}
+}
+
+// files returns the contents. Contents without applied fixes are the same as the original ones.
+func (f *fixer) files() (*Files, error) {
+	res := *f.original
+
+	if f.drawIO != nil && f.drawIO.dirty {
+		bs, err := f.drawIO.bytes()
+		if err != nil {
+			return nil, fmt.Errorf("pfdfix.fixer.files: %w", err)
+		}
+		res.PFD = bs
+	}
+
+	if f.atomicProcessTableDirty {
+		bs, err := pfdtableencoding.RewriteAtomicProcessTable(f.original.AtomicProcessTable, f.atomicProcessTable)
+		if err != nil {
+			return nil, fmt.Errorf("pfdfix.fixer.files: %w", err)
+		}
+		res.AtomicProcessTable = bs
+	}
+
+	if f.atomicDeliverableTableDirty {
+		bs, err := pfdtableencoding.RewriteAtomicDeliverableTable(f.original.AtomicDeliverableTable, f.atomicDeliverableTable)
+		if err != nil {
+			return nil, fmt.Errorf("pfdfix.fixer.files: %w", err)
+		}
+		res.AtomicDeliverableTable = bs
+	}
+
+	if f.compositeProcessTableDirty {
+		bs, err := pfdtableencoding.RewriteCompositeProcessTable(f.original.CompositeProcessTable, f.compositeProcessTable)
+		if err != nil {
+			return nil, fmt.Errorf("pfdfix.fixer.files: %w", err)
+		}
+		res.CompositeProcessTable = bs
+	}
+
+	return &res, nil
+}
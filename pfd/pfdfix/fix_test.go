@@ -0,0 +1,145 @@
+package pfdfix
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+const drawIOFile = `<mxfile host="65bd71144e">
+    <diagram id="d" name="P0">
+        <mxGraphModel dx="734" dy="530">
+            <root>
+                <mxCell id="0"/>
+                <mxCell id="1" parent="0"/>
+                <mxCell id="2" value="D1: Spec" style="rounded=0;whiteSpace=wrap;html=1;" vertex="1" parent="1">
+                    <mxGeometry x="320" y="240" width="120" height="80" as="geometry"/>
+                </mxCell>
+                <mxCell id="3" value="P1: Implement" style="ellipse;whiteSpace=wrap;html=1;" vertex="1" parent="1">
+                    <mxGeometry x="480" y="240" width="120" height="80" as="geometry"/>
+                </mxCell>
+                <mxCell id="4" value="D2: Code" style="rounded=0;whiteSpace=wrap;html=1;" vertex="1" parent="1">
+                    <mxGeometry x="640" y="240" width="120" height="80" as="geometry"/>
+                </mxCell>
+                <mxCell id="5" value="D2: Source &amp; tests" style="rounded=0;whiteSpace=wrap;html=1;" vertex="1" parent="1">
+                    <mxGeometry x="640" y="400" width="120" height="80" as="geometry"/>
+                </mxCell>
+                <mxCell id="6" style="edgeStyle=orthogonalEdgeStyle;html=1;" edge="1" parent="1" source="2" target="3">
+                    <mxGeometry relative="1" as="geometry"/>
+                </mxCell>
+                <mxCell id="7" style="edgeStyle=orthogonalEdgeStyle;dashed=1;html=1;" edge="1" parent="1" source="3" target="4">
+                    <mxGeometry relative="1" as="geometry"/>
+                </mxCell>
+            </root>
+        </mxGraphModel>
+    </diagram>
+</mxfile>
+`
+
+func TestApply(t *testing.T) {
+	files := &Files{
+		PFD:                    []byte(drawIOFile),
+		AtomicProcessTable:     []byte("ID\tDescription\tEst. Work Volume\nP0\tUnknown\t1\nP2\tRemoved\t1\n"),
+		AtomicDeliverableTable: []byte("ID\tDescription\nD1\tSpec\nD2\tCode\n"),
+	}
+
+	problems := []checkers.Problem{
+		checkers.NewProblem("consistent-desc", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D2"))...).
+			WithFixes(checkers.NewFix(pfdcommon.EditSetDescription{ID: "D2", Description: "Source & tests"})),
+		checkers.NewProblem("no-p2d-fb", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "P1", "D2"))...).
+			WithFixes(checkers.NewFix(pfdcommon.EditSetFeedback{Source: "P1", Target: "D2", IsFeedback: false})),
+		checkers.NewProblem("missing-ap-table", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, "P1"))...).
+			WithFixes(checkers.NewFix(pfdcommon.EditAddRow{Table: pfd.TableTypeAtomicProcess, ID: "P1", Description: "Implement"})),
+		checkers.NewProblem("extra-ap-table", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, "P2"))...).
+			WithFixes(checkers.NewFix(pfdcommon.EditRemoveRow{Table: pfd.TableTypeAtomicProcess, ID: "P2"})),
+		checkers.NewProblem("missing-cp-table", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeCompositeProcessTable, "P3"))...).
+			WithFixes(checkers.NewFix(pfdcommon.EditAddRow{Table: pfd.TableTypeCompositeProcess, ID: "P3"})),
+		checkers.NewProblem("no-desc", checkers.SeverityWarning, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D1"))...),
+	}
+
+	actual, unfixed, err := Apply(files, problems, slog.New(slogtest.NewTestHandler(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPFD := `<mxfile host="65bd71144e">
+    <diagram id="d" name="P0">
+        <mxGraphModel dx="734" dy="530">
+            <root>
+                <mxCell id="0"/>
+                <mxCell id="1" parent="0"/>
+                <mxCell id="2" value="D1: Spec" style="rounded=0;whiteSpace=wrap;html=1;" vertex="1" parent="1">
+                    <mxGeometry x="320" y="240" width="120" height="80" as="geometry"/>
+                </mxCell>
+                <mxCell id="3" value="P1: Implement" style="ellipse;whiteSpace=wrap;html=1;" vertex="1" parent="1">
+                    <mxGeometry x="480" y="240" width="120" height="80" as="geometry"/>
+                </mxCell>
+                <mxCell id="4" value="D2: Source &amp;amp; tests" style="rounded=0;whiteSpace=wrap;html=1;" vertex="1" parent="1">
+                    <mxGeometry x="640" y="240" width="120" height="80" as="geometry"/>
+                </mxCell>
+                <mxCell id="5" value="D2: Source &amp;amp; tests" style="rounded=0;whiteSpace=wrap;html=1;" vertex="1" parent="1">
+                    <mxGeometry x="640" y="400" width="120" height="80" as="geometry"/>
+                </mxCell>
+                <mxCell id="6" style="edgeStyle=orthogonalEdgeStyle;html=1;" edge="1" parent="1" source="2" target="3">
+                    <mxGeometry relative="1" as="geometry"/>
+                </mxCell>
+                <mxCell id="7" style="edgeStyle=orthogonalEdgeStyle;html=1;" edge="1" parent="1" source="3" target="4">
+                    <mxGeometry relative="1" as="geometry"/>
+                </mxCell>
+            </root>
+        </mxGraphModel>
+    </diagram>
+</mxfile>
+`
+	if string(actual.PFD) != expectedPFD {
+		t.Error(cmp.Diff(expectedPFD, string(actual.PFD)))
+	}
+
+	expectedAPTable := "ID\tDescription\tEst. Work Volume\nP0\tUnknown\t1\nP1\tImplement\t\n"
+	if string(actual.AtomicProcessTable) != expectedAPTable {
+		t.Error(cmp.Diff(expectedAPTable, string(actual.AtomicProcessTable)))
+	}
+
+	// NOTE: Tables without applied fixes are kept as is.
+	if string(actual.AtomicDeliverableTable) != string(files.AtomicDeliverableTable) {
+		t.Error(cmp.Diff(string(files.AtomicDeliverableTable), string(actual.AtomicDeliverableTable)))
+	}
+
+	expectedUnfixed := []checkers.ProblemID{"missing-cp-table", "no-desc"}
+	actualUnfixed := make([]checkers.ProblemID, 0, len(unfixed))
+	for _, problem := range unfixed {
+		actualUnfixed = append(actualUnfixed, problem.ProblemID)
+	}
+	if !cmp.Equal(expectedUnfixed, actualUnfixed) {
+		t.Error(cmp.Diff(expectedUnfixed, actualUnfixed))
+	}
+}
+
+func TestApplyCSV(t *testing.T) {
+	files := &Files{
+		AtomicProcessTable: []byte("ID,Description\nP2,Removed\n"),
+	}
+
+	problems := []checkers.Problem{
+		checkers.NewProblem("missing-ap-table", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, "P1"))...).
+			WithFixes(checkers.NewFix(pfdcommon.EditAddRow{Table: pfd.TableTypeAtomicProcess, ID: "P1", Description: "Implement, test"})),
+		checkers.NewProblem("extra-ap-table", checkers.SeverityError, pfdcommon.NewLocations(pfdcommon.NewLocation(pfdcommon.LocationTypeAtomicProcessTable, "P2"))...).
+			WithFixes(checkers.NewFix(pfdcommon.EditRemoveRow{Table: pfd.TableTypeAtomicProcess, ID: "P2"})),
+	}
+
+	actual, _, err := Apply(files, problems, slog.New(slogtest.NewTestHandler(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: Fixed tables are written in the same format as the original ones.
+	expectedAPTable := "ID,Description\nP1,\"Implement, test\"\n"
+	if string(actual.AtomicProcessTable) != expectedAPTable {
+		t.Error(cmp.Diff(expectedAPTable, string(actual.AtomicProcessTable)))
+	}
+}
@@ -0,0 +1,144 @@
+package pfdfix
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
+)
+
+// Conflict is the description that both the diagram and the table changed since the last sync.
+type Conflict struct {
+	ID      pfd.NodeID
+	Base    string
+	Diagram string
+	Table   string
+}
+
+// SyncPrefer is the side that conflicts of syncs are resolved to.
+type SyncPrefer string
+
+const (
+	// SyncPreferNone reports conflicts without rewriting the labels.
+	SyncPreferNone SyncPrefer = ""
+
+	// SyncPreferTable rewrites the labels of conflicts to the descriptions of the table.
+	SyncPreferTable SyncPrefer = "table"
+)
+
+// SyncDescriptions rewrites the labels of the draw.io file to the descriptions of the table. Styles and geometries of
+// the cells are kept. base is the descriptions at the last sync, and the descriptions that both the diagram and the
+// table changed since then are not rewritten but returned as conflicts. Labels of the descriptions not in base are
+// rewritten only if they already match the table, and the others are returned as conflicts because edits in draw.io
+// cannot be told from them. Conflicts are resolved to the side that prefer gives instead, which is the way to start
+// syncing diagrams that have never been synced. It returns the rewritten file and the descriptions to record as the
+// next base.
+func SyncDescriptions(bs []byte, descs map[pfd.NodeID]string, base map[pfd.NodeID]string, prefer SyncPrefer, logger *slog.Logger) ([]byte, map[pfd.NodeID]string, []Conflict, error) {
+	if !IsFixableDrawIO(bs) {
+		return nil, nil, nil, fmt.Errorf("pfdfix.SyncDescriptions: only draw.io files are supported")
+	}
+
+	d, err := newDrawIO(bs, logger)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("pfdfix.SyncDescriptions: %w", err)
+	}
+
+	nextBase := maps.Clone(base)
+	if nextBase == nil {
+		nextBase = make(map[pfd.NodeID]string, len(descs))
+	}
+
+	conflicts := make([]Conflict, 0)
+	for _, id := range slices.Sorted(maps.Keys(descs)) {
+		desc := descs[id]
+		if desc == "" {
+			// NOTE: New rows have empty descriptions until writers write them, so they never clear the labels.
+			continue
+		}
+
+		diagramDescs, ok := d.cellDescriptions(id)
+		if !ok {
+			logger.Debug("pfdfix.SyncDescriptions: missing cell", "id", id)
+			continue
+		}
+
+		baseDesc, hasBase := base[id]
+		diagramDesc, diagramChanged := diagramDescs[0], false
+		upToDate := true
+		for _, cellDesc := range diagramDescs {
+			if cellDesc != desc {
+				upToDate = false
+				// NOTE: Without the base, labels different from the table may have been edited in draw.io.
+				if !hasBase {
+					diagramDesc, diagramChanged = cellDesc, true
+				}
+			}
+			if hasBase && cellDesc != baseDesc {
+				diagramDesc, diagramChanged = cellDesc, true
+			}
+		}
+		if upToDate {
+			nextBase[id] = desc
+			continue
+		}
+
+		tableChanged := !hasBase || desc != baseDesc
+		if !tableChanged {
+			// NOTE: Only the diagram changed, so the table should be updated by pfdtable -existing instead.
+			continue
+		}
+		if diagramChanged && prefer != SyncPreferTable {
+			conflicts = append(conflicts, Conflict{ID: id, Base: baseDesc, Diagram: diagramDesc, Table: desc})
+			continue
+		}
+
+		apply, ok := d.prepareSetDescription(id, desc)
+		if !ok {
+			continue
+		}
+		apply()
+		nextBase[id] = desc
+	}
+
+	if !d.dirty {
+		return bs, nextBase, conflicts, nil
+	}
+
+	res, err := d.bytes()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("pfdfix.SyncDescriptions: %w", err)
+	}
+	return res, nextBase, conflicts, nil
+}
+
+// cellDescriptions returns the descriptions in the labels of the cells of the node.
+func (d *drawIO) cellDescriptions(id pfd.NodeID) ([]string, bool) {
+	locs, ok := d.srcMap.NodeIDMap[id]
+	if !ok || locs.Len() == 0 {
+		return nil, false
+	}
+
+	descs := make([]string, 0, locs.Len())
+	sb := &strings.Builder{}
+	for _, loc := range locs.Iter() {
+		cell, ok := d.cells[loc]
+		if !ok {
+			return nil, false
+		}
+		value, _ := cell.GetAttr("value", "")
+		sb.Reset()
+		if err := pfddrawio.ParseValueHTML(pfddrawio.ValueHTML(value), sb); err != nil {
+			return nil, false
+		}
+		_, desc, err := pfddrawio.ParseVertexValue(sb.String())
+		if err != nil {
+			return nil, false
+		}
+		descs = append(descs, desc)
+	}
+	return descs, true
+}
@@ -0,0 +1,117 @@
+package pfdfix
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSyncDescriptions(t *testing.T) {
+	testCases := map[string]struct {
+		Descs             map[pfd.NodeID]string
+		Base              map[pfd.NodeID]string
+		Prefer            SyncPrefer
+		ExpectedValues    []string
+		NotExpectedValues []string
+		ExpectedBase      map[pfd.NodeID]string
+		ExpectedConflicts []Conflict
+	}{
+		"no base": {
+			Descs:             map[pfd.NodeID]string{"D1": "Specification", "P1": "Implement", "P9": "Missing"},
+			Base:              nil,
+			ExpectedValues:    []string{`value="D1: Spec" style="rounded=0;whiteSpace=wrap;html=1;"`},
+			NotExpectedValues: []string{`value="D1: Specification"`},
+			ExpectedBase:      map[pfd.NodeID]string{"P1": "Implement"},
+			ExpectedConflicts: []Conflict{{ID: "D1", Base: "", Diagram: "Spec", Table: "Specification"}},
+		},
+		"no base and prefer table": {
+			Descs:             map[pfd.NodeID]string{"D1": "Specification", "P1": "Implement"},
+			Base:              nil,
+			Prefer:            SyncPreferTable,
+			ExpectedValues:    []string{`value="D1: Specification"`},
+			ExpectedBase:      map[pfd.NodeID]string{"D1": "Specification", "P1": "Implement"},
+			ExpectedConflicts: []Conflict{},
+		},
+		"both changed and prefer table": {
+			Descs:             map[pfd.NodeID]string{"D1": "Specification"},
+			Base:              map[pfd.NodeID]string{"D1": "Requirements"},
+			Prefer:            SyncPreferTable,
+			ExpectedValues:    []string{`value="D1: Specification"`},
+			ExpectedBase:      map[pfd.NodeID]string{"D1": "Specification"},
+			ExpectedConflicts: []Conflict{},
+		},
+		"not in base": {
+			Descs:             map[pfd.NodeID]string{"D1": "Specification", "P1": "Implement"},
+			Base:              map[pfd.NodeID]string{"P1": "Implement"},
+			ExpectedValues:    []string{`value="D1: Spec"`},
+			ExpectedBase:      map[pfd.NodeID]string{"P1": "Implement"},
+			ExpectedConflicts: []Conflict{{ID: "D1", Base: "", Diagram: "Spec", Table: "Specification"}},
+		},
+		"only table changed": {
+			Descs:             map[pfd.NodeID]string{"D1": "Specification"},
+			Base:              map[pfd.NodeID]string{"D1": "Spec"},
+			ExpectedValues:    []string{`value="D1: Specification"`},
+			ExpectedBase:      map[pfd.NodeID]string{"D1": "Specification"},
+			ExpectedConflicts: []Conflict{},
+		},
+		"only diagram changed": {
+			Descs:             map[pfd.NodeID]string{"P1": "Impl"},
+			Base:              map[pfd.NodeID]string{"P1": "Impl"},
+			ExpectedValues:    []string{`value="P1: Implement"`},
+			NotExpectedValues: []string{`value="P1: Impl"`},
+			ExpectedBase:      map[pfd.NodeID]string{"P1": "Impl"},
+			ExpectedConflicts: []Conflict{},
+		},
+		"both changed": {
+			Descs:             map[pfd.NodeID]string{"D1": "Specification"},
+			Base:              map[pfd.NodeID]string{"D1": "Requirements"},
+			ExpectedValues:    []string{`value="D1: Spec"`},
+			NotExpectedValues: []string{`value="D1: Specification"`},
+			ExpectedBase:      map[pfd.NodeID]string{"D1": "Requirements"},
+			ExpectedConflicts: []Conflict{{ID: "D1", Base: "Requirements", Diagram: "Spec", Table: "Specification"}},
+		},
+		"empty description": {
+			Descs:             map[pfd.NodeID]string{"D1": ""},
+			Base:              nil,
+			ExpectedValues:    []string{`value="D1: Spec"`},
+			ExpectedBase:      map[pfd.NodeID]string{},
+			ExpectedConflicts: []Conflict{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bs, base, conflicts, err := SyncDescriptions([]byte(drawIOFile), tc.Descs, tc.Base, tc.Prefer, slog.New(slogtest.NewTestHandler(t)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := string(bs)
+			for _, value := range tc.ExpectedValues {
+				if !strings.Contains(s, value) {
+					t.Errorf("missing %s in:\n%s", value, s)
+				}
+			}
+			for _, value := range tc.NotExpectedValues {
+				if strings.Contains(s, value) {
+					t.Errorf("unexpected %s in:\n%s", value, s)
+				}
+			}
+			if !strings.Contains(s, `<mxGeometry x="320" y="240" width="120" height="80" as="geometry"/>`) {
+				t.Errorf("geometry is not kept:\n%s", s)
+			}
+
+			if !reflect.DeepEqual(base, tc.ExpectedBase) {
+				t.Error(cmp.Diff(tc.ExpectedBase, base))
+			}
+			if !reflect.DeepEqual(conflicts, tc.ExpectedConflicts) {
+				t.Error(cmp.Diff(tc.ExpectedConflicts, conflicts))
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package pfdfix
+
+import (
+	"slices"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+)
+
+// addRow inserts the row before the first row with a greater ID to keep the order of the other rows. It returns false
+// if the table already has the row.
+func addRow[R any](rows []R, row R, idOf func(R) pfd.NodeID) ([]R, bool) {
+	id := idOf(row)
+	if slices.ContainsFunc(rows, func(r R) bool { return idOf(r) == id }) {
+		return rows, false
+	}
+
+	i := slices.IndexFunc(rows, func(r R) bool { return idOf(r).Compare(id) > 0 })
+	if i < 0 {
+		return append(rows, row), true
+	}
+	return slices.Insert(rows, i, row), true
+}
+
+// removeRows removes the rows with the ID. It returns false if the table has no such rows.
+func removeRows[R any](rows []R, id pfd.NodeID, idOf func(R) pfd.NodeID) ([]R, bool) {
+	res := slices.DeleteFunc(slices.Clone(rows), func(r R) bool { return idOf(r) == id })
+	return res, len(res) != len(rows)
+}
@@ -0,0 +1,126 @@
+package pfdmetrics
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+)
+
+// Range is the inclusive range of a metric. Nil bounds are unbounded.
+type Range struct {
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+}
+
+// Contains returns true if the value is in the range. Nil ranges contain any values.
+func (r *Range) Contains(v int) bool {
+	if r == nil {
+		return true
+	}
+	if r.Min != nil && v < *r.Min {
+		return false
+	}
+	if r.Max != nil && v > *r.Max {
+		return false
+	}
+	return true
+}
+
+// Thresholds is the ranges of the metrics. Metrics out of the ranges are reported as warnings. Nil ranges are not
+// checked.
+type Thresholds struct {
+	FanIn              *Range `json:"fan_in,omitempty"`
+	FanOut             *Range `json:"fan_out,omitempty"`
+	Depth              *Range `json:"depth,omitempty"`
+	Width              *Range `json:"width,omitempty"`
+	FeedbackLoops      *Range `json:"feedback_loops,omitempty"`
+	FeedbackLoopLength *Range `json:"feedback_loop_length,omitempty"`
+	CompositeDepth     *Range `json:"composite_depth,omitempty"`
+	Dominators         *Range `json:"dominators,omitempty"`
+}
+
+// NewChecker returns the checker that reports the metrics out of the thresholds. The thresholds may be nil.
+func NewChecker(thresholds *Thresholds) checkers.Checker[*fsmcommon.Target] {
+	return checkers.AtomicChecker[*fsmcommon.Target]{
+		ID: "metrics",
+		AvailableIfFunc: func(t *fsmcommon.Target) bool {
+			return thresholds != nil
+		},
+		CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
+			const fanInProblemID = "metric-fan-in"
+			const fanOutProblemID = "metric-fan-out"
+			const depthProblemID = "metric-depth"
+			const widthProblemID = "metric-width"
+			const feedbackLoopsProblemID = "metric-feedback-loops"
+			const feedbackLoopLengthProblemID = "metric-feedback-loop-length"
+			const compositeDepthProblemID = "metric-composite-depth"
+			const dominatorsProblemID = "metric-dominators"
+
+			m, err := Measure(t.PFD)
+			if err != nil {
+				if errors.Is(err, ErrCyclicExceptFeedback) {
+					// NOTE: Reported by acyclic-except-fb.
+					return nil
+				}
+				return fmt.Errorf("pfdmetrics.NewChecker: %w", err)
+			}
+
+			for _, pm := range m.Processes {
+				if !thresholds.FanIn.Contains(pm.FanIn) {
+					ch <- checkers.NewProblem(fanInProblemID, checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypePFD, fsmcommon.NewAtomicProcessID(pm.ID)))
+				}
+				if !thresholds.FanOut.Contains(pm.FanOut) {
+					ch <- checkers.NewProblem(fanOutProblemID, checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypePFD, fsmcommon.NewAtomicProcessID(pm.ID)))
+				}
+			}
+
+			if !thresholds.Depth.Contains(m.Depth) {
+				ch <- checkers.NewProblem(depthProblemID, checkers.SeverityWarning, processesLocation(m.LongestPath))
+			}
+			if !thresholds.Width.Contains(m.Width) {
+				ch <- checkers.NewProblem(widthProblemID, checkers.SeverityWarning, processesLocation(m.WidestLevel))
+			}
+
+			if !thresholds.FeedbackLoops.Contains(len(m.FeedbackLoops)) {
+				ids := make([]fsmcommon.ID, 0)
+				for _, d := range t.PFD.FeedbackSourceDeliverables().Iter() {
+					ids = append(ids, fsmcommon.NewAtomicDeliverableID(d))
+				}
+				ch <- checkers.NewProblem(feedbackLoopsProblemID, checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypePFD, ids...))
+			}
+			for _, loop := range m.FeedbackLoops {
+				if !thresholds.FeedbackLoopLength.Contains(loop.Len()) {
+					ch <- checkers.NewProblem(feedbackLoopLengthProblemID, checkers.SeverityWarning, processesLocation(loop.AtomicProcesses))
+				}
+			}
+
+			if !thresholds.CompositeDepth.Contains(m.CompositeDepth) {
+				ids := make([]fsmcommon.ID, 0, len(m.DeepestComposition))
+				for _, cp := range m.DeepestComposition {
+					ids = append(ids, fsmcommon.NewCompositeProcessID(cp))
+				}
+				ch <- checkers.NewProblem(compositeDepthProblemID, checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypePFD, ids...))
+			}
+
+			if !thresholds.Dominators.Contains(len(m.Dominators)) {
+				ids := make([]fsmcommon.ID, 0, len(m.Dominators))
+				for _, d := range m.Dominators {
+					ids = append(ids, fsmcommon.NewAtomicDeliverableID(d))
+				}
+				ch <- checkers.NewProblem(dominatorsProblemID, checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypePFD, ids...))
+			}
+			return nil
+		},
+	}
+}
+
+func processesLocation(aps []pfd.AtomicProcessID) fsmcommon.Location {
+	ids := make([]fsmcommon.ID, 0, len(aps))
+	for _, ap := range aps {
+		ids = append(ids, fsmcommon.NewAtomicProcessID(ap))
+	}
+	return fsmcommon.NewLocation(fsmcommon.LocationTypePFD, ids...)
+}
@@ -0,0 +1,93 @@
+package pfdmetrics
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/chans"
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewChecker(t *testing.T) {
+	one := 1
+	two := 2
+
+	testCases := map[string]struct {
+		PFD        *pfd.PFD
+		Thresholds *Thresholds
+		Want       []checkers.Problem
+	}{
+		"no thresholds": {
+			PFD:        pfd.PresetNestedLoop,
+			Thresholds: nil,
+			Want:       []checkers.Problem{},
+		},
+		"in thresholds": {
+			PFD:        pfd.PresetNestedLoop,
+			Thresholds: &Thresholds{FanIn: &Range{Max: &two}, Depth: &Range{Min: &one}},
+			Want:       []checkers.Problem{},
+		},
+		"out of thresholds": {
+			PFD: pfd.PresetNestedLoop,
+			Thresholds: &Thresholds{
+				FanIn:              &Range{Max: &one},
+				Depth:              &Range{Max: &two},
+				FeedbackLoops:      &Range{Max: &one},
+				FeedbackLoopLength: &Range{Max: &two},
+				CompositeDepth:     &Range{Min: &one},
+			},
+			Want: []checkers.Problem{
+				checkers.NewProblem("metric-fan-in", checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypePFD, fsmcommon.NewAtomicProcessID("P1"))),
+				checkers.NewProblem("metric-fan-in", checkers.SeverityWarning, fsmcommon.NewLocation(fsmcommon.LocationTypePFD, fsmcommon.NewAtomicProcessID("P2"))),
+				checkers.NewProblem("metric-depth", checkers.SeverityWarning, fsmcommon.NewLocation(
+					fsmcommon.LocationTypePFD,
+					fsmcommon.NewAtomicProcessID("P1"),
+					fsmcommon.NewAtomicProcessID("P2"),
+					fsmcommon.NewAtomicProcessID("P3"),
+				)),
+				checkers.NewProblem("metric-feedback-loops", checkers.SeverityWarning, fsmcommon.NewLocation(
+					fsmcommon.LocationTypePFD,
+					fsmcommon.NewAtomicDeliverableID("D3"),
+					fsmcommon.NewAtomicDeliverableID("D4"),
+				)),
+				checkers.NewProblem("metric-feedback-loop-length", checkers.SeverityWarning, fsmcommon.NewLocation(
+					fsmcommon.LocationTypePFD,
+					fsmcommon.NewAtomicProcessID("P2"),
+					fsmcommon.NewAtomicProcessID("P3"),
+					fsmcommon.NewAtomicProcessID("P1"),
+				)),
+				checkers.NewProblem("metric-composite-depth", checkers.SeverityWarning, fsmcommon.Location{Type: fsmcommon.LocationTypePFD, RelatedIDs: []fsmcommon.ID{}}),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p, err := pfd.NewSafePFDByUnsafePFD(testCase.PFD)
+			if err != nil {
+				t.Fatalf("pfd.NewSafePFDByUnsafePFD: %v", err)
+			}
+			m, err := fsmcommon.NewMemoized(nil, nil, nil, nil)
+			if err != nil {
+				t.Fatalf("fsmcommon.NewMemoized: %v", err)
+			}
+			ch := make(chan checkers.Problem)
+			go func() {
+				defer close(ch)
+				tgt := fsmcommon.NewTarget(p, nil, nil, nil, nil, nil, m, slog.New(slogtest.NewTestHandler(t)))
+				if err := NewChecker(testCase.Thresholds).Check(tgt, ch); err != nil {
+					t.Errorf("NewChecker: %v", err)
+				}
+			}()
+			got := chans.Slice(ch)
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Error(cmp.Diff(testCase.Want, got))
+			}
+		})
+	}
+}
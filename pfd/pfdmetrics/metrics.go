@@ -0,0 +1,320 @@
+package pfdmetrics
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Kuniwak/pfd-tools/graph"
+	"github.com/Kuniwak/pfd-tools/pairs"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// ErrCyclicExceptFeedback is returned if the PFD has cycles without feedback edges. Depths and widths are undefined for
+// such PFDs.
+var ErrCyclicExceptFeedback = errors.New("pfdmetrics.Measure: cyclic except feedback")
+
+// Metrics is the structural metrics of a PFD. The metrics are used to judge whether the PFD is too coarse or too fine.
+type Metrics struct {
+	// Processes is the metrics of the atomic processes in order.
+	Processes []ProcessMetrics `json:"processes"`
+
+	// Depth is the number of the atomic processes on the longest path of the graph except feedback.
+	Depth int `json:"depth"`
+
+	// LongestPath is the atomic processes on the longest path in order.
+	LongestPath []pfd.AtomicProcessID `json:"longest_path"`
+
+	// Width is the maximum number of the atomic processes at the same depth.
+	Width int `json:"width"`
+
+	// WidestLevel is the atomic processes at the first depth of the width.
+	WidestLevel []pfd.AtomicProcessID `json:"widest_level"`
+
+	// FeedbackLoops is the simple cycles including feedback edges in order.
+	FeedbackLoops []FeedbackLoop `json:"feedback_loops"`
+
+	// CompositeDepth is the maximum nesting depth of the composite processes. It is 0 if there are no composite
+	// processes.
+	CompositeDepth int `json:"composite_depth"`
+
+	// DeepestComposition is the composite processes of the deepest nesting from the outermost one.
+	DeepestComposition []pfd.CompositeProcessID `json:"deepest_composition"`
+
+	// Dominators is the atomic deliverables on every path from the initial deliverables to the final deliverables.
+	Dominators []pfd.AtomicDeliverableID `json:"dominators"`
+}
+
+// ProcessMetrics is the metrics of an atomic process.
+type ProcessMetrics struct {
+	ID pfd.AtomicProcessID `json:"id"`
+
+	// FanIn is the number of the input deliverables including feedback.
+	FanIn int `json:"fan_in"`
+
+	// FanOut is the number of the output deliverables.
+	FanOut int `json:"fan_out"`
+}
+
+// FeedbackLoop is a simple cycle including feedback edges.
+type FeedbackLoop struct {
+	// AtomicProcesses is the atomic processes on the loop in order.
+	AtomicProcesses []pfd.AtomicProcessID `json:"atomic_processes"`
+
+	// FeedbackSources is the feedback source deliverables on the loop.
+	FeedbackSources []pfd.AtomicDeliverableID `json:"feedback_sources"`
+}
+
+// Len returns the length of the loop in atomic processes.
+func (l FeedbackLoop) Len() int {
+	return len(l.AtomicProcesses)
+}
+
+// Measure returns the metrics of the PFD. Feedback loops are all the simple cycles, so it may be slow for PFDs with
+// many interleaved feedback edges.
+func Measure(p *pfd.ValidPFD) (*Metrics, error) {
+	aps := p.AtomicProcesses.Slice()
+	slices.SortFunc(aps, pfd.AtomicProcessID.Compare)
+
+	processes := make([]ProcessMetrics, 0, len(aps))
+	for _, ap := range aps {
+		processes = append(processes, ProcessMetrics{
+			ID:     ap,
+			FanIn:  p.InputDeliverablesIncludingFeedback(ap).Len(),
+			FanOut: p.OutputDeliverables(ap).Len(),
+		})
+	}
+
+	levels, err := levelsExceptFeedback(p, aps)
+	if err != nil {
+		return nil, fmt.Errorf("pfdmetrics.Measure: %w", err)
+	}
+	longestPath := longestPath(p, aps, levels)
+	widestLevel := widestLevel(aps, levels)
+	deepestComposition := deepestComposition(p)
+
+	return &Metrics{
+		Processes:          processes,
+		Depth:              len(longestPath),
+		LongestPath:        longestPath,
+		Width:              len(widestLevel),
+		WidestLevel:        widestLevel,
+		FeedbackLoops:      feedbackLoops(p),
+		CompositeDepth:     len(deepestComposition),
+		DeepestComposition: deepestComposition,
+		Dominators:         dominators(p),
+	}, nil
+}
+
+// predecessorsExceptFeedback returns the atomic processes that output the inputs of the atomic process except
+// feedback.
+func predecessorsExceptFeedback(p *pfd.ValidPFD, ap pfd.AtomicProcessID) []pfd.AtomicProcessID {
+	preds := sets.New(pfd.AtomicProcessID.Compare)
+	for _, d := range p.InputDeliverablesExceptFeedback(ap).Iter() {
+		if src, ok := p.SourceAtomicProcess(d); ok {
+			preds.Add(pfd.AtomicProcessID.Compare, src)
+		}
+	}
+	return preds.Slice()
+}
+
+// levelsExceptFeedback returns the depths of the atomic processes. Atomic processes without predecessors are at the
+// depth 1.
+func levelsExceptFeedback(p *pfd.ValidPFD, aps []pfd.AtomicProcessID) (map[pfd.AtomicProcessID]int, error) {
+	g := &graph.Graph{
+		Nodes: sets.NewWithCapacity[graph.Node](len(aps)),
+		Edges: sets.New(pairs.Compare(graph.Node.Compare, graph.Node.Compare)),
+	}
+	for _, ap := range aps {
+		g.Nodes.Add(graph.Node.Compare, graph.Node(ap))
+		for _, pred := range predecessorsExceptFeedback(p, ap) {
+			g.Edges.Add(pairs.Compare(graph.Node.Compare, graph.Node.Compare), pairs.New(graph.Node(pred), graph.Node(ap)))
+		}
+	}
+
+	order := g.TopologicalSort()
+	if order == nil {
+		return nil, ErrCyclicExceptFeedback
+	}
+
+	levels := make(map[pfd.AtomicProcessID]int, len(aps))
+	for _, n := range order {
+		ap := pfd.AtomicProcessID(n)
+		level := 1
+		for _, pred := range predecessorsExceptFeedback(p, ap) {
+			level = max(level, levels[pred]+1)
+		}
+		levels[ap] = level
+	}
+	return levels, nil
+}
+
+// longestPath returns the first longest path in the order of the atomic process IDs.
+func longestPath(p *pfd.ValidPFD, aps []pfd.AtomicProcessID, levels map[pfd.AtomicProcessID]int) []pfd.AtomicProcessID {
+	var last pfd.AtomicProcessID
+	depth := 0
+	for _, ap := range aps {
+		if levels[ap] > depth {
+			last = ap
+			depth = levels[ap]
+		}
+	}
+
+	path := make([]pfd.AtomicProcessID, depth)
+	for i := depth - 1; i >= 0; i-- {
+		path[i] = last
+		for _, pred := range predecessorsExceptFeedback(p, last) {
+			if levels[pred] == i {
+				last = pred
+				break
+			}
+		}
+	}
+	return path
+}
+
+// widestLevel returns the atomic processes at the first depth that has the most atomic processes.
+func widestLevel(aps []pfd.AtomicProcessID, levels map[pfd.AtomicProcessID]int) []pfd.AtomicProcessID {
+	byLevel := make(map[int][]pfd.AtomicProcessID)
+	depth := 0
+	for _, ap := range aps {
+		byLevel[levels[ap]] = append(byLevel[levels[ap]], ap)
+		depth = max(depth, levels[ap])
+	}
+
+	res := []pfd.AtomicProcessID{}
+	for level := 1; level <= depth; level++ {
+		if len(byLevel[level]) > len(res) {
+			res = byLevel[level]
+		}
+	}
+	return res
+}
+
+// feedbackLoops returns the simple cycles of the graph including feedback edges. Every cycle includes feedback edges
+// if the graph except feedback is acyclic.
+func feedbackLoops(p *pfd.ValidPFD) []FeedbackLoop {
+	g := &graph.Graph{
+		Nodes: sets.NewWithCapacity[graph.Node](p.AtomicProcesses.Len() + p.AtomicDeliverables.Len()),
+		Edges: sets.New(pairs.Compare(graph.Node.Compare, graph.Node.Compare)),
+	}
+	for _, d := range p.AtomicDeliverables.Iter() {
+		g.Nodes.Add(graph.Node.Compare, graph.Node(d))
+	}
+	for _, ap := range p.AtomicProcesses.Iter() {
+		g.Nodes.Add(graph.Node.Compare, graph.Node(ap))
+		for _, d := range p.InputDeliverablesIncludingFeedback(ap).Iter() {
+			g.Edges.Add(pairs.Compare(graph.Node.Compare, graph.Node.Compare), pairs.New(graph.Node(d), graph.Node(ap)))
+		}
+		for _, d := range p.OutputDeliverables(ap).Iter() {
+			g.Edges.Add(pairs.Compare(graph.Node.Compare, graph.Node.Compare), pairs.New(graph.Node(ap), graph.Node(d)))
+		}
+	}
+
+	cycles := g.Cycles()
+	loops := make([]FeedbackLoop, 0, cycles.Len())
+	for _, cycle := range cycles.Iter() {
+		loop := FeedbackLoop{AtomicProcesses: []pfd.AtomicProcessID{}, FeedbackSources: []pfd.AtomicDeliverableID{}}
+		for i, n := range cycle {
+			if p.AtomicProcesses.Contains(pfd.AtomicProcessID.Compare, pfd.AtomicProcessID(n)) {
+				loop.AtomicProcesses = append(loop.AtomicProcesses, pfd.AtomicProcessID(n))
+				continue
+			}
+			d := pfd.AtomicDeliverableID(n)
+			next := pfd.AtomicProcessID(cycle[(i+1)%len(cycle)])
+			if p.FeedbackDestinationAtomicProcesses(d).Contains(pfd.AtomicProcessID.Compare, next) {
+				loop.FeedbackSources = append(loop.FeedbackSources, d)
+			}
+		}
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// deepestComposition returns the composite processes of the deepest nesting from the outermost one. Composite
+// processes are nested if the atomic processes of one are a proper subset of the other.
+func deepestComposition(p *pfd.ValidPFD) []pfd.CompositeProcessID {
+	cps := make([]pfd.CompositeProcessID, 0, len(p.ProcessComposition))
+	for cp := range p.ProcessComposition {
+		cps = append(cps, cp)
+	}
+	slices.SortFunc(cps, pfd.CompositeProcessID.Compare)
+
+	// NOTE: Outer composite processes have more atomic processes, so visit them first.
+	slices.SortStableFunc(cps, func(a, b pfd.CompositeProcessID) int {
+		return p.ProcessComposition[b].Len() - p.ProcessComposition[a].Len()
+	})
+
+	chains := make(map[pfd.CompositeProcessID][]pfd.CompositeProcessID, len(cps))
+	res := []pfd.CompositeProcessID{}
+	for _, cp := range cps {
+		var outer []pfd.CompositeProcessID
+		for _, parent := range cps {
+			if !p.ProcessComposition[cp].IsProperSubsetOf(pfd.AtomicProcessID.Compare, p.ProcessComposition[parent]) {
+				continue
+			}
+			if len(chains[parent]) > len(outer) {
+				outer = chains[parent]
+			}
+		}
+		chain := append(slices.Clone(outer), cp)
+		chains[cp] = chain
+		if len(chain) > len(res) {
+			res = chain
+		}
+	}
+	return res
+}
+
+// dominators returns the atomic deliverables on every path from the initial deliverables to the final deliverables of
+// the graph except feedback. It is empty if there are no such paths.
+func dominators(p *pfd.ValidPFD) []pfd.AtomicDeliverableID {
+	ds := p.AtomicDeliverables.Slice()
+	slices.SortFunc(ds, pfd.AtomicDeliverableID.Compare)
+
+	res := []pfd.AtomicDeliverableID{}
+	if !reachesFinal(p, "") {
+		return res
+	}
+	for _, d := range ds {
+		if !reachesFinal(p, d) {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// reachesFinal returns true if any final deliverable is reachable from any initial deliverable without the excluded
+// deliverable.
+func reachesFinal(p *pfd.ValidPFD, excluded pfd.AtomicDeliverableID) bool {
+	visited := sets.NewWithCapacity[pfd.AtomicDeliverableID](p.AtomicDeliverables.Len())
+	stack := make([]pfd.AtomicDeliverableID, 0, p.AtomicDeliverables.Len())
+	for _, d := range p.InitialDeliverables().Iter() {
+		if d != excluded {
+			stack = append(stack, d)
+		}
+	}
+
+	for len(stack) > 0 {
+		d := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited.Contains(pfd.AtomicDeliverableID.Compare, d) {
+			continue
+		}
+		visited.Add(pfd.AtomicDeliverableID.Compare, d)
+
+		aps := p.NotFeedbackDestinationAtomicProcesses(d)
+		if aps.Len() == 0 {
+			return true
+		}
+		for _, ap := range aps.Iter() {
+			for _, next := range p.OutputDeliverables(ap).Iter() {
+				if next != excluded {
+					stack = append(stack, next)
+				}
+			}
+		}
+	}
+	return false
+}
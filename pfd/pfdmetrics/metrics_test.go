@@ -0,0 +1,160 @@
+package pfdmetrics
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/google/go-cmp/cmp"
+)
+
+// presetComposite is PresetSequential with the composite process P10 of P1 and P2, and P11 of P1 in P10.
+var presetComposite = &pfd.PFD{
+	Nodes: sets.New(
+		(*pfd.Node).Compare,
+		&pfd.Node{ID: "D1", Description: "D1", Type: pfd.NodeTypeAtomicDeliverable},
+		&pfd.Node{ID: "D2", Description: "D2", Type: pfd.NodeTypeAtomicDeliverable},
+		&pfd.Node{ID: "D3", Description: "D3", Type: pfd.NodeTypeAtomicDeliverable},
+		&pfd.Node{ID: "P1", Description: "P1", Type: pfd.NodeTypeAtomicProcess},
+		&pfd.Node{ID: "P2", Description: "P2", Type: pfd.NodeTypeAtomicProcess},
+		&pfd.Node{ID: "P10", Description: "P10", Type: pfd.NodeTypeCompositeProcess},
+		&pfd.Node{ID: "P11", Description: "P11", Type: pfd.NodeTypeCompositeProcess},
+	),
+	Edges: sets.New(
+		(*pfd.Edge).Compare,
+		&pfd.Edge{Source: "D1", Target: "P1"},
+		&pfd.Edge{Source: "D2", Target: "P2"},
+		&pfd.Edge{Source: "P1", Target: "D2"},
+		&pfd.Edge{Source: "P2", Target: "D3"},
+	),
+	ProcessComposition: map[pfd.NodeID]*sets.Set[pfd.NodeID]{
+		"P10": sets.New(pfd.NodeID.Compare, "P1", "P2"),
+		"P11": sets.New(pfd.NodeID.Compare, "P1"),
+	},
+}
+
+func TestMeasure(t *testing.T) {
+	testCases := map[string]struct {
+		PFD      *pfd.PFD
+		Expected *Metrics
+	}{
+		"sequential": {
+			PFD: pfd.PresetSequential,
+			Expected: &Metrics{
+				Processes: []ProcessMetrics{
+					{ID: "P1", FanIn: 1, FanOut: 1},
+					{ID: "P2", FanIn: 1, FanOut: 1},
+				},
+				Depth:              2,
+				LongestPath:        []pfd.AtomicProcessID{"P1", "P2"},
+				Width:              1,
+				WidestLevel:        []pfd.AtomicProcessID{"P1"},
+				FeedbackLoops:      []FeedbackLoop{},
+				CompositeDepth:     0,
+				DeepestComposition: []pfd.CompositeProcessID{},
+				Dominators:         []pfd.AtomicDeliverableID{"D1", "D2", "D3"},
+			},
+		},
+		"nested loop": {
+			PFD: pfd.PresetNestedLoop,
+			Expected: &Metrics{
+				Processes: []ProcessMetrics{
+					{ID: "P1", FanIn: 2, FanOut: 1},
+					{ID: "P2", FanIn: 2, FanOut: 1},
+					{ID: "P3", FanIn: 1, FanOut: 1},
+				},
+				Depth:       3,
+				LongestPath: []pfd.AtomicProcessID{"P1", "P2", "P3"},
+				Width:       1,
+				WidestLevel: []pfd.AtomicProcessID{"P1"},
+				FeedbackLoops: []FeedbackLoop{
+					{AtomicProcesses: []pfd.AtomicProcessID{"P2"}, FeedbackSources: []pfd.AtomicDeliverableID{"D3"}},
+					{AtomicProcesses: []pfd.AtomicProcessID{"P2", "P3", "P1"}, FeedbackSources: []pfd.AtomicDeliverableID{"D4"}},
+				},
+				CompositeDepth:     0,
+				DeepestComposition: []pfd.CompositeProcessID{},
+				Dominators:         []pfd.AtomicDeliverableID{"D1", "D2", "D3", "D4"},
+			},
+		},
+		"butterfly loop": {
+			PFD: pfd.PresetButterflyLoop,
+			Expected: &Metrics{
+				Processes: []ProcessMetrics{
+					{ID: "P1", FanIn: 3, FanOut: 2},
+					{ID: "P2", FanIn: 1, FanOut: 1},
+					{ID: "P3", FanIn: 1, FanOut: 1},
+				},
+				Depth:       2,
+				LongestPath: []pfd.AtomicProcessID{"P1", "P2"},
+				Width:       2,
+				WidestLevel: []pfd.AtomicProcessID{"P2", "P3"},
+				FeedbackLoops: []FeedbackLoop{
+					{AtomicProcesses: []pfd.AtomicProcessID{"P2", "P1"}, FeedbackSources: []pfd.AtomicDeliverableID{"D4"}},
+					{AtomicProcesses: []pfd.AtomicProcessID{"P3", "P1"}, FeedbackSources: []pfd.AtomicDeliverableID{"D5"}},
+				},
+				CompositeDepth:     0,
+				DeepestComposition: []pfd.CompositeProcessID{},
+				Dominators:         []pfd.AtomicDeliverableID{"D1"},
+			},
+		},
+		"composite": {
+			PFD: presetComposite,
+			Expected: &Metrics{
+				Processes: []ProcessMetrics{
+					{ID: "P1", FanIn: 1, FanOut: 1},
+					{ID: "P2", FanIn: 1, FanOut: 1},
+				},
+				Depth:              2,
+				LongestPath:        []pfd.AtomicProcessID{"P1", "P2"},
+				Width:              1,
+				WidestLevel:        []pfd.AtomicProcessID{"P1"},
+				FeedbackLoops:      []FeedbackLoop{},
+				CompositeDepth:     2,
+				DeepestComposition: []pfd.CompositeProcessID{"P10", "P11"},
+				Dominators:         []pfd.AtomicDeliverableID{"D1", "D2", "D3"},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p, err := pfd.NewSafePFDByUnsafePFD(testCase.PFD)
+			if err != nil {
+				t.Fatalf("pfd.NewSafePFDByUnsafePFD: %v", err)
+			}
+			actual, err := Measure(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
+
+func TestMeasureCyclicExceptFeedback(t *testing.T) {
+	p, err := pfd.NewSafePFDByUnsafePFD(&pfd.PFD{
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Description: "D1", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "D2", Description: "D2", Type: pfd.NodeTypeAtomicDeliverable},
+			&pfd.Node{ID: "P1", Description: "P1", Type: pfd.NodeTypeAtomicProcess},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D1", Target: "P1"},
+			&pfd.Edge{Source: "D2", Target: "P1"},
+			&pfd.Edge{Source: "P1", Target: "D2"},
+		),
+	})
+	if err != nil {
+		t.Fatalf("pfd.NewSafePFDByUnsafePFD: %v", err)
+	}
+
+	if _, err := Measure(p); !errors.Is(err, ErrCyclicExceptFeedback) {
+		t.Errorf("want %v, got %v", ErrCyclicExceptFeedback, err)
+	}
+}
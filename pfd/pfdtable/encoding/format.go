@@ -1,12 +1,15 @@
 package encoding
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
 	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdcsv"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdhtml"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdxlsx"
 	"github.com/Kuniwak/pfd-tools/table"
 )
 
@@ -14,6 +17,10 @@ func NewAtomicProcessTableParser(format table.Format) (func(r io.Reader) (*pfd.A
 	switch format {
 	case table.FormatTSV:
 		return pfdtsv.ParseAtomicProcessTable, nil
+	case table.FormatCSV:
+		return pfdcsv.ParseAtomicProcessTable, nil
+	case table.FormatXLSX:
+		return pfdxlsx.ParseAtomicProcessTable, nil
 	case table.FormatHTML:
 		return nil, fmt.Errorf("pfdencoding.NewAtomicProcessTableParser: %q format is not supported", format)
 	default:
@@ -21,36 +28,73 @@ func NewAtomicProcessTableParser(format table.Format) (func(r io.Reader) (*pfd.A
 	}
 }
 
-func NewAtomicDeliverableTableParser(format table.Format) (func(r io.Reader) (*pfd.AtomicDeliverableTable, error), error) {
+func NewAtomicProcessTableWriter(format table.Format) (func(w io.Writer, table *pfd.AtomicProcessTable) error, error) {
 	switch format {
 	case table.FormatTSV:
-		return pfdtsv.ParseAtomicDeliverableTable, nil
+		return pfdtsv.WriteAtomicProcessTable, nil
+	case table.FormatCSV:
+		return pfdcsv.WriteAtomicProcessTable, nil
+	case table.FormatXLSX:
+		return pfdxlsx.WriteAtomicProcessTable, nil
 	case table.FormatHTML:
-		return nil, fmt.Errorf("pfdencoding.NewDeliverableTableParser: %q format is not supported", format)
+		return pfdhtml.WriteAtomicProcessTable, nil
 	default:
-		return nil, fmt.Errorf("pfdencoding.NewDeliverableTableParser: unknown format: %q", format)
+		return nil, fmt.Errorf("pfdencoding.NewAtomicProcessWriter: unknown format: %q", format)
 	}
 }
 
-func NewCompositeProcessTableParser(format table.Format) (func(r io.Reader) (*pfd.CompositeProcessTable, error), error) {
-	switch format {
-	case table.FormatTSV:
-		return pfdtsv.ParseCompositeProcessTable, nil
-	case table.FormatHTML:
-		return nil, fmt.Errorf("pfdencoding.NewCompositeProcessTableParser: %q format is not supported", format)
-	default:
-		return nil, fmt.Errorf("pfdencoding.NewCompositeProcessTableParser: unknown format: %q", format)
+// ParseAtomicProcessTable reads the table in the format detected by table.Detect.
+func ParseAtomicProcessTable(r io.Reader) (*pfd.AtomicProcessTable, error) {
+	format, r, err := table.Detect(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseAtomicProcessTable: %w", err)
+	}
+	parse, err := NewAtomicProcessTableParser(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseAtomicProcessTable: %w", err)
+	}
+	t, err := parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseAtomicProcessTable: %w", err)
 	}
+	return t, nil
 }
 
-func NewAtomicProcessTableWriter(format table.Format) (func(w io.Writer, table *pfd.AtomicProcessTable) error, error) {
+// RewriteAtomicProcessTable returns the table in the format of the original. The other sheets of XLSX workbooks are kept.
+func RewriteAtomicProcessTable(original []byte, t *pfd.AtomicProcessTable) ([]byte, error) {
+	format, _, err := table.Detect(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteAtomicProcessTable: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if format == table.FormatXLSX {
+		if err := pfdxlsx.UpdateAtomicProcessTable(buf, bytes.NewReader(original), t); err != nil {
+			return nil, fmt.Errorf("pfdencoding.RewriteAtomicProcessTable: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	write, err := NewAtomicProcessTableWriter(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteAtomicProcessTable: %w", err)
+	}
+	if err := write(buf, t); err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteAtomicProcessTable: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func NewAtomicDeliverableTableParser(format table.Format) (func(r io.Reader) (*pfd.AtomicDeliverableTable, error), error) {
 	switch format {
 	case table.FormatTSV:
-		return pfdtsv.WriteAtomicProcessTable, nil
+		return pfdtsv.ParseAtomicDeliverableTable, nil
+	case table.FormatCSV:
+		return pfdcsv.ParseAtomicDeliverableTable, nil
+	case table.FormatXLSX:
+		return pfdxlsx.ParseAtomicDeliverableTable, nil
 	case table.FormatHTML:
-		return pfdhtml.WriteAtomicProcessTable, nil
+		return nil, fmt.Errorf("pfdencoding.NewDeliverableTableParser: %q format is not supported", format)
 	default:
-		return nil, fmt.Errorf("pfdencoding.NewAtomicProcessWriter: unknown format: %q", format)
+		return nil, fmt.Errorf("pfdencoding.NewDeliverableTableParser: unknown format: %q", format)
 	}
 }
 
@@ -58,6 +102,10 @@ func NewAtomicDeliverableTableWriter(format table.Format) (func(w io.Writer, tab
 	switch format {
 	case table.FormatTSV:
 		return pfdtsv.WriteAtomicDeliverableTable, nil
+	case table.FormatCSV:
+		return pfdcsv.WriteAtomicDeliverableTable, nil
+	case table.FormatXLSX:
+		return pfdxlsx.WriteAtomicDeliverableTable, nil
 	case table.FormatHTML:
 		return pfdhtml.WriteAtomicDeliverableTable, nil
 	default:
@@ -65,35 +113,182 @@ func NewAtomicDeliverableTableWriter(format table.Format) (func(w io.Writer, tab
 	}
 }
 
-func NewCompositeProcessTableWriter(format table.Format) (func(w io.Writer, table *pfd.CompositeProcessTable) error, error) {
+// ParseAtomicDeliverableTable reads the table in the format detected by table.Detect.
+func ParseAtomicDeliverableTable(r io.Reader) (*pfd.AtomicDeliverableTable, error) {
+	format, r, err := table.Detect(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseAtomicDeliverableTable: %w", err)
+	}
+	parse, err := NewAtomicDeliverableTableParser(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseAtomicDeliverableTable: %w", err)
+	}
+	t, err := parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseAtomicDeliverableTable: %w", err)
+	}
+	return t, nil
+}
+
+// RewriteAtomicDeliverableTable returns the table in the format of the original. The other sheets of XLSX workbooks are kept.
+func RewriteAtomicDeliverableTable(original []byte, t *pfd.AtomicDeliverableTable) ([]byte, error) {
+	format, _, err := table.Detect(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteAtomicDeliverableTable: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if format == table.FormatXLSX {
+		if err := pfdxlsx.UpdateAtomicDeliverableTable(buf, bytes.NewReader(original), t); err != nil {
+			return nil, fmt.Errorf("pfdencoding.RewriteAtomicDeliverableTable: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	write, err := NewAtomicDeliverableTableWriter(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteAtomicDeliverableTable: %w", err)
+	}
+	if err := write(buf, t); err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteAtomicDeliverableTable: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func NewCompositeProcessTableParser(format table.Format) (func(r io.Reader) (*pfd.CompositeProcessTable, error), error) {
 	switch format {
 	case table.FormatTSV:
-		return pfdtsv.WriteCompositeProcessTable, nil
+		return pfdtsv.ParseCompositeProcessTable, nil
+	case table.FormatCSV:
+		return pfdcsv.ParseCompositeProcessTable, nil
+	case table.FormatXLSX:
+		return pfdxlsx.ParseCompositeProcessTable, nil
 	case table.FormatHTML:
-		return pfdhtml.WriteCompositeProcessTable, nil
+		return nil, fmt.Errorf("pfdencoding.NewCompositeProcessTableParser: %q format is not supported", format)
 	default:
-		return nil, fmt.Errorf("pfdencoding.NewCompositeProcessTableWriter: unknown format: %q", format)
+		return nil, fmt.Errorf("pfdencoding.NewCompositeProcessTableParser: unknown format: %q", format)
 	}
 }
 
-func NewCompositeDeliverableTableWriter(format table.Format) (func(w io.Writer, table *pfd.CompositeDeliverableTable) error, error) {
+func NewCompositeProcessTableWriter(format table.Format) (func(w io.Writer, table *pfd.CompositeProcessTable) error, error) {
 	switch format {
 	case table.FormatTSV:
-		return pfdtsv.WriteCompositeDeliverableTable, nil
+		return pfdtsv.WriteCompositeProcessTable, nil
+	case table.FormatCSV:
+		return pfdcsv.WriteCompositeProcessTable, nil
+	case table.FormatXLSX:
+		return pfdxlsx.WriteCompositeProcessTable, nil
 	case table.FormatHTML:
-		return pfdhtml.WriteCompositeDeliverableTable, nil
+		return pfdhtml.WriteCompositeProcessTable, nil
 	default:
-		return nil, fmt.Errorf("pfdencoding.NewCompositeDeliverableTableWriter: unknown format: %q", format)
+		return nil, fmt.Errorf("pfdencoding.NewCompositeProcessTableWriter: unknown format: %q", format)
+	}
+}
+
+// ParseCompositeProcessTable reads the table in the format detected by table.Detect.
+func ParseCompositeProcessTable(r io.Reader) (*pfd.CompositeProcessTable, error) {
+	format, r, err := table.Detect(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseCompositeProcessTable: %w", err)
+	}
+	parse, err := NewCompositeProcessTableParser(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseCompositeProcessTable: %w", err)
+	}
+	t, err := parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseCompositeProcessTable: %w", err)
+	}
+	return t, nil
+}
+
+// RewriteCompositeProcessTable returns the table in the format of the original. The other sheets of XLSX workbooks are kept.
+func RewriteCompositeProcessTable(original []byte, t *pfd.CompositeProcessTable) ([]byte, error) {
+	format, _, err := table.Detect(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteCompositeProcessTable: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if format == table.FormatXLSX {
+		if err := pfdxlsx.UpdateCompositeProcessTable(buf, bytes.NewReader(original), t); err != nil {
+			return nil, fmt.Errorf("pfdencoding.RewriteCompositeProcessTable: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	write, err := NewCompositeProcessTableWriter(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteCompositeProcessTable: %w", err)
 	}
+	if err := write(buf, t); err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteCompositeProcessTable: %w", err)
+	}
+	return buf.Bytes(), nil
 }
 
 func NewCompositeDeliverableTableParser(format table.Format) (func(r io.Reader) (*pfd.CompositeDeliverableTable, error), error) {
 	switch format {
 	case table.FormatTSV:
 		return pfdtsv.ParseCompositeDeliverableTable, nil
+	case table.FormatCSV:
+		return pfdcsv.ParseCompositeDeliverableTable, nil
+	case table.FormatXLSX:
+		return pfdxlsx.ParseCompositeDeliverableTable, nil
 	case table.FormatHTML:
 		return nil, fmt.Errorf("pfdencoding.NewCompositeDeliverableTableParser: %q format is not supported", format)
 	default:
 		return nil, fmt.Errorf("pfdencoding.NewCompositeDeliverableTableParser: unknown format: %q", format)
 	}
 }
+
+func NewCompositeDeliverableTableWriter(format table.Format) (func(w io.Writer, table *pfd.CompositeDeliverableTable) error, error) {
+	switch format {
+	case table.FormatTSV:
+		return pfdtsv.WriteCompositeDeliverableTable, nil
+	case table.FormatCSV:
+		return pfdcsv.WriteCompositeDeliverableTable, nil
+	case table.FormatXLSX:
+		return pfdxlsx.WriteCompositeDeliverableTable, nil
+	case table.FormatHTML:
+		return pfdhtml.WriteCompositeDeliverableTable, nil
+	default:
+		return nil, fmt.Errorf("pfdencoding.NewCompositeDeliverableTableWriter: unknown format: %q", format)
+	}
+}
+
+// ParseCompositeDeliverableTable reads the table in the format detected by table.Detect.
+func ParseCompositeDeliverableTable(r io.Reader) (*pfd.CompositeDeliverableTable, error) {
+	format, r, err := table.Detect(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseCompositeDeliverableTable: %w", err)
+	}
+	parse, err := NewCompositeDeliverableTableParser(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseCompositeDeliverableTable: %w", err)
+	}
+	t, err := parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.ParseCompositeDeliverableTable: %w", err)
+	}
+	return t, nil
+}
+
+// RewriteCompositeDeliverableTable returns the table in the format of the original. The other sheets of XLSX workbooks are kept.
+func RewriteCompositeDeliverableTable(original []byte, t *pfd.CompositeDeliverableTable) ([]byte, error) {
+	format, _, err := table.Detect(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteCompositeDeliverableTable: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	if format == table.FormatXLSX {
+		if err := pfdxlsx.UpdateCompositeDeliverableTable(buf, bytes.NewReader(original), t); err != nil {
+			return nil, fmt.Errorf("pfdencoding.RewriteCompositeDeliverableTable: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	write, err := NewCompositeDeliverableTableWriter(format)
+	if err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteCompositeDeliverableTable: %w", err)
+	}
+	if err := write(buf, t); err != nil {
+		return nil, fmt.Errorf("pfdencoding.RewriteCompositeDeliverableTable: %w", err)
+	}
+	return buf.Bytes(), nil
+}
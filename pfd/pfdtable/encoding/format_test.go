@@ -0,0 +1,129 @@
+package encoding
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/table"
+	"github.com/Kuniwak/pfd-tools/table/tablexlsx"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompositeDeliverableTableRoundTrip(t *testing.T) {
+	want := &pfd.CompositeDeliverableTable{
+		ExtraHeaders: []string{"Location"},
+		Rows: []*pfd.CompositeDeliverableRow{
+			{ID: "D1", Description: "Design, review", Deliverables: []pfd.AtomicDeliverableID{"D1.1", "D1.2"}, ExtraCells: []string{"https://example.com/1"}},
+			{ID: "D2", Description: "Code", Deliverables: []pfd.AtomicDeliverableID{"D2.1"}, ExtraCells: []string{""}},
+		},
+	}
+
+	for _, format := range []table.Format{table.FormatTSV, table.FormatCSV, table.FormatXLSX} {
+		t.Run(format.String(), func(t *testing.T) {
+			write, err := NewCompositeDeliverableTableWriter(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			if err := write(buf, want); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ParseCompositeDeliverableTable(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Error(cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func TestParseAtomicProcessTable(t *testing.T) {
+	want := &pfd.AtomicProcessTable{
+		ExtraHeaders: []string{"Est. Work Volume"},
+		Rows: []*pfd.AtomicProcessRow{
+			{ID: "P1", Description: "Implement", ExtraCells: []string{"1.5"}},
+		},
+	}
+
+	testCases := map[string]string{
+		"tsv":          "ID\tDescription\tEst. Work Volume\nP1\tImplement\t1.5\n",
+		"csv":          "ID,Description,Est. Work Volume\nP1,Implement,1.5\n",
+		"csv with BOM": "\xef\xbb\xbfID,Description,Est. Work Volume\r\nP1,Implement,1.5\r\n",
+	}
+
+	for name, input := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseAtomicProcessTable(bytes.NewReader([]byte(input)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Error(cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func TestRewriteAtomicProcessTable(t *testing.T) {
+	original := &tablexlsx.Workbook{
+		Sheets: []*tablexlsx.Sheet{
+			{Name: string(pfd.TableTypeAtomicDeliverable), Rows: [][]string{{"ID", "Description"}, {"D1", "Spec"}}},
+			{Name: string(pfd.TableTypeAtomicProcess), Rows: [][]string{{"ID", "Description"}, {"P1", "Old"}}},
+		},
+	}
+	buf := &bytes.Buffer{}
+	if err := tablexlsx.WriteWorkbook(buf, original); err != nil {
+		t.Fatal(err)
+	}
+
+	ap := &pfd.AtomicProcessTable{
+		ExtraHeaders: []string{},
+		Rows:         []*pfd.AtomicProcessRow{{ID: "P1", Description: "New", ExtraCells: []string{}}},
+	}
+	bs, err := RewriteAtomicProcessTable(buf.Bytes(), ap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tablexlsx.ParseWorkbook(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &tablexlsx.Workbook{
+		Sheets: []*tablexlsx.Sheet{
+			{Name: string(pfd.TableTypeAtomicDeliverable), Rows: [][]string{{"ID", "Description"}, {"D1", "Spec"}}},
+			{Name: string(pfd.TableTypeAtomicProcess), Rows: [][]string{{"ID", "Description"}, {"P1", "New"}}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	ad, err := ParseAtomicDeliverableTable(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ad.Rows) != 1 || ad.Rows[0].ID != "D1" {
+		t.Errorf("want the atomic deliverable sheet to be kept, got %v", ad.Rows)
+	}
+}
+
+func TestRewriteAtomicProcessTableKeepsFormat(t *testing.T) {
+	ap := &pfd.AtomicProcessTable{
+		ExtraHeaders: []string{},
+		Rows:         []*pfd.AtomicProcessRow{{ID: "P1", Description: "New", ExtraCells: []string{}}},
+	}
+
+	bs, err := RewriteAtomicProcessTable([]byte("ID,Description\nP1,Old\n"), ap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "ID,Description\nP1,New\n" {
+		t.Errorf("want CSV, got %q", string(bs))
+	}
+}
@@ -0,0 +1,86 @@
+package pfdcsv
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdrecords"
+	"github.com/Kuniwak/pfd-tools/table/tablecsv"
+)
+
+func WriteAtomicProcessTable(w io.Writer, table *pfd.AtomicProcessTable) error {
+	if err := tablecsv.WriteTable(w, &tablecsv.Table{Header: table.Header(), Rows: pfdrecords.AtomicProcessTableRows(table)}); err != nil {
+		return fmt.Errorf("pfdcsv.WriteAtomicProcessTable: %w", err)
+	}
+	return nil
+}
+
+func ParseAtomicProcessTable(r io.Reader) (*pfd.AtomicProcessTable, error) {
+	t, err := tablecsv.ParseTable(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdcsv.ParseAtomicProcessTable: %w", err)
+	}
+	table, err := pfdrecords.AtomicProcessTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("pfdcsv.ParseAtomicProcessTable: %w", err)
+	}
+	return table, nil
+}
+
+func WriteAtomicDeliverableTable(w io.Writer, table *pfd.AtomicDeliverableTable) error {
+	if err := tablecsv.WriteTable(w, &tablecsv.Table{Header: table.Header(), Rows: pfdrecords.AtomicDeliverableTableRows(table)}); err != nil {
+		return fmt.Errorf("pfdcsv.WriteAtomicDeliverableTable: %w", err)
+	}
+	return nil
+}
+
+func ParseAtomicDeliverableTable(r io.Reader) (*pfd.AtomicDeliverableTable, error) {
+	t, err := tablecsv.ParseTable(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdcsv.ParseAtomicDeliverableTable: %w", err)
+	}
+	table, err := pfdrecords.AtomicDeliverableTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("pfdcsv.ParseAtomicDeliverableTable: %w", err)
+	}
+	return table, nil
+}
+
+func WriteCompositeProcessTable(w io.Writer, table *pfd.CompositeProcessTable) error {
+	if err := tablecsv.WriteTable(w, &tablecsv.Table{Header: table.Header(), Rows: pfdrecords.CompositeProcessTableRows(table)}); err != nil {
+		return fmt.Errorf("pfdcsv.WriteCompositeProcessTable: %w", err)
+	}
+	return nil
+}
+
+func ParseCompositeProcessTable(r io.Reader) (*pfd.CompositeProcessTable, error) {
+	t, err := tablecsv.ParseTable(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdcsv.ParseCompositeProcessTable: %w", err)
+	}
+	table, err := pfdrecords.CompositeProcessTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("pfdcsv.ParseCompositeProcessTable: %w", err)
+	}
+	return table, nil
+}
+
+func WriteCompositeDeliverableTable(w io.Writer, table *pfd.CompositeDeliverableTable) error {
+	if err := tablecsv.WriteTable(w, &tablecsv.Table{Header: table.Header(), Rows: pfdrecords.CompositeDeliverableTableRows(table)}); err != nil {
+		return fmt.Errorf("pfdcsv.WriteCompositeDeliverableTable: %w", err)
+	}
+	return nil
+}
+
+func ParseCompositeDeliverableTable(r io.Reader) (*pfd.CompositeDeliverableTable, error) {
+	t, err := tablecsv.ParseTable(r)
+	if err != nil {
+		return nil, fmt.Errorf("pfdcsv.ParseCompositeDeliverableTable: %w", err)
+	}
+	table, err := pfdrecords.CompositeDeliverableTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("pfdcsv.ParseCompositeDeliverableTable: %w", err)
+	}
+	return table, nil
+}
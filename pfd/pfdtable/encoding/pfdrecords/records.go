@@ -0,0 +1,114 @@
+// Package pfdrecords converts the element tables from and to the headers and the rows of cells that are common to
+// the table formats.
+package pfdrecords
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+)
+
+func AtomicProcessTable(header []string, rows [][]string) (*pfd.AtomicProcessTable, error) {
+	if err := validateColumns(header, rows, 2); err != nil {
+		return nil, fmt.Errorf("pfdrecords.AtomicProcessTable: %w", err)
+	}
+	rows2 := make([]*pfd.AtomicProcessRow, 0, len(rows))
+	for _, row := range rows {
+		rows2 = append(rows2, &pfd.AtomicProcessRow{ID: pfd.AtomicProcessID(row[0]), Description: row[1], ExtraCells: row[2:]})
+	}
+	return &pfd.AtomicProcessTable{ExtraHeaders: header[2:], Rows: rows2}, nil
+}
+
+func AtomicProcessTableRows(table *pfd.AtomicProcessTable) [][]string {
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, append([]string{string(row.ID), row.Description}, row.ExtraCells...))
+	}
+	return rows
+}
+
+func AtomicDeliverableTable(header []string, rows [][]string) (*pfd.AtomicDeliverableTable, error) {
+	if err := validateColumns(header, rows, 2); err != nil {
+		return nil, fmt.Errorf("pfdrecords.AtomicDeliverableTable: %w", err)
+	}
+	rows2 := make([]*pfd.AtomicDeliverableRow, 0, len(rows))
+	for _, row := range rows {
+		rows2 = append(rows2, &pfd.AtomicDeliverableRow{ID: pfd.AtomicDeliverableID(row[0]), Description: row[1], ExtraCells: row[2:]})
+	}
+	return &pfd.AtomicDeliverableTable{ExtraHeaders: header[2:], Rows: rows2}, nil
+}
+
+func AtomicDeliverableTableRows(table *pfd.AtomicDeliverableTable) [][]string {
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, append([]string{string(row.ID), row.Description}, row.ExtraCells...))
+	}
+	return rows
+}
+
+func CompositeProcessTable(header []string, rows [][]string) (*pfd.CompositeProcessTable, error) {
+	if err := validateColumns(header, rows, 2); err != nil {
+		return nil, fmt.Errorf("pfdrecords.CompositeProcessTable: %w", err)
+	}
+	rows2 := make([]*pfd.CompositeProcessRow, 0, len(rows))
+	for _, row := range rows {
+		rows2 = append(rows2, &pfd.CompositeProcessRow{ID: pfd.CompositeProcessID(row[0]), Description: row[1], ExtraCells: row[2:]})
+	}
+	return &pfd.CompositeProcessTable{ExtraHeaders: header[2:], Rows: rows2}, nil
+}
+
+func CompositeProcessTableRows(table *pfd.CompositeProcessTable) [][]string {
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, append([]string{string(row.ID), row.Description}, row.ExtraCells...))
+	}
+	return rows
+}
+
+// CompositeDeliverableTable returns the table of the records. The third column is the comma-separated atomic
+// deliverables of the composite deliverables.
+func CompositeDeliverableTable(header []string, rows [][]string) (*pfd.CompositeDeliverableTable, error) {
+	if err := validateColumns(header, rows, 3); err != nil {
+		return nil, fmt.Errorf("pfdrecords.CompositeDeliverableTable: %w", err)
+	}
+	rows2 := make([]*pfd.CompositeDeliverableRow, 0, len(rows))
+	for _, row := range rows {
+		ss := strings.Split(row[2], ",")
+		ds := make([]pfd.AtomicDeliverableID, 0, len(ss))
+		for _, s := range ss {
+			ds = append(ds, pfd.AtomicDeliverableID(strings.TrimSpace(s)))
+		}
+		rows2 = append(rows2, &pfd.CompositeDeliverableRow{ID: pfd.CompositeDeliverableID(row[0]), Description: row[1], Deliverables: ds, ExtraCells: row[3:]})
+	}
+	return &pfd.CompositeDeliverableTable{ExtraHeaders: header[3:], Rows: rows2}, nil
+}
+
+func CompositeDeliverableTableRows(table *pfd.CompositeDeliverableTable) [][]string {
+	rows := make([][]string, 0, len(table.Rows))
+	sb := &strings.Builder{}
+	for _, row := range table.Rows {
+		sb.Reset()
+		for i, d := range row.Deliverables {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(string(d))
+		}
+		rows = append(rows, append([]string{string(row.ID), row.Description, sb.String()}, row.ExtraCells...))
+	}
+	return rows
+}
+
+// validateColumns returns an error if the header or the rows are shorter than the columns that the table needs.
+func validateColumns(header []string, rows [][]string, n int) error {
+	if len(header) < n {
+		return fmt.Errorf("pfdrecords.validateColumns: want at least %d columns, got %d", n, len(header))
+	}
+	for i, row := range rows {
+		if len(row) < n {
+			return fmt.Errorf("pfdrecords.validateColumns: row %d: want at least %d columns, got %d", i+1, n, len(row))
+		}
+	}
+	return nil
+}
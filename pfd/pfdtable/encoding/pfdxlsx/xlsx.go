@@ -0,0 +1,134 @@
+package pfdxlsx
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdrecords"
+	"github.com/Kuniwak/pfd-tools/table/tablexlsx"
+)
+
+// WriteAtomicProcessTable writes a workbook that has only the sheet named pfd.TableTypeAtomicProcess.
+func WriteAtomicProcessTable(w io.Writer, table *pfd.AtomicProcessTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: pfdrecords.AtomicProcessTableRows(table)}
+	if err := tablexlsx.WriteTable(w, string(pfd.TableTypeAtomicProcess), t); err != nil {
+		return fmt.Errorf("pfdxlsx.WriteAtomicProcessTable: %w", err)
+	}
+	return nil
+}
+
+// UpdateAtomicProcessTable writes the original workbook with the sheet of the table replaced.
+func UpdateAtomicProcessTable(w io.Writer, original io.Reader, table *pfd.AtomicProcessTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: pfdrecords.AtomicProcessTableRows(table)}
+	if err := tablexlsx.UpdateTable(w, original, string(pfd.TableTypeAtomicProcess), t); err != nil {
+		return fmt.Errorf("pfdxlsx.UpdateAtomicProcessTable: %w", err)
+	}
+	return nil
+}
+
+// ParseAtomicProcessTable reads the sheet named pfd.TableTypeAtomicProcess, or the only sheet of the workbook.
+func ParseAtomicProcessTable(r io.Reader) (*pfd.AtomicProcessTable, error) {
+	t, err := tablexlsx.ParseTable(r, string(pfd.TableTypeAtomicProcess))
+	if err != nil {
+		return nil, fmt.Errorf("pfdxlsx.ParseAtomicProcessTable: %w", err)
+	}
+	table, err := pfdrecords.AtomicProcessTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("pfdxlsx.ParseAtomicProcessTable: %w", err)
+	}
+	return table, nil
+}
+
+// WriteAtomicDeliverableTable writes a workbook that has only the sheet named pfd.TableTypeAtomicDeliverable.
+func WriteAtomicDeliverableTable(w io.Writer, table *pfd.AtomicDeliverableTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: pfdrecords.AtomicDeliverableTableRows(table)}
+	if err := tablexlsx.WriteTable(w, string(pfd.TableTypeAtomicDeliverable), t); err != nil {
+		return fmt.Errorf("pfdxlsx.WriteAtomicDeliverableTable: %w", err)
+	}
+	return nil
+}
+
+// UpdateAtomicDeliverableTable writes the original workbook with the sheet of the table replaced.
+func UpdateAtomicDeliverableTable(w io.Writer, original io.Reader, table *pfd.AtomicDeliverableTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: pfdrecords.AtomicDeliverableTableRows(table)}
+	if err := tablexlsx.UpdateTable(w, original, string(pfd.TableTypeAtomicDeliverable), t); err != nil {
+		return fmt.Errorf("pfdxlsx.UpdateAtomicDeliverableTable: %w", err)
+	}
+	return nil
+}
+
+// ParseAtomicDeliverableTable reads the sheet named pfd.TableTypeAtomicDeliverable, or the only sheet of the workbook.
+func ParseAtomicDeliverableTable(r io.Reader) (*pfd.AtomicDeliverableTable, error) {
+	t, err := tablexlsx.ParseTable(r, string(pfd.TableTypeAtomicDeliverable))
+	if err != nil {
+		return nil, fmt.Errorf("pfdxlsx.ParseAtomicDeliverableTable: %w", err)
+	}
+	table, err := pfdrecords.AtomicDeliverableTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("pfdxlsx.ParseAtomicDeliverableTable: %w", err)
+	}
+	return table, nil
+}
+
+// WriteCompositeProcessTable writes a workbook that has only the sheet named pfd.TableTypeCompositeProcess.
+func WriteCompositeProcessTable(w io.Writer, table *pfd.CompositeProcessTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: pfdrecords.CompositeProcessTableRows(table)}
+	if err := tablexlsx.WriteTable(w, string(pfd.TableTypeCompositeProcess), t); err != nil {
+		return fmt.Errorf("pfdxlsx.WriteCompositeProcessTable: %w", err)
+	}
+	return nil
+}
+
+// UpdateCompositeProcessTable writes the original workbook with the sheet of the table replaced.
+func UpdateCompositeProcessTable(w io.Writer, original io.Reader, table *pfd.CompositeProcessTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: pfdrecords.CompositeProcessTableRows(table)}
+	if err := tablexlsx.UpdateTable(w, original, string(pfd.TableTypeCompositeProcess), t); err != nil {
+		return fmt.Errorf("pfdxlsx.UpdateCompositeProcessTable: %w", err)
+	}
+	return nil
+}
+
+// ParseCompositeProcessTable reads the sheet named pfd.TableTypeCompositeProcess, or the only sheet of the workbook.
+func ParseCompositeProcessTable(r io.Reader) (*pfd.CompositeProcessTable, error) {
+	t, err := tablexlsx.ParseTable(r, string(pfd.TableTypeCompositeProcess))
+	if err != nil {
+		return nil, fmt.Errorf("pfdxlsx.ParseCompositeProcessTable: %w", err)
+	}
+	table, err := pfdrecords.CompositeProcessTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("pfdxlsx.ParseCompositeProcessTable: %w", err)
+	}
+	return table, nil
+}
+
+// WriteCompositeDeliverableTable writes a workbook that has only the sheet named pfd.TableTypeCompositeDeliverable.
+func WriteCompositeDeliverableTable(w io.Writer, table *pfd.CompositeDeliverableTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: pfdrecords.CompositeDeliverableTableRows(table)}
+	if err := tablexlsx.WriteTable(w, string(pfd.TableTypeCompositeDeliverable), t); err != nil {
+		return fmt.Errorf("pfdxlsx.WriteCompositeDeliverableTable: %w", err)
+	}
+	return nil
+}
+
+// UpdateCompositeDeliverableTable writes the original workbook with the sheet of the table replaced.
+func UpdateCompositeDeliverableTable(w io.Writer, original io.Reader, table *pfd.CompositeDeliverableTable) error {
+	t := &tablexlsx.Table{Header: table.Header(), Rows: pfdrecords.CompositeDeliverableTableRows(table)}
+	if err := tablexlsx.UpdateTable(w, original, string(pfd.TableTypeCompositeDeliverable), t); err != nil {
+		return fmt.Errorf("pfdxlsx.UpdateCompositeDeliverableTable: %w", err)
+	}
+	return nil
+}
+
+// ParseCompositeDeliverableTable reads the sheet named pfd.TableTypeCompositeDeliverable, or the only sheet of the workbook.
+func ParseCompositeDeliverableTable(r io.Reader) (*pfd.CompositeDeliverableTable, error) {
+	t, err := tablexlsx.ParseTable(r, string(pfd.TableTypeCompositeDeliverable))
+	if err != nil {
+		return nil, fmt.Errorf("pfdxlsx.ParseCompositeDeliverableTable: %w", err)
+	}
+	table, err := pfdrecords.CompositeDeliverableTable(t.Header, t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("pfdxlsx.ParseCompositeDeliverableTable: %w", err)
+	}
+	return table, nil
+}
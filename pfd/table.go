@@ -405,3 +405,12 @@ func ColumnMatchFunc(headerNameCandidates *sets.Set[string]) ColumnSelectFunc {
 		})
 	}
 }
+
+// Header returns the selected header string. Returns an empty string if there is no corresponding header string.
+func (f ColumnSelectFunc) Header(header []string) string {
+	idx := f(header)
+	if idx < 0 {
+		return ""
+	}
+	return header[idx]
+}
@@ -1,12 +1,72 @@
 package table
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
 type Format string
 
 const (
-	FormatTSV  Format = "tsv"
-	FormatHTML Format = "html"
+	FormatTSV     Format = "tsv"
+	FormatCSV     Format = "csv"
+	FormatXLSX    Format = "xlsx"
+	FormatHTML    Format = "html"
+	FormatUnknown Format = "unknown"
 )
 
 func (f Format) String() string {
 	return string(f)
 }
+
+// ParseFormat returns the format of the name. Empty names are unknown formats, and callers should detect the format
+// by Detect.
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "":
+		return FormatUnknown, nil
+	case "tsv":
+		return FormatTSV, nil
+	case "csv":
+		return FormatCSV, nil
+	case "xlsx":
+		return FormatXLSX, nil
+	case "html":
+		return FormatHTML, nil
+	default:
+		return FormatUnknown, fmt.Errorf("table.ParseFormat: unknown format: %q", s)
+	}
+}
+
+const (
+	xlsxPrefix = "PK\x03\x04"
+	htmlPrefix = "<"
+	utf8BOM    = "\xef\xbb\xbf"
+)
+
+// Detect returns the format of the table and the reader of the whole table. The UTF-8 BOM that spreadsheet
+// applications prepend to CSV files is removed. Text tables are TSV if the header has tabs, and CSV if the header has
+// commas but no tabs. Tables of a single column are TSV.
+func Detect(r io.Reader) (Format, io.Reader, error) {
+	// NOTE: XLSX files are ZIP archives and their central directories are at the end, so read all of them.
+	all, err := io.ReadAll(r)
+	if err != nil {
+		return FormatUnknown, r, fmt.Errorf("table.Detect: %w", err)
+	}
+
+	if bytes.HasPrefix(all, []byte(xlsxPrefix)) {
+		return FormatXLSX, bytes.NewReader(all), nil
+	}
+
+	all = bytes.TrimPrefix(all, []byte(utf8BOM))
+	if bytes.HasPrefix(bytes.TrimSpace(all), []byte(htmlPrefix)) {
+		return FormatHTML, bytes.NewReader(all), nil
+	}
+
+	header, _, _ := bytes.Cut(all, []byte("\n"))
+	if !bytes.Contains(header, []byte("\t")) && bytes.Contains(header, []byte(",")) {
+		return FormatCSV, bytes.NewReader(all), nil
+	}
+	return FormatTSV, bytes.NewReader(all), nil
+}
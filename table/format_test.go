@@ -0,0 +1,70 @@
+package table
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := map[string]struct {
+		Input        string
+		Expected     Format
+		ExpectedBody string
+	}{
+		"tsv": {
+			Input:        "ID\tDescription\nP1\tImplement, test\n",
+			Expected:     FormatTSV,
+			ExpectedBody: "ID\tDescription\nP1\tImplement, test\n",
+		},
+		"tsv with commas in header": {
+			Input:        "ID\tDescription\tNeeded Resources, Volume\n",
+			Expected:     FormatTSV,
+			ExpectedBody: "ID\tDescription\tNeeded Resources, Volume\n",
+		},
+		"csv": {
+			Input:        "ID,Description\nP1,\"Implement\ttest\"\n",
+			Expected:     FormatCSV,
+			ExpectedBody: "ID,Description\nP1,\"Implement\ttest\"\n",
+		},
+		"csv with BOM": {
+			Input:        "\xef\xbb\xbfID,Description\r\nP1,Implement\r\n",
+			Expected:     FormatCSV,
+			ExpectedBody: "ID,Description\r\nP1,Implement\r\n",
+		},
+		"single column": {
+			Input:        "ID\nP1\n",
+			Expected:     FormatTSV,
+			ExpectedBody: "ID\nP1\n",
+		},
+		"xlsx": {
+			Input:        "PK\x03\x04rest",
+			Expected:     FormatXLSX,
+			ExpectedBody: "PK\x03\x04rest",
+		},
+		"html": {
+			Input:        "<table><tr><th>ID</th></tr></table>",
+			Expected:     FormatHTML,
+			ExpectedBody: "<table><tr><th>ID</th></tr></table>",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			f, r, err := Detect(strings.NewReader(testCase.Input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f != testCase.Expected {
+				t.Errorf("want %q, got %q", testCase.Expected, f)
+			}
+			body, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != testCase.ExpectedBody {
+				t.Errorf("want %q, got %q", testCase.ExpectedBody, string(body))
+			}
+		})
+	}
+}
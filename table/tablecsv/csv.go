@@ -0,0 +1,52 @@
+package tablecsv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+func NewTable(header []string, rows [][]string) (*Table, error) {
+	for _, row := range rows {
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("tablecsv.NewTable: row length mismatch")
+		}
+	}
+	return &Table{Header: header, Rows: rows}, nil
+}
+
+func ParseTable(r io.Reader) (*Table, error) {
+	csvReader := csv.NewReader(r)
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("tablecsv.ParseTable: %w", err)
+	}
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("tablecsv.ParseTable: %w", err)
+	}
+	return &Table{Header: header, Rows: rows}, nil
+}
+
+func WriteTable(w io.Writer, table *Table) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(table.Header); err != nil {
+		return fmt.Errorf("tablecsv.WriteTable: %w", err)
+	}
+
+	for _, row := range table.Rows {
+		if err := csvWriter.Write(row); err != nil {
+			return fmt.Errorf("tablecsv.WriteTable: %w", err)
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("tablecsv.WriteTable: %w", err)
+	}
+	return nil
+}
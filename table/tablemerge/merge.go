@@ -0,0 +1,403 @@
+package tablemerge
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Table is a table keyed by the first column. All the tables of PFDs and FSMs are keyed by element IDs in the first
+// column.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Conflict is the cell or the row that both ours and theirs changed differently. Column is empty for rows that one
+// side deleted and the other side modified, and then Base, Ours and Theirs are the whole rows or nil if they are
+// missing. Otherwise, they are the cells of the column.
+type Conflict struct {
+	ID     string   `json:"id"`
+	Column string   `json:"column,omitempty"`
+	Base   []string `json:"base"`
+	Ours   []string `json:"ours"`
+	Theirs []string `json:"theirs"`
+}
+
+// Row is a merged row. Ours and Theirs are the same cells if the row has no conflicts. Otherwise, conflicted cells are
+// the ones of each side, and they are nil if the side deleted the row.
+type Row struct {
+	ID         string
+	Ours       []string
+	Theirs     []string
+	Conflicted bool
+}
+
+// Result is the merged table. Rows are in the merged order, and Conflicts are in the order of the rows.
+type Result struct {
+	Header    []string
+	Rows      []*Row
+	Conflicts []Conflict
+}
+
+// Merge merges ours and theirs row-wise and cell-wise. Rows are matched by IDs in the first column, and cells are
+// matched by the column names, so reordered columns are merged. Added rows and columns of both sides are kept, and
+// deleted rows and columns are deleted unless the other side modified them. Columns that one side deleted and the
+// other side modified are kept, and the modified cells are conflicts. The base may be empty without the header for the
+// tables that both sides added.
+func Merge(base, ours, theirs *Table) (*Result, error) {
+	baseIndex := &index{columns: map[string]int{}, rows: map[string][]string{}}
+	if len(base.Header) > 0 || len(base.Rows) > 0 {
+		var err error
+		baseIndex, err = newIndex(base)
+		if err != nil {
+			return nil, fmt.Errorf("tablemerge.Merge: base: %w", err)
+		}
+	}
+	oursIndex, err := newIndex(ours)
+	if err != nil {
+		return nil, fmt.Errorf("tablemerge.Merge: ours: %w", err)
+	}
+	theirsIndex, err := newIndex(theirs)
+	if err != nil {
+		return nil, fmt.Errorf("tablemerge.Merge: theirs: %w", err)
+	}
+
+	header, oursDeleted, theirsDeleted := mergeHeader(base.Header, ours.Header, theirs.Header)
+
+	res := &Result{Header: header, Rows: make([]*Row, 0, len(ours.Rows)), Conflicts: make([]Conflict, 0)}
+	for _, id := range mergeIDs(ours, theirs) {
+		baseRow, hasBase := baseIndex.row(id, header)
+		oursRow, hasOurs := oursIndex.row(id, header)
+		theirsRow, hasTheirs := theirsIndex.row(id, header)
+
+		switch {
+		case hasOurs && hasTheirs:
+			if !hasBase {
+				// NOTE: Rows added by both sides are merged as if the base has the row of empty cells.
+				baseRow = make([]string, len(header))
+			}
+			row := &Row{ID: id, Ours: make([]string, len(header)), Theirs: make([]string, len(header))}
+			for i, column := range header {
+				var cell string
+				var ok bool
+				switch {
+				case oursDeleted[i]:
+					cell, ok = "", isUnmodified(baseRow[i], theirsRow[i])
+				case theirsDeleted[i]:
+					cell, ok = "", isUnmodified(baseRow[i], oursRow[i])
+				default:
+					cell, ok = mergeCell(baseRow[i], oursRow[i], theirsRow[i])
+				}
+				if ok {
+					row.Ours[i] = cell
+					row.Theirs[i] = cell
+					continue
+				}
+				row.Ours[i] = oursRow[i]
+				row.Theirs[i] = theirsRow[i]
+				row.Conflicted = true
+				res.Conflicts = append(res.Conflicts, Conflict{
+					ID:     id,
+					Column: column,
+					Base:   []string{baseRow[i]},
+					Ours:   []string{oursRow[i]},
+					Theirs: []string{theirsRow[i]},
+				})
+			}
+			res.Rows = append(res.Rows, row)
+
+		case hasOurs:
+			if !hasBase {
+				res.Rows = append(res.Rows, addedRow(res, id, header, oursRow, theirsDeleted, false))
+				continue
+			}
+			if equalExcept(baseRow, oursRow, oursDeleted) {
+				// NOTE: Theirs deleted the row.
+				continue
+			}
+			res.Rows = append(res.Rows, &Row{ID: id, Ours: oursRow, Theirs: nil, Conflicted: true})
+			res.Conflicts = append(res.Conflicts, Conflict{ID: id, Base: baseRow, Ours: oursRow, Theirs: nil})
+
+		case hasTheirs:
+			if !hasBase {
+				res.Rows = append(res.Rows, addedRow(res, id, header, theirsRow, oursDeleted, true))
+				continue
+			}
+			if equalExcept(baseRow, theirsRow, theirsDeleted) {
+				// NOTE: Ours deleted the row.
+				continue
+			}
+			res.Rows = append(res.Rows, &Row{ID: id, Ours: nil, Theirs: theirsRow, Conflicted: true})
+			res.Conflicts = append(res.Conflicts, Conflict{ID: id, Base: baseRow, Ours: nil, Theirs: theirsRow})
+		}
+	}
+
+	res.dropColumns(func(i int) bool {
+		if !oursDeleted[i] && !theirsDeleted[i] {
+			return false
+		}
+		for _, conflict := range res.Conflicts {
+			if conflict.Column == header[i] {
+				return false
+			}
+		}
+		return true
+	})
+	return res, nil
+}
+
+// addedRow returns the row that only one side added. The cells in the columns that the other side deleted are
+// conflicts unless they are empty. isTheirs is true if theirs added the row.
+func addedRow(res *Result, id string, header []string, cells []string, deleted []bool, isTheirs bool) *Row {
+	cleared := slices.Clone(cells)
+	conflicted := false
+	for i, column := range header {
+		if !deleted[i] || cells[i] == "" {
+			continue
+		}
+		cleared[i] = ""
+		conflicted = true
+		conflict := Conflict{ID: id, Column: column, Base: []string{""}, Ours: []string{cells[i]}, Theirs: []string{""}}
+		if isTheirs {
+			conflict.Ours, conflict.Theirs = conflict.Theirs, conflict.Ours
+		}
+		res.Conflicts = append(res.Conflicts, conflict)
+	}
+	if !conflicted {
+		return &Row{ID: id, Ours: cells, Theirs: cells}
+	}
+	if isTheirs {
+		return &Row{ID: id, Ours: cleared, Theirs: cells, Conflicted: true}
+	}
+	return &Row{ID: id, Ours: cells, Theirs: cleared, Conflicted: true}
+}
+
+// dropColumns removes the columns from the header, the rows and the conflicts of whole rows.
+func (r *Result) dropColumns(drop func(i int) bool) {
+	keep := make([]int, 0, len(r.Header))
+	for i := range r.Header {
+		if !drop(i) {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == len(r.Header) {
+		return
+	}
+
+	pick := func(cells []string) []string {
+		if cells == nil {
+			return nil
+		}
+		picked := make([]string, 0, len(keep))
+		for _, i := range keep {
+			picked = append(picked, cells[i])
+		}
+		return picked
+	}
+	r.Header = pick(r.Header)
+	for _, row := range r.Rows {
+		row.Ours = pick(row.Ours)
+		row.Theirs = pick(row.Theirs)
+	}
+	for i, conflict := range r.Conflicts {
+		if conflict.Column != "" {
+			continue
+		}
+		r.Conflicts[i].Base = pick(conflict.Base)
+		r.Conflicts[i].Ours = pick(conflict.Ours)
+		r.Conflicts[i].Theirs = pick(conflict.Theirs)
+	}
+}
+
+// Table returns the merged table that conflicts are resolved to ours.
+func (r *Result) Table() *Table {
+	rows := make([][]string, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		if row.Ours == nil {
+			continue
+		}
+		rows = append(rows, row.Ours)
+	}
+	return &Table{Header: r.Header, Rows: rows}
+}
+
+const (
+	MarkerOurs      = "<<<<<<< ours"
+	MarkerSeparator = "======="
+	MarkerTheirs    = ">>>>>>> theirs"
+)
+
+// WriteMarkers writes the merged table in the text format of the delimiter. Conflicted rows are written with git-style
+// conflict markers, so they can be resolved in text editors.
+func WriteMarkers(w io.Writer, r *Result, comma rune) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = comma
+
+	writeMarker := func(marker string) error {
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(w, marker)
+		return err
+	}
+
+	if err := csvWriter.Write(r.Header); err != nil {
+		return fmt.Errorf("tablemerge.WriteMarkers: %w", err)
+	}
+	for _, row := range r.Rows {
+		if !row.Conflicted {
+			if err := csvWriter.Write(row.Ours); err != nil {
+				return fmt.Errorf("tablemerge.WriteMarkers: %w", err)
+			}
+			continue
+		}
+
+		if err := writeMarker(MarkerOurs); err != nil {
+			return fmt.Errorf("tablemerge.WriteMarkers: %w", err)
+		}
+		if row.Ours != nil {
+			if err := csvWriter.Write(row.Ours); err != nil {
+				return fmt.Errorf("tablemerge.WriteMarkers: %w", err)
+			}
+		}
+		if err := writeMarker(MarkerSeparator); err != nil {
+			return fmt.Errorf("tablemerge.WriteMarkers: %w", err)
+		}
+		if row.Theirs != nil {
+			if err := csvWriter.Write(row.Theirs); err != nil {
+				return fmt.Errorf("tablemerge.WriteMarkers: %w", err)
+			}
+		}
+		if err := writeMarker(MarkerTheirs); err != nil {
+			return fmt.Errorf("tablemerge.WriteMarkers: %w", err)
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("tablemerge.WriteMarkers: %w", err)
+	}
+	return nil
+}
+
+func mergeCell(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs:
+		return ours, true
+	case ours == base:
+		return theirs, true
+	case theirs == base:
+		return ours, true
+	default:
+		return "", false
+	}
+}
+
+// mergeHeader returns the columns of ours followed by the ones that only theirs has. It also returns whether each
+// column is the one that the side deleted from the base. Columns that both sides deleted are not returned.
+func mergeHeader(base, ours, theirs []string) ([]string, []bool, []bool) {
+	header := make([]string, 0, len(ours))
+	oursDeleted := make([]bool, 0, len(ours))
+	theirsDeleted := make([]bool, 0, len(ours))
+	for i, column := range ours {
+		header = append(header, column)
+		oursDeleted = append(oursDeleted, false)
+		// NOTE: The ID column is never deleted even if it is renamed.
+		theirsDeleted = append(theirsDeleted, i > 0 && slices.Contains(base, column) && !slices.Contains(theirs, column))
+	}
+	for _, column := range theirs[1:] {
+		if slices.Contains(ours, column) {
+			continue
+		}
+		header = append(header, column)
+		oursDeleted = append(oursDeleted, slices.Contains(base, column))
+		theirsDeleted = append(theirsDeleted, false)
+	}
+	return header, oursDeleted, theirsDeleted
+}
+
+// isUnmodified returns true if the cell of the column that the other side deleted is not modified. Cleared cells are
+// not modifications because the deletion clears them too.
+func isUnmodified(base, cell string) bool {
+	return cell == base || cell == ""
+}
+
+// equalExcept returns true if the rows are equal except in the columns that the side deleted.
+func equalExcept(base, row []string, deleted []bool) bool {
+	for i := range base {
+		if !deleted[i] && base[i] != row[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeIDs returns the IDs of ours followed by the ones only theirs has. The rows only theirs has are placed after the
+// rows that precede them in theirs, so that sorted tables are kept sorted.
+func mergeIDs(ours, theirs *Table) []string {
+	ids := make([]string, 0, len(ours.Rows))
+	for _, row := range ours.Rows {
+		ids = append(ids, row[0])
+	}
+
+	next := 0
+	for _, row := range theirs.Rows {
+		id := row[0]
+		if idx := slices.Index(ids, id); idx >= 0 {
+			next = idx + 1
+			continue
+		}
+		ids = slices.Insert(ids, next, id)
+		next++
+	}
+	return ids
+}
+
+type index struct {
+	columns map[string]int
+	rows    map[string][]string
+}
+
+func newIndex(t *Table) (*index, error) {
+	if len(t.Header) == 0 {
+		return nil, fmt.Errorf("tablemerge.newIndex: missing header")
+	}
+
+	columns := make(map[string]int, len(t.Header))
+	for i, column := range t.Header {
+		columns[column] = i
+	}
+
+	rows := make(map[string][]string, len(t.Rows))
+	for _, row := range t.Rows {
+		if len(row) == 0 {
+			return nil, fmt.Errorf("tablemerge.newIndex: empty row")
+		}
+		if _, ok := rows[row[0]]; ok {
+			return nil, fmt.Errorf("tablemerge.newIndex: duplicate ID: %q", row[0])
+		}
+		rows[row[0]] = row
+	}
+	return &index{columns: columns, rows: rows}, nil
+}
+
+// row returns the cells of the row in the order of the header. Cells of missing columns are empty.
+func (idx *index) row(id string, header []string) ([]string, bool) {
+	row, ok := idx.rows[id]
+	if !ok {
+		return nil, false
+	}
+
+	cells := make([]string, len(header))
+	cells[0] = id
+	for i, column := range header[1:] {
+		j, ok := idx.columns[column]
+		if !ok || j >= len(row) {
+			continue
+		}
+		cells[i+1] = row[j]
+	}
+	return cells, true
+}
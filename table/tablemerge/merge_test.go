@@ -0,0 +1,147 @@
+package tablemerge
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMerge(t *testing.T) {
+	testCases := map[string]struct {
+		Base              *Table
+		Ours              *Table
+		Theirs            *Table
+		Expected          *Table
+		ExpectedConflicts []Conflict
+	}{
+		"cells changed by each side": {
+			Base:              &Table{Header: []string{"ID", "Description", "Volume"}, Rows: [][]string{{"P1", "Design", "1"}}},
+			Ours:              &Table{Header: []string{"ID", "Description", "Volume"}, Rows: [][]string{{"P1", "Design API", "1"}}},
+			Theirs:            &Table{Header: []string{"ID", "Description", "Volume"}, Rows: [][]string{{"P1", "Design", "2"}}},
+			Expected:          &Table{Header: []string{"ID", "Description", "Volume"}, Rows: [][]string{{"P1", "Design API", "2"}}},
+			ExpectedConflicts: []Conflict{},
+		},
+		"rows added and deleted": {
+			Base:              &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}, {"P2", "B"}, {"P4", "D"}}},
+			Ours:              &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}, {"P4", "D"}, {"P5", "E"}}},
+			Theirs:            &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}, {"P2", "B"}, {"P3", "C"}}},
+			Expected:          &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}, {"P3", "C"}, {"P5", "E"}}},
+			ExpectedConflicts: []Conflict{},
+		},
+		"columns added and reordered": {
+			Base:              &Table{Header: []string{"ID", "Description", "Old"}, Rows: [][]string{{"P1", "A", "x"}}},
+			Ours:              &Table{Header: []string{"ID", "Old", "Description", "Ours"}, Rows: [][]string{{"P1", "x", "A", "o"}}},
+			Theirs:            &Table{Header: []string{"ID", "Description", "Theirs"}, Rows: [][]string{{"P1", "B", "t"}}},
+			Expected:          &Table{Header: []string{"ID", "Description", "Ours", "Theirs"}, Rows: [][]string{{"P1", "B", "o", "t"}}},
+			ExpectedConflicts: []Conflict{},
+		},
+		"column deleted and modified": {
+			Base:     &Table{Header: []string{"ID", "Description", "Note"}, Rows: [][]string{{"P1", "A", "x"}, {"P2", "B", "y"}}},
+			Ours:     &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}, {"P2", "B"}}},
+			Theirs:   &Table{Header: []string{"ID", "Description", "Note"}, Rows: [][]string{{"P1", "A", "IMPORTANT"}, {"P2", "B", "y"}}},
+			Expected: &Table{Header: []string{"ID", "Description", "Note"}, Rows: [][]string{{"P1", "A", ""}, {"P2", "B", ""}}},
+			ExpectedConflicts: []Conflict{
+				{ID: "P1", Column: "Note", Base: []string{"x"}, Ours: []string{""}, Theirs: []string{"IMPORTANT"}},
+			},
+		},
+		"column deleted and row added": {
+			Base:     &Table{Header: []string{"ID", "Description", "Note"}, Rows: [][]string{{"P1", "A", "x"}}},
+			Ours:     &Table{Header: []string{"ID", "Description", "Note"}, Rows: [][]string{{"P1", "A", "x"}, {"P2", "B", "new"}}},
+			Theirs:   &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}}},
+			Expected: &Table{Header: []string{"ID", "Description", "Note"}, Rows: [][]string{{"P1", "A", ""}, {"P2", "B", "new"}}},
+			ExpectedConflicts: []Conflict{
+				{ID: "P2", Column: "Note", Base: []string{""}, Ours: []string{"new"}, Theirs: []string{""}},
+			},
+		},
+		"column and row deleted": {
+			Base:              &Table{Header: []string{"ID", "Description", "Note"}, Rows: [][]string{{"P1", "A", "x"}, {"P2", "B", "y"}}},
+			Ours:              &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}, {"P2", "B"}}},
+			Theirs:            &Table{Header: []string{"ID", "Description", "Note"}, Rows: [][]string{{"P1", "A", "x"}}},
+			Expected:          &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}}},
+			ExpectedConflicts: []Conflict{},
+		},
+		"cell conflict": {
+			Base:     &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}}},
+			Ours:     &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "B"}}},
+			Theirs:   &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "C"}}},
+			Expected: &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "B"}}},
+			ExpectedConflicts: []Conflict{
+				{ID: "P1", Column: "Description", Base: []string{"A"}, Ours: []string{"B"}, Theirs: []string{"C"}},
+			},
+		},
+		"delete and modify": {
+			Base:     &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}}},
+			Ours:     &Table{Header: []string{"ID", "Description"}, Rows: [][]string{}},
+			Theirs:   &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "B"}}},
+			Expected: &Table{Header: []string{"ID", "Description"}, Rows: [][]string{}},
+			ExpectedConflicts: []Conflict{
+				{ID: "P1", Base: []string{"P1", "A"}, Ours: nil, Theirs: []string{"P1", "B"}},
+			},
+		},
+		"added by both sides": {
+			Base:              &Table{Header: []string{"ID", "Description", "Volume"}, Rows: [][]string{}},
+			Ours:              &Table{Header: []string{"ID", "Description", "Volume"}, Rows: [][]string{{"P1", "A", ""}}},
+			Theirs:            &Table{Header: []string{"ID", "Description", "Volume"}, Rows: [][]string{{"P1", "A", "3"}}},
+			Expected:          &Table{Header: []string{"ID", "Description", "Volume"}, Rows: [][]string{{"P1", "A", "3"}}},
+			ExpectedConflicts: []Conflict{},
+		},
+		"table added by both sides": {
+			Base:              &Table{},
+			Ours:              &Table{Header: []string{"ID", "Description", "Ours"}, Rows: [][]string{{"P1", "A", "o"}, {"P2", "B", ""}}},
+			Theirs:            &Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}, {"P3", "C"}}},
+			Expected:          &Table{Header: []string{"ID", "Description", "Ours"}, Rows: [][]string{{"P1", "A", "o"}, {"P3", "C", ""}, {"P2", "B", ""}}},
+			ExpectedConflicts: []Conflict{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			res, err := Merge(tc.Base, tc.Ours, tc.Theirs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual := res.Table()
+			if !reflect.DeepEqual(actual, tc.Expected) {
+				t.Error(cmp.Diff(tc.Expected, actual))
+			}
+			if !reflect.DeepEqual(res.Conflicts, tc.ExpectedConflicts) {
+				t.Error(cmp.Diff(tc.ExpectedConflicts, res.Conflicts))
+			}
+		})
+	}
+}
+
+func TestMergeDuplicateID(t *testing.T) {
+	base := &Table{Header: []string{"ID"}, Rows: [][]string{{"P1"}, {"P1"}}}
+	ours := &Table{Header: []string{"ID"}, Rows: [][]string{}}
+	if _, err := Merge(base, ours, ours); err == nil {
+		t.Error("want error for duplicate IDs")
+	}
+}
+
+func TestWriteMarkers(t *testing.T) {
+	res, err := Merge(
+		&Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A"}, {"P2", "B"}, {"P3", "C"}}},
+		&Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A1"}, {"P3", "C"}}},
+		&Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "A2"}, {"P2", "B2"}, {"P3", "C"}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteMarkers(buf, res, '\t'); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "ID\tDescription\n" +
+		"<<<<<<< ours\nP1\tA1\n=======\nP1\tA2\n>>>>>>> theirs\n" +
+		"<<<<<<< ours\n=======\nP2\tB2\n>>>>>>> theirs\n" +
+		"P3\tC\n"
+	if buf.String() != expected {
+		t.Error(cmp.Diff(expected, buf.String()))
+	}
+}
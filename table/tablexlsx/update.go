@@ -0,0 +1,570 @@
+package tablexlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	relTypeCalcChain     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/calcChain"
+	contentTypesPath     = "[Content_Types].xml"
+	worksheetContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"
+)
+
+// UpdateTable writes the original workbook with the sheet of the table replaced. The sheet is selected in the same
+// way as ParseTable, so the other sheets are kept and workbooks can have several tables. See UpdateSheet for the parts
+// that are kept.
+func UpdateTable(w io.Writer, original io.Reader, name string, table *Table) error {
+	pkg, err := openPackage(original)
+	if err != nil {
+		return fmt.Errorf("tablexlsx.UpdateTable: %w", err)
+	}
+	if ref, ok := pkg.tableSheetRef(name); ok {
+		name = ref.Name
+	}
+	if err := pkg.writeSheet(w, SheetByTable(name, table)); err != nil {
+		return fmt.Errorf("tablexlsx.UpdateTable: %w", err)
+	}
+	return nil
+}
+
+// UpdateSheet writes the original workbook with the sheet of the same name replaced, or with the sheet appended if
+// there is no such sheet. Only the part of the sheet is rewritten, and the other parts such as the other sheets, styles
+// and shared strings are copied unchanged. In the sheet, column widths and row heights are kept, and the cells of the
+// same values are kept as they are with their formulas and styles.
+func UpdateSheet(w io.Writer, original io.Reader, sheet *Sheet) error {
+	pkg, err := openPackage(original)
+	if err != nil {
+		return fmt.Errorf("tablexlsx.UpdateSheet: %w", err)
+	}
+	if err := pkg.writeSheet(w, sheet); err != nil {
+		return fmt.Errorf("tablexlsx.UpdateSheet: %w", err)
+	}
+	return nil
+}
+
+// xlsxPackage is the parts of an XLSX file that locate the sheets.
+type xlsxPackage struct {
+	zr               *zip.Reader
+	files            map[string]*zip.File
+	workbookPath     string
+	workbookRelsPath string
+	workbook         xlsxWorkbook
+	workbookRels     xlsxRelationships
+	targets          map[string]string
+	sharedStrings    []string
+}
+
+func openPackage(r io.Reader) (*xlsxPackage, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("tablexlsx.openPackage: %w", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(bs), int64(len(bs)))
+	if err != nil {
+		return nil, fmt.Errorf("tablexlsx.openPackage: %w", err)
+	}
+	pkg := &xlsxPackage{zr: zr, files: make(map[string]*zip.File, len(zr.File))}
+	for _, f := range zr.File {
+		pkg.files[f.Name] = f
+	}
+
+	pkg.workbookPath = defaultWorkbookPath
+	var rootRels xlsxRelationships
+	if ok, err := decodeXMLFile(pkg.files, "_rels/.rels", &rootRels); err != nil {
+		return nil, fmt.Errorf("tablexlsx.openPackage: %w", err)
+	} else if ok {
+		for _, rel := range rootRels.Relationships {
+			if rel.Type == relTypeOfficeDocument {
+				pkg.workbookPath = resolveTarget("", rel.Target)
+			}
+		}
+	}
+
+	if ok, err := decodeXMLFile(pkg.files, pkg.workbookPath, &pkg.workbook); err != nil {
+		return nil, fmt.Errorf("tablexlsx.openPackage: %w", err)
+	} else if !ok {
+		return nil, fmt.Errorf("tablexlsx.openPackage: missing workbook: %q", pkg.workbookPath)
+	}
+
+	workbookDir := path.Dir(pkg.workbookPath)
+	pkg.workbookRelsPath = path.Join(workbookDir, "_rels", path.Base(pkg.workbookPath)+".rels")
+	if _, err := decodeXMLFile(pkg.files, pkg.workbookRelsPath, &pkg.workbookRels); err != nil {
+		return nil, fmt.Errorf("tablexlsx.openPackage: %w", err)
+	}
+	pkg.targets = make(map[string]string, len(pkg.workbookRels.Relationships))
+	sharedStringsPath := path.Join(workbookDir, "sharedStrings.xml")
+	for _, rel := range pkg.workbookRels.Relationships {
+		pkg.targets[rel.ID] = resolveTarget(workbookDir, rel.Target)
+		if rel.Type == relTypeSharedStrings {
+			sharedStringsPath = resolveTarget(workbookDir, rel.Target)
+		}
+	}
+
+	var sst xlsxSharedStrings
+	if _, err := decodeXMLFile(pkg.files, sharedStringsPath, &sst); err != nil {
+		return nil, fmt.Errorf("tablexlsx.openPackage: %w", err)
+	}
+	pkg.sharedStrings = make([]string, 0, len(sst.Items))
+	for _, item := range sst.Items {
+		pkg.sharedStrings = append(pkg.sharedStrings, item.String())
+	}
+	return pkg, nil
+}
+
+// worksheetPath returns the path of the part of the sheet.
+func (pkg *xlsxPackage) worksheetPath(ref *xlsxSheetRef) (string, error) {
+	target, ok := pkg.targets[ref.RelationshipID]
+	if !ok {
+		return "", fmt.Errorf("tablexlsx.xlsxPackage.worksheetPath: %q: missing relationship: %q", ref.Name, ref.RelationshipID)
+	}
+	return target, nil
+}
+
+// tableSheetRef returns the sheet selected in the same way as Workbook.TableSheet.
+func (pkg *xlsxPackage) tableSheetRef(name string) (*xlsxSheetRef, bool) {
+	if ref, ok := pkg.sheetRef(name); ok {
+		return ref, true
+	}
+	if len(pkg.workbook.Sheets) == 1 {
+		return &pkg.workbook.Sheets[0], true
+	}
+	return nil, false
+}
+
+func (pkg *xlsxPackage) sheetRef(name string) (*xlsxSheetRef, bool) {
+	for i := range pkg.workbook.Sheets {
+		if pkg.workbook.Sheets[i].Name == name {
+			return &pkg.workbook.Sheets[i], true
+		}
+	}
+	return nil, false
+}
+
+// writeSheet writes the package with the sheet replaced or appended.
+func (pkg *xlsxPackage) writeSheet(w io.Writer, sheet *Sheet) error {
+	if err := validateSheetName(sheet.Name); err != nil {
+		return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+	}
+
+	replaced := make(map[string][]byte)
+	removed := make(map[string]bool)
+	added := make([]string, 0, 1)
+
+	if ref, ok := pkg.sheetRef(sheet.Name); ok {
+		target, err := pkg.worksheetPath(ref)
+		if err != nil {
+			return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+		}
+		original, err := pkg.readFile(target)
+		if err != nil {
+			return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+		}
+		content, formulasRemoved, err := replaceSheetData(original, sheet.Rows, pkg.sharedStrings)
+		if err != nil {
+			return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %q: %w", sheet.Name, err)
+		}
+		replaced[target] = content
+
+		if formulasRemoved {
+			// NOTE: Spreadsheet applications refuse calculation chains that refer to cells without formulas. They
+			// rebuild the chain if it is missing.
+			if err := pkg.removeCalcChain(replaced, removed); err != nil {
+				return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+			}
+		}
+	} else {
+		target, err := pkg.appendSheet(replaced, sheet.Name)
+		if err != nil {
+			return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+		}
+		replaced[target] = []byte(worksheetXML(sheet))
+		added = append(added, target)
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range pkg.zr.File {
+		if removed[f.Name] {
+			continue
+		}
+		content, ok := replaced[f.Name]
+		if !ok {
+			if err := zw.Copy(f); err != nil {
+				return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+			}
+			continue
+		}
+		if err := writeZipFile(zw, f.Name, string(content)); err != nil {
+			return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+		}
+	}
+	for _, name := range added {
+		if err := writeZipFile(zw, name, string(replaced[name])); err != nil {
+			return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("tablexlsx.xlsxPackage.writeSheet: %w", err)
+	}
+	return nil
+}
+
+// readFile returns the original content of the part.
+func (pkg *xlsxPackage) readFile(name string) ([]byte, error) {
+	f, ok := pkg.files[name]
+	if !ok {
+		return nil, fmt.Errorf("tablexlsx.xlsxPackage.readFile: missing part: %q", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("tablexlsx.xlsxPackage.readFile: %w", err)
+	}
+	defer rc.Close()
+	bs, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("tablexlsx.xlsxPackage.readFile: %w", err)
+	}
+	return bs, nil
+}
+
+// appendSheet adds the sheet to the workbook, the relationships and the content types, and returns the path of the
+// new part of the sheet.
+func (pkg *xlsxPackage) appendSheet(replaced map[string][]byte, name string) (string, error) {
+	workbookDir := path.Dir(pkg.workbookPath)
+
+	n := len(pkg.workbook.Sheets) + 1
+	for {
+		if _, ok := pkg.files[path.Join(workbookDir, "worksheets", fmt.Sprintf("sheet%d.xml", n))]; !ok {
+			break
+		}
+		n++
+	}
+	target := path.Join(workbookDir, "worksheets", fmt.Sprintf("sheet%d.xml", n))
+
+	relIDs := make([]string, 0, len(pkg.workbookRels.Relationships))
+	for _, rel := range pkg.workbookRels.Relationships {
+		relIDs = append(relIDs, rel.ID)
+	}
+	relID := fmt.Sprintf("rId%d", len(relIDs)+1)
+	for i := len(relIDs) + 1; slices.Contains(relIDs, relID); i++ {
+		relID = fmt.Sprintf("rId%d", i)
+	}
+
+	sheetID := 0
+	for _, ref := range pkg.workbook.Sheets {
+		sheetID = max(sheetID, ref.SheetID)
+	}
+	sheetID++
+
+	workbook, err := pkg.readFile(pkg.workbookPath)
+	if err != nil {
+		return "", fmt.Errorf("tablexlsx.xlsxPackage.appendSheet: %w", err)
+	}
+	sb := &strings.Builder{}
+	// NOTE: The namespace of the relationships is declared on the element because the prefix of the workbook is unknown.
+	sb.WriteString(`<sheet xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" name="`)
+	writeEscaped(sb, name)
+	fmt.Fprintf(sb, `" sheetId="%d" r:id="%s"/>`, sheetID, relID)
+	workbook, err = insertBefore(workbook, "</sheets>", sb.String())
+	if err != nil {
+		return "", fmt.Errorf("tablexlsx.xlsxPackage.appendSheet: %w", err)
+	}
+	replaced[pkg.workbookPath] = workbook
+
+	workbookRels, err := pkg.readFile(pkg.workbookRelsPath)
+	if err != nil {
+		return "", fmt.Errorf("tablexlsx.xlsxPackage.appendSheet: %w", err)
+	}
+	workbookRels, err = insertBefore(workbookRels, "</Relationships>", fmt.Sprintf(`<Relationship Id="%s" Type="%s" Target="/%s"/>`, relID, relTypeWorksheet, target))
+	if err != nil {
+		return "", fmt.Errorf("tablexlsx.xlsxPackage.appendSheet: %w", err)
+	}
+	replaced[pkg.workbookRelsPath] = workbookRels
+
+	contentTypes, err := pkg.readFile(contentTypesPath)
+	if err != nil {
+		return "", fmt.Errorf("tablexlsx.xlsxPackage.appendSheet: %w", err)
+	}
+	contentTypes, err = insertBefore(contentTypes, "</Types>", fmt.Sprintf(`<Override PartName="/%s" ContentType="%s"/>`, target, worksheetContentType))
+	if err != nil {
+		return "", fmt.Errorf("tablexlsx.xlsxPackage.appendSheet: %w", err)
+	}
+	replaced[contentTypesPath] = contentTypes
+	return target, nil
+}
+
+var (
+	calcChainRelationshipPattern = regexp.MustCompile(`<Relationship\s[^>]*Type="` + regexp.QuoteMeta(relTypeCalcChain) + `"[^>]*/>`)
+	calcChainOverridePattern     = regexp.MustCompile(`<Override\s[^>]*PartName="/[^"]*calcChain\.xml"[^>]*/>`)
+)
+
+// removeCalcChain removes the calculation chain and the references to it.
+func (pkg *xlsxPackage) removeCalcChain(replaced map[string][]byte, removed map[string]bool) error {
+	target := ""
+	for _, rel := range pkg.workbookRels.Relationships {
+		if rel.Type == relTypeCalcChain {
+			target = pkg.targets[rel.ID]
+		}
+	}
+	if target == "" {
+		return nil
+	}
+	removed[target] = true
+
+	workbookRels, err := pkg.readFile(pkg.workbookRelsPath)
+	if err != nil {
+		return fmt.Errorf("tablexlsx.xlsxPackage.removeCalcChain: %w", err)
+	}
+	replaced[pkg.workbookRelsPath] = calcChainRelationshipPattern.ReplaceAll(workbookRels, nil)
+
+	if _, ok := pkg.files[contentTypesPath]; ok {
+		contentTypes, err := pkg.readFile(contentTypesPath)
+		if err != nil {
+			return fmt.Errorf("tablexlsx.xlsxPackage.removeCalcChain: %w", err)
+		}
+		replaced[contentTypesPath] = calcChainOverridePattern.ReplaceAll(contentTypes, nil)
+	}
+	return nil
+}
+
+func insertBefore(bs []byte, closing string, s string) ([]byte, error) {
+	i := bytes.LastIndex(bs, []byte(closing))
+	if i < 0 {
+		return nil, fmt.Errorf("tablexlsx.insertBefore: missing %q", closing)
+	}
+	res := make([]byte, 0, len(bs)+len(s))
+	res = append(res, bs[:i]...)
+	res = append(res, s...)
+	res = append(res, bs[i:]...)
+	return res, nil
+}
+
+// originalCell is a cell of the original sheet. Raw is nil if the cell has no reference, because the cells before it
+// may be removed.
+type originalCell struct {
+	Raw     []byte
+	Value   string
+	Style   string
+	Formula bool
+}
+
+// originalRow is a row of the original sheet. Attrs are the attributes without namespaces except the row number and
+// the spans, such as the height.
+type originalRow struct {
+	Attrs []xml.Attr
+	Cells map[int]*originalCell
+}
+
+type xlsxOriginalCell struct {
+	xlsxCell
+	Style   string  `xml:"s,attr"`
+	Formula *string `xml:"f"`
+}
+
+// replaceSheetData returns the worksheet with the sheet data replaced by the rows. The other elements of the worksheet
+// such as column widths are kept, and the cells of the same values are kept as they are. It also returns whether
+// formulas are removed. Worksheets that have namespace prefixes are written again as a whole.
+func replaceSheetData(original []byte, rows [][]string, sharedStrings []string) ([]byte, bool, error) {
+	start, end, oldRows, err := parseSheetData(original, sharedStrings)
+	if err != nil {
+		return nil, false, fmt.Errorf("tablexlsx.replaceSheetData: %w", err)
+	}
+	if start < 0 {
+		return []byte(worksheetXML(&Sheet{Rows: rows})), true, nil
+	}
+
+	n := len(rows)
+	for i := range oldRows {
+		n = max(n, i+1)
+	}
+
+	formulasRemoved := false
+	sb := &strings.Builder{}
+	sb.WriteString(`<sheetData>`)
+	for i := 0; i < n; i++ {
+		var row []string
+		if i < len(rows) {
+			row = rows[i]
+		}
+		oldRow, hasOldRow := oldRows[i]
+
+		cols := make([]int, 0, len(row))
+		for j, cell := range row {
+			if cell != "" {
+				cols = append(cols, j)
+			}
+		}
+		if hasOldRow {
+			for j := range oldRow.Cells {
+				if !slices.Contains(cols, j) {
+					cols = append(cols, j)
+				}
+			}
+		}
+		slices.Sort(cols)
+
+		cells := &strings.Builder{}
+		for _, j := range cols {
+			value := ""
+			if j < len(row) {
+				value = row[j]
+			}
+
+			var oldCell *originalCell
+			if hasOldRow {
+				oldCell = oldRow.Cells[j]
+			}
+			if oldCell != nil && oldCell.Raw != nil && oldCell.Value == value {
+				cells.Write(oldCell.Raw)
+				continue
+			}
+
+			style := ""
+			if oldCell != nil {
+				style = oldCell.Style
+				if oldCell.Formula {
+					formulasRemoved = true
+				}
+			}
+			writeCell(cells, cellReference(j, i), style, value, i > 0)
+		}
+
+		if !hasOldRow && cells.Len() == 0 {
+			continue
+		}
+		fmt.Fprintf(sb, `<row r="%d"`, i+1)
+		if hasOldRow {
+			for _, attr := range oldRow.Attrs {
+				sb.WriteString(` ` + attr.Name.Local + `="`)
+				writeEscaped(sb, attr.Value)
+				sb.WriteString(`"`)
+			}
+		}
+		sb.WriteString(`>`)
+		sb.WriteString(cells.String())
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData>`)
+
+	res := make([]byte, 0, len(original))
+	res = append(res, original[:start]...)
+	res = append(res, sb.String()...)
+	res = append(res, original[end:]...)
+	return res, formulasRemoved, nil
+}
+
+// writeCell writes the cell in the same way as worksheetXML. Empty cells are written only if they have styles.
+func writeCell(sb *strings.Builder, ref string, style string, value string, numeric bool) {
+	styleAttr := ""
+	if style != "" {
+		styleAttr = ` s="` + style + `"`
+	}
+	if value == "" {
+		if style != "" {
+			fmt.Fprintf(sb, `<c r="%s"%s/>`, ref, styleAttr)
+		}
+		return
+	}
+	if numeric && isNumber(value) {
+		fmt.Fprintf(sb, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, value)
+		return
+	}
+	fmt.Fprintf(sb, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, styleAttr)
+	writeEscaped(sb, value)
+	sb.WriteString(`</t></is></c>`)
+}
+
+// parseSheetData returns the byte offsets of the sheet data element and its rows by 0-origin indices. The offsets are
+// negative if the worksheet has no sheet data without namespace prefixes.
+func parseSheetData(original []byte, sharedStrings []string) (int, int, map[int]*originalRow, error) {
+	d := xml.NewDecoder(bytes.NewReader(original))
+	start, end := -1, -1
+	rows := make(map[int]*originalRow)
+	var row *originalRow
+	rowIndex := -1
+	for {
+		offset := int(d.InputOffset())
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("tablexlsx.parseSheetData: %w", err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "sheetData":
+				if !isUnprefixed(original[offset:], "sheetData") {
+					return -1, -1, rows, nil
+				}
+				start = offset
+			case "row":
+				row = &originalRow{Cells: make(map[int]*originalCell)}
+				rowIndex++
+				for _, attr := range token.Attr {
+					switch {
+					case attr.Name.Space == "" && attr.Name.Local == "r":
+						ref, err := strconv.Atoi(attr.Value)
+						if err != nil {
+							return 0, 0, nil, fmt.Errorf("tablexlsx.parseSheetData: %w", err)
+						}
+						rowIndex = ref - 1
+					case attr.Name.Space == "" && attr.Name.Local != "spans":
+						row.Attrs = append(row.Attrs, attr)
+					}
+				}
+				rows[rowIndex] = row
+			case "c":
+				if row == nil {
+					continue
+				}
+				var cell xlsxOriginalCell
+				if err := d.DecodeElement(&cell, &token); err != nil {
+					return 0, 0, nil, fmt.Errorf("tablexlsx.parseSheetData: %w", err)
+				}
+				col := len(row.Cells)
+				if cell.Ref != "" {
+					col, err = columnIndex(cell.Ref)
+					if err != nil {
+						return 0, 0, nil, fmt.Errorf("tablexlsx.parseSheetData: %w", err)
+					}
+				}
+				value, err := cellValue(&cell.xlsxCell, sharedStrings)
+				if err != nil {
+					return 0, 0, nil, fmt.Errorf("tablexlsx.parseSheetData: %q: %w", cell.Ref, err)
+				}
+				var raw []byte
+				if cell.Ref != "" {
+					raw = original[offset:d.InputOffset()]
+				}
+				row.Cells[col] = &originalCell{Raw: raw, Value: value, Style: cell.Style, Formula: cell.Formula != nil}
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "sheetData":
+				end = int(d.InputOffset())
+			case "row":
+				row = nil
+			}
+		}
+	}
+	if start < 0 || end < 0 {
+		return -1, -1, rows, nil
+	}
+	return start, end, rows, nil
+}
+
+// isUnprefixed returns true if the start tag at the beginning of bs has the local name without a namespace prefix.
+func isUnprefixed(bs []byte, local string) bool {
+	return bytes.HasPrefix(bs, []byte("<"+local)) && len(bs) > len(local)+1 && strings.ContainsRune(" \t\r\n/>", rune(bs[len(local)+1]))
+}
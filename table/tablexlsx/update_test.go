@@ -0,0 +1,198 @@
+package tablexlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// styledWorkbook is a workbook that spreadsheet applications write. The table sheet has column widths, styles and a
+// formula, and the other sheet has a formula in the calculation chain.
+var styledWorkbook = []struct {
+	Name    string
+	Content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/><Override PartName="/xl/sharedStrings.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sharedStrings+xml"/><Override PartName="/xl/calcChain.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.calcChain+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="ATOMIC_PROCESS" sheetId="1" r:id="rId1"/><sheet name="Summary" sheetId="3" r:id="rId2"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/><Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/><Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/><Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/calcChain" Target="calcChain.xml"/></Relationships>`},
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font/><font><b/></font></fonts><cellXfs count="2"><xf fontId="0"/><xf fontId="1" applyFont="1"/></cellXfs></styleSheet>`},
+	{"xl/sharedStrings.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>ID</t></si><si><t>Description</t></si><si><t>P1</t></si></sst>`},
+	{"xl/worksheets/sheet1.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><cols><col min="2" max="2" width="40" customWidth="1"/></cols><sheetData>` +
+		`<row r="1" spans="1:3" ht="24" customHeight="1"><c r="A1" s="1" t="s"><v>0</v></c><c r="B1" s="1" t="s"><v>1</v></c><c r="C1" s="1" t="inlineStr"><is><t>Length</t></is></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>Design</v></c><c r="C2"><f>LEN(B2)</f><v>6</v></c></row>` +
+		`</sheetData><pageMargins left="0.7" right="0.7" top="0.75" bottom="0.75" header="0.3" footer="0.3"/></worksheet>`},
+	{"xl/worksheets/sheet2.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row r="1"><c r="A1"><f>COUNTA(ATOMIC_PROCESS!A:A)-1</f><v>1</v></c></row></sheetData></worksheet>`},
+	{"xl/calcChain.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<calcChain xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><c r="C2" i="1"/><c r="A1" i="3"/></calcChain>`},
+}
+
+func newStyledWorkbook(t *testing.T) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, part := range styledWorkbook {
+		fw, err := zw.Create(part.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(fw, part.Content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readParts(t *testing.T, bs []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(bs), int64(len(bs)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(content)
+	}
+	return parts
+}
+
+func TestUpdateTable(t *testing.T) {
+	testCases := map[string]struct {
+		Name                string
+		Table               *Table
+		ExpectedSheet       string
+		ExpectedChanged     []string
+		ExpectedRemoved     []string
+		ExpectedContains    map[string][]string
+		ExpectedNotContains map[string][]string
+	}{
+		"values of formulas unchanged": {
+			Name: "ATOMIC_PROCESS",
+			Table: &Table{
+				Header: []string{"ID", "Description", "Length"},
+				Rows:   [][]string{{"P1", "Design", "6"}, {"P2", "Implement", ""}},
+			},
+			ExpectedSheet:   "ATOMIC_PROCESS",
+			ExpectedChanged: []string{"xl/worksheets/sheet1.xml"},
+			ExpectedContains: map[string][]string{
+				"xl/worksheets/sheet1.xml": {
+					`<cols><col min="2" max="2" width="40" customWidth="1"/></cols>`,
+					`<row r="1" ht="24" customHeight="1"><c r="A1" s="1" t="s"><v>0</v></c>`,
+					`<c r="C2"><f>LEN(B2)</f><v>6</v></c>`,
+					`<c r="B3" t="inlineStr"><is><t xml:space="preserve">Implement</t></is></c>`,
+					`<pageMargins `,
+				},
+			},
+		},
+		"formula overwritten": {
+			Name: "ATOMIC_PROCESS",
+			Table: &Table{
+				Header: []string{"ID", "Description"},
+				Rows:   [][]string{{"P1", "Design API"}},
+			},
+			ExpectedSheet:   "ATOMIC_PROCESS",
+			ExpectedChanged: []string{"xl/worksheets/sheet1.xml", "xl/_rels/workbook.xml.rels", "[Content_Types].xml"},
+			ExpectedRemoved: []string{"xl/calcChain.xml"},
+			ExpectedContains: map[string][]string{
+				"xl/worksheets/sheet1.xml": {`<c r="C1" s="1"/>`},
+			},
+			ExpectedNotContains: map[string][]string{
+				"xl/worksheets/sheet1.xml":   {`<f>`},
+				"xl/_rels/workbook.xml.rels": {`calcChain`},
+				"[Content_Types].xml":        {`calcChain`},
+			},
+		},
+		"missing sheet": {
+			Name: "RESOURCE",
+			Table: &Table{
+				Header: []string{"ID", "Description"},
+				Rows:   [][]string{{"R1", "Engineer"}},
+			},
+			ExpectedSheet:   "RESOURCE",
+			ExpectedChanged: []string{"xl/workbook.xml", "xl/_rels/workbook.xml.rels", "[Content_Types].xml"},
+			ExpectedContains: map[string][]string{
+				"xl/workbook.xml":            {`sheetId="4" r:id="rId6"/></sheets>`},
+				"xl/_rels/workbook.xml.rels": {`<Relationship Id="rId6" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet3.xml"/>`},
+				"[Content_Types].xml":        {`<Override PartName="/xl/worksheets/sheet3.xml"`},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			original := newStyledWorkbook(t)
+			buf := &bytes.Buffer{}
+			if err := UpdateTable(buf, bytes.NewReader(original), tc.Name, tc.Table); err != nil {
+				t.Fatal(err)
+			}
+
+			originalParts := readParts(t, original)
+			parts := readParts(t, buf.Bytes())
+			for _, part := range styledWorkbook {
+				content, ok := parts[part.Name]
+				switch {
+				case slices.Contains(tc.ExpectedRemoved, part.Name):
+					if ok {
+						t.Errorf("want %s removed", part.Name)
+					}
+				case slices.Contains(tc.ExpectedChanged, part.Name):
+					if content == originalParts[part.Name] {
+						t.Errorf("want %s changed", part.Name)
+					}
+				default:
+					if content != originalParts[part.Name] {
+						t.Errorf("want %s unchanged:\n%s", part.Name, cmp.Diff(originalParts[part.Name], content))
+					}
+				}
+			}
+			for part, ss := range tc.ExpectedContains {
+				for _, s := range ss {
+					if !strings.Contains(parts[part], s) {
+						t.Errorf("missing %s in %s:\n%s", s, part, parts[part])
+					}
+				}
+			}
+			for part, ss := range tc.ExpectedNotContains {
+				for _, s := range ss {
+					if strings.Contains(parts[part], s) {
+						t.Errorf("unexpected %s in %s:\n%s", s, part, parts[part])
+					}
+				}
+			}
+
+			got, err := ParseTable(bytes.NewReader(buf.Bytes()), tc.ExpectedSheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.Table) {
+				t.Error(cmp.Diff(tc.Table, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,512 @@
+package tablexlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Workbook is the sheets of an XLSX file. Only the values of the cells are read and written, so styles and formulas
+// are not preserved. Use UpdateTable or UpdateSheet to keep them in existing workbooks.
+type Workbook struct {
+	Sheets []*Sheet
+}
+
+// Sheet is a worksheet. Rows are not padded, so the lengths of the rows may differ.
+type Sheet struct {
+	Name string
+	Rows [][]string
+}
+
+// Sheet returns the sheet of the name.
+func (wb *Workbook) Sheet(name string) (*Sheet, bool) {
+	for _, sheet := range wb.Sheets {
+		if sheet.Name == name {
+			return sheet, true
+		}
+	}
+	return nil, false
+}
+
+// SetSheet replaces the sheet of the same name, or appends the sheet if there is no such sheet.
+func (wb *Workbook) SetSheet(sheet *Sheet) {
+	for i, s := range wb.Sheets {
+		if s.Name == sheet.Name {
+			wb.Sheets[i] = sheet
+			return
+		}
+	}
+	wb.Sheets = append(wb.Sheets, sheet)
+}
+
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+func NewTable(header []string, rows [][]string) (*Table, error) {
+	for _, row := range rows {
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("tablexlsx.NewTable: row length mismatch")
+		}
+	}
+	return &Table{Header: header, Rows: rows}, nil
+}
+
+// TableBySheet returns the table of the sheet. The first non-empty row is the header, and empty rows are skipped
+// because spreadsheet applications keep formatted rows without values. Rows are padded to the length of the header.
+func TableBySheet(sheet *Sheet) (*Table, error) {
+	var header []string
+	rows := make([][]string, 0, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		if isEmptyRow(row) {
+			continue
+		}
+		if header == nil {
+			header = trimTrailingEmptyCells(row)
+			continue
+		}
+
+		row = trimTrailingEmptyCells(row)
+		if len(row) > len(header) {
+			return nil, fmt.Errorf("tablexlsx.TableBySheet: %q: row %d has cells out of the header", sheet.Name, i+1)
+		}
+		padded := make([]string, len(header))
+		copy(padded, row)
+		rows = append(rows, padded)
+	}
+	if header == nil {
+		return nil, fmt.Errorf("tablexlsx.TableBySheet: %q: missing header", sheet.Name)
+	}
+	return &Table{Header: header, Rows: rows}, nil
+}
+
+// SheetByTable returns the sheet of the table.
+func SheetByTable(name string, table *Table) *Sheet {
+	rows := make([][]string, 0, len(table.Rows)+1)
+	rows = append(rows, table.Header)
+	rows = append(rows, table.Rows...)
+	return &Sheet{Name: name, Rows: rows}
+}
+
+// TableSheet returns the sheet of the table. The sheet of the name is preferred, and the only sheet is used for
+// workbooks that have a single sheet of any name.
+func (wb *Workbook) TableSheet(name string) (*Sheet, error) {
+	if sheet, ok := wb.Sheet(name); ok {
+		return sheet, nil
+	}
+	if len(wb.Sheets) == 1 {
+		return wb.Sheets[0], nil
+	}
+	return nil, fmt.Errorf("tablexlsx.Workbook.TableSheet: missing sheet: %q", name)
+}
+
+// ParseTable reads the table of the sheet selected by Workbook.TableSheet.
+func ParseTable(r io.Reader, name string) (*Table, error) {
+	wb, err := ParseWorkbook(r)
+	if err != nil {
+		return nil, fmt.Errorf("tablexlsx.ParseTable: %w", err)
+	}
+	sheet, err := wb.TableSheet(name)
+	if err != nil {
+		return nil, fmt.Errorf("tablexlsx.ParseTable: %w", err)
+	}
+	table, err := TableBySheet(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("tablexlsx.ParseTable: %w", err)
+	}
+	return table, nil
+}
+
+// WriteTable writes a workbook that has only the sheet of the table.
+func WriteTable(w io.Writer, name string, table *Table) error {
+	if err := WriteWorkbook(w, &Workbook{Sheets: []*Sheet{SheetByTable(name, table)}}); err != nil {
+		return fmt.Errorf("tablexlsx.WriteTable: %w", err)
+	}
+	return nil
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func trimTrailingEmptyCells(row []string) []string {
+	n := len(row)
+	for n > 0 && row[n-1] == "" {
+		n--
+	}
+	return row[:n]
+}
+
+const (
+	relTypeOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	relTypeWorksheet      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet"
+	relTypeSharedStrings  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings"
+	defaultWorkbookPath   = "xl/workbook.xml"
+)
+
+type xlsxRelationships struct {
+	Relationships []xlsxRelationship `xml:"Relationship"`
+}
+
+type xlsxRelationship struct {
+	ID     string `xml:"Id,attr"`
+	Type   string `xml:"Type,attr"`
+	Target string `xml:"Target,attr"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []xlsxSheetRef `xml:"sheets>sheet"`
+}
+
+type xlsxSheetRef struct {
+	Name    string `xml:"name,attr"`
+	SheetID int    `xml:"sheetId,attr"`
+	// NOTE: The attribute is r:id, and namespaces of attributes without namespaces in tags are not checked.
+	RelationshipID string `xml:"id,attr"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText is the text of shared strings and inline strings. Phonetic guides in rPh are not a part of the text.
+type xlsxRichText struct {
+	Text *string       `xml:"t"`
+	Runs []xlsxTextRun `xml:"r"`
+}
+
+type xlsxTextRun struct {
+	Text string `xml:"t"`
+}
+
+func (t *xlsxRichText) String() string {
+	sb := &strings.Builder{}
+	if t.Text != nil {
+		sb.WriteString(*t.Text)
+	}
+	for _, run := range t.Runs {
+		sb.WriteString(run.Text)
+	}
+	return sb.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []xlsxRow `xml:"sheetData>row"`
+}
+
+type xlsxRow struct {
+	Ref   int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	Ref          string        `xml:"r,attr"`
+	Type         string        `xml:"t,attr"`
+	Value        string        `xml:"v"`
+	InlineString *xlsxRichText `xml:"is"`
+}
+
+// ParseWorkbook reads the values of the cells of all the sheets. Numbers are formatted in the shortest decimal
+// notation, and dates are serial numbers because number formats are not read.
+func ParseWorkbook(r io.Reader) (*Workbook, error) {
+	pkg, err := openPackage(r)
+	if err != nil {
+		return nil, fmt.Errorf("tablexlsx.ParseWorkbook: %w", err)
+	}
+
+	wb := &Workbook{Sheets: make([]*Sheet, 0, len(pkg.workbook.Sheets))}
+	for _, ref := range pkg.workbook.Sheets {
+		target, err := pkg.worksheetPath(&ref)
+		if err != nil {
+			return nil, fmt.Errorf("tablexlsx.ParseWorkbook: %w", err)
+		}
+		var ws xlsxWorksheet
+		if ok, err := decodeXMLFile(pkg.files, target, &ws); err != nil {
+			return nil, fmt.Errorf("tablexlsx.ParseWorkbook: %q: %w", ref.Name, err)
+		} else if !ok {
+			return nil, fmt.Errorf("tablexlsx.ParseWorkbook: %q: missing worksheet: %q", ref.Name, target)
+		}
+		rows, err := worksheetRows(&ws, pkg.sharedStrings)
+		if err != nil {
+			return nil, fmt.Errorf("tablexlsx.ParseWorkbook: %q: %w", ref.Name, err)
+		}
+		wb.Sheets = append(wb.Sheets, &Sheet{Name: ref.Name, Rows: rows})
+	}
+	return wb, nil
+}
+
+func decodeXMLFile(files map[string]*zip.File, name string, v any) (bool, error) {
+	f, ok := files[name]
+	if !ok {
+		return false, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return false, fmt.Errorf("tablexlsx.decodeXMLFile: %w", err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return false, fmt.Errorf("tablexlsx.decodeXMLFile: %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// resolveTarget returns the path in the archive of the relationship target. Targets starting with a slash are
+// relative to the root of the archive.
+func resolveTarget(dir string, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(dir, target)
+}
+
+func worksheetRows(ws *xlsxWorksheet, sharedStrings []string) ([][]string, error) {
+	rows := make([][]string, 0, len(ws.Rows))
+	for _, row := range ws.Rows {
+		// NOTE: The row numbers may be omitted, and then the rows are consecutive.
+		index := len(rows)
+		if row.Ref > 0 {
+			index = row.Ref - 1
+		}
+		if index < len(rows) {
+			return nil, fmt.Errorf("tablexlsx.worksheetRows: rows out of order: %d", row.Ref)
+		}
+		for len(rows) <= index {
+			rows = append(rows, []string{})
+		}
+
+		cells := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			col := len(cells)
+			if cell.Ref != "" {
+				var err error
+				col, err = columnIndex(cell.Ref)
+				if err != nil {
+					return nil, fmt.Errorf("tablexlsx.worksheetRows: %w", err)
+				}
+			}
+			if col < len(cells) {
+				return nil, fmt.Errorf("tablexlsx.worksheetRows: cells out of order: %q", cell.Ref)
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+
+			value, err := cellValue(&cell, sharedStrings)
+			if err != nil {
+				return nil, fmt.Errorf("tablexlsx.worksheetRows: %q: %w", cell.Ref, err)
+			}
+			cells = append(cells, value)
+		}
+		rows[index] = cells
+	}
+	return rows, nil
+}
+
+// columnIndex returns the 0-origin column index of the cell reference such as "AB12".
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("tablexlsx.columnIndex: malformed cell reference: %q", ref)
+	}
+	return col - 1, nil
+}
+
+func cellValue(cell *xlsxCell, sharedStrings []string) (string, error) {
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+		if err != nil {
+			return "", fmt.Errorf("tablexlsx.cellValue: %w", err)
+		}
+		if i < 0 || i >= len(sharedStrings) {
+			return "", fmt.Errorf("tablexlsx.cellValue: shared string out of range: %d", i)
+		}
+		return sharedStrings[i], nil
+	case "inlineStr":
+		if cell.InlineString == nil {
+			return "", nil
+		}
+		return cell.InlineString.String(), nil
+	case "b":
+		if cell.Value == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case "", "n":
+		f, err := strconv.ParseFloat(cell.Value, 64)
+		if err != nil {
+			return cell.Value, nil
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	default:
+		return cell.Value, nil
+	}
+}
+
+// WriteWorkbook writes the workbook. Cells in the shortest decimal notation are written as numbers so that
+// spreadsheet applications can calculate them, and the other cells and the header rows are written as strings.
+func WriteWorkbook(w io.Writer, wb *Workbook) error {
+	if len(wb.Sheets) == 0 {
+		return fmt.Errorf("tablexlsx.WriteWorkbook: no sheets")
+	}
+	names := make(map[string]bool, len(wb.Sheets))
+	for _, sheet := range wb.Sheets {
+		if err := validateSheetName(sheet.Name); err != nil {
+			return fmt.Errorf("tablexlsx.WriteWorkbook: %w", err)
+		}
+		if names[sheet.Name] {
+			return fmt.Errorf("tablexlsx.WriteWorkbook: duplicated sheet name: %q", sheet.Name)
+		}
+		names[sheet.Name] = true
+	}
+
+	zw := zip.NewWriter(w)
+
+	contentTypes := &strings.Builder{}
+	contentTypes.WriteString(xml.Header)
+	contentTypes.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	contentTypes.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	contentTypes.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	contentTypes.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	for i := range wb.Sheets {
+		fmt.Fprintf(contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	contentTypes.WriteString(`</Types>`)
+	if err := writeZipFile(zw, "[Content_Types].xml", contentTypes.String()); err != nil {
+		return fmt.Errorf("tablexlsx.WriteWorkbook: %w", err)
+	}
+
+	rootRels := xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + relTypeOfficeDocument + `" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	if err := writeZipFile(zw, "_rels/.rels", rootRels); err != nil {
+		return fmt.Errorf("tablexlsx.WriteWorkbook: %w", err)
+	}
+
+	workbook := &strings.Builder{}
+	workbookRels := &strings.Builder{}
+	workbook.WriteString(xml.Header)
+	workbook.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(xml.Header)
+	workbookRels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, sheet := range wb.Sheets {
+		workbook.WriteString(`<sheet name="`)
+		writeEscaped(workbook, sheet.Name)
+		fmt.Fprintf(workbook, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+		fmt.Fprintf(workbookRels, `<Relationship Id="rId%d" Type="%s" Target="worksheets/sheet%d.xml"/>`, i+1, relTypeWorksheet, i+1)
+	}
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+	if err := writeZipFile(zw, "xl/workbook.xml", workbook.String()); err != nil {
+		return fmt.Errorf("tablexlsx.WriteWorkbook: %w", err)
+	}
+	if err := writeZipFile(zw, "xl/_rels/workbook.xml.rels", workbookRels.String()); err != nil {
+		return fmt.Errorf("tablexlsx.WriteWorkbook: %w", err)
+	}
+
+	for i, sheet := range wb.Sheets {
+		if err := writeZipFile(zw, fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheetXML(sheet)); err != nil {
+			return fmt.Errorf("tablexlsx.WriteWorkbook: %w", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("tablexlsx.WriteWorkbook: %w", err)
+	}
+	return nil
+}
+
+// validateSheetName returns an error if spreadsheet applications cannot open the sheet of the name.
+func validateSheetName(name string) error {
+	if name == "" {
+		return fmt.Errorf("tablexlsx.validateSheetName: empty sheet name")
+	}
+	if len([]rune(name)) > 31 {
+		return fmt.Errorf("tablexlsx.validateSheetName: too long sheet name: %q", name)
+	}
+	if strings.ContainsAny(name, `[]:*?/\`) {
+		return fmt.Errorf("tablexlsx.validateSheetName: invalid characters in sheet name: %q", name)
+	}
+	return nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, content string) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return fmt.Errorf("tablexlsx.writeZipFile: %w", err)
+	}
+	if _, err := io.WriteString(fw, content); err != nil {
+		return fmt.Errorf("tablexlsx.writeZipFile: %w", err)
+	}
+	return nil
+}
+
+func worksheetXML(sheet *Sheet) string {
+	sb := &strings.Builder{}
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range sheet.Rows {
+		fmt.Fprintf(sb, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			if cell == "" {
+				continue
+			}
+			ref := cellReference(j, i)
+			if i > 0 && isNumber(cell) {
+				fmt.Fprintf(sb, `<c r="%s"><v>%s</v></c>`, ref, cell)
+				continue
+			}
+			fmt.Fprintf(sb, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			writeEscaped(sb, cell)
+			sb.WriteString(`</t></is></c>`)
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+func writeEscaped(sb *strings.Builder, s string) {
+	// NOTE: Writes to strings.Builder never fail.
+	_ = xml.EscapeText(sb, []byte(s))
+}
+
+// isNumber returns true if the cell is in the shortest decimal notation, so that reading the number back gives the
+// same text.
+func isNumber(cell string) bool {
+	f, err := strconv.ParseFloat(cell, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return false
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64) == cell
+}
+
+// cellReference returns the cell reference such as "AB12" of the 0-origin column and row indices.
+func cellReference(col int, row int) string {
+	var letters []byte
+	for col++; col > 0; col = (col - 1) / 26 {
+		letters = append([]byte{byte('A' + (col-1)%26)}, letters...)
+	}
+	return string(letters) + strconv.Itoa(row+1)
+}
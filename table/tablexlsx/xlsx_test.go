@@ -0,0 +1,143 @@
+package tablexlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWorkbookRoundTrip(t *testing.T) {
+	wb := &Workbook{
+		Sheets: []*Sheet{
+			{Name: "ATOMIC_PROCESS", Rows: [][]string{
+				{"ID", "Description", "Est. Work Volume"},
+				{"P1", "Write <spec> & \"review\"", "10"},
+				{"P2", "Line 1\nLine 2", "1.50"},
+			}},
+			{Name: "RESOURCE", Rows: [][]string{
+				{"ID", "Description"},
+				{"R1", "担当者"},
+			}},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteWorkbook(buf, wb); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseWorkbook(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, wb) {
+		t.Error(cmp.Diff(wb, got))
+	}
+}
+
+func TestParseWorkbook(t *testing.T) {
+	// NOTE: Spreadsheet applications write shared strings with rich text and phonetic guides, and omit empty cells.
+	files := map[string]string{
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`,
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/data.xml"/><Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>ID</t></si><si><t>Description</t></si><si><r><t>Imple</t></r><r><rPr><b/></rPr><t>ment</t></r></si><si><t>設計</t><rPh sb="0" eb="2"><t>セッケイ</t></rPh></si></sst>`,
+		"xl/worksheets/data.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>Done</t></is></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="C3"><v>0.33333333333333331</v></c><c r="D3" t="b"><v>1</v></c></row>` +
+			`<row r="4"><c r="A4" t="s"><v>3</v></c><c r="B4" t="str"><v>x</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		fw, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	wb, err := ParseWorkbook(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, ok := wb.Sheet("Sheet1")
+	if !ok {
+		t.Fatalf("missing sheet: %v", wb.Sheets)
+	}
+	got, err := TableBySheet(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Table{
+		Header: []string{"ID", "Description", "", "Done"},
+		Rows: [][]string{
+			{"Implement", "", "0.3333333333333333", "TRUE"},
+			{"設計", "x", "", ""},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestTableBySheetError(t *testing.T) {
+	testCases := map[string]*Sheet{
+		"empty": {Name: "Sheet1", Rows: [][]string{{}, {"", ""}}},
+		"cells out of the header": {Name: "Sheet1", Rows: [][]string{
+			{"ID", "Description"},
+			{"P1", "Implement", "extra"},
+		}},
+	}
+
+	for name, sheet := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := TableBySheet(sheet); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
+
+func TestCellReference(t *testing.T) {
+	testCases := map[string]struct {
+		Col      int
+		Row      int
+		Expected string
+	}{
+		"A1":   {Col: 0, Row: 0, Expected: "A1"},
+		"Z2":   {Col: 25, Row: 1, Expected: "Z2"},
+		"AA3":  {Col: 26, Row: 2, Expected: "AA3"},
+		"AZ10": {Col: 51, Row: 9, Expected: "AZ10"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ref := cellReference(testCase.Col, testCase.Row)
+			if ref != testCase.Expected {
+				t.Errorf("cellReference(%d, %d) = %q, want %q", testCase.Col, testCase.Row, ref, testCase.Expected)
+			}
+			col, err := columnIndex(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if col != testCase.Col {
+				t.Errorf("columnIndex(%q) = %d, want %d", ref, col, testCase.Col)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package textdiff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultContextLines is the number of unchanged lines around changes in hunks.
+const DefaultContextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is an operation of edit scripts. A and B are the line numbers before the operation is applied.
+type op struct {
+	Kind opKind
+	A    int
+	B    int
+}
+
+// WriteUnified writes the unified diff from a to b. It writes nothing if a and b are the same.
+func WriteUnified(w io.Writer, aName, bName string, a, b string) error {
+	as := SplitLines(a)
+	bs := SplitLines(b)
+	ops := diff(as, bs)
+
+	hs := hunks(ops, DefaultContextLines)
+	if len(hs) == 0 {
+		return nil
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", aName, bName)
+	for _, h := range hs {
+		writeHunk(sb, ops[h[0]:h[1]], as, bs)
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("textdiff.WriteUnified: %w", err)
+	}
+	return nil
+}
+
+// SplitLines splits the text into lines. Lines keep their line terminators.
+func SplitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diff returns the shortest edit script from a to b by the Myers' algorithm.
+func diff(a, b []string) []op {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	trace := make([][]int, 0)
+
+	var d int
+search:
+	for d = 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset]
+			} else {
+				x = v[k-1+offset] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	reversed := make([]op, 0, n+m)
+	x, y := n, m
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+offset]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, op{Kind: opEqual, A: x, B: y})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, op{Kind: opInsert, A: x, B: y})
+		} else {
+			x--
+			reversed = append(reversed, op{Kind: opDelete, A: x, B: y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, op{Kind: opEqual, A: x, B: y})
+	}
+
+	ops := make([]op, len(reversed))
+	for i, o := range reversed {
+		ops[len(reversed)-1-i] = o
+	}
+	return ops
+}
+
+// hunks returns the ranges of the operations in hunks. Changes closer than twice the context lines are in the same
+// hunk.
+func hunks(ops []op, contextLines int) [][2]int {
+	res := make([][2]int, 0)
+	i := 0
+	for i < len(ops) {
+		if ops[i].Kind == opEqual {
+			i++
+			continue
+		}
+
+		start := max(0, i-contextLines)
+		lastChange := i
+		for j := i + 1; j < len(ops) && j <= lastChange+2*contextLines; j++ {
+			if ops[j].Kind != opEqual {
+				lastChange = j
+			}
+		}
+		end := min(len(ops), lastChange+1+contextLines)
+		res = append(res, [2]int{start, end})
+		i = end
+	}
+	return res
+}
+
+func writeHunk(sb *strings.Builder, ops []op, a, b []string) {
+	aLen, bLen := 0, 0
+	for _, o := range ops {
+		switch o.Kind {
+		case opEqual:
+			aLen++
+			bLen++
+		case opDelete:
+			aLen++
+		case opInsert:
+			bLen++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(ops[0].A, aLen), hunkRange(ops[0].B, bLen))
+	for _, o := range ops {
+		switch o.Kind {
+		case opEqual:
+			writeLine(sb, ' ', a[o.A])
+		case opDelete:
+			writeLine(sb, '-', a[o.A])
+		case opInsert:
+			writeLine(sb, '+', b[o.B])
+		}
+	}
+}
+
+// hunkRange returns the range in the same way as GNU diff. Empty ranges start at the line before them.
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, length)
+	}
+}
+
+func writeLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
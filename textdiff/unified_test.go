@@ -0,0 +1,106 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteUnified(t *testing.T) {
+	testCases := map[string]struct {
+		A        string
+		B        string
+		Expected string
+	}{
+		"same": {
+			A:        "a\nb\n",
+			B:        "a\nb\n",
+			Expected: "",
+		},
+		"empty to non-empty": {
+			A: "",
+			B: "a\n",
+			Expected: `--- a.txt
++++ b.txt
+@@ -0,0 +1 @@
++a
+`,
+		},
+		"change in the middle": {
+			A: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			B: "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			Expected: `--- a.txt
++++ b.txt
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		"separated hunks": {
+			A: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			B: "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			Expected: `--- a.txt
++++ b.txt
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,3 @@
+ 9
+ 10
+ 11
+-12
+`,
+		},
+		"merged hunks": {
+			A: "1\n2\n3\n4\n5\n6\n7\n",
+			B: "1\n3\n4\n5\n6\n7\n8\n",
+			Expected: `--- a.txt
++++ b.txt
+@@ -1,7 +1,7 @@
+ 1
+-2
+ 3
+ 4
+ 5
+ 6
+ 7
++8
+`,
+		},
+		"no newline at end of file": {
+			A: "a\nb",
+			B: "a\nc",
+			Expected: `--- a.txt
++++ b.txt
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			if err := WriteUnified(sb, "a.txt", "b.txt", tc.A, tc.B); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tc.Expected {
+				t.Error(cmp.Diff(tc.Expected, sb.String()))
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Kuniwak/pfd-tools/allcheckers"
 	"github.com/Kuniwak/pfd-tools/bizday"
 	"github.com/Kuniwak/pfd-tools/locale"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
@@ -41,6 +42,8 @@ var (
 	ConfigLongFlag                     = "config"
 )
 
+const DefaultMaximalAvailableAllocationsThreshold = 10
+
 type CommonOptions struct {
 	Help     bool          `json:"help"`
 	Version  bool          `json:"version"`
@@ -137,11 +140,31 @@ type FSMOptions struct {
 	PFDReader                            io.Reader `json:"-"`
 	AtomicProcessTableReader             io.Reader `json:"-"`
 	AtomicDeliverableTableReader         io.Reader `json:"-"`
+	CompositeProcessTableReader          io.Reader `json:"-"`
 	CompositeDeliverableTableReader      io.Reader `json:"-"`
 	ResourceTableReader                  io.Reader `json:"-"`
 	MilestoneTableReader                 io.Reader `json:"-"`
 	GroupTableReader                     io.Reader `json:"-"`
 	MaximalAvailableAllocationsThreshold int       `json:"maximal_available_allocations_threshold"`
+
+	// Paths are the resolved paths of the files for tools that rewrite them. The path of optional files and embedded
+	// files may be empty.
+	PFDPath                       string `json:"-"`
+	AtomicProcessTablePath        string `json:"-"`
+	AtomicDeliverableTablePath    string `json:"-"`
+	CompositeProcessTablePath     string `json:"-"`
+	CompositeDeliverableTablePath string `json:"-"`
+	ResourceTablePath             string `json:"-"`
+	MilestoneTablePath            string `json:"-"`
+	GroupTablePath                string `json:"-"`
+
+	// Lint is the configuration of the rules. It is nil if the run config has no lint section.
+	Lint *allcheckers.Config `json:"-"`
+
+	// Calendar and Search are the settings of the plans in the project document. They are nil if the document has no
+	// such sections.
+	Calendar *ProjectCalendar `json:"-"`
+	Search   *ProjectSearch   `json:"-"`
 }
 
 type FSMRawOptions struct {
@@ -160,6 +183,9 @@ type FSMRawOptions struct {
 	GroupTablePath                       string `json:"group_table"`
 	ShortGroupTablePath                  string `json:"-"`
 	MaximalAvailableAllocationsThreshold int    `json:"maximal_available_allocations_threshold"`
+
+	// Lint is the configuration of the rules. It is applied by pfdlint and the tools that lint before running.
+	Lint *allcheckers.Config `json:"lint"`
 }
 
 func DeclareAtomicProcessTableOptions(flags *flag.FlagSet, shortPath *string, path *string) {
@@ -313,7 +339,7 @@ func DeclareConfigOptions(flags *flag.FlagSet, shortPath *string, path *string)
 func DeclareFSMOptions(flags *flag.FlagSet, options *FSMRawOptions, configLongPath *string, configShortPath *string) {
 	flags.StringVar(&options.ShortPFDPath, PFDShortFlag, "", "path to the PFD")
 	flags.StringVar(&options.PFDPath, PFDLongFlag, "", "path to the PFD")
-	flags.IntVar(&options.MaximalAvailableAllocationsThreshold, "maximal-available-allocations-threshold", 10, "use only maximal available allocations if number of newly allocatable atomic processes is greater than the threshold. do not use maximal available allocations if threshold is not positive")
+	flags.IntVar(&options.MaximalAvailableAllocationsThreshold, "maximal-available-allocations-threshold", DefaultMaximalAvailableAllocationsThreshold, "use only maximal available allocations if number of newly allocatable atomic processes is greater than the threshold. do not use maximal available allocations if threshold is not positive")
 	DeclareAtomicProcessTableOptions(flags, &options.ShortAtomicProcessTablePath, &options.AtomicProcessTablePath)
 	DeclareAtomicDeliverableTableOptions(flags, &options.ShortAtomicDeliverableTablePath, &options.AtomicDeliverableTablePath)
 	DeclareCompositeDeliverableTableOptions(flags, &options.ShortCompositeDeliverableTablePath, &options.CompositeDeliverableTablePath)
@@ -339,37 +365,15 @@ func ValidateFSMOptionsOrConfig(rawOptions *FSMRawOptions, configShortPath *stri
 }
 
 func ValidateFSMOptions(options *FSMRawOptions, basePath string) (*FSMOptions, error) {
-	pfdReader, _, err := ValidatePFDOptions(&options.ShortPFDPath, &options.PFDPath, basePath)
-	if err != nil {
-		return nil, fmt.Errorf("cmd.ValidateFSMOptions: %w", err)
-	}
-	atomicProcessTableReader, _, err := ValidateAtomicProcessTableOptions(&options.ShortAtomicProcessTablePath, &options.AtomicProcessTablePath, basePath)
-	if err != nil {
-		return nil, fmt.Errorf("cmd.ValidateFSMOptions: %w", err)
-	}
-	resourceTableReader, _, err := ValidateResourceTableOptions(&options.ShortResourceTablePath, &options.ResourceTablePath, basePath)
-	if err != nil {
-		return nil, fmt.Errorf("cmd.ValidateFSMOptions: %w", err)
-	}
-	atomicDeliverableTableReader, _, err := ValidateAtomicDeliverableTableOptions(&options.ShortAtomicDeliverableTablePath, &options.AtomicDeliverableTablePath, basePath)
+	fsmOptions, err := ValidateProject(NewProjectByRawOptions(options), basePath)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ValidateFSMOptions: %w", err)
 	}
-	compositeDeliverableTableReader, _, err := ValidateCompositeDeliverableTableOptions(&options.ShortCompositeDeliverableTablePath, &options.CompositeDeliverableTablePath, basePath)
-	if err != nil {
-		return nil, fmt.Errorf("cmd.ValidateFSMOptions: %w", err)
-	}
-
-	return &FSMOptions{
-		PFDReader:                            pfdReader,
-		AtomicProcessTableReader:             atomicProcessTableReader,
-		AtomicDeliverableTableReader:         atomicDeliverableTableReader,
-		CompositeDeliverableTableReader:      compositeDeliverableTableReader,
-		ResourceTableReader:                  resourceTableReader,
-		MaximalAvailableAllocationsThreshold: options.MaximalAvailableAllocationsThreshold,
-	}, nil
+	return fsmOptions, nil
 }
 
+// ValidateFSMOptionsJSON reads the run config or the project document. The options given by the flags are
+// overwritten by the ones of the document.
 func ValidateFSMOptionsJSON(shortPath *string, longPath *string, rawOptions FSMRawOptions) (*FSMOptions, error) {
 	path := *longPath
 	if *shortPath != "" {
@@ -384,12 +388,13 @@ func ValidateFSMOptionsJSON(shortPath *string, longPath *string, rawOptions FSMR
 	}
 	defer r.Close()
 
+	project := NewProjectByRawOptions(&rawOptions)
 	decoder := json.NewDecoder(r)
-	if err := decoder.Decode(&rawOptions); err != nil {
+	if err := decoder.Decode(project); err != nil {
 		return nil, fmt.Errorf("cmd.ValidateFSMOptionsJSON: %w", err)
 	}
 
-	options, err := ValidateFSMOptions(&rawOptions, basePath)
+	options, err := ValidateProject(project, basePath)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ValidateFSMOptionsJSON: %w", err)
 	}
@@ -527,6 +532,9 @@ type BusinessTimeFuncRawOptions struct {
 	Duration                  float64
 	Weekdays                  string
 	AdditionalNotBusinessDays string
+
+	// NotBusinessDays are the dates of the project document that are added to the ones in AdditionalNotBusinessDays.
+	NotBusinessDays []string
 }
 
 func DeclareBusinessTimeFuncOptions(flags *flag.FlagSet, options *BusinessTimeFuncRawOptions) {
@@ -624,6 +632,14 @@ func ValidateBusinessTimeFuncOptions(options *BusinessTimeFuncRawOptions) (*Busi
 		f.Close()
 	}
 
+	for _, notBusinessDayText := range options.NotBusinessDays {
+		notBusinessDay, err := time.ParseInLocation("2006-01-02", notBusinessDayText, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("tools.ValidateBusinessTimeFuncOptions: %w", err)
+		}
+		additionalNotBusinessDays.Add(bizday.Day.Compare, bizday.NewDayByTime(notBusinessDay))
+	}
+
 	businessHoursFunc, err := bizday.NewBusinessHoursFunc(startTime, duration)
 	if err != nil {
 		return nil, fmt.Errorf("tools.ValidateBusinessTimeFuncOptions: %w", err)
@@ -675,3 +691,25 @@ func ValidatePlanOutputFormat(options *PlanOutputFormatRawOptions, logger *slog.
 		return nil, "", fmt.Errorf("tools.ValidatePlanOutputFormat: invalid output format: %q", options.OutputFormat)
 	}
 }
+
+type GraphOutputFormat string
+
+const (
+	GraphOutputFormatDot     GraphOutputFormat = "dot"
+	GraphOutputFormatGraphML GraphOutputFormat = "graphml"
+)
+
+func DeclareGraphOutputFormatOptions(flags *flag.FlagSet, outputFormat *string) {
+	flags.StringVar(outputFormat, "out-format", "", "output format (available: dot, graphml)")
+}
+
+func ValidateGraphOutputFormat(outputFormat string) (GraphOutputFormat, error) {
+	switch outputFormat {
+	case "", "dot":
+		return GraphOutputFormatDot, nil
+	case "graphml":
+		return GraphOutputFormatGraphML, nil
+	default:
+		return "", fmt.Errorf("tools.ValidateGraphOutputFormat: invalid output format: %q", outputFormat)
+	}
+}
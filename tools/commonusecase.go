@@ -1,7 +1,9 @@
 package tools
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"log/slog"
 	"strings"
 
@@ -11,9 +13,10 @@ import (
 	"github.com/Kuniwak/pfd-tools/pfd"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
-	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmtsv"
+	fsmtableencoding "github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
-	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
 )
 
 type FSMEnvSeed struct {
@@ -25,13 +28,19 @@ type FSMEnvSeed struct {
 	MilestoneTable                       *fsmtable.MilestoneTable
 	GroupTable                           *fsmtable.GroupTable
 	MaximalAvailableAllocationsThreshold int
+
+	// Lint is the configuration of the rules from the run config. It is nil if not configured.
+	Lint *allcheckers.Config
+
+	// Suppressions are the suppressions on the comment layers of the draw.io file.
+	Suppressions []allcheckers.Suppression
 }
 
 func ParseFSMEnvSeed(fsOpts *FSMOptions, logger *slog.Logger) (*FSMEnvSeed, error) {
 	var compositeDeliverableTable *pfd.CompositeDeliverableTable
 	if fsOpts.CompositeDeliverableTableReader != nil {
 		var err error
-		compositeDeliverableTable, err = pfdtsv.ParseCompositeDeliverableTable(fsOpts.CompositeDeliverableTableReader)
+		compositeDeliverableTable, err = pfdtableencoding.ParseCompositeDeliverableTable(fsOpts.CompositeDeliverableTableReader)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
@@ -40,32 +49,45 @@ func ParseFSMEnvSeed(fsOpts *FSMOptions, logger *slog.Logger) (*FSMEnvSeed, erro
 		CompositeDeliverableTable: compositeDeliverableTable,
 	}
 
-	up, err := pfdfmt.Parse("", fsOpts.PFDReader, parseOpts, logger)
+	// NOTE: The PFD is read here because the suppressions are read from the same bytes.
+	pfdBytes, err := io.ReadAll(fsOpts.PFDReader)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	up, err := pfdfmt.Parse("", bytes.NewReader(pfdBytes), parseOpts, logger)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	diagrams, err := ReadDiagrams(pfdBytes, logger)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	suppressions, err := allcheckers.DiagramSuppressions(diagrams)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
-	atomicProcessTable, err := pfdtsv.ParseAtomicProcessTable(fsOpts.AtomicProcessTableReader)
+	atomicProcessTable, err := pfdtableencoding.ParseAtomicProcessTable(fsOpts.AtomicProcessTableReader)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseFSMTable: %w", err)
 	}
-	atomicDeliverableTable, err := pfdtsv.ParseAtomicDeliverableTable(fsOpts.AtomicDeliverableTableReader)
+	atomicDeliverableTable, err := pfdtableencoding.ParseAtomicDeliverableTable(fsOpts.AtomicDeliverableTableReader)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseFSMTable: %w", err)
 	}
-	resourceTable, err := fsmtsv.ParseResourceTable(fsOpts.ResourceTableReader)
+	resourceTable, err := fsmtableencoding.ParseResourceTable(fsOpts.ResourceTableReader)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseFSMTable: %w", err)
 	}
 	var milestoneTable *fsmtable.MilestoneTable
 	if fsOpts.MilestoneTableReader != nil {
-		milestoneTable, err = fsmtsv.ParseMilestoneTable(fsOpts.MilestoneTableReader)
+		milestoneTable, err = fsmtableencoding.ParseMilestoneTable(fsOpts.MilestoneTableReader)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseFSMTable: %w", err)
 		}
 	}
 	var groupTable *fsmtable.GroupTable
 	if fsOpts.GroupTableReader != nil {
-		groupTable, err = fsmtsv.ParseGroupTable(fsOpts.GroupTableReader)
+		groupTable, err = fsmtableencoding.ParseGroupTable(fsOpts.GroupTableReader)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseFSMTable: %w", err)
 		}
@@ -79,11 +101,43 @@ func ParseFSMEnvSeed(fsOpts *FSMOptions, logger *slog.Logger) (*FSMEnvSeed, erro
 		MilestoneTable:                       milestoneTable,
 		GroupTable:                           groupTable,
 		MaximalAvailableAllocationsThreshold: fsOpts.MaximalAvailableAllocationsThreshold,
+		Lint:                                 fsOpts.Lint,
+		Suppressions:                         suppressions,
 	}, nil
 }
 
+// ReadDiagrams returns the diagrams of the PFD. PFDs other than draw.io files have no diagrams.
+func ReadDiagrams(bs []byte, logger *slog.Logger) ([]pfddrawio.Diagram, error) {
+	format, _, err := pfdfmt.Detect(bytes.NewReader(bs))
+	if err != nil {
+		return nil, fmt.Errorf("tools.ReadDiagrams: %w", err)
+	}
+	if format != pfdfmt.FormatDrawio {
+		return nil, nil
+	}
+
+	diagrams, err := pfddrawio.ParseDiagrams(bytes.NewReader(bs), logger)
+	if err != nil {
+		return nil, fmt.Errorf("tools.ReadDiagrams: %w", err)
+	}
+	return diagrams, nil
+}
+
+// ValidateFSMEnvSeed fails if the lint reports errors. The lint section of the run config and the suppressions are
+// applied in the same way as pfdlint.
 func ValidateFSMEnvSeed(fsmEnvSeed *FSMEnvSeed, logger *slog.Logger, locale locale.Locale) error {
-	ps, err := allcheckers.Lint(
+	filter, err := allcheckers.NewFilter(fsmEnvSeed.Lint, fsmEnvSeed.Suppressions, fsmEnvSeed.PFD)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	userRules, err := fsmEnvSeed.Lint.CompileUserRules()
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	lintFunc := allcheckers.NewFilteredLintFunc(allcheckers.NewLintFuncWithConfig(userRules, fsmEnvSeed.Lint.MetricThresholds(), logger), filter)
+
+	ps, err := allcheckers.LintByFunc(
+		lintFunc,
 		fsmEnvSeed.PFD,
 		fsmEnvSeed.AtomicProcessTable,
 		fsmEnvSeed.AtomicDeliverableTable,
@@ -92,7 +146,6 @@ func ValidateFSMEnvSeed(fsmEnvSeed *FSMEnvSeed, logger *slog.Logger, locale loca
 		fsmEnvSeed.ResourceTable,
 		fsmEnvSeed.MilestoneTable,
 		fsmEnvSeed.GroupTable,
-		logger,
 	)
 	if err != nil {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
@@ -62,6 +62,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
+	tools.ApplyProjectSearch(flags, &searchRawOptions, fsmOptions.Search)
 
 	searchFunc, err := tools.ValidateSearchOptions(&searchRawOptions)
 	if err != nil {
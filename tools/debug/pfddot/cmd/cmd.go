@@ -5,10 +5,14 @@ import (
 	"log/slog"
 
 	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmreporter"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddot"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
-	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdgraphml"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
 	"github.com/Kuniwak/pfd-tools/slograw"
+	"github.com/Kuniwak/pfd-tools/tools"
 	"github.com/Kuniwak/pfd-tools/version"
 )
 
@@ -37,25 +41,57 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	logger := slog.New(slograw.NewHandler(inout.Stderr, opts.CommonOptions.LogLevel))
 
-	compositeDeliverableTable, err := pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
-	if err != nil {
-		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	parseOpts := &pfdfmt.ParseOptions{}
+	if opts.CompositeDeliverableTableReader != nil {
+		compositeDeliverableTable, err := pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		parseOpts.CompositeDeliverableTable = compositeDeliverableTable
 	}
 
-	p, err := pfdfmt.Parse("", opts.PFDReader, &pfdfmt.ParseOptions{
-		CompositeDeliverableTable: compositeDeliverableTable,
-	}, logger)
+	p, err := pfdfmt.Parse("", opts.PFDReader, parseOpts, logger)
 	if err != nil {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 
-	d, err := pfddot.Dot(p)
-	if err != nil {
-		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
-	}
+	switch opts.OutputFormat {
+	case tools.GraphOutputFormatDot:
+		overlay := &pfddot.Overlay{}
+		if opts.HasPlan {
+			plan, err := fsm.ParsePlan(opts.PlanReader)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+			overlay.StartTimes = pfddot.NewStartTimes(fsmreporter.BuildTimelineTable(plan, logger))
+		}
+		if opts.HasCriticalPath {
+			overlay.CriticalPath, err = parseCriticalPath(opts.CriticalPathReader)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+		}
+		if opts.HasProblems {
+			overlay.Problems, err = parseProblems(opts.ProblemsReader)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+		}
 
-	if err := d.Write(inout.Stdout); err != nil {
-		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		d, err := pfddot.DotWithOverlay(p, overlay)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+
+		if err := d.Write(inout.Stdout); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+	case tools.GraphOutputFormatGraphML:
+		if err := pfdgraphml.Write(inout.Stdout, p); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+	default:
+		return fmt.Errorf("cmd.MainCommandByOptions: unknown output format: %q", opts.OutputFormat)
 	}
 
 	return nil
@@ -1,18 +1,69 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Kuniwak/pfd-tools/cli"
 )
 
 func TestCmd(t *testing.T) {
-	spy := cli.SpyProcInout()
-	exitStatus := MainCommandByArgs([]string{"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv"}, spy.NewProcInout())
+	testCases := map[string]struct {
+		Args     []string
+		Expected []string
+	}{
+		"dot": {
+			Args: []string{"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv"},
+		},
+		"json without composite deliverable table": {
+			Args:     []string{"-p", "testdata/simple/pfd.json"},
+			Expected: []string{`P1 [label="P1: Process"; shape="ellipse"; ];`},
+		},
+		"plan": {
+			Args: []string{"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv", "-plan", "testdata/simple/plan.json"},
+		},
+		"critical path": {
+			Args:     []string{"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv", "-critical-path", "testdata/simple/critical_path.tsv"},
+			Expected: []string{`P1 [label="P1: Process"; penwidth=2; peripheries=2; shape="ellipse"; tooltip="critical path"; ];`},
+		},
+		"problems": {
+			Args: []string{"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv", "-problems", "testdata/simple/problems.json"},
+			Expected: []string{
+				`D1 [color="orange"; fillcolor="lightgrey"; label="D1: Initial deliverable"; shape="box"; tooltip="WARNING: no-desc"; ];`,
+				`P1 [color="red"; fillcolor="lightgrey"; label="P1: Process"; shape="ellipse"; tooltip="ERROR: never-allocatable"; ];`,
+			},
+		},
+		"graphml": {
+			Args: []string{"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv", "-out-format", "graphml"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs(tc.Args, spy.NewProcInout())
+
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Log(spy.Stdout.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+			for _, expected := range tc.Expected {
+				if !strings.Contains(spy.Stdout.String(), expected) {
+					t.Errorf("missing %s in:\n%s", expected, spy.Stdout.String())
+				}
+			}
+		})
+	}
+}
 
-	if exitStatus != 0 {
-		t.Log(spy.Stderr.String())
-		t.Log(spy.Stdout.String())
-		t.Errorf("exitStatus = %d, want 0", exitStatus)
+func TestCmdDrawIOWithoutCompositeDeliverableTable(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-p", "testdata/simple/pfd.drawio"}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+	if !strings.Contains(spy.Stderr.String(), "missing composite deliverable table") {
+		t.Errorf("want missing composite deliverable table, got %q", spy.Stderr.String())
 	}
 }
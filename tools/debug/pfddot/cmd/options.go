@@ -15,13 +15,20 @@ type Options struct {
 	CommonOptions                   *tools.CommonOptions
 	PFDReader                       io.Reader
 	CompositeDeliverableTableReader io.Reader
+	OutputFormat                    tools.GraphOutputFormat
+	HasPlan                         bool
+	PlanReader                      io.Reader
+	HasCriticalPath                 bool
+	CriticalPathReader              io.Reader
+	HasProblems                     bool
+	ProblemsReader                  io.Reader
 }
 
 func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 	flags := flag.NewFlagSet("pfddot", flag.ContinueOnError)
 	flags.SetOutput(inout.Stderr)
 	flags.Usage = func() {
-		fmt.Fprintln(flags.Output(), "Usage: pfddot [options] -p <pfd> -cd <composite-deliverable-table>")
+		fmt.Fprintln(flags.Output(), "Usage: pfddot [options] -p <pfd> [-cd <composite-deliverable-table>] [-out-format dot|graphml] [-plan <plan>] [-critical-path <criticalpath output>] [-problems <pfdlint json>]")
 		fmt.Fprintln(flags.Output(), "\nOptions")
 		flags.PrintDefaults()
 	}
@@ -34,6 +41,18 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 	tools.DeclarePFDOptions(flags, &pfdPathShortFlag, &pfdPathLongFlag)
 	tools.DeclareCompositeDeliverableTableOptions(flags, &compositeDeliverableTablePathShortFlag, &compositeDeliverableTablePathLongFlag)
 
+	var outputFormatFlag string
+	tools.DeclareGraphOutputFormatOptions(flags, &outputFormatFlag)
+
+	var planLongPath string
+	flags.StringVar(&planLongPath, "plan", "", "path to the plan to shade atomic processes by start times (only for dot)")
+
+	var criticalPathLongPath string
+	flags.StringVar(&criticalPathLongPath, "critical-path", "", "path to the output of criticalpath to draw atomic processes on critical paths with double borders (only for dot)")
+
+	var problemsLongPath string
+	flags.StringVar(&problemsLongPath, "problems", "", "path to the output of pfdlint -format json to color nodes by the severities of their problems (only for dot)")
+
 	if err := flags.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return &Options{CommonOptions: &tools.CommonOptions{Help: true}}, nil
@@ -59,10 +78,62 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
-	compositeDeliverableTableReader, _, err := tools.ValidateCompositeDeliverableTableOptions(&compositeDeliverableTablePathShortFlag, &compositeDeliverableTablePathLongFlag, cwd)
+
+	var compositeDeliverableTableReader io.Reader
+	if compositeDeliverableTablePathShortFlag != "" || compositeDeliverableTablePathLongFlag != "" {
+		compositeDeliverableTableReader, _, err = tools.ValidateCompositeDeliverableTableOptions(&compositeDeliverableTablePathShortFlag, &compositeDeliverableTablePathLongFlag, cwd)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+	}
+
+	outputFormat, err := tools.ValidateGraphOutputFormat(outputFormatFlag)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
 
-	return &Options{CommonOptions: commonOptions, PFDReader: pfdReader, CompositeDeliverableTableReader: compositeDeliverableTableReader}, nil
+	var planReader io.Reader
+	var hasPlan bool
+	if planLongPath != "" {
+		hasPlan = true
+		planReader, err = os.Open(planLongPath)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+	} else {
+		hasPlan = false
+	}
+
+	var criticalPathReader io.Reader
+	var hasCriticalPath bool
+	if criticalPathLongPath != "" {
+		hasCriticalPath = true
+		criticalPathReader, err = os.Open(criticalPathLongPath)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+	}
+
+	var problemsReader io.Reader
+	var hasProblems bool
+	if problemsLongPath != "" {
+		hasProblems = true
+		problemsReader, err = os.Open(problemsLongPath)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+	}
+
+	return &Options{
+		CommonOptions:                   commonOptions,
+		PFDReader:                       pfdReader,
+		CompositeDeliverableTableReader: compositeDeliverableTableReader,
+		OutputFormat:                    outputFormat,
+		HasPlan:                         hasPlan,
+		PlanReader:                      planReader,
+		HasCriticalPath:                 hasCriticalPath,
+		CriticalPathReader:              criticalPathReader,
+		HasProblems:                     hasProblems,
+		ProblemsReader:                  problemsReader,
+	}, nil
 }
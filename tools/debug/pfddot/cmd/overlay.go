@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Kuniwak/pfd-tools/allcheckers"
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+)
+
+// parseCriticalPath parses the TSV written by criticalpath. Minimum elasticities are "-" if they do not exist.
+func parseCriticalPath(r io.Reader) (fsm.CriticalPathInfo, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.FieldsPerRecord = 3
+
+	if _, err := cr.Read(); err != nil {
+		return nil, fmt.Errorf("cmd.parseCriticalPath: missing header: %w", err)
+	}
+
+	res := make(fsm.CriticalPathInfo)
+	for {
+		record, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return res, nil
+			}
+			return nil, fmt.Errorf("cmd.parseCriticalPath: %w", err)
+		}
+
+		maximumElasticity, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.parseCriticalPath: %s: %w", record[0], err)
+		}
+		item := &fsm.CriticalPathInfoItem{MaximumElasticity: execmodel.Time(maximumElasticity)}
+
+		if record[2] != "-" {
+			minimumElasticity, err := strconv.ParseFloat(record[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("cmd.parseCriticalPath: %s: %w", record[0], err)
+			}
+			item.HasMinimumElasticity = true
+			item.MinimumElasticity = execmodel.Time(minimumElasticity)
+		}
+
+		res[pfd.AtomicProcessID(record[0])] = item
+	}
+}
+
+// parseProblems parses the JSON written by pfdlint -format json. Locations are read as the locations of the IDs on the
+// PFD because only the nodes of them are drawn.
+func parseProblems(r io.Reader) ([]checkers.Problem, error) {
+	ps, err := allcheckers.ReadJSON(r)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.parseProblems: %w", err)
+	}
+
+	res := make([]checkers.Problem, 0, len(ps))
+	for _, p := range ps {
+		severity, err := checkers.ParseSeverity(p.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.parseProblems: %w", err)
+		}
+
+		locations := make([]checkers.Location, 0, len(p.Locations))
+		for _, loc := range p.Locations {
+			ids := make([]pfd.NodeID, 0, len(loc.IDs))
+			for _, id := range loc.IDs {
+				// NOTE: IDs of resources, milestones and groups are not node IDs, but they match no nodes.
+				ids = append(ids, pfd.NodeID(id))
+			}
+			locations = append(locations, pfdcommon.NewLocation(pfdcommon.LocationTypePFD, ids...))
+		}
+
+		res = append(res, checkers.NewProblem(p.ProblemID, severity, locations...))
+	}
+	return res, nil
+}
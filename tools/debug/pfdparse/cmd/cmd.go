@@ -1,13 +1,15 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"log/slog"
 
 	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
-	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdjson"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
 	"github.com/Kuniwak/pfd-tools/slograw"
 	"github.com/Kuniwak/pfd-tools/version"
 )
@@ -39,12 +41,16 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	logger := slog.New(slograw.NewHandler(inout.Stderr, opts.CommonOptions.LogLevel))
 
-	compositeDeliverableTable, err := pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
-	if err != nil {
-		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	var compositeDeliverableTable *pfd.CompositeDeliverableTable
+	if opts.CompositeDeliverableTableReader != nil {
+		var err error
+		compositeDeliverableTable, err = pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
 	}
 
-	pfds, err := pfdfmt.Parse("", opts.PFDReader, &pfdfmt.ParseOptions{
+	pfds, srcMap, err := pfdfmt.ParseWithSourceMap("", opts.PFDReader, &pfdfmt.ParseOptions{
 		CompositeDeliverableTable: compositeDeliverableTable,
 	}, logger)
 	if err != nil {
@@ -52,10 +58,17 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 
-	encoder := json.NewEncoder(inout.Stdout)
-	encoder.SetIndent("", "  ")
-	err = encoder.Encode(pfds)
-	if err != nil {
+	if opts.SourceMap {
+		if srcMap == nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: source map is available only for draw.io")
+		}
+		if err := pfddrawio.WriteWithSourceMap(inout.Stdout, pfds, srcMap); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		return nil
+	}
+
+	if err := pfdjson.Write(inout.Stdout, pfds); err != nil {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 	return nil
@@ -7,12 +7,32 @@ import (
 )
 
 func TestCmd(t *testing.T) {
+	testCases := map[string][]string{
+		"drawio":     {"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv"},
+		"json":       {"-p", "testdata/simple/pfd.json"},
+		"source map": {"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv", "-source-map"},
+	}
+
+	for name, args := range testCases {
+		t.Run(name, func(t *testing.T) {
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs(args, spy.NewProcInout())
+
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Log(spy.Stdout.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+		})
+	}
+}
+
+func TestCmdSourceMapWithoutDrawio(t *testing.T) {
 	spy := cli.SpyProcInout()
-	exitStatus := MainCommandByArgs([]string{"-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv"}, spy.NewProcInout())
+	exitStatus := MainCommandByArgs([]string{"-p", "testdata/simple/pfd.json", "-source-map"}, spy.NewProcInout())
 
-	if exitStatus != 0 {
-		t.Log(spy.Stderr.String())
+	if exitStatus == 0 {
 		t.Log(spy.Stdout.String())
-		t.Errorf("exitStatus = %d, want 0", exitStatus)
+		t.Errorf("exitStatus = %d, want non-zero", exitStatus)
 	}
 }
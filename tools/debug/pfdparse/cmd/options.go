@@ -15,23 +15,24 @@ type Options struct {
 	CommonOptions                   *tools.CommonOptions
 	PFDReader                       io.Reader
 	CompositeDeliverableTableReader io.Reader
+	SourceMap                       bool
 }
 
 func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 	flags := flag.NewFlagSet("parse", flag.ContinueOnError)
 	flags.SetOutput(inout.Stderr)
 	flags.Usage = func() {
-		fmt.Fprintln(flags.Output(), "Usage: pfdparse [options] -p <pfd> -cd <composite-deliverable-table>")
+		fmt.Fprintln(flags.Output(), "Usage: pfdparse [options] -p <pfd> [-cd <composite-deliverable-table>] [-source-map]")
 		fmt.Fprintln(flags.Output(), "\nOptions")
 		flags.PrintDefaults()
 		fmt.Fprintf(flags.Output(), `
 Example
   $ pfdparse path/to/example.drawio
   {
+    "version": 1,
     "nodes": [
       {
         "id": "D1",
-        "desc": "",
         "type": "DELIVERABLE"
       },
       ...
@@ -43,13 +44,50 @@ Example
       },
       ...
     ],
-	"composition": {
+    "process_composition": {
       "P0": [
         "P1",
         "P2"
       ],
-	  ...
-	]
+      ...
+    }
+  }
+
+  $ pfdparse -source-map -p path/to/example.drawio -cd path/to/composite_deliverable.tsv
+  {
+    "version": 1,
+    "pfd": {
+      "version": 1,
+      "nodes": [...],
+      "edges": [...]
+    },
+    "source_map": {
+      "nodes": [
+        {
+          "id": "D1",
+          "locations": [
+            {
+              "diagram_id": "PrNXtJMoFKdakpcB9KSm",
+              "cell_id": "5"
+            }
+          ]
+        },
+        ...
+      ],
+      "edges": [
+        {
+          "source": "D1",
+          "target": "P2",
+          "locations": [
+            {
+              "diagram_id": "PrNXtJMoFKdakpcB9KSm",
+              "cell_id": "9"
+            }
+          ]
+        },
+        ...
+      ]
+    }
   }
 `)
 	}
@@ -62,6 +100,9 @@ Example
 	tools.DeclarePFDOptions(flags, &pfdPathShortFlag, &pfdPathLongFlag)
 	tools.DeclareCompositeDeliverableTableOptions(flags, &compositeDeliverableTablePathShortFlag, &compositeDeliverableTablePathLongFlag)
 
+	var sourceMapFlag bool
+	flags.BoolVar(&sourceMapFlag, "source-map", false, "output the PFD with the draw.io cells that nodes and edges are parsed from (only for draw.io)")
+
 	if err := flags.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return &Options{CommonOptions: &tools.CommonOptions{Help: true}}, nil
@@ -87,10 +128,18 @@ Example
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
-	compositeDeliverableTableReader, _, err := tools.ValidateCompositeDeliverableTableOptions(&compositeDeliverableTablePathShortFlag, &compositeDeliverableTablePathLongFlag, cwd)
-	if err != nil {
-		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+	var compositeDeliverableTableReader io.Reader
+	if compositeDeliverableTablePathShortFlag != "" || compositeDeliverableTablePathLongFlag != "" {
+		compositeDeliverableTableReader, _, err = tools.ValidateCompositeDeliverableTableOptions(&compositeDeliverableTablePathShortFlag, &compositeDeliverableTablePathLongFlag, cwd)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
 	}
 
-	return &Options{CommonOptions: commonOptions, PFDReader: pfdReader, CompositeDeliverableTableReader: compositeDeliverableTableReader}, nil
+	return &Options{
+		CommonOptions:                   commonOptions,
+		PFDReader:                       pfdReader,
+		CompositeDeliverableTableReader: compositeDeliverableTableReader,
+		SourceMap:                       sourceMapFlag,
+	}, nil
 }
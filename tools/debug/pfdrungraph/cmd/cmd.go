@@ -59,13 +59,27 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 
-	dot, err := fsmviz.Dot(env.IsCompleted, g, plans, logger)
-	if err != nil {
-		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
-	}
+	switch opts.OutputFormat {
+	case tools.GraphOutputFormatDot:
+		dot, err := fsmviz.Dot(env.IsCompleted, g, plans, logger)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
 
-	if err := dot.Write(inout.Stdout); err != nil {
-		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		if err := dot.Write(inout.Stdout); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+	case tools.GraphOutputFormatGraphML:
+		doc, err := fsmviz.GraphML(env.IsCompleted, g, plans, logger)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+
+		if err := doc.Write(inout.Stdout); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+	default:
+		return fmt.Errorf("cmd.MainCommandByOptions: unknown output format: %q", opts.OutputFormat)
 	}
 	return nil
 }
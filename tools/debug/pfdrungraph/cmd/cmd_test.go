@@ -7,12 +7,21 @@ import (
 )
 
 func TestCmd(t *testing.T) {
-	spy := cli.SpyProcInout()
-	exitStatus := MainCommandByArgs([]string{"-f", "testdata/simple/config.json", "-poor"}, spy.NewProcInout())
+	testCases := map[string][]string{
+		"dot":     {"-f", "testdata/simple/config.json", "-poor"},
+		"graphml": {"-f", "testdata/simple/config.json", "-poor", "-out-format", "graphml"},
+	}
+
+	for name, args := range testCases {
+		t.Run(name, func(t *testing.T) {
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs(args, spy.NewProcInout())
 
-	if exitStatus != 0 {
-		t.Log(spy.Stderr.String())
-		t.Log(spy.Stdout.String())
-		t.Errorf("exitStatus = %d, want 0", exitStatus)
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Log(spy.Stdout.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+		})
 	}
 }
@@ -17,13 +17,14 @@ type Options struct {
 	FSMOptions    *tools.FSMOptions
 	SearchFunc    fsm.SearchFunc
 	MaxDepth      int
+	OutputFormat  tools.GraphOutputFormat
 }
 
 func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 	flags := flag.NewFlagSet("pfdrungraph", flag.ContinueOnError)
 	flags.SetOutput(inout.Stderr)
 	flags.Usage = func() {
-		fmt.Fprintln(flags.Output(), "Usage: pfdrungraph [options] -p <pfd> [-a <atomic-process-table>] [-d <deliverable-table>] [-r <resource-table>]")
+		fmt.Fprintln(flags.Output(), "Usage: pfdrungraph [options] -p <pfd> [-a <atomic-process-table>] [-d <deliverable-table>] [-r <resource-table>] [-out-format dot|graphml]")
 		fmt.Fprintln(flags.Output(), "\nOptions")
 		flags.PrintDefaults()
 	}
@@ -41,6 +42,9 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 	var maxDepth int
 	flags.IntVar(&maxDepth, "max-depth", 100, "max depth")
 
+	var outputFormatFlag string
+	tools.DeclareGraphOutputFormatOptions(flags, &outputFormatFlag)
+
 	if err := flags.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return &Options{CommonOptions: &tools.CommonOptions{Help: true}}, nil
@@ -66,6 +70,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
+	tools.ApplyProjectSearch(flags, &searchRawOptions, fsmOptions.Search)
 
 	var searchFunc fsm.SearchFunc
 	searchFunc, err = tools.ValidateSearchOptions(&searchRawOptions)
@@ -73,10 +78,16 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
 
+	outputFormat, err := tools.ValidateGraphOutputFormat(outputFormatFlag)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+	}
+
 	return &Options{
 		CommonOptions: commonOptions,
 		FSMOptions:    fsmOptions,
 		SearchFunc:    searchFunc,
 		MaxDepth:      maxDepth,
+		OutputFormat:  outputFormat,
 	}, nil
 }
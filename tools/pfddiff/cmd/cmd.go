@@ -10,7 +10,7 @@ import (
 	"github.com/Kuniwak/pfd-tools/locale"
 	"github.com/Kuniwak/pfd-tools/pfd"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
-	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
 	"github.com/Kuniwak/pfd-tools/version"
 )
 
@@ -97,7 +97,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 }
 
 func buildDiff(opts *Options) (*pfd.DiffResult, error) {
-	compDelivTable1, err := pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader1)
+	compDelivTable1, err := pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader1)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
@@ -109,7 +109,7 @@ func buildDiff(opts *Options) (*pfd.DiffResult, error) {
 		return nil, fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 
-	compDelivTable2, err := pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader2)
+	compDelivTable2, err := pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader2)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
@@ -218,7 +218,7 @@ func writePrompt(w io.Writer, diffReader io.Reader, loc locale.Locale) error {
 			return fmt.Errorf("cmd.writePromptTemplate: %w", err)
 		}
 		return nil
-	case locale.LocaleEn:
+	default:
 		if _, err := io.WriteString(w, `Summarize the diff in the JSON string at the end of the output as a prompt for Agentic AIs:
 
 # How to read the diff data
@@ -310,7 +310,5 @@ To summarize the diff, please combine the node description and ID.
 			return fmt.Errorf("cmd.writePromptTemplate: %w", err)
 		}
 		return nil
-	default:
-		panic(fmt.Sprintf("cmd.writePromptTemplate: unknown locale: %s", loc))
 	}
 }
@@ -1,19 +1,34 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/Kuniwak/pfd-tools/allcheckers"
+	"github.com/Kuniwak/pfd-tools/chans"
 	"github.com/Kuniwak/pfd-tools/checkers"
 	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/locale"
 	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
-	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmtsv"
+	fsmtableencoding "github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
-	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdfix"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
 	"github.com/Kuniwak/pfd-tools/slograw"
+	"github.com/Kuniwak/pfd-tools/table"
+	"github.com/Kuniwak/pfd-tools/table/tablexlsx"
+	"github.com/Kuniwak/pfd-tools/textdiff"
+	"github.com/Kuniwak/pfd-tools/tools"
 	"github.com/Kuniwak/pfd-tools/version"
 	"golang.org/x/sync/errgroup"
 )
@@ -43,27 +58,65 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 		return nil
 	}
 
+	if opts.Explain != "" {
+		if err := writeExplanation(inout.Stdout, opts.Explain, opts.CommonOptions.Locale); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		return nil
+	}
+
 	logger := slog.New(slograw.NewHandler(inout.Stderr, opts.CommonOptions.LogLevel))
 
+	// NOTE: Suppressions and fixes need the original contents after parsing, so read them up front.
+	fixing := opts.Fix || opts.FixDryRun
+	atomicProcessTableReader := opts.AtomicProcessTableReader
+	atomicDeliverableTableReader := opts.AtomicDeliverableTableReader
+	compositeProcessTableReader := opts.CompositeProcessTableReader
+	var original pfdfix.Files
+	pfdBytes, pfdReader, err := readAll(opts.PFDReader)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	if fixing {
+		original.PFD = pfdBytes
+		if opts.HasAtomicProcessTable {
+			original.AtomicProcessTable, atomicProcessTableReader, err = readAll(atomicProcessTableReader)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+		}
+		if opts.HasAtomicDeliverableTable {
+			original.AtomicDeliverableTable, atomicDeliverableTableReader, err = readAll(atomicDeliverableTableReader)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+		}
+		if opts.HasCompositeProcessTable {
+			original.CompositeProcessTable, compositeProcessTableReader, err = readAll(compositeProcessTableReader)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+		}
+	}
+
 	parseOpts := &pfdfmt.ParseOptions{}
 	var compositeDeliverableTable *pfd.CompositeDeliverableTable
 	if opts.HasCompositeDeliverableTable {
-		var err error
-		compositeDeliverableTable, err = pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+		compositeDeliverableTable, err = pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
 		if err != nil {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
 		parseOpts.CompositeDeliverableTable = compositeDeliverableTable
 	}
 
-	p, err := pfdfmt.Parse("", opts.PFDReader, parseOpts, logger)
+	p, srcMap, err := pfdfmt.ParseWithSourceMap("", pfdReader, parseOpts, logger)
 	if err != nil {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 
 	var atomicTable *pfd.AtomicProcessTable
 	if opts.HasAtomicProcessTable {
-		atomicTable, err = pfdtsv.ParseAtomicProcessTable(opts.AtomicProcessTableReader)
+		atomicTable, err = pfdtableencoding.ParseAtomicProcessTable(atomicProcessTableReader)
 		if err != nil {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
@@ -73,7 +126,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	var atomicDeliverableTable *pfd.AtomicDeliverableTable
 	if opts.HasAtomicDeliverableTable {
-		atomicDeliverableTable, err = pfdtsv.ParseAtomicDeliverableTable(opts.AtomicDeliverableTableReader)
+		atomicDeliverableTable, err = pfdtableencoding.ParseAtomicDeliverableTable(atomicDeliverableTableReader)
 		if err != nil {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
@@ -83,7 +136,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	var compositeProcessTable *pfd.CompositeProcessTable
 	if opts.HasCompositeProcessTable {
-		compositeProcessTable, err = pfdtsv.ParseCompositeProcessTable(opts.CompositeProcessTableReader)
+		compositeProcessTable, err = pfdtableencoding.ParseCompositeProcessTable(compositeProcessTableReader)
 		if err != nil {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
@@ -93,7 +146,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	var resourceTable *fsmtable.ResourceTable
 	if opts.HasResourceTable {
-		resourceTable, err = fsmtsv.ParseResourceTable(opts.ResourceTableReader)
+		resourceTable, err = fsmtableencoding.ParseResourceTable(opts.ResourceTableReader)
 		if err != nil {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
@@ -103,7 +156,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	var milestoneTable *fsmtable.MilestoneTable
 	if opts.HasMilestoneTable {
-		milestoneTable, err = fsmtsv.ParseMilestoneTable(opts.MilestoneTableReader)
+		milestoneTable, err = fsmtableencoding.ParseMilestoneTable(opts.MilestoneTableReader)
 		if err != nil {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
@@ -112,16 +165,43 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 	}
 	var groupTable *fsmtable.GroupTable
 	if opts.HasGroupTable {
-		groupTable, err = fsmtsv.ParseGroupTable(opts.GroupTableReader)
+		groupTable, err = fsmtableencoding.ParseGroupTable(opts.GroupTableReader)
 		if err != nil {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
 	} else {
 		groupTable = nil
 	}
+
+	diagrams, err := tools.ReadDiagrams(pfdBytes, logger)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	sources := allcheckers.NewSources(locationPaths(opts), diagrams, srcMap, atomicTable, atomicDeliverableTable, compositeProcessTable, compositeDeliverableTable, resourceTable, milestoneTable, groupTable)
+	reporter, err := newReporter(opts, sources, inout)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+
+	suppressions, err := allcheckers.DiagramSuppressions(diagrams)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	filter, err := allcheckers.NewFilter(opts.Lint, suppressions, p)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+	userRules, err := opts.Lint.CompileUserRules()
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+
 	var eg errgroup.Group
 	ch := make(chan checkers.Problem)
-	lintFunc := allcheckers.NewLintFunc(logger)
+	lintFunc := allcheckers.NewFilteredLintFunc(
+		allcheckers.NewLintFuncWithProblems(allcheckers.NewLintFuncWithConfig(userRules, opts.Lint.MetricThresholds(), logger), pfddrawio.CheckDiagrams(diagrams)),
+		filter,
+	)
 
 	eg.Go(func() error {
 		if err = lintFunc(p, atomicTable, atomicDeliverableTable, compositeProcessTable, compositeDeliverableTable, resourceTable, milestoneTable, groupTable, ch); err != nil {
@@ -130,10 +210,67 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 		return nil
 	})
 
+	// NOTE: Fixes and baselines need all the problems before reporting.
+	if fixing || opts.HasBaseline || opts.WriteBaselinePath != "" {
+		var problems []checkers.Problem
+		eg.Go(func() error {
+			problems = chans.Slice(ch)
+			return nil
+		})
+
+		if err := eg.Wait(); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+
+		if opts.WriteBaselinePath != "" {
+			if err := writeBaseline(opts.WriteBaselinePath, problems); err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+			return nil
+		}
+
+		unfixed := problems
+		if fixing {
+			unfixed, err = applyFixes(opts, &original, problems, inout, logger)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+		}
+
+		reported := unfixed
+		if opts.HasBaseline {
+			baseline, err := allcheckers.ReadBaseline(opts.BaselineReader)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+
+			var fixed []allcheckers.BaselineEntry
+			reported, fixed = baseline.Diff(unfixed)
+			// NOTE: Stale entries are written on stderr even in silent mode because stdout is for the report.
+			for _, entry := range fixed {
+				_, _ = fmt.Fprintf(inout.Stderr, "fixed: %s: %s\n", entry.ProblemID, entry.Locations)
+			}
+			if len(fixed) > 0 {
+				_, _ = fmt.Fprintf(inout.Stderr, "stale baseline entries: %d. Write the baseline again to remove them.\n", len(fixed))
+			}
+		}
+
+		count, err := reporter(chans.From(reported))
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+
+		// NOTE: Dry runs fix nothing, so fixable problems are still problems.
+		if count > 0 || (opts.FixDryRun && len(unfixed) < len(problems)) {
+			return ErrProblemsFound
+		}
+		return nil
+	}
+
 	var count int
 	eg.Go(func() error {
 		var err error
-		count, err = opts.Reporter(ch)
+		count, err = reporter(ch)
 		if err != nil {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
@@ -150,3 +287,195 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	return nil
 }
+
+// locationPaths returns the paths of the files that locations of the types point to.
+func locationPaths(opts *Options) map[fsmcommon.LocationType]string {
+	return map[fsmcommon.LocationType]string{
+		fsmcommon.LocationTypePFD:                       opts.PFDPath,
+		fsmcommon.LocationTypeAtomicProcessTable:        opts.AtomicProcessTablePath,
+		fsmcommon.LocationTypeAtomicDeliverableTable:    opts.AtomicDeliverableTablePath,
+		fsmcommon.LocationTypeCompositeProcessTable:     opts.CompositeProcessTablePath,
+		fsmcommon.LocationTypeCompositeDeliverableTable: opts.CompositeDeliverableTablePath,
+		fsmcommon.LocationTypeResourceTable:             opts.ResourceTablePath,
+		fsmcommon.LocationTypeMilestoneTable:            opts.MilestoneTablePath,
+		fsmcommon.LocationTypeGroupTable:                opts.GroupTablePath,
+	}
+}
+
+func newReporter(opts *Options, sources *allcheckers.Sources, inout *cli.ProcInout) (allcheckers.Func, error) {
+	switch opts.Format {
+	case allcheckers.FormatTSV:
+		return allcheckers.NewTSV(inout.Stdout, opts.CommonOptions.Locale), nil
+	case allcheckers.FormatJSON:
+		return allcheckers.NewJSON(inout.Stdout, opts.CommonOptions.Locale, sources), nil
+	case allcheckers.FormatSARIF:
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("cmd.newReporter: %w", err)
+		}
+
+		uris := make(map[fsmcommon.LocationType]string, len(sources.Paths))
+		for t, path := range sources.Paths {
+			if path == "" {
+				continue
+			}
+			uris[t] = artifactURI(path, cwd)
+		}
+
+		artifacts := &allcheckers.SARIFArtifacts{URIs: uris, SourceMap: sources.SourceMap}
+		return allcheckers.NewSARIF(inout.Stdout, opts.CommonOptions.Locale, artifacts), nil
+	default:
+		return nil, fmt.Errorf("cmd.newReporter: unknown format: %q", opts.Format)
+	}
+}
+
+// artifactURI returns the URI relative to the working directory, so that code scanning UIs can resolve it from the
+// repository root. Files outside the working directory have file URIs.
+func artifactURI(path string, cwd string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	rel, err := filepath.Rel(cwd, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+	}
+	return (&url.URL{Path: filepath.ToSlash(rel)}).String()
+}
+
+// applyFixes rewrites the files in their modes or writes the unified diff of them on stderr, and returns the problems that are not fixed.
+func applyFixes(opts *Options, original *pfdfix.Files, problems []checkers.Problem, inout *cli.ProcInout, logger *slog.Logger) ([]checkers.Problem, error) {
+	fixed, unfixed, err := pfdfix.Apply(original, problems, logger)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.applyFixes: %w", err)
+	}
+
+	targets := []struct {
+		Path     string
+		Sheet    string
+		Original []byte
+		Fixed    []byte
+	}{
+		{Path: opts.PFDPath, Original: original.PFD, Fixed: fixed.PFD},
+		{Path: opts.AtomicProcessTablePath, Sheet: string(pfd.TableTypeAtomicProcess), Original: original.AtomicProcessTable, Fixed: fixed.AtomicProcessTable},
+		{Path: opts.AtomicDeliverableTablePath, Sheet: string(pfd.TableTypeAtomicDeliverable), Original: original.AtomicDeliverableTable, Fixed: fixed.AtomicDeliverableTable},
+		{Path: opts.CompositeProcessTablePath, Sheet: string(pfd.TableTypeCompositeProcess), Original: original.CompositeProcessTable, Fixed: fixed.CompositeProcessTable},
+	}
+
+	paths := make([]string, 0, len(targets))
+	originals := make(map[string][]byte, len(targets))
+	contents := make(map[string][]byte, len(targets))
+	for _, target := range targets {
+		if bytes.Equal(target.Original, target.Fixed) {
+			continue
+		}
+
+		prev, ok := contents[target.Path]
+		if !ok {
+			paths = append(paths, target.Path)
+			originals[target.Path] = target.Original
+			contents[target.Path] = target.Fixed
+			continue
+		}
+
+		// NOTE: Tables can share a workbook, and each of the fixed workbooks has only its own sheet fixed.
+		merged, err := mergeSheet(prev, target.Fixed, target.Sheet)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.applyFixes: %s: %w", target.Path, err)
+		}
+		contents[target.Path] = merged
+	}
+
+	for _, path := range paths {
+		if opts.FixDryRun {
+			// NOTE: Diffs are written on stderr because stdout is for the report that may be JSON or SARIF.
+			if format, _, err := table.Detect(bytes.NewReader(originals[path])); err == nil && format == table.FormatXLSX {
+				_, _ = fmt.Fprintf(inout.Stderr, "Binary files %s and %s differ\n", path, path)
+				continue
+			}
+			if err := textdiff.WriteUnified(inout.Stderr, path, path, string(originals[path]), string(contents[path])); err != nil {
+				return nil, fmt.Errorf("cmd.applyFixes: %w", err)
+			}
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.applyFixes: %w", err)
+		}
+
+		logger.Info("cmd.applyFixes: rewriting", "path", path)
+		if err := os.WriteFile(path, contents[path], info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("cmd.applyFixes: %w", err)
+		}
+	}
+
+	return unfixed, nil
+}
+
+// mergeSheet returns the workbook with the sheet of the name replaced by the one of the fixed workbook. The other parts
+// of the workbook are kept.
+func mergeSheet(workbook []byte, fixed []byte, name string) ([]byte, error) {
+	fixedWB, err := tablexlsx.ParseWorkbook(bytes.NewReader(fixed))
+	if err != nil {
+		return nil, fmt.Errorf("cmd.mergeSheet: %w", err)
+	}
+	sheet, ok := fixedWB.Sheet(name)
+	if !ok {
+		return nil, fmt.Errorf("cmd.mergeSheet: missing sheet: %q", name)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tablexlsx.UpdateSheet(buf, bytes.NewReader(workbook), sheet); err != nil {
+		return nil, fmt.Errorf("cmd.mergeSheet: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeBaseline(path string, problems []checkers.Problem) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cmd.writeBaseline: %w", err)
+	}
+	defer f.Close()
+
+	if err := allcheckers.WriteBaseline(f, allcheckers.NewBaseline(problems)); err != nil {
+		return fmt.Errorf("cmd.writeBaseline: %w", err)
+	}
+	return nil
+}
+
+func readAll(r io.Reader) ([]byte, io.Reader, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cmd.readAll: %w", err)
+	}
+	return bs, bytes.NewReader(bs), nil
+}
+
+// writeExplanation writes the message, the explanation and the suggested fix of the problem ID. Sections missing in
+// every catalog are omitted.
+func writeExplanation(w io.Writer, id checkers.ProblemID, l locale.Locale) error {
+	entry, entryLocale, ok := allcheckers.LookupMessage(id, l)
+	if !ok {
+		return fmt.Errorf("cmd.writeExplanation: unknown problem ID: %q", id)
+	}
+	labels := allcheckers.LookupLabels(entryLocale)
+
+	sb := &strings.Builder{}
+	sb.WriteString(string(id))
+	sb.WriteString("\t")
+	sb.WriteString(entry.Message)
+	sb.WriteString("\n")
+	if entry.Explanation != "" {
+		fmt.Fprintf(sb, "\n%s\n%s\n", labels.Explanation, entry.Explanation)
+	}
+	if entry.Fix != "" {
+		fmt.Fprintf(sb, "\n%s\n%s\n", labels.Fix, entry.Fix)
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("cmd.writeExplanation: %w", err)
+	}
+	return nil
+}
@@ -1,9 +1,21 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/Kuniwak/pfd-tools/allcheckers"
+	"github.com/Kuniwak/pfd-tools/checkers"
 	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/locale"
+	"github.com/Kuniwak/pfd-tools/table/tabletsv"
+	"github.com/Kuniwak/pfd-tools/table/tablexlsx"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestMainCommandByArgs(t *testing.T) {
@@ -27,3 +39,466 @@ func TestMainCommandByArgs(t *testing.T) {
 		}
 	})
 }
+
+func TestMainCommandByArgsProjectTables(t *testing.T) {
+	testCases := map[string]struct {
+		MilestoneTable     string
+		ExpectedExitStatus int
+	}{
+		"valid milestone table": {
+			MilestoneTable:     "ID\tDescription\tGroups\tSuccessors\nM1\tMilestone 1\t\t\n",
+			ExpectedExitStatus: 0,
+		},
+		"broken milestone table": {
+			MilestoneTable:     "PK\x03\x04broken",
+			ExpectedExitStatus: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := copyTestdata(t, "testdata/simple")
+			if err := os.WriteFile(filepath.Join(dir, "milestone.tsv"), []byte(tc.MilestoneTable), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config := `{
+	"pfd": "pfd.drawio",
+	"atomic_process_table": "atomic_proc.tsv",
+	"atomic_deliverable_table": "deliv.tsv",
+	"composite_deliverable_table": "comp_deliv.tsv",
+	"resource_table": "resource.tsv",
+	"milestone_table": "milestone.tsv"
+}`
+			if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-f", filepath.Join(dir, "config.json")}, spy.NewProcInout())
+			if exitStatus != tc.ExpectedExitStatus {
+				t.Log(spy.Stderr.String())
+				t.Log(spy.Stdout.String())
+				t.Errorf("exitStatus = %d, want %d", exitStatus, tc.ExpectedExitStatus)
+			}
+		})
+	}
+}
+
+func TestMainCommandByArgsConfig(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-locale", "en", "-f", "testdata/configured/config.json"}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Log(spy.Stderr.String())
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+
+	// NOTE: no-desc of D2 is suppressed on the comment layer, and the severity of no-desc is configured.
+	expected := "ERROR\tno-desc\tPlease add a concise description.\tPFD[P1]\n"
+	if spy.Stdout.String() != expected {
+		t.Error(cmp.Diff(expected, spy.Stdout.String()))
+	}
+}
+
+func TestMainCommandByArgsDrawIODefects(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-locale", "en", "-f", "testdata/defective/config.json"}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Log(spy.Stderr.String())
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+
+	// NOTE: The edge without the source is skipped when parsing the PFD, and the copy of D1 overlaps the original.
+	expected := "ERROR\tdrawio-dangling-edge\tThe edge on the draw.io diagram is not connected to a deliverable or a process at both ends, so it is not a part of the PFD.\tDRAWIO[wRU_aafd9vpDkhm-03GV, 8]\n" +
+		"WARNING\tdrawio-overlapping-duplicate\tThe shapes with the same ID overlap on the draw.io diagram.\tDRAWIO[wRU_aafd9vpDkhm-03GV, 2], DRAWIO[wRU_aafd9vpDkhm-03GV, 9]\n"
+	if spy.Stdout.String() != expected {
+		t.Error(cmp.Diff(expected, spy.Stdout.String()))
+	}
+}
+
+func TestMainCommandByArgsUserRules(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-locale", "ja", "-f", "testdata/configured/user_rules.json"}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Log(spy.Stderr.String())
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+
+	expected := "ERROR\tsmall-process\tプロセスをより小さく分割してください。\tPFD[P1]\n"
+	if spy.Stdout.String() != expected {
+		t.Error(cmp.Diff(expected, spy.Stdout.String()))
+	}
+}
+
+func TestMainCommandByArgsMetrics(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-locale", "en", "-f", "testdata/configured/metrics.json"}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Log(spy.Stderr.String())
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+
+	expected := "WARNING\tmetric-fan-in\tThe process has too many or too few inputs.\tPFD[P1]\n" +
+		"WARNING\tmetric-depth\tThe longest path of the PFD is too long or too short.\tPFD[P1]\n"
+	if spy.Stdout.String() != expected {
+		t.Error(cmp.Diff(expected, spy.Stdout.String()))
+	}
+}
+
+func TestMainCommandByArgsSARIF(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-format", "sarif", "-locale", "en", "-f", "testdata/invalid/config.json"}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Log(spy.Stderr.String())
+		t.Log(spy.Stdout.String())
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+
+	var log allcheckers.SARIFLog
+	if err := json.Unmarshal(spy.Stdout.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != allcheckers.SARIFVersion {
+		t.Errorf("version = %q, want %q", log.Version, allcheckers.SARIFVersion)
+	}
+
+	uris := make(map[string]bool)
+	for _, result := range log.Runs[0].Results {
+		for _, loc := range result.Locations {
+			if loc.PhysicalLocation != nil {
+				uris[loc.PhysicalLocation.ArtifactLocation.URI] = true
+			}
+		}
+	}
+	expected := map[string]bool{
+		"testdata/invalid/pfd.drawio":      true,
+		"testdata/invalid/atomic_proc.tsv": true,
+		"testdata/invalid/deliv.tsv":       true,
+	}
+	if !cmp.Equal(expected, uris) {
+		t.Error(cmp.Diff(expected, uris))
+	}
+}
+
+func TestMainCommandByArgsJSON(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-format", "json", "-locale", "en", "-f", "testdata/defective/config.json"}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Log(spy.Stderr.String())
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+
+	var problem struct {
+		ProblemID string                     `json:"problem_id"`
+		Locations []allcheckers.JSONLocation `json:"locations"`
+	}
+	line, _, _ := strings.Cut(spy.Stdout.String(), "\n")
+	if err := json.Unmarshal([]byte(line), &problem); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []allcheckers.JSONLocation{
+		{
+			Text: "DRAWIO[wRU_aafd9vpDkhm-03GV, 8]",
+			Type: allcheckers.LocationTypeDrawIO,
+			IDs:  []string{},
+			Spans: []checkers.Span{
+				{Kind: checkers.SpanKindDrawIO, Path: "testdata/defective/pfd.drawio", Page: "P0", PageID: "wRU_aafd9vpDkhm-03GV", CellID: "8"},
+			},
+		},
+	}
+	if problem.ProblemID != "drawio-dangling-edge" {
+		t.Errorf("problem_id = %q, want %q", problem.ProblemID, "drawio-dangling-edge")
+	}
+	if !cmp.Equal(expected, problem.Locations) {
+		t.Error(cmp.Diff(expected, problem.Locations))
+	}
+}
+
+func TestMainCommandByArgsExplain(t *testing.T) {
+	t.Run("known problem ID", func(t *testing.T) {
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-locale", "ja", "-explain", "single-src"}, spy.NewProcInout())
+		if exitStatus != 0 {
+			t.Log(spy.Stderr.String())
+			t.Errorf("exitStatus = %d, want 0", exitStatus)
+		}
+
+		entry, _, _ := allcheckers.LookupMessage("single-src", locale.LocaleJa)
+		expected := "single-src\t" + entry.Message + "\n\n説明\n" + entry.Explanation + "\n\n修正方法\n" + entry.Fix + "\n"
+		if spy.Stdout.String() != expected {
+			t.Error(cmp.Diff(expected, spy.Stdout.String()))
+		}
+	})
+
+	t.Run("locale without explanations", func(t *testing.T) {
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-locale", "ko", "-explain", "single-src"}, spy.NewProcInout())
+		if exitStatus != 0 {
+			t.Log(spy.Stderr.String())
+			t.Errorf("exitStatus = %d, want 0", exitStatus)
+		}
+
+		entry, _, _ := allcheckers.LookupMessage("single-src", locale.LocaleEn)
+		expected := "single-src\t" + entry.Message + "\n\nExplanation\n" + entry.Explanation + "\n\nFix\n" + entry.Fix + "\n"
+		if spy.Stdout.String() != expected {
+			t.Error(cmp.Diff(expected, spy.Stdout.String()))
+		}
+	})
+
+	t.Run("unknown problem ID", func(t *testing.T) {
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-explain", "no-such-problem"}, spy.NewProcInout())
+		if exitStatus != 1 {
+			t.Errorf("exitStatus = %d, want 1", exitStatus)
+		}
+	})
+}
+
+func TestMainCommandByArgsFix(t *testing.T) {
+	t.Run("fix", func(t *testing.T) {
+		dir := copyTestdata(t, "testdata/invalid")
+		if err := os.Chmod(filepath.Join(dir, "atomic_proc.tsv"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-fix", "-f", filepath.Join(dir, "config.json")}, spy.NewProcInout())
+		if exitStatus != 1 {
+			t.Log(spy.Stderr.String())
+			t.Log(spy.Stdout.String())
+			t.Errorf("exitStatus = %d, want 1", exitStatus)
+		}
+		if strings.Contains(spy.Stdout.String(), "missing-ap-table") {
+			t.Errorf("fixed problems should not be reported:\n%s", spy.Stdout.String())
+		}
+
+		info, err := os.Stat(filepath.Join(dir, "atomic_proc.tsv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+		}
+
+		bs, err := os.ReadFile(filepath.Join(dir, "atomic_proc.tsv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "ID\tDescription\tEst. Work Volume\tEst. Rework Volume Ratio\tNeeded Resources\tStart Condition\nProcess1\t\t\t\t\t\nProcess2\t\t\t\t\t\n"
+		if string(bs) != expected {
+			t.Error(cmp.Diff(expected, string(bs)))
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		dir := copyTestdata(t, "testdata/invalid")
+		path := filepath.Join(dir, "atomic_proc.tsv")
+		before, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-fix-dry-run", "-f", filepath.Join(dir, "config.json")}, spy.NewProcInout())
+		if exitStatus != 1 {
+			t.Log(spy.Stderr.String())
+			t.Log(spy.Stdout.String())
+			t.Errorf("exitStatus = %d, want 1", exitStatus)
+		}
+
+		expected := fmt.Sprintf("--- %[1]s\n+++ %[1]s\n@@ -1,3 +1,3 @@\n", path)
+		if !strings.Contains(spy.Stderr.String(), expected) {
+			t.Errorf("missing diff %q:\n%s", expected, spy.Stderr.String())
+		}
+		if strings.Contains(spy.Stdout.String(), expected) {
+			t.Errorf("want no diffs in the report:\n%s", spy.Stdout.String())
+		}
+
+		after, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(before) != string(after) {
+			t.Error(cmp.Diff(string(before), string(after)))
+		}
+	})
+}
+
+func TestMainCommandByArgsFixEmbedded(t *testing.T) {
+	for _, flag := range []string{"-fix", "-fix-dry-run"} {
+		t.Run(flag, func(t *testing.T) {
+			dir := copyTestdata(t, "testdata/invalid")
+			project := `{
+	"pfd": "pfd.drawio",
+	"atomic_process_table": {
+		"header": ["ID", "Description", "Est. Work Volume", "Est. Rework Volume Ratio", "Needed Resources", "Start Condition"],
+		"rows": [["P1", "Process", "", "", "", ""]]
+	},
+	"atomic_deliverable_table": "deliv.tsv",
+	"composite_deliverable_table": "comp_deliv.tsv",
+	"resource_table": "resource.tsv"
+}`
+			projectPath := filepath.Join(dir, "project.json")
+			if err := os.WriteFile(projectPath, []byte(project), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{flag, "-f", projectPath}, spy.NewProcInout())
+			if exitStatus != 1 {
+				t.Errorf("exitStatus = %d, want 1", exitStatus)
+			}
+			if !strings.Contains(spy.Stderr.String(), "not available for files embedded in the project document: atomic_process_table") {
+				t.Errorf("want embedded error, got %q", spy.Stderr.String())
+			}
+			if spy.Stdout.Len() > 0 {
+				t.Errorf("want no report, got:\n%s", spy.Stdout.String())
+			}
+		})
+	}
+}
+
+func TestMainCommandByArgsFixWorkbook(t *testing.T) {
+	dir := copyTestdata(t, "testdata/invalid")
+
+	sheets := map[string]string{
+		"ATOMIC_PROCESS":     "atomic_proc.tsv",
+		"ATOMIC_DELIVERABLE": "deliv.tsv",
+		"RESOURCE":           "resource.tsv",
+	}
+	wb := &tablexlsx.Workbook{}
+	for _, name := range []string{"ATOMIC_PROCESS", "ATOMIC_DELIVERABLE", "RESOURCE"} {
+		f, err := os.Open(filepath.Join(dir, sheets[name]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tsv, err := tabletsv.ParseTable(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wb.SetSheet(tablexlsx.SheetByTable(name, &tablexlsx.Table{Header: tsv.Header, Rows: tsv.Rows}))
+	}
+	buf := &bytes.Buffer{}
+	if err := tablexlsx.WriteWorkbook(buf, wb); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "tables.xlsx")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := `{"pfd": "pfd.drawio", "atomic_process_table": "tables.xlsx", "atomic_deliverable_table": "tables.xlsx", "composite_deliverable_table": "comp_deliv.tsv", "resource_table": "tables.xlsx"}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-fix", "-f", filepath.Join(dir, "config.json")}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Log(spy.Stderr.String())
+		t.Log(spy.Stdout.String())
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+	if strings.Contains(spy.Stdout.String(), "missing-ap-table") {
+		t.Errorf("fixed problems should not be reported:\n%s", spy.Stdout.String())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fixed, err := tablexlsx.ParseWorkbook(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: Both of the tables in the workbook are fixed, and the sheets of the other tables are kept.
+	expected := map[string]*tablexlsx.Table{
+		"ATOMIC_PROCESS": {
+			Header: []string{"ID", "Description", "Est. Work Volume", "Est. Rework Volume Ratio", "Needed Resources", "Start Condition"},
+			Rows:   [][]string{{"Process1", "", "", "", "", ""}, {"Process2", "", "", "", "", ""}},
+		},
+		"ATOMIC_DELIVERABLE": {
+			Header: []string{"ID", "Description", "Available Time", "Max Revision"},
+			Rows:   [][]string{{"Deliverable", "", "", ""}},
+		},
+		"RESOURCE": {
+			Header: []string{"ID", "Description"},
+			Rows:   [][]string{{"R1", "Resource 1"}},
+		},
+	}
+	for name, want := range expected {
+		sheet, ok := fixed.Sheet(name)
+		if !ok {
+			t.Errorf("missing sheet: %q", name)
+			continue
+		}
+		got, err := tablexlsx.TableBySheet(sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(want, got) {
+			t.Error(cmp.Diff(want, got))
+		}
+	}
+}
+
+func TestMainCommandByArgsBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-write-baseline", path, "-f", "testdata/invalid/config.json"}, spy.NewProcInout())
+	if exitStatus != 0 {
+		t.Log(spy.Stderr.String())
+		t.Fatalf("exitStatus = %d, want 0", exitStatus)
+	}
+
+	t.Run("known problems", func(t *testing.T) {
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-baseline", path, "-f", "testdata/invalid/config.json"}, spy.NewProcInout())
+		if exitStatus != 0 {
+			t.Log(spy.Stderr.String())
+			t.Log(spy.Stdout.String())
+			t.Errorf("exitStatus = %d, want 0", exitStatus)
+		}
+		if spy.Stdout.Len() > 0 {
+			t.Errorf("known problems should not be reported:\n%s", spy.Stdout.String())
+		}
+	})
+
+	t.Run("fixed problems", func(t *testing.T) {
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-silent", "-baseline", path, "-f", "testdata/simple/config.json"}, spy.NewProcInout())
+		if exitStatus != 0 {
+			t.Log(spy.Stderr.String())
+			t.Log(spy.Stdout.String())
+			t.Errorf("exitStatus = %d, want 0", exitStatus)
+		}
+		for _, expected := range []string{"fixed: no-d2d: PFD[Deliverable, Deliverable]\n", "stale baseline entries: "} {
+			if !strings.Contains(spy.Stderr.String(), expected) {
+				t.Errorf("missing %q:\n%s", expected, spy.Stderr.String())
+			}
+		}
+	})
+
+	t.Run("new problems", func(t *testing.T) {
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-baseline", path, "-f", "testdata/configured/config.json"}, spy.NewProcInout())
+		if exitStatus != 1 {
+			t.Log(spy.Stderr.String())
+			t.Log(spy.Stdout.String())
+			t.Errorf("exitStatus = %d, want 1", exitStatus)
+		}
+	})
+}
+
+func copyTestdata(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS(src)); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/Kuniwak/pfd-tools/allcheckers"
+	"github.com/Kuniwak/pfd-tools/checkers"
 	"github.com/Kuniwak/pfd-tools/cli"
 	"github.com/Kuniwak/pfd-tools/tools"
 )
@@ -45,7 +46,37 @@ type Options struct {
 	HasGroupTable    bool
 	GroupTableReader io.Reader
 
-	Reporter allcheckers.Func
+	Format allcheckers.Format
+
+	// Explain is the problem ID to print the long explanation of instead of linting. It is empty if not explaining.
+	Explain checkers.ProblemID
+
+	// Lint is the configuration of rules from the run config. It is nil if not configured.
+	Lint *allcheckers.Config
+
+	// Fix rewrites the PFD and the tables in place by the fixes of the problems.
+	Fix bool
+
+	// FixDryRun writes the unified diff of the fixes on stderr instead of rewriting the files.
+	FixDryRun bool
+
+	// HasBaseline reports only the problems not in the baseline.
+	HasBaseline    bool
+	BaselineReader io.Reader
+
+	// WriteBaselinePath is the path to write the baseline of the problems instead of reporting them. It is empty if
+	// not writing.
+	WriteBaselinePath string
+
+	// Paths are the paths of the files to fix or to point from SARIF logs. The paths of the files not given are empty.
+	PFDPath                       string
+	AtomicProcessTablePath        string
+	AtomicDeliverableTablePath    string
+	CompositeProcessTablePath     string
+	CompositeDeliverableTablePath string
+	ResourceTablePath             string
+	MilestoneTablePath            string
+	GroupTablePath                string
 }
 
 func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
@@ -68,6 +99,27 @@ Example
   $ pfdlint -locale ja -f ./path/to/project.json
   WARNING no-desc 端的な説明を追加してください。  [D2]
   ERROR   single-src      成果物が複数のプロセスから出力されています。成果物はただ1つのプロセスから出力されるべきです。   [D3]
+
+  $ pfdlint -explain single-src
+  single-src	A deliverable should be output from only one process. This includes output through feedback edges.
+
+  Explanation
+  A deliverable is output from more than one process. ...
+
+  Fix
+  Split the deliverable into one deliverable for each process, or merge the processes.
+
+  $ pfdlint -fix-dry-run -f ./path/to/project.json > /dev/null
+  --- path/to/ap.tsv
+  +++ path/to/ap.tsv
+  @@ -1,2 +1,3 @@
+   ID	Description
+   P1	Process
+  +P2	Review
+
+  $ pfdlint -write-baseline ./path/to/baseline.json -f ./path/to/project.json
+  $ pfdlint -baseline ./path/to/baseline.json -f ./path/to/project.json
+  WARN: cmd.MainCommandByOptions: fixed problems in the baseline: problem_id=no-desc, locations=PFD[D3]
 `)
 
 	}
@@ -75,7 +127,12 @@ Example
 	var commonRawOptions tools.CommonRawOptions
 	tools.DeclareCommonOptions(flags, &commonRawOptions)
 
-	formatFlag := flags.String("format", "tsv", "format of the fsmreporter")
+	formatFlag := flags.String("format", "tsv", "format of the fsmreporter (available: tsv, json, sarif)")
+	fixFlag := flags.Bool("fix", false, "rewrite the PFD and the tables in place to fix problems if possible (draw.io, TSV, CSV and XLSX only)")
+	fixDryRunFlag := flags.Bool("fix-dry-run", false, "write the unified diff of the fixes on stderr instead of rewriting files")
+	baselineFlag := flags.String("baseline", "", "path to the baseline file. problems in the baseline are not reported")
+	writeBaselineFlag := flags.String("write-baseline", "", "write the baseline file of the current problems to the path instead of reporting them")
+	explainFlag := flags.String("explain", "", "print the long explanation and the suggested fix of the problem ID instead of linting")
 
 	var pfdShortPath, pfdLongPath string
 	tools.DeclarePFDOptions(flags, &pfdShortPath, &pfdLongPath)
@@ -120,12 +177,45 @@ Example
 		return &Options{CommonOptions: commonOptions}, nil
 	}
 
+	if *explainFlag != "" {
+		explain := checkers.ProblemID(*explainFlag)
+		if !allcheckers.IsKnownProblemID(explain) {
+			return nil, fmt.Errorf("cmd.ParseOptions: unknown problem ID: %q", *explainFlag)
+		}
+		return &Options{CommonOptions: commonOptions, Explain: explain}, nil
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
 
+	if *fixFlag && *fixDryRunFlag {
+		return nil, errors.New("cmd.ParseOptions: -fix and -fix-dry-run are exclusive")
+	}
+
+	if *writeBaselineFlag != "" && (*baselineFlag != "" || *fixFlag || *fixDryRunFlag) {
+		return nil, errors.New("cmd.ParseOptions: -write-baseline is exclusive with -baseline, -fix and -fix-dry-run")
+	}
+
+	var baselineReader io.Reader
+	hasBaseline := *baselineFlag != ""
+	if hasBaseline {
+		baselineReader, err = os.Open(*baselineFlag)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+	}
+
 	var pfdReader io.Reader
+	var pfdPath string
+	var atomicProcessTablePath string
+	var atomicDeliverableTablePath string
+	var compositeProcessTablePath string
+	var compositeDeliverableTablePath string
+	var resourceTablePath string
+	var milestoneTablePath string
+	var groupTablePath string
 	var hasAtomicProcessTable bool
 	var atomicProcessTableReader io.Reader
 	var hasAtomicDeliverableTable bool
@@ -140,33 +230,72 @@ Example
 	var milestoneTableReader io.Reader
 	var hasGroupTable bool
 	var groupTableReader io.Reader
+	var lintConfig *allcheckers.Config
 	if configShortPath != "" || configLongPath != "" {
 		fsmOptions, err := tools.ValidateFSMOptionsJSON(&configShortPath, &configLongPath, tools.FSMRawOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
 		pfdReader = fsmOptions.PFDReader
+		pfdPath = fsmOptions.PFDPath
 
 		hasAtomicProcessTable = true
 		atomicProcessTableReader = fsmOptions.AtomicProcessTableReader
+		atomicProcessTablePath = fsmOptions.AtomicProcessTablePath
 
 		hasAtomicDeliverableTable = true
 		atomicDeliverableTableReader = fsmOptions.AtomicDeliverableTableReader
+		atomicDeliverableTablePath = fsmOptions.AtomicDeliverableTablePath
 
 		hasCompositeDeliverableTable = true
 		compositeDeliverableTableReader = fsmOptions.CompositeDeliverableTableReader
+		compositeDeliverableTablePath = fsmOptions.CompositeDeliverableTablePath
 
 		hasResourceTable = true
 		resourceTableReader = fsmOptions.ResourceTableReader
+		resourceTablePath = fsmOptions.ResourceTablePath
+
+		hasCompositeProcessTable = fsmOptions.CompositeProcessTableReader != nil
+		compositeProcessTableReader = fsmOptions.CompositeProcessTableReader
+		compositeProcessTablePath = fsmOptions.CompositeProcessTablePath
+
+		hasMilestoneTable = fsmOptions.MilestoneTableReader != nil
+		milestoneTableReader = fsmOptions.MilestoneTableReader
+		milestoneTablePath = fsmOptions.MilestoneTablePath
+
+		hasGroupTable = fsmOptions.GroupTableReader != nil
+		groupTableReader = fsmOptions.GroupTableReader
+		groupTablePath = fsmOptions.GroupTablePath
+
+		lintConfig = fsmOptions.Lint
+
+		// NOTE: Fixes are written back to the files, so the ones embedded in the project document cannot be fixed.
+		if *fixFlag || *fixDryRunFlag {
+			fixables := []struct {
+				Name string
+				Path string
+				Has  bool
+			}{
+				{Name: "pfd", Path: pfdPath, Has: true},
+				{Name: "atomic_process_table", Path: atomicProcessTablePath, Has: hasAtomicProcessTable},
+				{Name: "atomic_deliverable_table", Path: atomicDeliverableTablePath, Has: hasAtomicDeliverableTable},
+				{Name: "composite_process_table", Path: compositeProcessTablePath, Has: hasCompositeProcessTable},
+			}
+			for _, fixable := range fixables {
+				if fixable.Has && fixable.Path == "" {
+					return nil, fmt.Errorf("cmd.ParseOptions: -fix and -fix-dry-run are not available for files embedded in the project document: %s", fixable.Name)
+				}
+			}
+		}
 	} else {
-		pfdReader, _, err = tools.ValidatePFDOptions(&pfdShortPath, &pfdLongPath, cwd)
+		pfdReader, pfdPath, err = tools.ValidatePFDOptions(&pfdShortPath, &pfdLongPath, cwd)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
 
 		hasAtomicProcessTable = atomicProcessTableShortPath != "" || atomicProcessTableLongPath != ""
 		if hasAtomicProcessTable {
-			atomicProcessTableReader, _, err = tools.ValidateAtomicProcessTableOptions(&atomicProcessTableShortPath, &atomicProcessTableLongPath, cwd)
+			atomicProcessTableReader, atomicProcessTablePath, err = tools.ValidateAtomicProcessTableOptions(&atomicProcessTableShortPath, &atomicProcessTableLongPath, cwd)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -174,7 +303,7 @@ Example
 
 		hasAtomicDeliverableTable = atomicDeliverableTableShortPath != "" || atomicDeliverableTableLongPath != ""
 		if hasAtomicDeliverableTable {
-			atomicDeliverableTableReader, _, err = tools.ValidateAtomicDeliverableTableOptions(&atomicDeliverableTableShortPath, &atomicDeliverableTableLongPath, cwd)
+			atomicDeliverableTableReader, atomicDeliverableTablePath, err = tools.ValidateAtomicDeliverableTableOptions(&atomicDeliverableTableShortPath, &atomicDeliverableTableLongPath, cwd)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -182,7 +311,7 @@ Example
 
 		hasCompositeDeliverableTable = compositeDeliverableTableShortPath != "" || compositeDeliverableTableLongPath != ""
 		if hasCompositeDeliverableTable {
-			compositeDeliverableTableReader, _, err = tools.ValidateCompositeDeliverableTableOptions(&compositeDeliverableTableShortPath, &compositeDeliverableTableLongPath, cwd)
+			compositeDeliverableTableReader, compositeDeliverableTablePath, err = tools.ValidateCompositeDeliverableTableOptions(&compositeDeliverableTableShortPath, &compositeDeliverableTableLongPath, cwd)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -190,43 +319,46 @@ Example
 
 		hasResourceTable = resourceTableShortPath != "" || resourceTableLongPath != ""
 		if hasResourceTable {
-			resourceTableReader, _, err = tools.ValidateResourceTableOptions(&resourceTableShortPath, &resourceTableLongPath, cwd)
+			resourceTableReader, resourceTablePath, err = tools.ValidateResourceTableOptions(&resourceTableShortPath, &resourceTableLongPath, cwd)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
 		}
 	}
 
-	hasCompositeProcessTable = compositeProcessTableShortPath != "" || compositeProcessTableLongPath != ""
-	if hasCompositeProcessTable {
-		compositeProcessTableReader, _, err = tools.ValidateCompositeProcessTableOptions(&compositeProcessTableShortPath, &compositeProcessTableLongPath, cwd)
+	// NOTE: The tables of the run config are used unless the flags are given.
+	if compositeProcessTableShortPath != "" || compositeProcessTableLongPath != "" {
+		hasCompositeProcessTable = true
+		compositeProcessTableReader, compositeProcessTablePath, err = tools.ValidateCompositeProcessTableOptions(&compositeProcessTableShortPath, &compositeProcessTableLongPath, cwd)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
 	}
 
-	hasMilestoneTable = milestoneTableShortPath != "" || milestoneTableLongPath != ""
-	if hasMilestoneTable {
-		milestoneTableReader, _, err = tools.ValidateMilestoneTableOptions(&milestoneTableShortPath, &milestoneTableLongPath, cwd)
+	if milestoneTableShortPath != "" || milestoneTableLongPath != "" {
+		hasMilestoneTable = true
+		milestoneTableReader, milestoneTablePath, err = tools.ValidateMilestoneTableOptions(&milestoneTableShortPath, &milestoneTableLongPath, cwd)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
 	}
 
-	hasGroupTable = groupTableShortPath != "" || groupTableLongPath != ""
-	if hasGroupTable {
-		groupTableReader, _, err = tools.ValidateGroupTableOptions(&groupTableShortPath, &groupTableLongPath, cwd)
+	if groupTableShortPath != "" || groupTableLongPath != "" {
+		hasGroupTable = true
+		groupTableReader, groupTablePath, err = tools.ValidateGroupTableOptions(&groupTableShortPath, &groupTableLongPath, cwd)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
 	}
 
-	var rep allcheckers.Func
+	var format allcheckers.Format
 	switch *formatFlag {
 	case "tsv":
-		rep = allcheckers.NewTSV(inout.Stdout, commonOptions.Locale)
+		format = allcheckers.FormatTSV
 	case "json":
-		rep = allcheckers.NewJSON(inout.Stdout, commonOptions.Locale)
+		format = allcheckers.FormatJSON
+	case "sarif":
+		format = allcheckers.FormatSARIF
 	default:
 		return nil, fmt.Errorf("cmd.ParseOptions: unknown format: %q", *formatFlag)
 	}
@@ -248,6 +380,20 @@ Example
 		HasGroupTable:                   hasGroupTable,
 		GroupTableReader:                groupTableReader,
 		CommonOptions:                   commonOptions,
-		Reporter:                        rep,
+		Format:                          format,
+		Lint:                            lintConfig,
+		Fix:                             *fixFlag,
+		FixDryRun:                       *fixDryRunFlag,
+		HasBaseline:                     hasBaseline,
+		BaselineReader:                  baselineReader,
+		WriteBaselinePath:               *writeBaselineFlag,
+		PFDPath:                         pfdPath,
+		AtomicProcessTablePath:          atomicProcessTablePath,
+		AtomicDeliverableTablePath:      atomicDeliverableTablePath,
+		CompositeProcessTablePath:       compositeProcessTablePath,
+		CompositeDeliverableTablePath:   compositeDeliverableTablePath,
+		ResourceTablePath:               resourceTablePath,
+		MilestoneTablePath:              milestoneTablePath,
+		GroupTablePath:                  groupTablePath,
 	}, nil
 }
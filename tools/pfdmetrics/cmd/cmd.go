@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdmetrics"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
+	"github.com/Kuniwak/pfd-tools/slograw"
+	"github.com/Kuniwak/pfd-tools/version"
+)
+
+func MainCommandByArgs(args []string, inout *cli.ProcInout) int {
+	opts, err := ParseOptions(args, inout)
+	if err != nil {
+		fmt.Fprintln(inout.Stderr, err.Error())
+		return 1
+	}
+	if err := MainCommandByOptions(opts, inout); err != nil {
+		fmt.Fprintln(inout.Stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
+	if opts.CommonOptions.Help {
+		return nil
+	}
+
+	if opts.CommonOptions.Version {
+		fmt.Fprintln(inout.Stdout, version.Version)
+		return nil
+	}
+
+	logger := slog.New(slograw.NewHandler(inout.Stderr, opts.CommonOptions.LogLevel))
+
+	parseOpts := &pfdfmt.ParseOptions{}
+	if opts.CompositeDeliverableTableReader != nil {
+		compositeDeliverableTable, err := pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		parseOpts.CompositeDeliverableTable = compositeDeliverableTable
+	}
+
+	unsafePFD, err := pfdfmt.Parse("", opts.PFDReader, parseOpts, logger)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+
+	p, err := pfd.NewSafePFDByUnsafePFD(unsafePFD)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+
+	m, err := pfdmetrics.Measure(p)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		encoder := json.NewEncoder(inout.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(m); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+	default:
+		writeTSV(inout, m)
+	}
+	return nil
+}
+
+func writeTSV(inout *cli.ProcInout, m *pfdmetrics.Metrics) {
+	w := csv.NewWriter(inout.Stdout)
+	w.Comma = '\t'
+	defer w.Flush()
+
+	w.Write([]string{"METRIC", "SUBJECTS", "VALUE"})
+	for _, pm := range m.Processes {
+		w.Write([]string{"fan_in", string(pm.ID), strconv.Itoa(pm.FanIn)})
+		w.Write([]string{"fan_out", string(pm.ID), strconv.Itoa(pm.FanOut)})
+	}
+	w.Write([]string{"depth", join(m.LongestPath), strconv.Itoa(m.Depth)})
+	w.Write([]string{"width", join(m.WidestLevel), strconv.Itoa(m.Width)})
+	for _, loop := range m.FeedbackLoops {
+		w.Write([]string{"feedback_loop_length", join(loop.AtomicProcesses), strconv.Itoa(loop.Len())})
+	}
+	w.Write([]string{"feedback_loops", "", strconv.Itoa(len(m.FeedbackLoops))})
+	w.Write([]string{"composite_depth", join(m.DeepestComposition), strconv.Itoa(m.CompositeDepth)})
+	w.Write([]string{"dominators", join(m.Dominators), strconv.Itoa(len(m.Dominators))})
+}
+
+func join[T ~string](ids []T) string {
+	sb := &strings.Builder{}
+	for i, id := range ids {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(string(id))
+	}
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdmetrics"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMainCommandByArgs(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-f", "testdata/simple/config.json"}, spy.NewProcInout())
+	if exitStatus != 0 {
+		t.Log(spy.Stderr.String())
+		t.Errorf("exitStatus = %d, want 0", exitStatus)
+	}
+
+	expected := "METRIC\tSUBJECTS\tVALUE\n" +
+		"fan_in\tP1\t2\n" +
+		"fan_out\tP1\t1\n" +
+		"depth\tP1\t1\n" +
+		"width\tP1\t1\n" +
+		"feedback_loop_length\tP1\t1\n" +
+		"feedback_loops\t\t1\n" +
+		"composite_depth\t\t0\n" +
+		"dominators\tD1,D2\t2\n"
+	if spy.Stdout.String() != expected {
+		t.Error(cmp.Diff(expected, spy.Stdout.String()))
+	}
+}
+
+func TestMainCommandByArgsJSON(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-format", "json", "-p", "testdata/simple/pfd.drawio", "-cd", "testdata/simple/comp_deliv.tsv"}, spy.NewProcInout())
+	if exitStatus != 0 {
+		t.Log(spy.Stderr.String())
+		t.Errorf("exitStatus = %d, want 0", exitStatus)
+	}
+
+	var m pfdmetrics.Metrics
+	if err := json.Unmarshal(spy.Stdout.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Depth != 1 || m.Width != 1 || len(m.FeedbackLoops) != 1 {
+		t.Errorf("unexpected metrics: %s", spy.Stdout.String())
+	}
+}
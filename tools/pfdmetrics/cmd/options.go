@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/tools"
+)
+
+type Format string
+
+const (
+	FormatTSV  Format = "tsv"
+	FormatJSON Format = "json"
+)
+
+type Options struct {
+	CommonOptions *tools.CommonOptions
+
+	PFDReader io.Reader
+
+	// CompositeDeliverableTableReader is the reader of the composite deliverable table. It is nil if not given.
+	CompositeDeliverableTableReader io.Reader
+
+	Format Format
+}
+
+func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
+	flags := flag.NewFlagSet("pfdmetrics", flag.ContinueOnError)
+	flags.SetOutput(inout.Stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: pfdmetrics [options] -p <pfd> [-cd <composite-deliverable-table>]")
+		fmt.Fprintln(flags.Output(), "\nOptions")
+		flags.PrintDefaults()
+		fmt.Fprintf(flags.Output(), `
+Example
+  $ pfdmetrics -p path/to/pfd.drawio
+  METRIC	SUBJECTS	VALUE
+  fan_in	P1	1
+  fan_out	P1	1
+  ...
+  depth	P1,P2,P3	3
+  ...
+
+  $ pfdmetrics -f path/to/config.json -format json
+`)
+	}
+
+	var commonRawOptions tools.CommonRawOptions
+	tools.DeclareCommonOptions(flags, &commonRawOptions)
+
+	var pfdShortPath, pfdLongPath string
+	tools.DeclarePFDOptions(flags, &pfdShortPath, &pfdLongPath)
+
+	var compositeDeliverableTableShortPath, compositeDeliverableTableLongPath string
+	tools.DeclareCompositeDeliverableTableOptions(flags, &compositeDeliverableTableShortPath, &compositeDeliverableTableLongPath)
+
+	var configShortPath, configLongPath string
+	tools.DeclareConfigOptions(flags, &configShortPath, &configLongPath)
+
+	format := flags.String("format", string(FormatTSV), "output format (available: tsv, json)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return &Options{CommonOptions: &tools.CommonOptions{Help: true}}, nil
+		}
+		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+	}
+
+	commonOptions, err := tools.ValidateCommonOptions(&commonRawOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+	}
+
+	if commonOptions.Version {
+		return &Options{CommonOptions: commonOptions}, nil
+	}
+
+	switch Format(*format) {
+	case FormatTSV, FormatJSON:
+	default:
+		return nil, fmt.Errorf("cmd.ParseOptions: invalid format: %q", *format)
+	}
+
+	if configShortPath != "" || configLongPath != "" {
+		fsmOptions, err := tools.ValidateFSMOptionsJSON(&configShortPath, &configLongPath, tools.FSMRawOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+		return &Options{
+			CommonOptions:                   commonOptions,
+			PFDReader:                       fsmOptions.PFDReader,
+			CompositeDeliverableTableReader: fsmOptions.CompositeDeliverableTableReader,
+			Format:                          Format(*format),
+		}, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+	}
+
+	pfdReader, _, err := tools.ValidatePFDOptions(&pfdShortPath, &pfdLongPath, cwd)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+	}
+
+	var compositeDeliverableTableReader io.Reader
+	if compositeDeliverableTableShortPath != "" || compositeDeliverableTableLongPath != "" {
+		compositeDeliverableTableReader, _, err = tools.ValidateCompositeDeliverableTableOptions(&compositeDeliverableTableShortPath, &compositeDeliverableTableLongPath, cwd)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+	}
+
+	return &Options{
+		CommonOptions:                   commonOptions,
+		PFDReader:                       pfdReader,
+		CompositeDeliverableTableReader: compositeDeliverableTableReader,
+		Format:                          Format(*format),
+	}, nil
+}
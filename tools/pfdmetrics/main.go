@@ -0,0 +1,10 @@
+package main
+
+import (
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/tools/pfdmetrics/cmd"
+)
+
+func main() {
+	cli.Run(cmd.MainCommandByArgs)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Kuniwak/pfd-tools/cli"
@@ -35,3 +36,15 @@ func TestMainCommandByArgs(t *testing.T) {
 		}
 	})
 }
+
+func TestMainCommandByArgsLint(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"-f", "testdata/lint.json", "-poor"}, spy.NewProcInout())
+	if exitStatus != 1 {
+		t.Log(spy.Stdout.String())
+		t.Errorf("exitStatus = %d, want 1", exitStatus)
+	}
+	if !strings.Contains(spy.Stderr.String(), "small-process") {
+		t.Errorf("want the error of the user-defined rule, got %q", spy.Stderr.String())
+	}
+}
@@ -80,6 +80,8 @@ Example
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
+	tools.ApplyProjectCalendar(flags, &planOutputFormatRawOptions.BusinessTimeFuncRawOptions, fsmOptions.Calendar)
+	tools.ApplyProjectSearch(flags, &searchRawOptions, fsmOptions.Search)
 
 	planReporter, outputFormat, err := tools.ValidatePlanOutputFormat(&planOutputFormatRawOptions, commonOptions.Logger)
 	if err != nil {
@@ -11,9 +11,9 @@ import (
 	"github.com/Kuniwak/pfd-tools/cli"
 	"github.com/Kuniwak/pfd-tools/pfd"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
-	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmtsv"
+	fsmtableencoding "github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
-	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
 	"github.com/Kuniwak/pfd-tools/tools"
 )
 
@@ -148,22 +148,42 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
-		atomicProcessTable, err = pfdtsv.ParseAtomicProcessTable(fsmOptions.AtomicProcessTableReader)
+		atomicProcessTable, err = pfdtableencoding.ParseAtomicProcessTable(fsmOptions.AtomicProcessTableReader)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
-		atomicDeliverableTable, err = pfdtsv.ParseAtomicDeliverableTable(fsmOptions.AtomicDeliverableTableReader)
+		atomicDeliverableTable, err = pfdtableencoding.ParseAtomicDeliverableTable(fsmOptions.AtomicDeliverableTableReader)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
-		compositeDeliverableTable, err = pfdtsv.ParseCompositeDeliverableTable(fsmOptions.CompositeDeliverableTableReader)
-		if err != nil {
-			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		if fsmOptions.CompositeDeliverableTableReader != nil {
+			compositeDeliverableTable, err = pfdtableencoding.ParseCompositeDeliverableTable(fsmOptions.CompositeDeliverableTableReader)
+			if err != nil {
+				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+			}
+		}
+		if fsmOptions.CompositeProcessTableReader != nil {
+			compositeProcessTable, err = pfdtableencoding.ParseCompositeProcessTable(fsmOptions.CompositeProcessTableReader)
+			if err != nil {
+				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+			}
 		}
-		resourceTable, err = fsmtsv.ParseResourceTable(fsmOptions.ResourceTableReader)
+		resourceTable, err = fsmtableencoding.ParseResourceTable(fsmOptions.ResourceTableReader)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
+		if fsmOptions.MilestoneTableReader != nil {
+			milestoneTable, err = fsmtableencoding.ParseMilestoneTable(fsmOptions.MilestoneTableReader)
+			if err != nil {
+				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+			}
+		}
+		if fsmOptions.GroupTableReader != nil {
+			groupTable, err = fsmtableencoding.ParseGroupTable(fsmOptions.GroupTableReader)
+			if err != nil {
+				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+			}
+		}
 		p, err = pfdfmt.Parse("", fsmOptions.PFDReader, &pfdfmt.ParseOptions{CompositeDeliverableTable: compositeDeliverableTable}, commonOptions.Logger)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
@@ -174,7 +194,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
-			atomicProcessTable, err = pfdtsv.ParseAtomicProcessTable(atomicProcessTableReader)
+			atomicProcessTable, err = pfdtableencoding.ParseAtomicProcessTable(atomicProcessTableReader)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -185,7 +205,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
-			atomicDeliverableTable, err = pfdtsv.ParseAtomicDeliverableTable(atomicDeliverableTableReader)
+			atomicDeliverableTable, err = pfdtableencoding.ParseAtomicDeliverableTable(atomicDeliverableTableReader)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -196,7 +216,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
-			compositeDeliverableTable, err = pfdtsv.ParseCompositeDeliverableTable(compositeDeliverableTableReader)
+			compositeDeliverableTable, err = pfdtableencoding.ParseCompositeDeliverableTable(compositeDeliverableTableReader)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -207,7 +227,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
-			resourceTable, err = fsmtsv.ParseResourceTable(resourceTableReader)
+			resourceTable, err = fsmtableencoding.ParseResourceTable(resourceTableReader)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -218,7 +238,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
-			milestoneTable, err = fsmtsv.ParseMilestoneTable(milestoneTableReader)
+			milestoneTable, err = fsmtableencoding.ParseMilestoneTable(milestoneTableReader)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -229,7 +249,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
-			groupTable, err = fsmtsv.ParseGroupTable(groupTableReader)
+			groupTable, err = fsmtableencoding.ParseGroupTable(groupTableReader)
 			if err != nil {
 				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 			}
@@ -241,7 +261,7 @@ func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
-		compositeProcessTable, err = pfdtsv.ParseCompositeProcessTable(compositeProcessTableReader)
+		compositeProcessTable, err = pfdtableencoding.ParseCompositeProcessTable(compositeProcessTableReader)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
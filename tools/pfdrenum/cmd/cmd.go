@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"log/slog"
+	"os"
 
 	"github.com/Kuniwak/pfd-tools/cli"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
@@ -43,6 +45,12 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	logger.Debug("detected format", "format", f)
 
+	w := opts.Writer
+	buf := &bytes.Buffer{}
+	if opts.InplacePath != "" {
+		w = buf
+	}
+
 	switch f {
 	case pfdfmt.FormatDrawio:
 		nodes, err := pfddrawio.Renumber(r, logger)
@@ -50,7 +58,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 		}
 		for _, node := range nodes {
-			if err := node.Write(opts.Writer); err != nil {
+			if err := node.Write(w); err != nil {
 				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 			}
 		}
@@ -58,5 +66,15 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 		return fmt.Errorf("cmd.MainCommandByOptions: not supported format: %q", f)
 	}
 
+	if opts.InplacePath != "" {
+		info, err := os.Stat(opts.InplacePath)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		if err := os.WriteFile(opts.InplacePath, buf.Bytes(), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+	}
+
 	return nil
 }
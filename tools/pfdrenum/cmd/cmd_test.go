@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Kuniwak/pfd-tools/cli"
@@ -15,3 +19,74 @@ func TestMainCommandByArgs(t *testing.T) {
 		t.Errorf("exitStatus = %d, want 0", exitStatus)
 	}
 }
+
+func TestMainCommandByArgsInplace(t *testing.T) {
+	t.Run("draw.io", func(t *testing.T) {
+		path := copyFile(t, "testdata/simple/pfd.drawio", 0600)
+
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-inplace", path}, spy.NewProcInout())
+		if exitStatus != 0 {
+			t.Log(spy.Stderr.String())
+			t.Fatalf("exitStatus = %d, want 0", exitStatus)
+		}
+		if spy.Stdout.Len() > 0 {
+			t.Errorf("want nothing on stdout, got:\n%s", spy.Stdout.String())
+		}
+
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(bytes.TrimSpace(bs), []byte("<mxfile")) {
+			t.Errorf("want draw.io file, got:\n%s", string(bs))
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+		}
+	})
+
+	for _, src := range []string{"../../../pfd/pfdencoding/pfddrawio/testdata/example.png", "../../../pfd/pfdencoding/pfddrawio/testdata/example.svg"} {
+		t.Run(filepath.Ext(src), func(t *testing.T) {
+			path := copyFile(t, src, 0644)
+			before, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-inplace", path}, spy.NewProcInout())
+			if exitStatus != 1 {
+				t.Errorf("exitStatus = %d, want 1", exitStatus)
+			}
+			if !strings.Contains(spy.Stderr.String(), "only available for draw.io files") {
+				t.Errorf("want error for editable images, got %q", spy.Stderr.String())
+			}
+
+			after, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(before, after) {
+				t.Error("want the image kept")
+			}
+		})
+	}
+}
+
+func copyFile(t *testing.T, src string, perm os.FileMode) string {
+	t.Helper()
+	bs, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), filepath.Base(src))
+	if err := os.WriteFile(path, bs, perm); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -9,13 +9,19 @@ import (
 	"os"
 
 	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfddrawio"
 	"github.com/Kuniwak/pfd-tools/tools"
 )
 
 type Options struct {
 	CommonOptions *tools.CommonOptions
 	Reader        io.Reader
-	Writer        io.Writer
+
+	// Writer is nil if InplacePath is given.
+	Writer io.Writer
+
+	// InplacePath is the path of the file to overwrite after renumbering. It is empty unless inplace flag is given.
+	InplacePath string
 }
 
 func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
@@ -39,7 +45,7 @@ Example
 	var commonRawOptions tools.CommonRawOptions
 	tools.DeclareCommonOptions(flags, &commonRawOptions)
 
-	inplaceFlag := flags.Bool("inplace", false, "overwrite the file in place")
+	inplaceFlag := flags.Bool("inplace", false, "overwrite the file in place (draw.io files only)")
 
 	if err := flags.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
@@ -58,7 +64,6 @@ Example
 	}
 
 	var r io.Reader
-	var w io.Writer
 
 	shouldReadFromStdin := false
 	var inputFilePath string
@@ -85,20 +90,22 @@ Example
 	}
 
 	if *inplaceFlag {
-		buf := bytes.NewBuffer(nil)
-		if _, err := io.Copy(buf, r); err != nil {
-			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		if shouldReadFromStdin {
+			return nil, fmt.Errorf("cmd.ParseOptions: inplace flag needs the path of the file")
 		}
-		r = buf
 
-		var err error
-		w, err = os.OpenFile(inputFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		bs, err := io.ReadAll(r)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
-	} else {
-		w = inout.Stdout
+		// NOTE: Renumbered diagrams are written as plain XML, so editable PNG and SVG files would lose their images.
+		if bytes.HasPrefix(bs, []byte(pfddrawio.PNGSignature)) || pfddrawio.IsSVG(bs) {
+			return nil, fmt.Errorf("cmd.ParseOptions: inplace flag is only available for draw.io files, not for editable PNG and SVG files: %s", inputFilePath)
+		}
+
+		// NOTE: The file is written after renumbering, so that it is kept if renumbering fails.
+		return &Options{Reader: bytes.NewReader(bs), InplacePath: inputFilePath, CommonOptions: commonOptions}, nil
 	}
 
-	return &Options{Reader: r, Writer: w, CommonOptions: commonOptions}, nil
+	return &Options{Reader: r, Writer: inout.Stdout, CommonOptions: commonOptions}, nil
 }
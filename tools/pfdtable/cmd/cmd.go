@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 
 	"github.com/Kuniwak/pfd-tools/cli"
@@ -10,12 +12,17 @@ import (
 	fsmtableencoding "github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding"
 	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
 	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
-	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
 	"github.com/Kuniwak/pfd-tools/slograw"
+	"github.com/Kuniwak/pfd-tools/table"
+	"github.com/Kuniwak/pfd-tools/tools"
 	"github.com/Kuniwak/pfd-tools/version"
 )
 
 func MainCommandByArgs(args []string, inout *cli.ProcInout) int {
+	if len(args) > 0 && args[0] == "merge" {
+		return MergeCommandByArgs(args[1:], inout)
+	}
+
 	opts, err := ParseOptions(args, inout)
 	if err != nil {
 		_, _ = fmt.Fprintln(inout.Stderr, err.Error())
@@ -40,6 +47,26 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 	logger := slog.New(slograw.NewHandler(inout.Stderr, opts.CommonOptions.LogLevel))
 
+	if opts.SyncToDiagram {
+		if err := syncToDiagram(opts, inout, logger); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		return nil
+	}
+
+	if opts.ExportProject {
+		project, err := tools.NewEmbeddedProject(opts.FSMOptions)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		e := json.NewEncoder(opts.Writer)
+		e.SetIndent("", "  ")
+		if err := e.Encode(project); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		return nil
+	}
+
 	switch opts.TableCategory {
 	case TableCategoryPFD:
 		switch opts.PFDTableType {
@@ -51,7 +78,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			var cdTable *pfd.CompositeDeliverableTable
 			if opts.HasCompositeDeliverableTable {
 				var err error
-				cdTable, err = pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+				cdTable, err = pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -72,7 +99,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			}
 
 			if opts.HasExistingTable {
-				tableParser, err := pfdtableencoding.NewAtomicProcessTableParser(opts.InputFormat)
+				tableParser, err := pfdtableencoding.NewAtomicProcessTableParser(opts.ExistingTableFormat)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -84,7 +111,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 				processTable.Refresh(p, nodeMap)
 
-				if err := tableWriter(opts.Writer, processTable); err != nil {
+				if err := writeExistingTable(opts, processTable, tableWriter, pfdtableencoding.RewriteAtomicProcessTable); err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
 			} else {
@@ -103,7 +130,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			var cdTable *pfd.CompositeDeliverableTable
 			if opts.HasCompositeDeliverableTable {
 				var err error
-				cdTable, err = pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+				cdTable, err = pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -124,7 +151,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			}
 
 			if opts.HasExistingTable {
-				tableParser, err := pfdtableencoding.NewAtomicDeliverableTableParser(opts.InputFormat)
+				tableParser, err := pfdtableencoding.NewAtomicDeliverableTableParser(opts.ExistingTableFormat)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -136,7 +163,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 				deliverableTable.Refresh(p, nodeMap)
 
-				if err := tableWriter(opts.Writer, deliverableTable); err != nil {
+				if err := writeExistingTable(opts, deliverableTable, tableWriter, pfdtableencoding.RewriteAtomicDeliverableTable); err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
 			} else {
@@ -155,7 +182,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			var cdTable *pfd.CompositeDeliverableTable
 			if opts.HasCompositeDeliverableTable {
 				var err error
-				cdTable, err = pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+				cdTable, err = pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -176,7 +203,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			}
 
 			if opts.HasExistingTable {
-				tableParser, err := pfdtableencoding.NewCompositeProcessTableParser(opts.InputFormat)
+				tableParser, err := pfdtableencoding.NewCompositeProcessTableParser(opts.ExistingTableFormat)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -188,7 +215,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 				compositeProcessTable.Refresh(p, nodeMap)
 
-				if err := tableWriter(opts.Writer, compositeProcessTable); err != nil {
+				if err := writeExistingTable(opts, compositeProcessTable, tableWriter, pfdtableencoding.RewriteCompositeProcessTable); err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
 			} else {
@@ -207,7 +234,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			var cdTable *pfd.CompositeDeliverableTable
 			if opts.HasCompositeDeliverableTable {
 				var err error
-				cdTable, err = pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+				cdTable, err = pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -226,7 +253,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			}
 
 			if opts.HasExistingTable {
-				tableParser, err := pfdtableencoding.NewCompositeDeliverableTableParser(opts.InputFormat)
+				tableParser, err := pfdtableencoding.NewCompositeDeliverableTableParser(opts.ExistingTableFormat)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -238,7 +265,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 				compositeDeliverableTable.Refresh(p, nodeMap)
 
-				if err := tableWriter(opts.Writer, compositeDeliverableTable); err != nil {
+				if err := writeExistingTable(opts, compositeDeliverableTable, tableWriter, pfdtableencoding.RewriteCompositeDeliverableTable); err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
 			} else {
@@ -262,7 +289,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			var cdTable *pfd.CompositeDeliverableTable
 			if opts.HasCompositeDeliverableTable {
 				var err error
-				cdTable, err = pfdtsv.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
+				cdTable, err = pfdtableencoding.ParseCompositeDeliverableTable(opts.CompositeDeliverableTableReader)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -277,12 +304,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 			nodeMap := pfd.NewNodeMap(p.Nodes, logger)
 
-			apTableParser, err := pfdtableencoding.NewAtomicProcessTableParser(opts.InputFormat)
-			if err != nil {
-				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
-			}
-
-			apTable, err := apTableParser(opts.AtomicProcessTableReader)
+			apTable, err := parseAtomicProcessTable(opts)
 			if err != nil {
 				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 			}
@@ -298,7 +320,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			}
 
 			if opts.HasExistingTable {
-				tableParser, err := fsmtableencoding.NewResourceTableParser(opts.InputFormat)
+				tableParser, err := fsmtableencoding.NewResourceTableParser(opts.ExistingTableFormat)
 				if err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
@@ -310,7 +332,7 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 
 				resourceTable.Refresh(p.AtomicProcesses(), nodeMap, neededResourceSetsFunc)
 
-				if err := tableWriter(opts.Writer, resourceTable); err != nil {
+				if err := writeExistingTable(opts, resourceTable, tableWriter, fsmtableencoding.RewriteResourceTable); err != nil {
 					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 				}
 			} else {
@@ -322,7 +344,101 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 			return nil
 
 		case fsmtable.TableTypeMilestone:
-			return fmt.Errorf("cmd.MainCommandByOptions: not implemented")
+			apTable, err := parseAtomicProcessTable(opts)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+
+			milestoneGroupsMap, err := fsmtable.MilestoneGroupsMapByTable(apTable, fsmtable.DefaultMilestoneColumnMatchFunc, fsmtable.DefaultGroupColumnMatchFunc)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+
+			tableWriter, err := fsmtableencoding.NewMilestoneTableWriter(opts.OutputFormat)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+
+			if opts.HasExistingTable {
+				tableParser, err := fsmtableencoding.NewMilestoneTableParser(opts.ExistingTableFormat)
+				if err != nil {
+					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+				}
+
+				milestoneTable, err := tableParser(opts.ExistingTableReader)
+				if err != nil {
+					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+				}
+
+				orphans := milestoneTable.Refresh(milestoneGroupsMap)
+
+				if err := writeExistingTable(opts, milestoneTable, tableWriter, fsmtableencoding.RewriteMilestoneTable); err != nil {
+					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+				}
+
+				// NOTE: Orphans are kept in the table because they may have descriptions and successors to move, but they
+				// make the command fail so that they are not left unnoticed.
+				for _, milestone := range orphans.Iter() {
+					_, _ = fmt.Fprintf(inout.Stderr, "orphan: %s: no atomic processes refer to the milestone\n", milestone)
+				}
+				if orphans.Len() > 0 {
+					return fmt.Errorf("cmd.MainCommandByOptions: %d orphaned milestones", orphans.Len())
+				}
+			} else {
+				milestoneTable := fsmtable.NewMilestoneTable(milestoneGroupsMap)
+				if err := tableWriter(opts.Writer, milestoneTable); err != nil {
+					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+				}
+			}
+			return nil
+
+		case fsmtable.TableTypeGroup:
+			apTable, err := parseAtomicProcessTable(opts)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+
+			groups, err := fsmtable.GroupsByTable(apTable, fsmtable.DefaultGroupColumnMatchFunc)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+
+			tableWriter, err := fsmtableencoding.NewGroupTableWriter(opts.OutputFormat)
+			if err != nil {
+				return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+			}
+
+			if opts.HasExistingTable {
+				tableParser, err := fsmtableencoding.NewGroupTableParser(opts.ExistingTableFormat)
+				if err != nil {
+					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+				}
+
+				groupTable, err := tableParser(opts.ExistingTableReader)
+				if err != nil {
+					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+				}
+
+				orphans := groupTable.Refresh(groups)
+
+				if err := writeExistingTable(opts, groupTable, tableWriter, fsmtableencoding.RewriteGroupTable); err != nil {
+					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+				}
+
+				// NOTE: Orphans are kept in the same way as milestones.
+				for _, group := range orphans.Iter() {
+					_, _ = fmt.Fprintf(inout.Stderr, "orphan: %s: no atomic processes belong to the group\n", group)
+				}
+				if orphans.Len() > 0 {
+					return fmt.Errorf("cmd.MainCommandByOptions: %d orphaned groups", orphans.Len())
+				}
+			} else {
+				groupTable := fsmtable.NewGroupTable(groups)
+				if err := tableWriter(opts.Writer, groupTable); err != nil {
+					return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+				}
+			}
+			return nil
 
 		default:
 			return fmt.Errorf("cmd.MainCommandByOptions: unknown fsmtable type: %q", opts.FSMTableType)
@@ -331,3 +447,42 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 		panic(fmt.Sprintf("cmd.MainCommandByOptions: unknown table category: %q", opts.TableCategory))
 	}
 }
+
+// parseAtomicProcessTable parses the atomic process table that the FSM tables are derived from. The input format is
+// detected from the contents of the table if it is not given, apart from the format of the existing table.
+func parseAtomicProcessTable(opts *Options) (*pfd.AtomicProcessTable, error) {
+	apTableParser := pfdtableencoding.ParseAtomicProcessTable
+	if opts.InputFormat != table.FormatUnknown {
+		var err error
+		apTableParser, err = pfdtableencoding.NewAtomicProcessTableParser(opts.InputFormat)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.parseAtomicProcessTable: %w", err)
+		}
+	}
+
+	apTable, err := apTableParser(opts.AtomicProcessTableReader)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.parseAtomicProcessTable: %w", err)
+	}
+	return apTable, nil
+}
+
+// writeExistingTable writes the updated existing table. Workbooks are rewritten instead of written so that the other
+// sheets are kept.
+func writeExistingTable[T any](opts *Options, t T, write func(w io.Writer, t T) error, rewrite func(original []byte, t T) ([]byte, error)) error {
+	if opts.ExistingTableFormat != table.FormatXLSX || opts.OutputFormat != table.FormatXLSX {
+		if err := write(opts.Writer, t); err != nil {
+			return fmt.Errorf("cmd.writeExistingTable: %w", err)
+		}
+		return nil
+	}
+
+	bs, err := rewrite(opts.ExistingTable, t)
+	if err != nil {
+		return fmt.Errorf("cmd.writeExistingTable: %w", err)
+	}
+	if _, err := opts.Writer.Write(bs); err != nil {
+		return fmt.Errorf("cmd.writeExistingTable: %w", err)
+	}
+	return nil
+}
@@ -1,9 +1,19 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/table/tablexlsx"
+	"github.com/Kuniwak/pfd-tools/tools"
 )
 
 func TestMainCommandByArgs(t *testing.T) {
@@ -87,4 +97,378 @@ func TestMainCommandByArgs(t *testing.T) {
 			}
 		})
 	})
+	t.Run("milestone", func(t *testing.T) {
+		t.Run("no -existing", func(t *testing.T) {
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "m", "-ap", "testdata/loop/atomic_proc.tsv"}, spy.NewProcInout())
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+			expected := "ID\tDescription\tGroups\tSuccessors\nM1\t\tG1\t\nM2\t\tG1\t\n"
+			if spy.Stdout.String() != expected {
+				t.Errorf("want %q, got %q", expected, spy.Stdout.String())
+			}
+		})
+		t.Run("-existing", func(t *testing.T) {
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "m", "-ap", "testdata/loop/atomic_proc.tsv", "-existing", "testdata/loop/milestone.tsv"}, spy.NewProcInout())
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+			expected := "ID\tDescription\tGroups\tSuccessors\nM1\tMilestone 1\tG1\tM2\nM2\tMilestone 2\tG1\t\n"
+			if spy.Stdout.String() != expected {
+				t.Errorf("want %q, got %q", expected, spy.Stdout.String())
+			}
+		})
+		t.Run("-existing xlsx with orphans", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "milestone.xlsx")
+			buf := &bytes.Buffer{}
+			if err := tablexlsx.WriteTable(buf, string(fsmtable.TableTypeMilestone), &tablexlsx.Table{
+				Header: []string{"ID", "Description", "Groups", "Successors"},
+				Rows:   [][]string{{"M1", "Milestone 1", "G1", "M2"}, {"M9", "Removed", "", ""}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "m", "-ap", "testdata/loop/atomic_proc.tsv", "-existing", path}, spy.NewProcInout())
+			if exitStatus != 1 {
+				t.Errorf("exitStatus = %d, want 1", exitStatus)
+			}
+			if !strings.Contains(spy.Stderr.String(), "orphan: M9") {
+				t.Errorf("want the orphaned milestone, got %q", spy.Stderr.String())
+			}
+			milestoneTable, err := tablexlsx.ParseTable(bytes.NewReader(spy.Stdout.Bytes()), string(fsmtable.TableTypeMilestone))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(milestoneTable.Rows) != 3 {
+				t.Errorf("want M1, M2 and M9, got %v", milestoneTable.Rows)
+			}
+		})
+	})
+	t.Run("group", func(t *testing.T) {
+		t.Run("no -existing", func(t *testing.T) {
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "g", "-ap", "testdata/loop/atomic_proc.tsv"}, spy.NewProcInout())
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+			expected := "ID\tDescription\nG1\t\n"
+			if spy.Stdout.String() != expected {
+				t.Errorf("want %q, got %q", expected, spy.Stdout.String())
+			}
+		})
+		t.Run("-existing with orphans", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "group.tsv")
+			if err := os.WriteFile(path, []byte("ID\tDescription\nG1\tGroup 1\nG9\tRemoved\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "g", "-ap", "testdata/loop/atomic_proc.tsv", "-existing", path}, spy.NewProcInout())
+			if exitStatus != 1 {
+				t.Errorf("exitStatus = %d, want 1", exitStatus)
+			}
+			expected := "ID\tDescription\nG1\tGroup 1\nG9\tRemoved\n"
+			if spy.Stdout.String() != expected {
+				t.Errorf("want %q, got %q", expected, spy.Stdout.String())
+			}
+			if !strings.Contains(spy.Stderr.String(), "orphan: G9") {
+				t.Errorf("want the orphaned group, got %q", spy.Stderr.String())
+			}
+		})
+	})
+	t.Run("formats", func(t *testing.T) {
+		t.Run("-o csv", func(t *testing.T) {
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "ap", "-o", "csv", "-p", "testdata/loop/pfd.drawio", "-cd", "testdata/loop/comp_deliv.tsv", "-existing", "testdata/loop/atomic_proc.tsv"}, spy.NewProcInout())
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+			if !strings.HasPrefix(spy.Stdout.String(), "ID,Description,Est. Work Volume,") {
+				t.Errorf("want CSV, got %q", spy.Stdout.String())
+			}
+		})
+		t.Run("-o xlsx", func(t *testing.T) {
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "ap", "-o", "xlsx", "-p", "testdata/loop/pfd.drawio", "-cd", "testdata/loop/comp_deliv.tsv"}, spy.NewProcInout())
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+			wb, err := tablexlsx.ParseWorkbook(bytes.NewReader(spy.Stdout.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := wb.Sheet(string(pfd.TableTypeAtomicProcess)); !ok {
+				t.Errorf("missing sheet: %q", pfd.TableTypeAtomicProcess)
+			}
+		})
+		t.Run("-existing xlsx -inplace", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tables.xlsx")
+			original := &tablexlsx.Workbook{
+				Sheets: []*tablexlsx.Sheet{
+					{Name: string(pfd.TableTypeAtomicDeliverable), Rows: [][]string{{"ID", "Description"}, {"D1", "Deliverable"}}},
+					{Name: string(pfd.TableTypeAtomicProcess), Rows: [][]string{{"ID", "Description"}}},
+				},
+			}
+			buf := &bytes.Buffer{}
+			if err := tablexlsx.WriteWorkbook(buf, original); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "ap", "-p", "testdata/loop/pfd.drawio", "-cd", "testdata/loop/comp_deliv.tsv", "-existing", path, "-inplace"}, spy.NewProcInout())
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			wb, err := tablexlsx.ParseWorkbook(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(wb.Sheets) != 2 {
+				t.Fatalf("want 2 sheets, got %d", len(wb.Sheets))
+			}
+			ap, _ := wb.Sheet(string(pfd.TableTypeAtomicProcess))
+			if len(ap.Rows) < 2 {
+				t.Errorf("want updated rows, got %v", ap.Rows)
+			}
+		})
+		t.Run("-existing xlsx and -ap tsv", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "resource.xlsx")
+			buf := &bytes.Buffer{}
+			if err := tablexlsx.WriteTable(buf, string(fsmtable.TableTypeResource), &tablexlsx.Table{Header: []string{"ID", "Description"}, Rows: [][]string{{"R1", "Resource 1"}}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"-t", "r", "-p", "testdata/loop/pfd.drawio", "-cd", "testdata/loop/comp_deliv.tsv", "-ap", "testdata/loop/atomic_proc.tsv", "-existing", path}, spy.NewProcInout())
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Errorf("exitStatus = %d, want 0", exitStatus)
+			}
+			if _, err := tablexlsx.ParseTable(bytes.NewReader(spy.Stdout.Bytes()), string(fsmtable.TableTypeResource)); err != nil {
+				t.Errorf("want XLSX, got %v", err)
+			}
+		})
+	})
+	t.Run("-sync-to-diagram", func(t *testing.T) {
+		dir := t.TempDir()
+		pfdPath := filepath.Join(dir, "pfd.drawio")
+		original, err := os.ReadFile("testdata/loop/pfd.drawio")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(pfdPath, original, 0600); err != nil {
+			t.Fatal(err)
+		}
+		tablePath := filepath.Join(dir, "deliv.tsv")
+		if err := os.WriteFile(tablePath, []byte("ID\tDescription\nD2\tPolished deliverable\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		basePath := filepath.Join(dir, "sync_base.json")
+
+		// NOTE: Labels different from the table cannot be rewritten until they are synced once or the table is preferred.
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-t", "ad", "-sync-to-diagram", "-existing", tablePath, "-inplace", "-p", pfdPath}, spy.NewProcInout())
+		if exitStatus != 1 {
+			t.Errorf("exitStatus = %d, want 1", exitStatus)
+		}
+		if !strings.Contains(spy.Stderr.String(), `conflict: D2: diagram "Deliverable", table "Polished deliverable" (never synced)`) {
+			t.Errorf("want conflict, got %q", spy.Stderr.String())
+		}
+		bs, err := os.ReadFile(pfdPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(bs, original) {
+			t.Errorf("want the PFD kept, got:\n%s", string(bs))
+		}
+		if _, err := os.Stat(filepath.Join(dir, "pfd.sync_base.json")); err != nil {
+			t.Errorf("want the default base next to the PFD: %v", err)
+		}
+
+		spy = cli.SpyProcInout()
+		exitStatus = MainCommandByArgs([]string{"-t", "ad", "-sync-to-diagram", "-sync-prefer", "table", "-sync-base", basePath, "-existing", tablePath, "-inplace", "-p", pfdPath}, spy.NewProcInout())
+		if exitStatus != 0 {
+			t.Log(spy.Stderr.String())
+			t.Fatalf("exitStatus = %d, want 0", exitStatus)
+		}
+
+		bs, err = os.ReadFile(pfdPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(bs), `value="D2: Polished deliverable" style="rounded=0;whiteSpace=wrap;html=1;"`) {
+			t.Errorf("want rewritten label, got:\n%s", string(bs))
+		}
+		info, err := os.Stat(pfdPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+		}
+
+		// NOTE: Both sides change since the last sync.
+		if err := os.WriteFile(pfdPath, bytes.ReplaceAll(bs, []byte("D2: Polished deliverable"), []byte("D2: Diagram edit")), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(tablePath, []byte("ID\tDescription\nD2\tTable edit\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		spy = cli.SpyProcInout()
+		exitStatus = MainCommandByArgs([]string{"-t", "ad", "-sync-to-diagram", "-sync-base", basePath, "-existing", tablePath, "-inplace", "-p", pfdPath}, spy.NewProcInout())
+		if exitStatus != 1 {
+			t.Errorf("exitStatus = %d, want 1", exitStatus)
+		}
+		if !strings.Contains(spy.Stderr.String(), "conflict: D2") {
+			t.Errorf("want conflict, got %q", spy.Stderr.String())
+		}
+	})
+	t.Run("-export-project", func(t *testing.T) {
+		spy := cli.SpyProcInout()
+		exitStatus := MainCommandByArgs([]string{"-export-project", "-f", "testdata/loop/config.json"}, spy.NewProcInout())
+		if exitStatus != 0 {
+			t.Log(spy.Stderr.String())
+			t.Fatalf("exitStatus = %d, want 0", exitStatus)
+		}
+
+		var project tools.Project
+		if err := json.Unmarshal(spy.Stdout.Bytes(), &project); err != nil {
+			t.Fatal(err)
+		}
+		if project.PFD == nil || project.PFD.Content == "" {
+			t.Errorf("want embedded pfd, got %v", project.PFD)
+		}
+		if project.ResourceTable == nil || project.ResourceTable.Path != "" || len(project.ResourceTable.Rows) != 1 {
+			t.Errorf("want embedded resource table, got %v", project.ResourceTable)
+		}
+
+		fsmOptions, err := tools.ValidateProject(&project, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tools.ParseFSMEnvSeed(fsmOptions, slog.New(slog.DiscardHandler)); err != nil {
+			t.Errorf("want parsable project, got %v", err)
+		}
+	})
+	t.Run("merge", func(t *testing.T) {
+		dir := t.TempDir()
+		basePath := filepath.Join(dir, "base.tsv")
+		oursPath := filepath.Join(dir, "ours.tsv")
+		theirsPath := filepath.Join(dir, "theirs.tsv")
+		if err := os.WriteFile(basePath, []byte("ID\tDescription\nP1\tDesign\nP2\tTest\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(theirsPath, []byte("ID\tDescription\nP1\tDesign\nP2\tTest CLI\nP3\tRelease\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run("clean", func(t *testing.T) {
+			if err := os.WriteFile(oursPath, []byte("ID\tDescription\nP1\tDesign API\nP2\tTest\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"merge", "-out", oursPath, basePath, oursPath, theirsPath}, spy.NewProcInout())
+			if exitStatus != 0 {
+				t.Log(spy.Stderr.String())
+				t.Fatalf("exitStatus = %d, want 0", exitStatus)
+			}
+
+			bs, err := os.ReadFile(oursPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := "ID\tDescription\nP1\tDesign API\nP2\tTest CLI\nP3\tRelease\n"
+			if string(bs) != expected {
+				t.Errorf("got %q, want %q", string(bs), expected)
+			}
+		})
+		t.Run("conflict markers", func(t *testing.T) {
+			if err := os.WriteFile(oursPath, []byte("ID\tDescription\nP1\tDesign\nP2\tTest API\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"merge", basePath, oursPath, theirsPath}, spy.NewProcInout())
+			if exitStatus != 1 {
+				t.Errorf("exitStatus = %d, want 1", exitStatus)
+			}
+			if !strings.Contains(spy.Stdout.String(), "<<<<<<< ours\nP2\tTest API\n=======\nP2\tTest CLI\n>>>>>>> theirs\n") {
+				t.Errorf("want conflict markers, got:\n%s", spy.Stdout.String())
+			}
+		})
+		t.Run("added by both sides", func(t *testing.T) {
+			emptyPath := filepath.Join(dir, "empty.tsv")
+			if err := os.WriteFile(emptyPath, []byte{}, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(oursPath, []byte("ID\tDescription\nP1\tDesign API\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"merge", emptyPath, oursPath, theirsPath}, spy.NewProcInout())
+			if exitStatus != 1 {
+				t.Log(spy.Stderr.String())
+				t.Errorf("exitStatus = %d, want 1", exitStatus)
+			}
+			expected := "ID\tDescription\n<<<<<<< ours\nP1\tDesign API\n=======\nP1\tDesign\n>>>>>>> theirs\nP2\tTest CLI\nP3\tRelease\n"
+			if spy.Stdout.String() != expected {
+				t.Errorf("got %q, want %q", spy.Stdout.String(), expected)
+			}
+		})
+		t.Run("-json-report", func(t *testing.T) {
+			if err := os.WriteFile(oursPath, []byte("ID\tDescription\nP1\tDesign\nP2\tTest API\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			reportPath := filepath.Join(dir, "report.json")
+
+			spy := cli.SpyProcInout()
+			exitStatus := MainCommandByArgs([]string{"merge", "-json-report", reportPath, "-o", "csv", basePath, oursPath, theirsPath}, spy.NewProcInout())
+			if exitStatus != 1 {
+				t.Errorf("exitStatus = %d, want 1", exitStatus)
+			}
+			expected := "ID,Description\nP1,Design\nP2,Test API\nP3,Release\n"
+			if spy.Stdout.String() != expected {
+				t.Errorf("got %q, want %q", spy.Stdout.String(), expected)
+			}
+
+			bs, err := os.ReadFile(reportPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var report MergeReport
+			if err := json.Unmarshal(bs, &report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Conflicts) != 1 || report.Conflicts[0].ID != "P2" || report.Conflicts[0].Column != "Description" {
+				t.Errorf("want conflict of P2, got %v", report.Conflicts)
+			}
+		})
+	})
 }
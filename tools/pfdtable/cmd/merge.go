@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/table"
+	"github.com/Kuniwak/pfd-tools/table/tablecsv"
+	"github.com/Kuniwak/pfd-tools/table/tablemerge"
+	"github.com/Kuniwak/pfd-tools/table/tabletsv"
+	"github.com/Kuniwak/pfd-tools/tools"
+	"github.com/Kuniwak/pfd-tools/version"
+)
+
+type MergeOptions struct {
+	CommonOptions  *tools.CommonOptions
+	Base           []byte
+	Ours           []byte
+	Theirs         []byte
+	OutputPath     string
+	JSONReportPath string
+	OutputFormat   table.Format
+}
+
+// MergeReport is the JSON conflict report of the merge.
+type MergeReport struct {
+	Conflicts []tablemerge.Conflict `json:"conflicts"`
+}
+
+func MergeCommandByArgs(args []string, inout *cli.ProcInout) int {
+	opts, err := ParseMergeOptions(args, inout)
+	if err != nil {
+		_, _ = fmt.Fprintln(inout.Stderr, err.Error())
+		return 1
+	}
+	if err := MergeCommandByOptions(opts, inout); err != nil {
+		_, _ = fmt.Fprintln(inout.Stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+func ParseMergeOptions(args []string, inout *cli.ProcInout) (*MergeOptions, error) {
+	flags := flag.NewFlagSet("pfdtable merge", flag.ContinueOnError)
+	flags.SetOutput(inout.Stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: pfdtable merge [options] <base> <ours> <theirs>")
+		_, _ = fmt.Fprintln(flags.Output(), "\nOptions")
+		flags.PrintDefaults()
+		_, _ = fmt.Fprintf(flags.Output(), `
+Example
+  $ pfdtable merge base.tsv ours.tsv theirs.tsv
+  ID      Description
+  <<<<<<< ours
+  P1      Implement API
+  =======
+  P1      Implement CLI
+  >>>>>>> theirs
+  P2      Test
+  ...
+
+  $ # Write the conflicts as JSON and resolve them to ours
+  $ pfdtable merge -json-report conflicts.json base.tsv ours.tsv theirs.tsv
+
+  $ # Use as a git merge driver
+  $ git config merge.pfdtable.driver "pfdtable merge -out %%A %%O %%A %%B"
+`)
+	}
+
+	var commonRawOptions tools.CommonRawOptions
+	tools.DeclareCommonOptions(flags, &commonRawOptions)
+
+	outFlag := flags.String("out", "", "path of the merged table. it is written to stdout if empty")
+	jsonReportFlag := flags.String("json-report", "", "path of the JSON conflict report. conflicts are resolved to ours instead of writing conflict markers if given")
+	outputFormatShortFlag := flags.String("o", "", "format of the merged table (available: tsv, csv; the format of ours if empty)")
+	outputFormatFlag := flags.String("output-format", "", "format of the merged table (available: tsv, csv; the format of ours if empty)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return &MergeOptions{CommonOptions: &tools.CommonOptions{Help: true}}, nil
+		}
+		return nil, fmt.Errorf("cmd.ParseMergeOptions: %w", err)
+	}
+
+	commonOptions, err := tools.ValidateCommonOptions(&commonRawOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseMergeOptions: %w", err)
+	}
+
+	if commonOptions.Version {
+		return &MergeOptions{CommonOptions: commonOptions}, nil
+	}
+
+	if flags.NArg() != 3 {
+		return nil, fmt.Errorf("cmd.ParseMergeOptions: base, ours and theirs must be specified")
+	}
+
+	// NOTE: Read all the tables before writing, because git merge drivers write the result to the file of ours.
+	contents := make([][]byte, 0, 3)
+	for _, path := range flags.Args() {
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseMergeOptions: %w", err)
+		}
+		contents = append(contents, bs)
+	}
+
+	var outputFormatString string
+	if *outputFormatShortFlag != "" {
+		outputFormatString = *outputFormatShortFlag
+	} else {
+		outputFormatString = *outputFormatFlag
+	}
+
+	outputFormat, err := table.ParseFormat(outputFormatString)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseMergeOptions: invalid output format: %w", err)
+	}
+	if outputFormat == table.FormatUnknown {
+		outputFormat, _, err = table.Detect(bytes.NewReader(contents[1]))
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseMergeOptions: %w", err)
+		}
+	}
+	if outputFormat != table.FormatTSV && outputFormat != table.FormatCSV {
+		return nil, fmt.Errorf("cmd.ParseMergeOptions: merge supports TSV and CSV: %q", outputFormat)
+	}
+
+	return &MergeOptions{
+		CommonOptions:  commonOptions,
+		Base:           contents[0],
+		Ours:           contents[1],
+		Theirs:         contents[2],
+		OutputPath:     *outFlag,
+		JSONReportPath: *jsonReportFlag,
+		OutputFormat:   outputFormat,
+	}, nil
+}
+
+// MergeCommandByOptions merges the tables and writes the merged table. Conflicts are written as conflict markers, or
+// as the JSON report if the path is given, and make the command fail as git merge drivers do.
+func MergeCommandByOptions(opts *MergeOptions, inout *cli.ProcInout) error {
+	if opts.CommonOptions.Help {
+		return nil
+	}
+
+	if opts.CommonOptions.Version {
+		_, _ = fmt.Fprintln(inout.Stdout, version.Version)
+		return nil
+	}
+
+	// NOTE: git passes an empty base if both sides add the table.
+	base := &tablemerge.Table{}
+	if len(bytes.TrimSpace(opts.Base)) > 0 {
+		var err error
+		base, err = parseMergeTable(opts.Base)
+		if err != nil {
+			return fmt.Errorf("cmd.MergeCommandByOptions: base: %w", err)
+		}
+	}
+	ours, err := parseMergeTable(opts.Ours)
+	if err != nil {
+		return fmt.Errorf("cmd.MergeCommandByOptions: ours: %w", err)
+	}
+	theirs, err := parseMergeTable(opts.Theirs)
+	if err != nil {
+		return fmt.Errorf("cmd.MergeCommandByOptions: theirs: %w", err)
+	}
+
+	res, err := tablemerge.Merge(base, ours, theirs)
+	if err != nil {
+		return fmt.Errorf("cmd.MergeCommandByOptions: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if opts.JSONReportPath != "" {
+		if err := writeMergeTable(buf, res.Table(), opts.OutputFormat); err != nil {
+			return fmt.Errorf("cmd.MergeCommandByOptions: %w", err)
+		}
+		if err := writeMergeReport(opts.JSONReportPath, &MergeReport{Conflicts: res.Conflicts}); err != nil {
+			return fmt.Errorf("cmd.MergeCommandByOptions: %w", err)
+		}
+	} else {
+		comma := '\t'
+		if opts.OutputFormat == table.FormatCSV {
+			comma = ','
+		}
+		if err := tablemerge.WriteMarkers(buf, res, comma); err != nil {
+			return fmt.Errorf("cmd.MergeCommandByOptions: %w", err)
+		}
+	}
+
+	if opts.OutputPath != "" {
+		if err := os.WriteFile(opts.OutputPath, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("cmd.MergeCommandByOptions: %w", err)
+		}
+	} else {
+		if _, err := inout.Stdout.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("cmd.MergeCommandByOptions: %w", err)
+		}
+	}
+
+	if len(res.Conflicts) > 0 {
+		return fmt.Errorf("cmd.MergeCommandByOptions: %d conflicts", len(res.Conflicts))
+	}
+	return nil
+}
+
+func parseMergeTable(bs []byte) (*tablemerge.Table, error) {
+	format, r, err := table.Detect(bytes.NewReader(bs))
+	if err != nil {
+		return nil, fmt.Errorf("cmd.parseMergeTable: %w", err)
+	}
+
+	switch format {
+	case table.FormatTSV:
+		t, err := tabletsv.ParseTable(r)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.parseMergeTable: %w", err)
+		}
+		return &tablemerge.Table{Header: t.Header, Rows: t.Rows}, nil
+	case table.FormatCSV:
+		t, err := tablecsv.ParseTable(r)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.parseMergeTable: %w", err)
+		}
+		return &tablemerge.Table{Header: t.Header, Rows: t.Rows}, nil
+	default:
+		return nil, fmt.Errorf("cmd.parseMergeTable: merge supports TSV and CSV: %q", format)
+	}
+}
+
+func writeMergeTable(w io.Writer, t *tablemerge.Table, format table.Format) error {
+	switch format {
+	case table.FormatTSV:
+		if err := tabletsv.WriteTable(w, &tabletsv.Table{Header: t.Header, Rows: t.Rows}); err != nil {
+			return fmt.Errorf("cmd.writeMergeTable: %w", err)
+		}
+	case table.FormatCSV:
+		if err := tablecsv.WriteTable(w, &tablecsv.Table{Header: t.Header, Rows: t.Rows}); err != nil {
+			return fmt.Errorf("cmd.writeMergeTable: %w", err)
+		}
+	default:
+		panic(fmt.Sprintf("cmd.writeMergeTable: unsupported format: %q", format))
+	}
+	return nil
+}
+
+func writeMergeReport(path string, report *MergeReport) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("cmd.writeMergeReport: %w", err)
+	}
+	defer f.Close()
+
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	if err := e.Encode(report); err != nil {
+		return fmt.Errorf("cmd.writeMergeReport: %w", err)
+	}
+	return nil
+}
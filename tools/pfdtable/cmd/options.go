@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/Kuniwak/pfd-tools/cli"
 	"github.com/Kuniwak/pfd-tools/pfd"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdfix"
 	"github.com/Kuniwak/pfd-tools/table"
 	"github.com/Kuniwak/pfd-tools/tools"
 )
@@ -30,13 +33,22 @@ type Options struct {
 	AtomicProcessTableReader        io.Reader
 	HasCompositeDeliverableTable    bool
 	CompositeDeliverableTableReader io.Reader
+	ExistingTable                   []byte
 	ExistingTableReader             io.Reader
 	HasExistingTable                bool
 	IsInplace                       bool
+	ExportProject                   bool
+	SyncToDiagram                   bool
+	PFD                             []byte
+	PFDPath                         string
+	SyncBasePath                    string
+	SyncPrefer                      pfdfix.SyncPrefer
+	FSMOptions                      *tools.FSMOptions
 	TableCategory                   TableCategory
 	PFDTableType                    pfd.TableType
 	FSMTableType                    fsmtable.TableType
 	InputFormat                     table.Format
+	ExistingTableFormat             table.Format
 	OutputFormat                    table.Format
 }
 
@@ -72,6 +84,33 @@ Example
   ID      Description     Location
   D1      Implementation  https://example.com/1
   ...
+
+  $ # Print updated milestone table from the Milestone and Group columns of the atomic process table
+  $ pfdtable -t m -ap path/to/atomic_proc.tsv -existing path/to/milestone.tsv
+  ID      Description     Groups  Successors
+  M1      Design          G1      M2
+  ...
+
+  $ # Write the table as CSV
+  $ pfdtable -t ap -o csv -p path/to/pfd.drawio
+  ID,Description
+  P1,Implement
+  ...
+
+  $ # Update the ATOMIC_PROCESS sheet of the workbook and keep the other sheets
+  $ pfdtable -t ap -existing path/to/tables.xlsx -inplace -p path/to/pfd.drawio
+
+  $ # Rewrite the labels of the PFD to the descriptions of the deliverable table
+  $ pfdtable -t ad -sync-to-diagram -sync-base path/to/sync_base.json -existing path/to/deliv.tsv -inplace -p path/to/pfd.drawio
+
+  $ # Start syncing a PFD that has never been synced by taking the descriptions of the table
+  $ pfdtable -t ad -sync-to-diagram -sync-prefer table -existing path/to/deliv.tsv -inplace -p path/to/pfd.drawio
+
+  $ # Export the PFD and the tables of the run config into a single project document
+  $ pfdtable -export-project -f path/to/config.json > path/to/project.json
+
+  $ # Merge the tables of two branches (see pfdtable merge -h)
+  $ pfdtable merge base.tsv ours.tsv theirs.tsv
 `)
 	}
 
@@ -87,14 +126,21 @@ Example
 	var compositeDeliverableTableShortPath, compositeDeliverableTableLongPath string
 	tools.DeclareCompositeDeliverableTableOptions(flags, &compositeDeliverableTableShortPath, &compositeDeliverableTableLongPath)
 
-	typeShortFlag := flags.String("t", "", "type of the table (available: ap(atomic-process), ad(atomic-deliverable), cp(composite-process), cd(composite-deliverable), r(resource))")
-	typeFlag := flags.String("type", "", "type of the table (available: ap(atomic-process), ad(atomic-deliverable), cp(composite-process), cd(composite-deliverable), r(resource))")
+	var configShortPath, configLongPath string
+	tools.DeclareConfigOptions(flags, &configShortPath, &configLongPath)
+
+	typeShortFlag := flags.String("t", "", "type of the table (available: ap(atomic-process), ad(atomic-deliverable), cp(composite-process), cd(composite-deliverable), r(resource), m(milestone), g(group))")
+	typeFlag := flags.String("type", "", "type of the table (available: ap(atomic-process), ad(atomic-deliverable), cp(composite-process), cd(composite-deliverable), r(resource), m(milestone), g(group))")
 	existingPathFlag := flags.String("existing", "", "path of the existing fsmtable")
 	inplaceFlag := flags.Bool("inplace", false, "overwrite the file in place")
-	inputFormatShortFlag := flags.String("i", "", "format of the input PFD")
-	inputFormatFlag := flags.String("input-format", "", "format of the input PFD")
-	outputFormatShortFlag := flags.String("o", "", "format of the output fsmtable")
-	outputFormatFlag := flags.String("output-format", "", "format of the output fsmtable")
+	inputFormatShortFlag := flags.String("i", "", "format of the input tables (available: tsv, csv, xlsx; detected from the contents if empty)")
+	inputFormatFlag := flags.String("input-format", "", "format of the input tables (available: tsv, csv, xlsx; detected from the contents if empty)")
+	outputFormatShortFlag := flags.String("o", "", "format of the output table (available: tsv, csv, xlsx, html; the format of the existing table or tsv if empty)")
+	outputFormatFlag := flags.String("output-format", "", "format of the output table (available: tsv, csv, xlsx, html; the format of the existing table or tsv if empty)")
+	syncToDiagramFlag := flags.Bool("sync-to-diagram", false, "rewrite the descriptions of the PFD to the ones of the existing table (draw.io only). the PFD is overwritten if inplace flag is given")
+	syncPreferFlag := flags.String("sync-prefer", "", "resolve conflicts of sync-to-diagram flag to the given side instead of reporting them (available: table)")
+	syncBaseFlag := flags.String("sync-base", "", "path of the descriptions at the last sync to detect conflicts (default: sync_base.json suffixed to the PFD path without the extension). it is updated if inplace flag is given")
+	exportProjectFlag := flags.Bool("export-project", false, "export the PFD and the tables of the run config as a project document embedding all of them")
 
 	if err := flags.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
@@ -117,6 +163,22 @@ Example
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
 
+	if *exportProjectFlag {
+		if configShortPath == "" && configLongPath == "" {
+			return nil, fmt.Errorf("cmd.ParseOptions: export-project flag is only valid when run config is specified")
+		}
+		fsmOptions, err := tools.ValidateFSMOptionsJSON(&configShortPath, &configLongPath, tools.FSMRawOptions{MaximalAvailableAllocationsThreshold: tools.DefaultMaximalAvailableAllocationsThreshold})
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+		return &Options{
+			CommonOptions: commonOptions,
+			ExportProject: true,
+			FSMOptions:    fsmOptions,
+			Writer:        inout.Stdout,
+		}, nil
+	}
+
 	var tableTypeString string
 	if *typeShortFlag != "" {
 		tableTypeString = *typeShortFlag
@@ -125,9 +187,10 @@ Example
 	}
 
 	var pfdReader io.Reader
+	var pfdPath string
 	hasPFD := false
 	if pfdShortPath != "" || pfdLongPath != "" {
-		pfdReader, _, err = tools.ValidatePFDOptions(&pfdShortPath, &pfdLongPath, cwd)
+		pfdReader, pfdPath, err = tools.ValidatePFDOptions(&pfdShortPath, &pfdLongPath, cwd)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
@@ -174,11 +237,17 @@ Example
 		}
 		tableCategory = TableCategoryFSM
 		fsmTableType = fsmtable.TableTypeMilestone
+	case "g", "group":
+		apTableReader, _, err = tools.ValidateAtomicProcessTableOptions(&atomicProcessTableShortPath, &atomicProcessTableLongPath, cwd)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+		tableCategory = TableCategoryFSM
+		fsmTableType = fsmtable.TableTypeGroup
 	default:
 		return nil, fmt.Errorf("cmd.ParseOptions: invalid table type: %q", tableTypeString)
 	}
 
-	var outputFormat table.Format
 	var outputFormatString string
 	if *outputFormatShortFlag != "" {
 		outputFormatString = *outputFormatShortFlag
@@ -186,16 +255,11 @@ Example
 		outputFormatString = *outputFormatFlag
 	}
 
-	switch outputFormatString {
-	case "tsv", "":
-		outputFormat = table.FormatTSV
-	case "html":
-		outputFormat = table.FormatHTML
-	default:
-		return nil, fmt.Errorf("cmd.ParseOptions: invalid output format: %q", outputFormatString)
+	outputFormat, err := table.ParseFormat(outputFormatString)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseOptions: invalid output format: %w", err)
 	}
 
-	var inputFormat table.Format
 	var inputFormatString string
 	if *inputFormatShortFlag != "" {
 		inputFormatString = *inputFormatShortFlag
@@ -203,29 +267,105 @@ Example
 		inputFormatString = *inputFormatFlag
 	}
 
-	switch inputFormatString {
-	case "tsv", "":
-		inputFormat = table.FormatTSV
-	case "html":
-		inputFormat = table.FormatHTML
-	default:
-		return nil, fmt.Errorf("cmd.ParseOptions: invalid input format: %q", inputFormatString)
+	inputFormat, err := table.ParseFormat(inputFormatString)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseOptions: invalid input format: %w", err)
 	}
 
+	var existingTable []byte
 	var existingTableReader io.Reader
+	existingTableFormat := table.FormatUnknown
 	hasExistingTable := *existingPathFlag != ""
 	if hasExistingTable {
-		bs, err := os.ReadFile(*existingPathFlag)
+		existingTable, err = os.ReadFile(*existingPathFlag)
 		if err != nil {
 			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 		}
-		existingTableReader = bytes.NewReader(bs)
+		existingTableReader = bytes.NewReader(existingTable)
+
+		// NOTE: The format of the existing table is detected apart from the other input tables, which are detected
+		// when they are parsed.
+		existingTableFormat = inputFormat
+		if existingTableFormat == table.FormatUnknown {
+			existingTableFormat, _, err = table.Detect(bytes.NewReader(existingTable))
+			if err != nil {
+				return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+			}
+		}
+	}
+
+	if outputFormat == table.FormatUnknown {
+		// NOTE: Updated tables are written in the same format as the existing ones by default.
+		if hasExistingTable {
+			outputFormat = existingTableFormat
+		} else {
+			outputFormat = table.FormatTSV
+		}
 	}
 
 	if !hasExistingTable && *inplaceFlag {
 		return nil, fmt.Errorf("cmd.ParseOptions: inplace flag is only valid when existing table is specified")
 	}
 
+	if *syncBaseFlag != "" && !*syncToDiagramFlag {
+		return nil, fmt.Errorf("cmd.ParseOptions: sync-base flag is only valid when sync-to-diagram flag is specified")
+	}
+
+	if *syncPreferFlag != "" && !*syncToDiagramFlag {
+		return nil, fmt.Errorf("cmd.ParseOptions: sync-prefer flag is only valid when sync-to-diagram flag is specified")
+	}
+
+	syncPrefer := pfdfix.SyncPrefer(*syncPreferFlag)
+	switch syncPrefer {
+	case pfdfix.SyncPreferNone, pfdfix.SyncPreferTable:
+	default:
+		return nil, fmt.Errorf("cmd.ParseOptions: unknown sync-prefer: %q", *syncPreferFlag)
+	}
+
+	if *syncToDiagramFlag {
+		if tableCategory != TableCategoryPFD {
+			return nil, fmt.Errorf("cmd.ParseOptions: sync-to-diagram flag is only valid for ap, ad, cp and cd")
+		}
+		if !hasPFD {
+			return nil, fmt.Errorf("cmd.ParseOptions: sync-to-diagram flag is only valid when pfd is specified")
+		}
+		if !hasExistingTable {
+			return nil, fmt.Errorf("cmd.ParseOptions: sync-to-diagram flag is only valid when existing table is specified")
+		}
+
+		// NOTE: The PFD is read here because it may be overwritten in place.
+		pfdBytes, err := io.ReadAll(pfdReader)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+
+		// NOTE: The base is always kept next to the PFD by default, otherwise labels edited in draw.io cannot be told
+		// from outdated ones.
+		syncBasePath := *syncBaseFlag
+		if syncBasePath == "" {
+			syncBasePath = strings.TrimSuffix(pfdPath, filepath.Ext(pfdPath)) + ".sync_base.json"
+		}
+
+		return &Options{
+			CommonOptions:       commonOptions,
+			HasPFD:              hasPFD,
+			PFD:                 pfdBytes,
+			PFDPath:             pfdPath,
+			ExistingTable:       existingTable,
+			ExistingTableReader: existingTableReader,
+			HasExistingTable:    hasExistingTable,
+			TableCategory:       tableCategory,
+			PFDTableType:        pfdTableType,
+			InputFormat:         inputFormat,
+			ExistingTableFormat: existingTableFormat,
+			IsInplace:           *inplaceFlag,
+			SyncToDiagram:       true,
+			SyncBasePath:        syncBasePath,
+			SyncPrefer:          syncPrefer,
+			Writer:              inout.Stdout,
+		}, nil
+	}
+
 	var writer io.Writer
 	if *inplaceFlag {
 		writer, err = os.OpenFile(*existingPathFlag, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
@@ -243,6 +383,7 @@ Example
 		HasCompositeDeliverableTable:    hasCompositeDeliverableTable,
 		CompositeDeliverableTableReader: compositeDeliverableTableReader,
 		AtomicProcessTableReader:        apTableReader,
+		ExistingTable:                   existingTable,
 		ExistingTableReader:             existingTableReader,
 		HasExistingTable:                hasExistingTable,
 		TableCategory:                   tableCategory,
@@ -250,6 +391,7 @@ Example
 		FSMTableType:                    fsmTableType,
 		OutputFormat:                    outputFormat,
 		InputFormat:                     inputFormat,
+		ExistingTableFormat:             existingTableFormat,
 		IsInplace:                       *inplaceFlag,
 		Writer:                          writer,
 	}, nil
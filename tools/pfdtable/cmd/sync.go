@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdfix"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
+)
+
+// syncToDiagram rewrites the labels of the PFD to the descriptions of the existing table. Conflicts are reported and
+// make the command fail after writing the other descriptions.
+func syncToDiagram(opts *Options, inout *cli.ProcInout, logger *slog.Logger) error {
+	descs, err := existingDescriptionMap(opts)
+	if err != nil {
+		return fmt.Errorf("cmd.syncToDiagram: %w", err)
+	}
+
+	base, err := readSyncBase(opts.SyncBasePath)
+	if err != nil {
+		return fmt.Errorf("cmd.syncToDiagram: %w", err)
+	}
+
+	bs, nextBase, conflicts, err := pfdfix.SyncDescriptions(opts.PFD, descs, base, opts.SyncPrefer, logger)
+	if err != nil {
+		return fmt.Errorf("cmd.syncToDiagram: %w", err)
+	}
+
+	if opts.IsInplace {
+		info, err := os.Stat(opts.PFDPath)
+		if err != nil {
+			return fmt.Errorf("cmd.syncToDiagram: %w", err)
+		}
+		if err := os.WriteFile(opts.PFDPath, bs, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("cmd.syncToDiagram: %w", err)
+		}
+		// NOTE: The base is updated only if the PFD is written, otherwise the next sync misses the changes.
+		if err := writeSyncBase(opts.SyncBasePath, nextBase); err != nil {
+			return fmt.Errorf("cmd.syncToDiagram: %w", err)
+		}
+	} else {
+		if _, err := opts.Writer.Write(bs); err != nil {
+			return fmt.Errorf("cmd.syncToDiagram: %w", err)
+		}
+	}
+
+	for _, conflict := range conflicts {
+		if _, ok := base[conflict.ID]; !ok {
+			_, _ = fmt.Fprintf(inout.Stderr, "conflict: %s: diagram %q, table %q (never synced)\n", conflict.ID, conflict.Diagram, conflict.Table)
+			continue
+		}
+		_, _ = fmt.Fprintf(inout.Stderr, "conflict: %s: diagram %q, table %q (last synced %q)\n", conflict.ID, conflict.Diagram, conflict.Table, conflict.Base)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("cmd.syncToDiagram: %d conflicts", len(conflicts))
+	}
+	return nil
+}
+
+func existingDescriptionMap(opts *Options) (map[pfd.NodeID]string, error) {
+	descs := make(map[pfd.NodeID]string)
+	switch opts.PFDTableType {
+	case pfd.TableTypeAtomicProcess:
+		tableParser, err := pfdtableencoding.NewAtomicProcessTableParser(opts.ExistingTableFormat)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.existingDescriptionMap: %w", err)
+		}
+		t, err := tableParser(opts.ExistingTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.existingDescriptionMap: %w", err)
+		}
+		for _, row := range t.Rows {
+			descs[pfd.NodeID(row.ID)] = row.Description
+		}
+
+	case pfd.TableTypeAtomicDeliverable:
+		tableParser, err := pfdtableencoding.NewAtomicDeliverableTableParser(opts.ExistingTableFormat)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.existingDescriptionMap: %w", err)
+		}
+		t, err := tableParser(opts.ExistingTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.existingDescriptionMap: %w", err)
+		}
+		for _, row := range t.Rows {
+			descs[pfd.NodeID(row.ID)] = row.Description
+		}
+
+	case pfd.TableTypeCompositeProcess:
+		tableParser, err := pfdtableencoding.NewCompositeProcessTableParser(opts.ExistingTableFormat)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.existingDescriptionMap: %w", err)
+		}
+		t, err := tableParser(opts.ExistingTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.existingDescriptionMap: %w", err)
+		}
+		for _, row := range t.Rows {
+			descs[pfd.NodeID(row.ID)] = row.Description
+		}
+
+	case pfd.TableTypeCompositeDeliverable:
+		tableParser, err := pfdtableencoding.NewCompositeDeliverableTableParser(opts.ExistingTableFormat)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.existingDescriptionMap: %w", err)
+		}
+		t, err := tableParser(opts.ExistingTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.existingDescriptionMap: %w", err)
+		}
+		for _, row := range t.Rows {
+			descs[pfd.NodeID(row.ID)] = row.Description
+		}
+
+	default:
+		panic(fmt.Sprintf("cmd.existingDescriptionMap: unknown pfdtable type: %q", opts.PFDTableType))
+	}
+	return descs, nil
+}
+
+// readSyncBase reads the descriptions at the last sync. It returns an empty base if the file does not exist yet.
+func readSyncBase(path string) (map[pfd.NodeID]string, error) {
+	base := make(map[pfd.NodeID]string)
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return base, nil
+		}
+		return nil, fmt.Errorf("cmd.readSyncBase: %w", err)
+	}
+	if err := json.Unmarshal(bs, &base); err != nil {
+		return nil, fmt.Errorf("cmd.readSyncBase: %w", err)
+	}
+	return base, nil
+}
+
+func writeSyncBase(path string, base map[pfd.NodeID]string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("cmd.writeSyncBase: %w", err)
+	}
+	defer f.Close()
+
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	if err := e.Encode(base); err != nil {
+		return fmt.Errorf("cmd.writeSyncBase: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdfmt"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdencoding/pfdtext"
+	"github.com/Kuniwak/pfd-tools/slograw"
+	"github.com/Kuniwak/pfd-tools/version"
+)
+
+func MainCommandByArgs(args []string, inout *cli.ProcInout) int {
+	opts, err := ParseOptions(args, inout)
+	if err != nil {
+		fmt.Fprintln(inout.Stderr, err.Error())
+		return 1
+	}
+	if err := MainCommandByOptions(opts, inout); err != nil {
+		fmt.Fprintln(inout.Stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
+	if opts.CommonOptions.Help {
+		return nil
+	}
+
+	if opts.CommonOptions.Version {
+		fmt.Fprintln(inout.Stdout, version.Version)
+		return nil
+	}
+
+	logger := slog.New(slograw.NewHandler(inout.Stderr, opts.CommonOptions.LogLevel))
+
+	f, r, err := pfdfmt.Detect(opts.Reader)
+	if err != nil {
+		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+	}
+
+	logger.Debug("detected format", "format", f)
+
+	switch f {
+	case pfdfmt.FormatText:
+		file, err := pfdtext.ParseFile(r)
+		if err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+		if err := pfdtext.Format(opts.Writer, file); err != nil {
+			return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
+		}
+	default:
+		return fmt.Errorf("cmd.MainCommandByOptions: not supported format: %q", f)
+	}
+
+	return nil
+}
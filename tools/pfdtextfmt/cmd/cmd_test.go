@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMainCommandByArgs(t *testing.T) {
+	spy := cli.SpyProcInout()
+	exitStatus := MainCommandByArgs([]string{"testdata/simple/pfd.pfd"}, spy.NewProcInout())
+	if exitStatus != 0 {
+		t.Log(spy.Stderr.String())
+		t.Log(spy.Stdout.String())
+		t.Errorf("exitStatus = %d, want 0", exitStatus)
+	}
+
+	expected, err := os.ReadFile("testdata/simple/pfd.pfd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spy.Stdout.String() != string(expected) {
+		t.Error(cmp.Diff(string(expected), spy.Stdout.String()))
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Kuniwak/pfd-tools/cli"
+	"github.com/Kuniwak/pfd-tools/tools"
+)
+
+type Options struct {
+	CommonOptions *tools.CommonOptions
+	Reader        io.Reader
+	Writer        io.Writer
+}
+
+func ParseOptions(args []string, inout *cli.ProcInout) (*Options, error) {
+	flags := flag.NewFlagSet("pfdtextfmt", flag.ContinueOnError)
+	flags.SetOutput(inout.Stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: pfdtextfmt [options] [<path>]")
+		fmt.Fprintln(flags.Output(), "\nOptions")
+		flags.PrintDefaults()
+		fmt.Fprintf(flags.Output(), `
+Example
+  $ pfdtextfmt path/to/pfd.pfd
+  pfd "Example"
+
+  deliverable D1 "Spec"
+  ...
+
+  $ pfdtextfmt -inplace path/to/pfd.pfd
+`)
+	}
+
+	var commonRawOptions tools.CommonRawOptions
+	tools.DeclareCommonOptions(flags, &commonRawOptions)
+
+	inplaceFlag := flags.Bool("inplace", false, "overwrite the file in place")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return &Options{CommonOptions: &tools.CommonOptions{Help: true}}, nil
+		}
+		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+	}
+
+	commonOptions, err := tools.ValidateCommonOptions(&commonRawOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+	}
+
+	if commonOptions.Version {
+		return &Options{CommonOptions: commonOptions}, nil
+	}
+
+	var r io.Reader
+	var w io.Writer
+
+	shouldReadFromStdin := false
+	var inputFilePath string
+
+	if flags.NArg() < 1 {
+		shouldReadFromStdin = true
+	} else if flags.NArg() > 1 {
+		return nil, fmt.Errorf("cmd.ParseOptions: too many arguments")
+	} else {
+		inputFilePath = flags.Arg(0)
+		if inputFilePath == "" {
+			shouldReadFromStdin = true
+		}
+	}
+
+	if shouldReadFromStdin {
+		r = inout.Stdin
+	} else {
+		var err error
+		r, err = os.Open(inputFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+	}
+
+	if *inplaceFlag {
+		buf := bytes.NewBuffer(nil)
+		if _, err := io.Copy(buf, r); err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+		r = buf
+
+		var err error
+		w, err = os.OpenFile(inputFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
+		}
+	} else {
+		w = inout.Stdout
+	}
+
+	return &Options{Reader: r, Writer: w, CommonOptions: commonOptions}, nil
+}
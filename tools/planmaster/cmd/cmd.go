@@ -8,8 +8,8 @@ import (
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmmasterschedule"
 	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable"
-	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmtsv"
-	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdtsv"
+	fsmtableencoding "github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
 	"github.com/Kuniwak/pfd-tools/version"
 )
 
@@ -40,18 +40,18 @@ func MainCommandByOptions(opts *Options, inout *cli.ProcInout) error {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 
-	atomicProcessTable, err := pfdtsv.ParseAtomicProcessTable(opts.AtomicProcessTableReader)
+	atomicProcessTable, err := pfdtableencoding.ParseAtomicProcessTable(opts.AtomicProcessTableReader)
 	if err != nil {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 	aps := atomicProcessTable.AtomicProcesses()
 
-	milestoneTable, err := fsmtsv.ParseMilestoneTable(opts.MilestoneTableReader)
+	milestoneTable, err := fsmtableencoding.ParseMilestoneTable(opts.MilestoneTableReader)
 	if err != nil {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
 
-	groupTable, err := fsmtsv.ParseGroupTable(opts.GroupTableReader)
+	groupTable, err := fsmtableencoding.ParseGroupTable(opts.GroupTableReader)
 	if err != nil {
 		return fmt.Errorf("cmd.MainCommandByOptions: %w", err)
 	}
@@ -73,17 +73,18 @@ Example
 		return nil, fmt.Errorf("cmd.ParseOptions: plan path is required")
 	}
 
-	planReporter, outputFormat, err := tools.ValidatePlanOutputFormat(&planOutputFormatRawOptions, slog.New(slograw.NewHandler(inout.Stderr, commonOptions.LogLevel)))
+	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
 
-	cwd, err := os.Getwd()
+	fsmOptions, err := tools.ValidateFSMOptionsOrConfig(&fsmRawOptions, &configShortPath, &configLongPath, cwd)
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
+	tools.ApplyProjectCalendar(flags, &planOutputFormatRawOptions.BusinessTimeFuncRawOptions, fsmOptions.Calendar)
 
-	fsmOptions, err := tools.ValidateFSMOptionsOrConfig(&fsmRawOptions, &configShortPath, &configLongPath, cwd)
+	planReporter, outputFormat, err := tools.ValidatePlanOutputFormat(&planOutputFormatRawOptions, slog.New(slograw.NewHandler(inout.Stderr, commonOptions.LogLevel)))
 	if err != nil {
 		return nil, fmt.Errorf("cmd.ParseOptions: %w", err)
 	}
@@ -0,0 +1,422 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Kuniwak/pfd-tools/allcheckers"
+	fsmtableencoding "github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmtable/encoding/fsmrecords"
+	pfdtableencoding "github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdtable/encoding/pfdrecords"
+	"github.com/Kuniwak/pfd-tools/table/tabletsv"
+)
+
+// Project is the project document. It is a superset of the run config, so each file can be either referenced by the
+// path relative to the document or embedded in the document. Sharing a project needs only the document if all the
+// files are embedded.
+type Project struct {
+	PFD                       *ProjectFile  `json:"pfd,omitempty"`
+	AtomicProcessTable        *ProjectTable `json:"atomic_process_table,omitempty"`
+	AtomicDeliverableTable    *ProjectTable `json:"atomic_deliverable_table,omitempty"`
+	CompositeProcessTable     *ProjectTable `json:"composite_process_table,omitempty"`
+	CompositeDeliverableTable *ProjectTable `json:"composite_deliverable_table,omitempty"`
+	ResourceTable             *ProjectTable `json:"resource_table,omitempty"`
+	MilestoneTable            *ProjectTable `json:"milestone_table,omitempty"`
+	GroupTable                *ProjectTable `json:"group_table,omitempty"`
+
+	MaximalAvailableAllocationsThreshold int `json:"maximal_available_allocations_threshold"`
+
+	// Calendar is the business calendar of the plans. It is nil if the document has no calendar section.
+	Calendar *ProjectCalendar `json:"calendar,omitempty"`
+
+	// Search is the search quality of the plans. It is nil if the document has no search section.
+	Search *ProjectSearch `json:"search,omitempty"`
+
+	// Lint is the configuration of the rules. It is applied by pfdlint and the tools that lint before running.
+	Lint *allcheckers.Config `json:"lint,omitempty"`
+}
+
+// ProjectFile is a file referenced by the path or embedded. Binary contents such as editable PNG files are embedded
+// in Base64. It is a string of the path in JSON if it is referenced.
+type ProjectFile struct {
+	Path          string `json:"path,omitempty"`
+	Content       string `json:"content,omitempty"`
+	ContentBase64 []byte `json:"content_base64,omitempty"`
+}
+
+type projectFileJSON ProjectFile
+
+func (f *ProjectFile) UnmarshalJSON(bs []byte) error {
+	var path string
+	if err := json.Unmarshal(bs, &path); err == nil {
+		*f = ProjectFile{Path: path}
+		return nil
+	}
+	var v projectFileJSON
+	if err := json.Unmarshal(bs, &v); err != nil {
+		return fmt.Errorf("tools.ProjectFile.UnmarshalJSON: %w", err)
+	}
+	*f = ProjectFile(v)
+	return nil
+}
+
+func (f ProjectFile) MarshalJSON() ([]byte, error) {
+	if f.Content == "" && f.ContentBase64 == nil {
+		return json.Marshal(f.Path)
+	}
+	return json.Marshal(projectFileJSON(f))
+}
+
+// NewEmbeddedProjectFile returns the file that embeds the contents.
+func NewEmbeddedProjectFile(bs []byte) *ProjectFile {
+	if utf8.Valid(bs) {
+		return &ProjectFile{Content: string(bs)}
+	}
+	return &ProjectFile{ContentBase64: bs}
+}
+
+// Open returns the reader of the file and the resolved path. The path is empty if the file is embedded.
+func (f *ProjectFile) Open(basePath string) (io.Reader, string, error) {
+	embedded := f.Content != "" || f.ContentBase64 != nil
+	if f.Path != "" && embedded {
+		return nil, "", fmt.Errorf("tools.ProjectFile.Open: both path and content are given: %q", f.Path)
+	}
+	if f.Content != "" {
+		return strings.NewReader(f.Content), "", nil
+	}
+	if f.ContentBase64 != nil {
+		return bytes.NewReader(f.ContentBase64), "", nil
+	}
+	if f.Path == "" {
+		return nil, "", fmt.Errorf("tools.ProjectFile.Open: missing path or content")
+	}
+
+	path := filepath.Join(basePath, f.Path)
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("tools.ProjectFile.Open: %w", err)
+	}
+	return r, path, nil
+}
+
+// ProjectTable is a table referenced by the path or embedded as the header and the rows. It is a string of the path
+// in JSON if it is referenced.
+type ProjectTable struct {
+	Path   string     `json:"path,omitempty"`
+	Header []string   `json:"header,omitempty"`
+	Rows   [][]string `json:"rows,omitempty"`
+}
+
+type projectTableJSON ProjectTable
+
+func (t *ProjectTable) UnmarshalJSON(bs []byte) error {
+	var path string
+	if err := json.Unmarshal(bs, &path); err == nil {
+		*t = ProjectTable{Path: path}
+		return nil
+	}
+	var v projectTableJSON
+	if err := json.Unmarshal(bs, &v); err != nil {
+		return fmt.Errorf("tools.ProjectTable.UnmarshalJSON: %w", err)
+	}
+	*t = ProjectTable(v)
+	return nil
+}
+
+func (t ProjectTable) MarshalJSON() ([]byte, error) {
+	if t.Header == nil {
+		return json.Marshal(t.Path)
+	}
+	if t.Rows == nil {
+		t.Rows = [][]string{}
+	}
+	return json.Marshal(projectTableJSON(t))
+}
+
+// Open returns the reader of the table and the resolved path. Embedded tables are read as TSV, and the path is empty.
+func (t *ProjectTable) Open(basePath string) (io.Reader, string, error) {
+	if t.Path != "" && t.Header != nil {
+		return nil, "", fmt.Errorf("tools.ProjectTable.Open: both path and header are given: %q", t.Path)
+	}
+	if t.Header != nil {
+		for i, row := range t.Rows {
+			if len(row) != len(t.Header) {
+				return nil, "", fmt.Errorf("tools.ProjectTable.Open: row %d: want %d cells, got %d", i+1, len(t.Header), len(row))
+			}
+		}
+		buf := &bytes.Buffer{}
+		if err := tabletsv.WriteTable(buf, &tabletsv.Table{Header: t.Header, Rows: t.Rows}); err != nil {
+			return nil, "", fmt.Errorf("tools.ProjectTable.Open: %w", err)
+		}
+		return buf, "", nil
+	}
+	if t.Path == "" {
+		return nil, "", fmt.Errorf("tools.ProjectTable.Open: missing path or header")
+	}
+
+	path := filepath.Join(basePath, t.Path)
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("tools.ProjectTable.Open: %w", err)
+	}
+	return r, path, nil
+}
+
+// ProjectCalendar is the business calendar. Empty values are the defaults of the flags.
+type ProjectCalendar struct {
+	StartDay        string   `json:"start,omitempty"`
+	StartTime       string   `json:"start_time,omitempty"`
+	Duration        *float64 `json:"duration,omitempty"`
+	Weekdays        []string `json:"weekdays,omitempty"`
+	NotBusinessDays []string `json:"not_business_days,omitempty"`
+}
+
+// ProjectSearch is the search quality. Empty values are the defaults of the flags.
+type ProjectSearch struct {
+	// Mode is best, better or poor.
+	Mode string `json:"mode,omitempty"`
+
+	// Quality is the quality preset. Custom qualities are given by the fields below.
+	Quality string `json:"quality,omitempty"`
+
+	NodeBudget   *int     `json:"node_budget,omitempty"`
+	TopKPerState *int     `json:"top_k_per_state,omitempty"`
+	Weight       *float64 `json:"weight,omitempty"`
+	MaxResults   *int     `json:"max_results,omitempty"`
+	Restarts     *int     `json:"restarts,omitempty"`
+	RandomSeed   *int64   `json:"random_seed,omitempty"`
+}
+
+// NewProjectByRawOptions returns the project of the paths given by the flags.
+func NewProjectByRawOptions(options *FSMRawOptions) *Project {
+	pathFile := func(shortPath string, longPath string) *ProjectFile {
+		if shortPath != "" {
+			return &ProjectFile{Path: shortPath}
+		}
+		if longPath != "" {
+			return &ProjectFile{Path: longPath}
+		}
+		return nil
+	}
+	pathTable := func(shortPath string, longPath string) *ProjectTable {
+		if f := pathFile(shortPath, longPath); f != nil {
+			return &ProjectTable{Path: f.Path}
+		}
+		return nil
+	}
+	return &Project{
+		PFD:                                  pathFile(options.ShortPFDPath, options.PFDPath),
+		AtomicProcessTable:                   pathTable(options.ShortAtomicProcessTablePath, options.AtomicProcessTablePath),
+		AtomicDeliverableTable:               pathTable(options.ShortAtomicDeliverableTablePath, options.AtomicDeliverableTablePath),
+		CompositeDeliverableTable:            pathTable(options.ShortCompositeDeliverableTablePath, options.CompositeDeliverableTablePath),
+		ResourceTable:                        pathTable(options.ShortResourceTablePath, options.ResourceTablePath),
+		MilestoneTable:                       pathTable(options.ShortMilestoneTablePath, options.MilestoneTablePath),
+		GroupTable:                           pathTable(options.ShortGroupTablePath, options.GroupTablePath),
+		MaximalAvailableAllocationsThreshold: options.MaximalAvailableAllocationsThreshold,
+		Lint:                                 options.Lint,
+	}
+}
+
+// ValidateProject opens the files of the project. Paths are relative to basePath. The PFD, the atomic process table,
+// the atomic deliverable table and the resource table are required.
+func ValidateProject(project *Project, basePath string) (*FSMOptions, error) {
+	if project.PFD == nil {
+		return nil, fmt.Errorf("tools.ValidateProject: missing pfd")
+	}
+	pfdReader, pfdPath, err := project.PFD.Open(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("tools.ValidateProject: pfd: %w", err)
+	}
+
+	options := &FSMOptions{
+		PFDReader:                            pfdReader,
+		PFDPath:                              pfdPath,
+		MaximalAvailableAllocationsThreshold: project.MaximalAvailableAllocationsThreshold,
+		Lint:                                 project.Lint,
+		Calendar:                             project.Calendar,
+		Search:                               project.Search,
+	}
+
+	entries := []struct {
+		Name     string
+		Table    *ProjectTable
+		Required bool
+		Reader   *io.Reader
+		Path     *string
+	}{
+		{Name: "atomic_process_table", Table: project.AtomicProcessTable, Required: true, Reader: &options.AtomicProcessTableReader, Path: &options.AtomicProcessTablePath},
+		{Name: "atomic_deliverable_table", Table: project.AtomicDeliverableTable, Required: true, Reader: &options.AtomicDeliverableTableReader, Path: &options.AtomicDeliverableTablePath},
+		{Name: "composite_process_table", Table: project.CompositeProcessTable, Reader: &options.CompositeProcessTableReader, Path: &options.CompositeProcessTablePath},
+		// NOTE: JSON-encoded PFDs include the deliverable composition, so the composite deliverable table is optional.
+		{Name: "composite_deliverable_table", Table: project.CompositeDeliverableTable, Reader: &options.CompositeDeliverableTableReader, Path: &options.CompositeDeliverableTablePath},
+		{Name: "resource_table", Table: project.ResourceTable, Required: true, Reader: &options.ResourceTableReader, Path: &options.ResourceTablePath},
+		{Name: "milestone_table", Table: project.MilestoneTable, Reader: &options.MilestoneTableReader, Path: &options.MilestoneTablePath},
+		{Name: "group_table", Table: project.GroupTable, Reader: &options.GroupTableReader, Path: &options.GroupTablePath},
+	}
+	for _, entry := range entries {
+		if entry.Table == nil {
+			if entry.Required {
+				return nil, fmt.Errorf("tools.ValidateProject: missing %s", entry.Name)
+			}
+			continue
+		}
+		r, path, err := entry.Table.Open(basePath)
+		if err != nil {
+			return nil, fmt.Errorf("tools.ValidateProject: %s: %w", entry.Name, err)
+		}
+		*entry.Reader = r
+		*entry.Path = path
+	}
+	return options, nil
+}
+
+// NewEmbeddedProject returns the project that embeds the PFD and all the tables of the options. The tables are
+// embedded in the header and the rows regardless of their formats.
+func NewEmbeddedProject(options *FSMOptions) (*Project, error) {
+	pfdBytes, err := io.ReadAll(options.PFDReader)
+	if err != nil {
+		return nil, fmt.Errorf("tools.NewEmbeddedProject: %w", err)
+	}
+
+	project := &Project{
+		PFD:                                  NewEmbeddedProjectFile(pfdBytes),
+		MaximalAvailableAllocationsThreshold: options.MaximalAvailableAllocationsThreshold,
+		Calendar:                             options.Calendar,
+		Search:                               options.Search,
+		Lint:                                 options.Lint,
+	}
+
+	if options.AtomicProcessTableReader != nil {
+		t, err := pfdtableencoding.ParseAtomicProcessTable(options.AtomicProcessTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("tools.NewEmbeddedProject: %w", err)
+		}
+		project.AtomicProcessTable = &ProjectTable{Header: t.Header(), Rows: pfdrecords.AtomicProcessTableRows(t)}
+	}
+	if options.AtomicDeliverableTableReader != nil {
+		t, err := pfdtableencoding.ParseAtomicDeliverableTable(options.AtomicDeliverableTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("tools.NewEmbeddedProject: %w", err)
+		}
+		project.AtomicDeliverableTable = &ProjectTable{Header: t.Header(), Rows: pfdrecords.AtomicDeliverableTableRows(t)}
+	}
+	if options.CompositeProcessTableReader != nil {
+		t, err := pfdtableencoding.ParseCompositeProcessTable(options.CompositeProcessTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("tools.NewEmbeddedProject: %w", err)
+		}
+		project.CompositeProcessTable = &ProjectTable{Header: t.Header(), Rows: pfdrecords.CompositeProcessTableRows(t)}
+	}
+	if options.CompositeDeliverableTableReader != nil {
+		t, err := pfdtableencoding.ParseCompositeDeliverableTable(options.CompositeDeliverableTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("tools.NewEmbeddedProject: %w", err)
+		}
+		project.CompositeDeliverableTable = &ProjectTable{Header: t.Header(), Rows: pfdrecords.CompositeDeliverableTableRows(t)}
+	}
+	if options.ResourceTableReader != nil {
+		t, err := fsmtableencoding.ParseResourceTable(options.ResourceTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("tools.NewEmbeddedProject: %w", err)
+		}
+		project.ResourceTable = &ProjectTable{Header: t.Header(), Rows: fsmrecords.ResourceTableRows(t)}
+	}
+	if options.MilestoneTableReader != nil {
+		t, err := fsmtableencoding.ParseMilestoneTable(options.MilestoneTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("tools.NewEmbeddedProject: %w", err)
+		}
+		project.MilestoneTable = &ProjectTable{Header: t.Header(), Rows: fsmrecords.MilestoneTableRows(t)}
+	}
+	if options.GroupTableReader != nil {
+		t, err := fsmtableencoding.ParseGroupTable(options.GroupTableReader)
+		if err != nil {
+			return nil, fmt.Errorf("tools.NewEmbeddedProject: %w", err)
+		}
+		project.GroupTable = &ProjectTable{Header: t.Header(), Rows: fsmrecords.GroupTableRows(t)}
+	}
+	return project, nil
+}
+
+// ApplyProjectCalendar overwrites the options by the calendar except the flags given explicitly. Not business days
+// are merged instead.
+func ApplyProjectCalendar(flags *flag.FlagSet, options *BusinessTimeFuncRawOptions, calendar *ProjectCalendar) {
+	if calendar == nil {
+		return
+	}
+	given := givenFlags(flags)
+	if !given["start"] && calendar.StartDay != "" {
+		options.StartDay = calendar.StartDay
+	}
+	if !given["start-time"] && calendar.StartTime != "" {
+		options.StartTime = calendar.StartTime
+	}
+	if !given["duration"] && calendar.Duration != nil {
+		options.Duration = *calendar.Duration
+	}
+	if !given["weekdays"] && calendar.Weekdays != nil {
+		options.Weekdays = strings.Join(calendar.Weekdays, ",")
+	}
+	// NOTE: Not business days of the calendar are merged into the existing ones as a set instead of overwriting them,
+	// so a date given in both is listed once in the order it first appears.
+	seen := make(map[string]bool, len(options.NotBusinessDays)+len(calendar.NotBusinessDays))
+	notBusinessDays := make([]string, 0, len(options.NotBusinessDays)+len(calendar.NotBusinessDays))
+	for _, day := range append(options.NotBusinessDays, calendar.NotBusinessDays...) {
+		if seen[day] {
+			continue
+		}
+		seen[day] = true
+		notBusinessDays = append(notBusinessDays, day)
+	}
+	options.NotBusinessDays = notBusinessDays
+}
+
+// ApplyProjectSearch overwrites the options by the search quality except the flags given explicitly.
+func ApplyProjectSearch(flags *flag.FlagSet, options *SearchRawOptions, search *ProjectSearch) {
+	if search == nil {
+		return
+	}
+	given := givenFlags(flags)
+	if !given["best"] && !given["better"] && !given["poor"] && search.Mode != "" {
+		options.Best = search.Mode == "best"
+		options.Better = search.Mode == "better"
+		options.Poor = search.Mode == "poor"
+	}
+	if !given["quality"] && search.Quality != "" {
+		options.QualityPreset = search.Quality
+	}
+	if !given["node-budget"] && search.NodeBudget != nil {
+		options.Quality.NodeBudget = *search.NodeBudget
+	}
+	if !given["top-k-per-state"] && search.TopKPerState != nil {
+		options.Quality.TopKPerState = *search.TopKPerState
+	}
+	if !given["weight"] && search.Weight != nil {
+		options.Quality.Weight = *search.Weight
+	}
+	if !given["max-results"] && search.MaxResults != nil {
+		options.Quality.MaxResults = *search.MaxResults
+	}
+	if !given["restarts"] && search.Restarts != nil {
+		options.Quality.Restarts = *search.Restarts
+	}
+	if !given["random-seed"] && search.RandomSeed != nil {
+		options.Quality.RandomSeed = *search.RandomSeed
+	}
+}
+
+func givenFlags(flags *flag.FlagSet) map[string]bool {
+	given := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	return given
+}
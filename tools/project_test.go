@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProjectUnmarshalJSON(t *testing.T) {
+	testCases := map[string]struct {
+		JSON     string
+		Expected *Project
+	}{
+		"paths": {
+			JSON: `{"pfd": "pfd.drawio", "atomic_process_table": "ap.tsv"}`,
+			Expected: &Project{
+				PFD:                &ProjectFile{Path: "pfd.drawio"},
+				AtomicProcessTable: &ProjectTable{Path: "ap.tsv"},
+			},
+		},
+		"embedded": {
+			JSON: `{"pfd": {"content": "<mxfile/>"}, "atomic_process_table": {"header": ["ID", "Description"], "rows": [["P1", "Process"]]}}`,
+			Expected: &Project{
+				PFD:                &ProjectFile{Content: "<mxfile/>"},
+				AtomicProcessTable: &ProjectTable{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "Process"}}},
+			},
+		},
+		"settings": {
+			JSON: `{"calendar": {"start": "2025-01-06", "not_business_days": ["2025-01-07"]}, "search": {"mode": "better", "quality": "s"}}`,
+			Expected: &Project{
+				Calendar: &ProjectCalendar{StartDay: "2025-01-06", NotBusinessDays: []string{"2025-01-07"}},
+				Search:   &ProjectSearch{Mode: "better", Quality: "s"},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var actual Project
+			if err := json.Unmarshal([]byte(tc.JSON), &actual); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(&actual, tc.Expected) {
+				t.Error(cmp.Diff(tc.Expected, &actual))
+			}
+
+			bs, err := json.Marshal(&actual)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var roundTripped Project
+			if err := json.Unmarshal(bs, &roundTripped); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(&roundTripped, tc.Expected) {
+				t.Error(cmp.Diff(tc.Expected, &roundTripped))
+			}
+		})
+	}
+}
+
+func TestProjectTableOpen(t *testing.T) {
+	table := &ProjectTable{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1", "Process"}}}
+	r, path, err := table.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "" {
+		t.Errorf("want empty path, got %q", path)
+	}
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "ID\tDescription\nP1\tProcess\n" {
+		t.Errorf("unexpected TSV: %q", string(bs))
+	}
+
+	invalid := &ProjectTable{Header: []string{"ID", "Description"}, Rows: [][]string{{"P1"}}}
+	if _, _, err := invalid.Open(t.TempDir()); err == nil {
+		t.Error("want error for the row that has too few cells")
+	}
+}
+
+func TestApplyProjectSearch(t *testing.T) {
+	nodeBudget := 100
+	search := &ProjectSearch{Mode: "better", Quality: "s", NodeBudget: &nodeBudget}
+
+	testCases := map[string]struct {
+		Args     []string
+		Expected SearchRawOptions
+	}{
+		"no flags": {
+			Args:     []string{},
+			Expected: SearchRawOptions{Better: true, QualityPreset: "s"},
+		},
+		"flags given": {
+			Args:     []string{"-best", "-quality", "l"},
+			Expected: SearchRawOptions{Best: true, QualityPreset: "l"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			var options SearchRawOptions
+			DeclareSearchOptions(flags, &options, 1)
+			if err := flags.Parse(tc.Args); err != nil {
+				t.Fatal(err)
+			}
+
+			ApplyProjectSearch(flags, &options, search)
+
+			if options.Best != tc.Expected.Best || options.Better != tc.Expected.Better || options.Poor != tc.Expected.Poor {
+				t.Errorf("want best=%t better=%t poor=%t, got best=%t better=%t poor=%t", tc.Expected.Best, tc.Expected.Better, tc.Expected.Poor, options.Best, options.Better, options.Poor)
+			}
+			if options.QualityPreset != tc.Expected.QualityPreset {
+				t.Errorf("want quality %q, got %q", tc.Expected.QualityPreset, options.QualityPreset)
+			}
+			if options.Quality.NodeBudget != nodeBudget {
+				t.Errorf("want node budget %d, got %d", nodeBudget, options.Quality.NodeBudget)
+			}
+		})
+	}
+}
+
+func TestApplyProjectCalendar(t *testing.T) {
+	calendar := &ProjectCalendar{NotBusinessDays: []string{"2025-01-07", "2025-01-08", "2025-01-07"}}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	options := BusinessTimeFuncRawOptions{NotBusinessDays: []string{"2025-01-06", "2025-01-08"}}
+	DeclareBusinessTimeFuncOptions(flags, &options)
+	if err := flags.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ApplyProjectCalendar(flags, &options, calendar)
+
+	expected := []string{"2025-01-06", "2025-01-08", "2025-01-07"}
+	if !reflect.DeepEqual(options.NotBusinessDays, expected) {
+		t.Error(cmp.Diff(expected, options.NotBusinessDays))
+	}
+}
@@ -0,0 +1,183 @@
+package userrules
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/execmodel/fsm/fsmchecker/fsmcommon"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+// NewPFDChecker returns the checker of the rules for processes and deliverables.
+func NewPFDChecker(rules []*CompiledRule, logger *slog.Logger) checkers.Checker[pfdcommon.Target] {
+	return checkers.AtomicChecker[pfdcommon.Target]{
+		ID: "user-rules",
+		AvailableIfFunc: func(t pfdcommon.Target) bool {
+			return len(rules) > 0
+		},
+		CheckFunc: func(t pfdcommon.Target, ch chan<- checkers.Problem) error {
+			var initials, finals *sets.Set[pfd.NodeID]
+			for _, r := range rules {
+				if r.For == KindAtomicDeliverable && initials == nil {
+					initials = t.PFD.InitialAtomicDeliverables(t.Memoized.NodeMap, logger)
+					finals = t.PFD.FinalAtomicDeliverables(t.Memoized.NodeMap, logger)
+				}
+			}
+
+			apRows := make(map[pfd.NodeID][]string)
+			var apHeaders []string
+			if t.AtomicProcessTable != nil {
+				apHeaders = t.AtomicProcessTable.ExtraHeaders
+				for _, row := range t.AtomicProcessTable.Rows {
+					apRows[pfd.NodeID(row.ID)] = row.ExtraCells
+				}
+			}
+
+			adRows := make(map[pfd.NodeID][]string)
+			var adHeaders []string
+			if t.AtomicDeliverableTable != nil {
+				adHeaders = t.AtomicDeliverableTable.ExtraHeaders
+				for _, row := range t.AtomicDeliverableTable.Rows {
+					adRows[pfd.NodeID(row.ID)] = row.ExtraCells
+				}
+			}
+
+			cpRows := make(map[pfd.NodeID][]string)
+			var cpHeaders []string
+			if t.CompositeProcessTable != nil {
+				cpHeaders = t.CompositeProcessTable.ExtraHeaders
+				for _, row := range t.CompositeProcessTable.Rows {
+					cpRows[pfd.NodeID(row.ID)] = row.ExtraCells
+				}
+			}
+
+			cdRows := make(map[pfd.NodeID][]string)
+			var cdHeaders []string
+			if t.CompositeDeliverableTable != nil {
+				cdHeaders = t.CompositeDeliverableTable.ExtraHeaders
+				for _, row := range t.CompositeDeliverableTable.Rows {
+					cdRows[pfd.NodeID(row.ID)] = row.ExtraCells
+				}
+			}
+
+			headers := map[Kind][]string{
+				KindAtomicProcess:        apHeaders,
+				KindAtomicDeliverable:    adHeaders,
+				KindCompositeProcess:     cpHeaders,
+				KindCompositeDeliverable: cdHeaders,
+			}
+			for _, r := range rules {
+				if hs, ok := headers[r.For]; ok {
+					if err := r.CheckColumns(hs); err != nil {
+						return fmt.Errorf("userrules.NewPFDChecker: %w", err)
+					}
+				}
+			}
+
+			for _, node := range t.PFD.Nodes.Iter() {
+				env := &Env{ID: string(node.ID), Description: node.Description}
+
+				var kind Kind
+				var hasRow bool
+				switch node.Type {
+				case pfd.NodeTypeAtomicProcess:
+					kind = KindAtomicProcess
+					env.Cells, hasRow = apRows[node.ID]
+				case pfd.NodeTypeAtomicDeliverable:
+					kind = KindAtomicDeliverable
+					env.Cells, hasRow = adRows[node.ID]
+					if initials != nil {
+						env.Initial = initials.Contains(pfd.NodeID.Compare, node.ID)
+						env.Final = finals.Contains(pfd.NodeID.Compare, node.ID)
+					}
+				case pfd.NodeTypeCompositeProcess:
+					kind = KindCompositeProcess
+					env.Cells, hasRow = cpRows[node.ID]
+				case pfd.NodeTypeCompositeDeliverable:
+					kind = KindCompositeDeliverable
+					env.Cells, hasRow = cdRows[node.ID]
+				default:
+					continue
+				}
+				env.Headers = headers[kind]
+
+				loc := pfdcommon.NewLocation(pfdcommon.LocationTypePFD, node.ID)
+				for _, r := range rules {
+					if r.For != kind {
+						continue
+					}
+					// NOTE: Rows missing in the tables are reported by missing-ap-table and the like.
+					if !hasRow && len(r.columns) > 0 {
+						continue
+					}
+					if err := r.report(env, loc, ch); err != nil {
+						return fmt.Errorf("userrules.NewPFDChecker: %w", err)
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// NewFSMChecker returns the checker of the rules for resources, milestones and groups.
+func NewFSMChecker(rules []*CompiledRule, logger *slog.Logger) checkers.Checker[*fsmcommon.Target] {
+	return checkers.AtomicChecker[*fsmcommon.Target]{
+		ID: "user-rules",
+		AvailableIfFunc: func(t *fsmcommon.Target) bool {
+			return len(rules) > 0
+		},
+		CheckFunc: func(t *fsmcommon.Target, ch chan<- checkers.Problem) error {
+			for _, r := range rules {
+				switch r.For {
+				case KindResource:
+					if t.ResourceTable == nil {
+						continue
+					}
+					if err := r.CheckColumns(t.ResourceTable.ExtraHeaders); err != nil {
+						return fmt.Errorf("userrules.NewFSMChecker: %w", err)
+					}
+					for _, row := range t.ResourceTable.Rows {
+						env := &Env{ID: string(row.ID), Description: row.Description, Headers: t.ResourceTable.ExtraHeaders, Cells: row.ExtraCells}
+						loc := fsmcommon.NewLocation(fsmcommon.LocationTypeResourceTable, fsmcommon.NewResourceID(row.ID))
+						if err := r.report(env, loc, ch); err != nil {
+							return fmt.Errorf("userrules.NewFSMChecker: %w", err)
+						}
+					}
+				case KindMilestone:
+					if t.MilestoneTable == nil {
+						continue
+					}
+					if err := r.CheckColumns(t.MilestoneTable.ExtraHeaders); err != nil {
+						return fmt.Errorf("userrules.NewFSMChecker: %w", err)
+					}
+					for _, row := range t.MilestoneTable.Rows {
+						env := &Env{ID: string(row.MilestoneID), Description: row.Description, Headers: t.MilestoneTable.ExtraHeaders, Cells: row.ExtraCells}
+						loc := fsmcommon.NewLocation(fsmcommon.LocationTypeMilestoneTable, fsmcommon.NewMilestoneID(row.MilestoneID))
+						if err := r.report(env, loc, ch); err != nil {
+							return fmt.Errorf("userrules.NewFSMChecker: %w", err)
+						}
+					}
+				case KindGroup:
+					if t.GroupTable == nil {
+						continue
+					}
+					if err := r.CheckColumns(t.GroupTable.ExtraHeaders); err != nil {
+						return fmt.Errorf("userrules.NewFSMChecker: %w", err)
+					}
+					for _, row := range t.GroupTable.Rows {
+						env := &Env{ID: string(row.ID), Description: row.Description, Headers: t.GroupTable.ExtraHeaders, Cells: row.ExtraCells}
+						loc := fsmcommon.NewLocation(fsmcommon.LocationTypeGroupTable, fsmcommon.NewGroupID(row.ID))
+						if err := r.report(env, loc, ch); err != nil {
+							return fmt.Errorf("userrules.NewFSMChecker: %w", err)
+						}
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,584 @@
+package userrules
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Kuniwak/pfd-tools/parser"
+	"github.com/Kuniwak/pfd-tools/sets"
+)
+
+type ExprType string
+
+const (
+	ExprTypeOr       ExprType = "OR"
+	ExprTypeAnd      ExprType = "AND"
+	ExprTypeNot      ExprType = "NOT"
+	ExprTypeCompare  ExprType = "COMPARE"
+	ExprTypeMatch    ExprType = "MATCH"
+	ExprTypeLiteral  ExprType = "LITERAL"
+	ExprTypeVariable ExprType = "VARIABLE"
+	ExprTypeColumn   ExprType = "COLUMN"
+)
+
+type Operator string
+
+const (
+	OperatorEq Operator = "=="
+	OperatorNe Operator = "!="
+	OperatorLt Operator = "<"
+	OperatorLe Operator = "<="
+	OperatorGt Operator = ">"
+	OperatorGe Operator = ">="
+)
+
+// Expr is an expression of user-defined rules.
+type Expr struct {
+	Type ExprType
+
+	// Operands are the operands. OR and AND have two or more operands, COMPARE has two, and NOT and MATCH have one.
+	Operands []*Expr
+
+	// Operator is the operator. Behavior is undefined when Type is other than ExprTypeCompare.
+	Operator Operator
+
+	// Pattern is the regular expression that the operand should match. Behavior is undefined when Type is other than
+	// ExprTypeMatch.
+	Pattern *regexp.Regexp
+
+	// Value is the value. Behavior is undefined when Type is other than ExprTypeLiteral.
+	Value Value
+
+	// Name is the name of the variable or the header of the column. Behavior is undefined when Type is other than
+	// ExprTypeVariable or ExprTypeColumn.
+	Name string
+
+	// rawPattern is the source of Pattern. It is compiled after parsing to report errors of regular expressions.
+	rawPattern string
+}
+
+type ValueType string
+
+const (
+	ValueTypeString ValueType = "STRING"
+	ValueTypeNumber ValueType = "NUMBER"
+	ValueTypeBool   ValueType = "BOOL"
+)
+
+type Value struct {
+	Type   ValueType
+	String string
+	Number float64
+	Bool   bool
+}
+
+func NewStringValue(s string) Value {
+	return Value{Type: ValueTypeString, String: s}
+}
+
+func NewNumberValue(n float64) Value {
+	return Value{Type: ValueTypeNumber, Number: n}
+}
+
+func NewBoolValue(b bool) Value {
+	return Value{Type: ValueTypeBool, Bool: b}
+}
+
+const (
+	VariableID          = "id"
+	VariableDescription = "description"
+	VariableInitial     = "initial"
+	VariableFinal       = "final"
+)
+
+// Env is the element that expressions are evaluated on.
+type Env struct {
+	ID          string
+	Description string
+
+	// Initial and Final are true if the element is an initial or a final atomic deliverable.
+	Initial bool
+	Final   bool
+
+	// Headers are the extra headers of the table.
+	Headers []string
+
+	// Cells are the extra cells of the row of the element. It is nil if the table has no rows of the element.
+	Cells []string
+}
+
+// ParseExpr parses the following syntax rules:
+//
+//	expr       = *SP or_expr
+//
+//	or_expr    = and_expr *( "||" *SP and_expr )
+//	and_expr   = unary    *( "&&" *SP unary )
+//	unary      = "!" *SP unary
+//	           / operand "=~" *SP string
+//	           / operand *1( comp_op *SP operand )
+//	comp_op    = "==" / "!=" / "<=" / ">=" / "<" / ">"
+//
+//	operand    = "(" *SP or_expr ")" *SP
+//	           / "[" *SP string "]" *SP
+//	           / string
+//	           / number
+//	           / ("true" / "false" / "id" / "description" / "initial" / "final") *SP
+//
+//	string     = (DQUOTE *(char / "'") DQUOTE / "'" *(char / DQUOTE) "'") *SP
+//	char       = "\" CHAR / %x20-21 / %x23-26 / %x28-5B / %x5D-10FFFF
+//	number     = *1"-" 1*DIGIT *1("." 1*DIGIT) *SP
+//	SP         = " " / HTAB / LF
+func ParseExpr(s string) (*Expr, error) {
+	rs := []rune(s)
+
+	newIndex := parser.SkipRune(Whitespaces, rs, 0)
+	e, newIndex := parseOrExpression(rs, newIndex)
+	if e == nil {
+		return nil, fmt.Errorf("userrules.ParseExpr: syntax error: %q", s)
+	}
+	if newIndex != len(rs) {
+		return nil, fmt.Errorf("userrules.ParseExpr: trailing garbage: %q", string(rs[newIndex:]))
+	}
+	if err := compilePatterns(e); err != nil {
+		return nil, fmt.Errorf("userrules.ParseExpr: %w", err)
+	}
+	return e, nil
+}
+
+var (
+	OrKeyword               = []rune("||")
+	AndKeyword              = []rune("&&")
+	NotKeyword              = []rune("!")
+	MatchKeyword            = []rune("=~")
+	ParenthesesOpenKeyword  = []rune("(")
+	ParenthesesCloseKeyword = []rune(")")
+	ColumnOpenKeyword       = []rune("[")
+	ColumnCloseKeyword      = []rune("]")
+	MinusKeyword            = []rune("-")
+	DotKeyword              = []rune(".")
+	Whitespaces             = sets.New(cmp.Compare, ' ', '\t', '\n')
+
+	// Operators are ordered to try longer operators first.
+	Operators = []Operator{OperatorEq, OperatorNe, OperatorLe, OperatorGe, OperatorLt, OperatorGt}
+)
+
+func parseOrExpression(s []rune, index int) (*Expr, int) {
+	e, newIndex := parseAndExpression(s, index)
+	if e == nil {
+		return nil, index
+	}
+
+	es := []*Expr{e}
+	for {
+		ok, afterOr := parser.ExpectKeyword(OrKeyword, s, newIndex)
+		if !ok {
+			break
+		}
+		afterOr = parser.SkipRune(Whitespaces, s, afterOr)
+
+		e, newIndex = parseAndExpression(s, afterOr)
+		if e == nil {
+			return nil, index
+		}
+		es = append(es, e)
+	}
+
+	if len(es) == 1 {
+		return es[0], newIndex
+	}
+	return &Expr{Type: ExprTypeOr, Operands: es}, newIndex
+}
+
+func parseAndExpression(s []rune, index int) (*Expr, int) {
+	e, newIndex := parseUnary(s, index)
+	if e == nil {
+		return nil, index
+	}
+
+	es := []*Expr{e}
+	for {
+		ok, afterAnd := parser.ExpectKeyword(AndKeyword, s, newIndex)
+		if !ok {
+			break
+		}
+		afterAnd = parser.SkipRune(Whitespaces, s, afterAnd)
+
+		e, newIndex = parseUnary(s, afterAnd)
+		if e == nil {
+			return nil, index
+		}
+		es = append(es, e)
+	}
+
+	if len(es) == 1 {
+		return es[0], newIndex
+	}
+	return &Expr{Type: ExprTypeAnd, Operands: es}, newIndex
+}
+
+func parseUnary(s []rune, index int) (*Expr, int) {
+	ok, newIndex := parser.ExpectKeyword(NotKeyword, s, index)
+	if ok {
+		newIndex = parser.SkipRune(Whitespaces, s, newIndex)
+		e, newIndex := parseUnary(s, newIndex)
+		if e == nil {
+			return nil, index
+		}
+		return &Expr{Type: ExprTypeNot, Operands: []*Expr{e}}, newIndex
+	}
+
+	lhs, newIndex := parseOperand(s, index)
+	if lhs == nil {
+		return nil, index
+	}
+
+	ok, afterMatch := parser.ExpectKeyword(MatchKeyword, s, newIndex)
+	if ok {
+		afterMatch = parser.SkipRune(Whitespaces, s, afterMatch)
+		ok, pattern, afterPattern := parseString(s, afterMatch)
+		if !ok {
+			return nil, index
+		}
+		return &Expr{Type: ExprTypeMatch, Operands: []*Expr{lhs}, rawPattern: pattern}, afterPattern
+	}
+
+	for _, op := range Operators {
+		ok, afterOp := parser.ExpectKeyword([]rune(op), s, newIndex)
+		if !ok {
+			continue
+		}
+		afterOp = parser.SkipRune(Whitespaces, s, afterOp)
+
+		rhs, afterRHS := parseOperand(s, afterOp)
+		if rhs == nil {
+			return nil, index
+		}
+		return &Expr{Type: ExprTypeCompare, Operator: op, Operands: []*Expr{lhs, rhs}}, afterRHS
+	}
+
+	return lhs, newIndex
+}
+
+func parseOperand(s []rune, index int) (*Expr, int) {
+	if e, newIndex := parseParenthesizedExpression(s, index); e != nil {
+		return e, newIndex
+	}
+
+	if e, newIndex := parseColumn(s, index); e != nil {
+		return e, newIndex
+	}
+
+	if ok, str, newIndex := parseString(s, index); ok {
+		return &Expr{Type: ExprTypeLiteral, Value: NewStringValue(str)}, newIndex
+	}
+
+	if ok, n, newIndex := parseNumber(s, index); ok {
+		return &Expr{Type: ExprTypeLiteral, Value: NewNumberValue(n)}, newIndex
+	}
+
+	return parseIdentifier(s, index)
+}
+
+func parseParenthesizedExpression(s []rune, index int) (*Expr, int) {
+	ok, newIndex := parser.ExpectKeyword(ParenthesesOpenKeyword, s, index)
+	if !ok {
+		return nil, index
+	}
+	newIndex = parser.SkipRune(Whitespaces, s, newIndex)
+
+	e, newIndex := parseOrExpression(s, newIndex)
+	if e == nil {
+		return nil, index
+	}
+
+	ok, newIndex = parser.ExpectKeyword(ParenthesesCloseKeyword, s, newIndex)
+	if !ok {
+		return nil, index
+	}
+	return e, parser.SkipRune(Whitespaces, s, newIndex)
+}
+
+func parseColumn(s []rune, index int) (*Expr, int) {
+	ok, newIndex := parser.ExpectKeyword(ColumnOpenKeyword, s, index)
+	if !ok {
+		return nil, index
+	}
+	newIndex = parser.SkipRune(Whitespaces, s, newIndex)
+
+	ok, header, newIndex := parseString(s, newIndex)
+	if !ok {
+		return nil, index
+	}
+
+	ok, newIndex = parser.ExpectKeyword(ColumnCloseKeyword, s, newIndex)
+	if !ok {
+		return nil, index
+	}
+	return &Expr{Type: ExprTypeColumn, Name: header}, parser.SkipRune(Whitespaces, s, newIndex)
+}
+
+func parseString(s []rune, index int) (bool, string, int) {
+	if index >= len(s) || (s[index] != '"' && s[index] != '\'') {
+		return false, "", index
+	}
+	quote := s[index]
+
+	var sb strings.Builder
+	newIndex := index + 1
+	for newIndex < len(s) {
+		r := s[newIndex]
+		switch r {
+		case quote:
+			return true, sb.String(), parser.SkipRune(Whitespaces, s, newIndex+1)
+		case '\\':
+			if newIndex+1 >= len(s) {
+				return false, "", index
+			}
+			sb.WriteRune(s[newIndex+1])
+			newIndex += 2
+		default:
+			sb.WriteRune(r)
+			newIndex++
+		}
+	}
+	return false, "", index
+}
+
+func parseNumber(s []rune, index int) (bool, float64, int) {
+	_, newIndex := parser.ExpectKeyword(MinusKeyword, s, index)
+
+	digits, newIndex := parser.AdvanceUntil(parser.IsDigit, s, newIndex)
+	if len(digits) == 0 {
+		return false, 0, index
+	}
+
+	ok, afterDot := parser.ExpectKeyword(DotKeyword, s, newIndex)
+	if ok {
+		digits, afterDot = parser.AdvanceUntil(parser.IsDigit, s, afterDot)
+		if len(digits) == 0 {
+			return false, 0, index
+		}
+		newIndex = afterDot
+	}
+
+	n, err := strconv.ParseFloat(string(s[index:newIndex]), 64)
+	if err != nil {
+		return false, 0, index
+	}
+	return true, n, parser.SkipRune(Whitespaces, s, newIndex)
+}
+
+var isIdentifierRune = parser.Or(parser.IsAlpha, parser.Contains(sets.New(cmp.Compare, '_')))
+
+func parseIdentifier(s []rune, index int) (*Expr, int) {
+	runes, newIndex := parser.AdvanceUntil(isIdentifierRune, s, index)
+	newIndex = parser.SkipRune(Whitespaces, s, newIndex)
+
+	switch name := string(runes); name {
+	case "true":
+		return &Expr{Type: ExprTypeLiteral, Value: NewBoolValue(true)}, newIndex
+	case "false":
+		return &Expr{Type: ExprTypeLiteral, Value: NewBoolValue(false)}, newIndex
+	case VariableID, VariableDescription, VariableInitial, VariableFinal:
+		return &Expr{Type: ExprTypeVariable, Name: name}, newIndex
+	default:
+		return nil, index
+	}
+}
+
+func compilePatterns(e *Expr) error {
+	if e.Type == ExprTypeMatch {
+		re, err := regexp.Compile(e.rawPattern)
+		if err != nil {
+			return fmt.Errorf("userrules.compilePatterns: %w", err)
+		}
+		e.Pattern = re
+	}
+	for _, operand := range e.Operands {
+		if err := compilePatterns(operand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Columns returns the headers of the columns that the expression refers to in the order of appearance.
+func (e *Expr) Columns() []string {
+	res := make([]string, 0)
+	var walk func(e *Expr)
+	walk = func(e *Expr) {
+		if e.Type == ExprTypeColumn && !slices.Contains(res, e.Name) {
+			res = append(res, e.Name)
+		}
+		for _, operand := range e.Operands {
+			walk(operand)
+		}
+	}
+	walk(e)
+	return res
+}
+
+// Eval returns the value of the expression on the element.
+func Eval(e *Expr, env *Env) (Value, error) {
+	switch e.Type {
+	case ExprTypeOr, ExprTypeAnd:
+		// NOTE: Short-circuit evaluation, so that rules like `["Volume"] == "" || ["Volume"] < 10` work.
+		shortCircuit := e.Type == ExprTypeOr
+		for _, operand := range e.Operands {
+			b, err := EvalBool(operand, env)
+			if err != nil {
+				return Value{}, fmt.Errorf("userrules.Eval: %w", err)
+			}
+			if b == shortCircuit {
+				return NewBoolValue(shortCircuit), nil
+			}
+		}
+		return NewBoolValue(!shortCircuit), nil
+
+	case ExprTypeNot:
+		b, err := EvalBool(e.Operands[0], env)
+		if err != nil {
+			return Value{}, fmt.Errorf("userrules.Eval: %w", err)
+		}
+		return NewBoolValue(!b), nil
+
+	case ExprTypeCompare:
+		lhs, err := Eval(e.Operands[0], env)
+		if err != nil {
+			return Value{}, fmt.Errorf("userrules.Eval: %w", err)
+		}
+		rhs, err := Eval(e.Operands[1], env)
+		if err != nil {
+			return Value{}, fmt.Errorf("userrules.Eval: %w", err)
+		}
+		b, err := compare(e.Operator, lhs, rhs)
+		if err != nil {
+			return Value{}, fmt.Errorf("userrules.Eval: %w", err)
+		}
+		return NewBoolValue(b), nil
+
+	case ExprTypeMatch:
+		v, err := Eval(e.Operands[0], env)
+		if err != nil {
+			return Value{}, fmt.Errorf("userrules.Eval: %w", err)
+		}
+		if v.Type != ValueTypeString {
+			return Value{}, fmt.Errorf("userrules.Eval: =~ expects a string, but got %s", v.Type)
+		}
+		return NewBoolValue(e.Pattern.MatchString(v.String)), nil
+
+	case ExprTypeLiteral:
+		return e.Value, nil
+
+	case ExprTypeVariable:
+		switch e.Name {
+		case VariableID:
+			return NewStringValue(env.ID), nil
+		case VariableDescription:
+			return NewStringValue(env.Description), nil
+		case VariableInitial:
+			return NewBoolValue(env.Initial), nil
+		case VariableFinal:
+			return NewBoolValue(env.Final), nil
+		default:
+			panic(fmt.Sprintf("userrules.Eval: unknown variable: %q", e.Name))
+		}
+
+	case ExprTypeColumn:
+		idx := slices.Index(env.Headers, e.Name)
+		if idx < 0 {
+			return Value{}, fmt.Errorf("userrules.Eval: missing column: %q", e.Name)
+		}
+		if env.Cells == nil {
+			return Value{}, fmt.Errorf("userrules.Eval: missing row: %q", env.ID)
+		}
+		if idx >= len(env.Cells) {
+			return NewStringValue(""), nil
+		}
+		return NewStringValue(env.Cells[idx]), nil
+
+	default:
+		panic(fmt.Sprintf("userrules.Eval: unknown expression type: %q", e.Type))
+	}
+}
+
+// EvalBool returns the value of the expression on the element. It returns an error if the value is not a boolean.
+func EvalBool(e *Expr, env *Env) (bool, error) {
+	v, err := Eval(e, env)
+	if err != nil {
+		return false, fmt.Errorf("userrules.EvalBool: %w", err)
+	}
+	if v.Type != ValueTypeBool {
+		return false, fmt.Errorf("userrules.EvalBool: expected a boolean, but got %s", v.Type)
+	}
+	return v.Bool, nil
+}
+
+// compare compares the values. Strings are compared as numbers if the other is a number or the operator is an
+// inequality, because cells of tables are always strings.
+func compare(op Operator, lhs, rhs Value) (bool, error) {
+	if lhs.Type == ValueTypeBool || rhs.Type == ValueTypeBool {
+		if lhs.Type != rhs.Type {
+			return false, fmt.Errorf("userrules.compare: cannot compare %s with %s", lhs.Type, rhs.Type)
+		}
+		switch op {
+		case OperatorEq:
+			return lhs.Bool == rhs.Bool, nil
+		case OperatorNe:
+			return lhs.Bool != rhs.Bool, nil
+		default:
+			return false, fmt.Errorf("userrules.compare: %s is not available for booleans", op)
+		}
+	}
+
+	var c int
+	if lhs.Type == ValueTypeString && rhs.Type == ValueTypeString && (op == OperatorEq || op == OperatorNe) {
+		c = strings.Compare(lhs.String, rhs.String)
+	} else {
+		l, err := toNumber(lhs)
+		if err != nil {
+			return false, fmt.Errorf("userrules.compare: %w", err)
+		}
+		r, err := toNumber(rhs)
+		if err != nil {
+			return false, fmt.Errorf("userrules.compare: %w", err)
+		}
+		c = cmp.Compare(l, r)
+	}
+
+	switch op {
+	case OperatorEq:
+		return c == 0, nil
+	case OperatorNe:
+		return c != 0, nil
+	case OperatorLt:
+		return c < 0, nil
+	case OperatorLe:
+		return c <= 0, nil
+	case OperatorGt:
+		return c > 0, nil
+	case OperatorGe:
+		return c >= 0, nil
+	default:
+		panic(fmt.Sprintf("userrules.compare: unknown operator: %q", op))
+	}
+}
+
+func toNumber(v Value) (float64, error) {
+	switch v.Type {
+	case ValueTypeNumber:
+		return v.Number, nil
+	case ValueTypeString:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v.String), 64)
+		if err != nil {
+			return 0, fmt.Errorf("userrules.toNumber: not a number: %q", v.String)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("userrules.toNumber: not a number: %s", v.Type)
+	}
+}
@@ -0,0 +1,144 @@
+package userrules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEval(t *testing.T) {
+	env := &Env{
+		ID:          "D12",
+		Description: "Design document",
+		Final:       true,
+		Headers:     []string{"Location", "Est. Work Volume"},
+		Cells:       []string{"", " 25 "},
+	}
+
+	testCases := map[string]struct {
+		Input    string
+		Expected Value
+	}{
+		"variable": {
+			Input:    `id`,
+			Expected: NewStringValue("D12"),
+		},
+		"column": {
+			Input:    `[ "Est. Work Volume" ]`,
+			Expected: NewStringValue(" 25 "),
+		},
+		"string equality": {
+			Input:    `["Location"] != ""`,
+			Expected: NewBoolValue(false),
+		},
+		"single quoted string": {
+			Input:    `description == 'Design document'`,
+			Expected: NewBoolValue(true),
+		},
+		"escaped string": {
+			Input:    `"\"\\" == '"\\'`,
+			Expected: NewBoolValue(true),
+		},
+		"numeric inequality": {
+			Input:    `["Est. Work Volume"] <= 20`,
+			Expected: NewBoolValue(false),
+		},
+		"numeric equality": {
+			Input:    `["Est. Work Volume"] == 25.0`,
+			Expected: NewBoolValue(true),
+		},
+		"negative number": {
+			Input:    `-1.5 < 0`,
+			Expected: NewBoolValue(true),
+		},
+		"match": {
+			Input:    `id =~ "^D[0-9]+$"`,
+			Expected: NewBoolValue(true),
+		},
+		"boolean": {
+			Input:    `final == true && !initial`,
+			Expected: NewBoolValue(true),
+		},
+		"precedence": {
+			Input:    `false && false || true`,
+			Expected: NewBoolValue(true),
+		},
+		"parentheses": {
+			Input:    ` false && (false || true) `,
+			Expected: NewBoolValue(false),
+		},
+		"short circuit": {
+			Input:    `true || ["Missing"] == ""`,
+			Expected: NewBoolValue(true),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			e, err := ParseExpr(testCase.Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := Eval(e, env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
+
+func TestParseExprError(t *testing.T) {
+	testCases := map[string]string{
+		"empty":                  ``,
+		"unknown variable":       `name == ""`,
+		"missing operand":        `id ==`,
+		"missing and operand":    `final &&`,
+		"unclosed string":        `id == "D1`,
+		"unclosed parentheses":   `(final`,
+		"unclosed column":        `["Location" == ""`,
+		"trailing garbage":       `final final`,
+		"non-string pattern":     `id =~ 1`,
+		"malformed pattern":      `id =~ "("`,
+		"column without a quote": `[Location] == ""`,
+	}
+
+	for name, input := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseExpr(input); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	env := &Env{
+		ID:      "P1",
+		Headers: []string{"Est. Work Volume"},
+		Cells:   []string{"TBD"},
+	}
+
+	testCases := map[string]string{
+		"missing column":    `["Location"] == ""`,
+		"not a number":      `["Est. Work Volume"] < 20`,
+		"boolean and other": `final == "true"`,
+		"boolean ordering":  `final < true`,
+		"match on boolean":  `final =~ "true"`,
+	}
+
+	for name, input := range testCases {
+		t.Run(name, func(t *testing.T) {
+			e, err := ParseExpr(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Eval(e, env); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
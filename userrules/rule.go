@@ -0,0 +1,188 @@
+package userrules
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/locale"
+)
+
+// Kind is the kind of elements that a rule checks.
+type Kind string
+
+const (
+	KindAtomicProcess        Kind = "atomic_process"
+	KindCompositeProcess     Kind = "composite_process"
+	KindAtomicDeliverable    Kind = "atomic_deliverable"
+	KindCompositeDeliverable Kind = "composite_deliverable"
+	KindResource             Kind = "resource"
+	KindMilestone            Kind = "milestone"
+	KindGroup                Kind = "group"
+)
+
+func (k Kind) IsValid() bool {
+	switch k {
+	case KindAtomicProcess, KindCompositeProcess, KindAtomicDeliverable, KindCompositeDeliverable, KindResource, KindMilestone, KindGroup:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rule is a user-defined rule. It reports the elements of the kind that satisfy Where but not Assert.
+type Rule struct {
+	// ID is the problem ID of the problems.
+	ID checkers.ProblemID `json:"id"`
+
+	// Severity is the severity of the problems. Available values are ERROR, WARNING and STYLE_PROBLEM. WARNING if empty.
+	Severity string `json:"severity,omitempty"`
+
+	// For is the kind of elements to check.
+	For Kind `json:"for"`
+
+	// Where is the expression to select elements to check. All the elements are checked if empty.
+	Where string `json:"where,omitempty"`
+
+	// Assert is the expression that the elements should satisfy.
+	Assert string `json:"assert"`
+
+	// Messages maps locales to the messages of the problems.
+	Messages map[locale.Locale]string `json:"messages"`
+}
+
+// CompiledRule is a validated Rule.
+type CompiledRule struct {
+	ID       checkers.ProblemID
+	Severity checkers.Severity
+	For      Kind
+
+	// Where is nil if all the elements are checked.
+	Where    *Expr
+	Assert   *Expr
+	Messages map[locale.Locale]string
+
+	// columns is the headers of the columns that Where and Assert refer to.
+	columns []string
+}
+
+// Compile validates the rules. Problem IDs of the rules should be unique.
+func Compile(rules []Rule) ([]*CompiledRule, error) {
+	res := make([]*CompiledRule, 0, len(rules))
+	ids := make(map[checkers.ProblemID]struct{}, len(rules))
+	for _, r := range rules {
+		if r.ID == "" {
+			return nil, fmt.Errorf("userrules.Compile: missing id")
+		}
+		if _, ok := ids[r.ID]; ok {
+			return nil, fmt.Errorf("userrules.Compile: duplicated id: %q", r.ID)
+		}
+		ids[r.ID] = struct{}{}
+
+		c, err := compileRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("userrules.Compile: %q: %w", r.ID, err)
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+func compileRule(r Rule) (*CompiledRule, error) {
+	severity := checkers.SeverityWarning
+	if r.Severity != "" {
+		var err error
+		severity, err = checkers.ParseSeverity(r.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("userrules.compileRule: %w", err)
+		}
+	}
+
+	if !r.For.IsValid() {
+		return nil, fmt.Errorf("userrules.compileRule: unknown kind: %q", r.For)
+	}
+
+	var where *Expr
+	if r.Where != "" {
+		var err error
+		where, err = ParseExpr(r.Where)
+		if err != nil {
+			return nil, fmt.Errorf("userrules.compileRule: where: %w", err)
+		}
+	}
+
+	assert, err := ParseExpr(r.Assert)
+	if err != nil {
+		return nil, fmt.Errorf("userrules.compileRule: assert: %w", err)
+	}
+
+	if len(r.Messages) == 0 {
+		return nil, fmt.Errorf("userrules.compileRule: missing messages")
+	}
+	for l := range r.Messages {
+		if _, err := locale.Parse(string(l)); err != nil || l == "" {
+			return nil, fmt.Errorf("userrules.compileRule: unknown locale: %q", l)
+		}
+	}
+
+	columns := assert.Columns()
+	if where != nil {
+		for _, column := range where.Columns() {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	return &CompiledRule{
+		ID:       r.ID,
+		Severity: severity,
+		For:      r.For,
+		Where:    where,
+		Assert:   assert,
+		Messages: r.Messages,
+		columns:  columns,
+	}, nil
+}
+
+// CheckColumns returns an error if the rule refers to columns not in the headers of the table of the kind.
+func (r *CompiledRule) CheckColumns(headers []string) error {
+	for _, column := range r.columns {
+		if !slices.Contains(headers, column) {
+			return fmt.Errorf("userrules.CompiledRule.CheckColumns: %q: missing column: %q", r.ID, column)
+		}
+	}
+	return nil
+}
+
+// Check returns true if the element violates the rule.
+func (r *CompiledRule) Check(env *Env) (bool, error) {
+	if r.Where != nil {
+		ok, err := EvalBool(r.Where, env)
+		if err != nil {
+			return false, fmt.Errorf("userrules.CompiledRule.Check: where: %w", err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	ok, err := EvalBool(r.Assert, env)
+	if err != nil {
+		return false, fmt.Errorf("userrules.CompiledRule.Check: assert: %w", err)
+	}
+	return !ok, nil
+}
+
+// report sends the problem if the element violates the rule. It returns an error if the rule cannot be evaluated on
+// the element, for example because of non-numeric cells, so that broken rules are not left unnoticed.
+func (r *CompiledRule) report(env *Env, loc checkers.Location, ch chan<- checkers.Problem) error {
+	violated, err := r.Check(env)
+	if err != nil {
+		return fmt.Errorf("userrules.CompiledRule.report: %q: %s: %w", r.ID, env.ID, err)
+	}
+	if violated {
+		ch <- checkers.NewProblem(r.ID, r.Severity, loc).WithMessages(r.Messages)
+	}
+	return nil
+}
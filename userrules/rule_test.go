@@ -0,0 +1,153 @@
+package userrules
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/Kuniwak/pfd-tools/chans"
+	"github.com/Kuniwak/pfd-tools/checkers"
+	"github.com/Kuniwak/pfd-tools/locale"
+	"github.com/Kuniwak/pfd-tools/pfd"
+	"github.com/Kuniwak/pfd-tools/pfd/pfdcheckers/pfdcommon"
+	"github.com/Kuniwak/pfd-tools/sets"
+	"github.com/Kuniwak/pfd-tools/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompileError(t *testing.T) {
+	messages := map[locale.Locale]string{locale.LocaleEn: "Bad."}
+
+	testCases := map[string][]Rule{
+		"missing id": {
+			{For: KindAtomicProcess, Assert: "true", Messages: messages},
+		},
+		"duplicated id": {
+			{ID: "r1", For: KindAtomicProcess, Assert: "true", Messages: messages},
+			{ID: "r1", For: KindAtomicDeliverable, Assert: "true", Messages: messages},
+		},
+		"unknown severity": {
+			{ID: "r1", Severity: "fatal", For: KindAtomicProcess, Assert: "true", Messages: messages},
+		},
+		"unknown kind": {
+			{ID: "r1", For: "edge", Assert: "true", Messages: messages},
+		},
+		"malformed where": {
+			{ID: "r1", For: KindAtomicProcess, Where: "(", Assert: "true", Messages: messages},
+		},
+		"missing assert": {
+			{ID: "r1", For: KindAtomicProcess, Messages: messages},
+		},
+		"missing messages": {
+			{ID: "r1", For: KindAtomicProcess, Assert: "true"},
+		},
+		"malformed locale": {
+			{ID: "r1", For: KindAtomicProcess, Assert: "true", Messages: map[locale.Locale]string{"fr_FR.UTF-8": "Mauvais."}},
+		},
+	}
+
+	for name, rules := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Compile(rules); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
+
+func TestNewPFDChecker(t *testing.T) {
+	// D1 -> P1 -> D2 -> P2 -> D3, P3
+	p := &pfd.PFD{
+		Nodes: sets.New(
+			(*pfd.Node).Compare,
+			&pfd.Node{ID: "D1", Type: pfd.NodeTypeAtomicDeliverable, Description: "D1"},
+			&pfd.Node{ID: "P1", Type: pfd.NodeTypeAtomicProcess, Description: "P1"},
+			&pfd.Node{ID: "D2", Type: pfd.NodeTypeAtomicDeliverable, Description: "D2"},
+			&pfd.Node{ID: "P2", Type: pfd.NodeTypeAtomicProcess, Description: "P2"},
+			&pfd.Node{ID: "D3", Type: pfd.NodeTypeAtomicDeliverable, Description: "D3"},
+			// NOTE: P3 is missing in the table.
+			&pfd.Node{ID: "P3", Type: pfd.NodeTypeAtomicProcess, Description: "P3"},
+		),
+		Edges: sets.New(
+			(*pfd.Edge).Compare,
+			&pfd.Edge{Source: "D1", Target: "P1"},
+			&pfd.Edge{Source: "P1", Target: "D2"},
+			&pfd.Edge{Source: "D2", Target: "P2"},
+			&pfd.Edge{Source: "P2", Target: "D3"},
+		),
+	}
+	apTable := &pfd.AtomicProcessTable{
+		ExtraHeaders: []string{"Est. Work Volume"},
+		Rows: []*pfd.AtomicProcessRow{
+			{ID: "P1", Description: "P1", ExtraCells: []string{"10"}},
+			{ID: "P2", Description: "P2", ExtraCells: []string{"30"}},
+		},
+	}
+	adTable := &pfd.AtomicDeliverableTable{
+		ExtraHeaders: []string{"Location"},
+		Rows: []*pfd.AtomicDeliverableRow{
+			{ID: "D1", Description: "D1", ExtraCells: []string{""}},
+			{ID: "D2", Description: "D2", ExtraCells: []string{""}},
+			{ID: "D3", Description: "D3", ExtraCells: []string{""}},
+		},
+	}
+
+	messages := map[locale.Locale]string{locale.LocaleEn: "Bad."}
+	testCases := map[string]struct {
+		Rule          Rule
+		Expected      []checkers.Problem
+		ExpectedError bool
+	}{
+		"final deliverables": {
+			Rule: Rule{ID: "final-location", Severity: "ERROR", For: KindAtomicDeliverable, Where: "final", Assert: `["Location"] != ""`, Messages: messages},
+			Expected: []checkers.Problem{
+				checkers.NewProblem("final-location", checkers.SeverityError, pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "D3")).WithMessages(messages),
+			},
+		},
+		"work volume": {
+			Rule: Rule{ID: "small-process", For: KindAtomicProcess, Assert: `["Est. Work Volume"] <= 20`, Messages: messages},
+			Expected: []checkers.Problem{
+				checkers.NewProblem("small-process", checkers.SeverityWarning, pfdcommon.NewLocation(pfdcommon.LocationTypePFD, "P2")).WithMessages(messages),
+			},
+		},
+		"missing column": {
+			Rule:          Rule{ID: "missing-column", For: KindAtomicProcess, Where: `["Missing"] == ""`, Assert: "true", Messages: messages},
+			Expected:      []checkers.Problem{},
+			ExpectedError: true,
+		},
+		"non-numeric cell": {
+			Rule:          Rule{ID: "numeric-location", For: KindAtomicDeliverable, Assert: `["Location"] < 10`, Messages: messages},
+			Expected:      []checkers.Problem{},
+			ExpectedError: true,
+		},
+		"missing table": {
+			Rule:          Rule{ID: "cp-volume", For: KindCompositeProcess, Assert: `["Volume"] != ""`, Messages: messages},
+			Expected:      []checkers.Problem{},
+			ExpectedError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := slog.New(slogtest.NewTestHandler(t))
+			rules, err := Compile([]Rule{testCase.Rule})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := pfdcommon.NewMemoized(p, logger)
+			ch := make(chan checkers.Problem)
+			go func() {
+				defer close(ch)
+				err := NewPFDChecker(rules, logger).Check(pfdcommon.NewTarget(p, apTable, adTable, nil, nil, m), ch)
+				if (err != nil) != testCase.ExpectedError {
+					t.Errorf("NewPFDChecker: %v, want error: %t", err, testCase.ExpectedError)
+				}
+			}()
+			actual := chans.Slice(ch)
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Error(cmp.Diff(testCase.Expected, actual))
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package xmldom
 
 import (
+	"bytes"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 )
 
 type NodeKind int
@@ -24,14 +27,53 @@ type Node struct {
 	Children []*Node
 	Data     []byte
 	PI       *xml.ProcInst
+
+	// raw is the original text of the node, or the start tag for elements. It is nil unless the node is parsed by
+	// ParseXMLPreservingFormat.
+	raw []byte
+
+	// rawEnd is the original end tag of elements. It is empty for self-closing elements.
+	rawEnd []byte
+
+	// rawAttr is the original attributes of elements to detect rewritten attributes.
+	rawAttr []xml.Attr
 }
 
 func ParseXML(r io.Reader) ([]*Node, error) {
+	nodes, err := parseXML(r, false)
+	if err != nil {
+		return nil, fmt.Errorf("xmldom.ParseXML: %w", err)
+	}
+	return nodes, nil
+}
+
+// ParseXMLPreservingFormat is the same as ParseXML, but the nodes remember the original text. WritePreservingFormat
+// writes it back, so that rewriting some attributes does not change the rest of the document.
+func ParseXMLPreservingFormat(r io.Reader) ([]*Node, error) {
+	nodes, err := parseXML(r, true)
+	if err != nil {
+		return nil, fmt.Errorf("xmldom.ParseXMLPreservingFormat: %w", err)
+	}
+	return nodes, nil
+}
+
+func parseXML(r io.Reader, preservesFormat bool) ([]*Node, error) {
+	var src []byte
+	if preservesFormat {
+		var err error
+		src, err = io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(src)
+	}
+
 	dec := xml.NewDecoder(r)
 	var root = &Node{Kind: ElementNode, Start: xml.StartElement{Name: xml.Name{Local: "TMP"}}}
 	stack := []*Node{root}
 
 	for {
+		start := dec.InputOffset()
 		tok, err := dec.Token()
 		if errors.Is(err, io.EOF) {
 			break
@@ -39,43 +81,56 @@ func ParseXML(r io.Reader) ([]*Node, error) {
 		if err != nil {
 			return nil, err
 		}
+		var raw []byte
+		if preservesFormat {
+			raw = src[start:dec.InputOffset()]
+		}
+
 		switch t := tok.(type) {
 		case xml.StartElement:
 			n := &Node{Kind: ElementNode, Start: t}
+			if preservesFormat {
+				n.raw = raw
+				n.rawAttr = slices.Clone(t.Attr)
+			}
 			parent := stack[len(stack)-1]
 			parent.Children = append(parent.Children, n)
 			stack = append(stack, n)
 		case xml.EndElement:
 			if len(stack) == 1 {
-				return nil, fmt.Errorf("xmldom.ParseXML: unexpected end element: %v", t.Name)
+				return nil, fmt.Errorf("unexpected end element: %v", t.Name)
 			}
 			cur := stack[len(stack)-1]
 			cur.End = t
+			if preservesFormat {
+				// NOTE: The decoder returns the end element of self-closing elements without consuming the input.
+				cur.rawEnd = raw
+			}
 			stack = stack[:len(stack)-1]
 		case xml.CharData:
 			b := append([]byte(nil), t...)
-			n := &Node{Kind: TextNode, Data: b}
+			n := &Node{Kind: TextNode, Data: b, raw: raw}
 			parent := stack[len(stack)-1]
 			parent.Children = append(parent.Children, n)
 		case xml.Comment:
 			b := append([]byte(nil), t...)
-			n := &Node{Kind: CommentNode, Data: b}
+			n := &Node{Kind: CommentNode, Data: b, raw: raw}
 			parent := stack[len(stack)-1]
 			parent.Children = append(parent.Children, n)
 		case xml.Directive:
 			b := append([]byte(nil), t...)
-			n := &Node{Kind: DirectiveNode, Data: b}
+			n := &Node{Kind: DirectiveNode, Data: b, raw: raw}
 			parent := stack[len(stack)-1]
 			parent.Children = append(parent.Children, n)
 		case xml.ProcInst:
 			pi := t
-			n := &Node{Kind: ProcInstNode, PI: &pi}
+			n := &Node{Kind: ProcInstNode, PI: &pi, raw: raw}
 			parent := stack[len(stack)-1]
 			parent.Children = append(parent.Children, n)
 		}
 	}
 	if len(stack) != 1 {
-		return nil, fmt.Errorf("xmldom.ParseXML: unclosed elements: %d", len(stack)-1)
+		return nil, fmt.Errorf("unclosed elements: %d", len(stack)-1)
 	}
 	return root.Children, nil
 }
@@ -92,6 +147,96 @@ func (n *Node) Write(w io.Writer) error {
 	return nil
 }
 
+// WritePreservingFormat writes the node as the original text parsed by ParseXMLPreservingFormat. Only start tags
+// with rewritten attributes and nodes without the original text are encoded again.
+func (n *Node) WritePreservingFormat(w io.Writer) error {
+	if err := n.writePreservingFormat(w); err != nil {
+		return fmt.Errorf("xmldom.Node.WritePreservingFormat: %w", err)
+	}
+	return nil
+}
+
+func (n *Node) writePreservingFormat(w io.Writer) error {
+	if n.raw == nil {
+		enc := xml.NewEncoder(w)
+		if err := n.EncodeTo(enc); err != nil {
+			return err
+		}
+		return enc.Flush()
+	}
+
+	if n.Kind != ElementNode {
+		_, err := w.Write(n.raw)
+		return err
+	}
+
+	selfClosing := len(n.rawEnd) == 0 && len(n.Children) == 0
+	if slices.Equal(n.Start.Attr, n.rawAttr) {
+		if _, err := w.Write(n.raw); err != nil {
+			return err
+		}
+	} else {
+		if _, err := io.WriteString(w, encodeStartTag(n.Start, selfClosing)); err != nil {
+			return err
+		}
+	}
+
+	for _, ch := range n.Children {
+		if err := ch.writePreservingFormat(w); err != nil {
+			return err
+		}
+	}
+
+	if len(n.rawEnd) > 0 {
+		_, err := w.Write(n.rawEnd)
+		return err
+	}
+	if selfClosing {
+		return nil
+	}
+	_, err := io.WriteString(w, "</"+qualifiedName(n.End.Name)+">")
+	return err
+}
+
+func encodeStartTag(start xml.StartElement, selfClosing bool) string {
+	sb := &strings.Builder{}
+	sb.WriteString("<")
+	sb.WriteString(qualifiedName(start.Name))
+	for _, attr := range start.Attr {
+		sb.WriteString(" ")
+		sb.WriteString(qualifiedName(attr.Name))
+		sb.WriteString(`="`)
+		sb.WriteString(attrEscaper.Replace(attr.Value))
+		sb.WriteString(`"`)
+	}
+	if selfClosing {
+		sb.WriteString("/>")
+	} else {
+		sb.WriteString(">")
+	}
+	return sb.String()
+}
+
+// NOTE: The decoder resolves prefixes of known namespaces to URLs, so the names of rewritten elements in such
+// namespaces are not restored.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// attrEscaper escapes attribute values in the same way as draw.io.
+var attrEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"\n", "&#10;",
+	"\r", "&#13;",
+	"\t", "&#9;",
+)
+
 func (n *Node) EncodeTo(enc *xml.Encoder) error {
 	switch n.Kind {
 	case ElementNode:
@@ -145,6 +290,10 @@ func (n *Node) Clone() *Node {
 		End:      n.End,
 		Children: make([]*Node, len(n.Children)),
 		Data:     n.Data,
+		PI:       n.PI,
+		raw:      n.raw,
+		rawEnd:   n.rawEnd,
+		rawAttr:  n.rawAttr,
 	}
 	for i, ch := range n.Children {
 		cloned.Children[i] = ch.Clone()
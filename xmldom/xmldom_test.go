@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -435,3 +436,67 @@ func openFile(filePath string) io.Reader {
 	}
 	return f
 }
+
+func TestWritePreservingFormat(t *testing.T) {
+	src := `<?xml version="1.0" encoding="UTF-8"?>
+<!-- comment -->
+<mxfile host='65bd71144e'>
+  <root>
+    <mxCell id="2" value="D1: &lt;b&gt;Spec&lt;/b&gt;" style="rounded=0;html=1;"/>
+    <mxCell id="3" value="P1" parent="1" ><mxGeometry as="geometry" /></mxCell>
+  </root>
+</mxfile>
+`
+
+	testCases := map[string]struct {
+		Rewrite  func(node *Node, attr xml.Attr) xml.Attr
+		Expected string
+	}{
+		"not rewritten": {
+			Rewrite:  func(_ *Node, attr xml.Attr) xml.Attr { return attr },
+			Expected: src,
+		},
+		"rewritten": {
+			Rewrite: func(node *Node, attr xml.Attr) xml.Attr {
+				if attr.Name.Local != "value" {
+					return attr
+				}
+				id, _ := node.GetAttr("id", "")
+				if id == "3" {
+					attr.Value = "P1: \"Implement\"\n&"
+				}
+				return attr
+			},
+			Expected: `<?xml version="1.0" encoding="UTF-8"?>
+<!-- comment -->
+<mxfile host='65bd71144e'>
+  <root>
+    <mxCell id="2" value="D1: &lt;b&gt;Spec&lt;/b&gt;" style="rounded=0;html=1;"/>
+    <mxCell id="3" value="P1: &quot;Implement&quot;&#10;&amp;" parent="1"><mxGeometry as="geometry" /></mxCell>
+  </root>
+</mxfile>
+`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			nodes, err := ParseXMLPreservingFormat(strings.NewReader(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sb := &strings.Builder{}
+			for _, node := range nodes {
+				node.RewriteAttr(testCase.Rewrite)
+				if err := node.WritePreservingFormat(sb); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if sb.String() != testCase.Expected {
+				t.Error(cmp.Diff(testCase.Expected, sb.String()))
+			}
+		})
+	}
+}